@@ -15,6 +15,7 @@ import (
 
 	_ "github.com/lib/pq"
 	"github.com/raulc0399/cpc/internal/database"
+	"github.com/raulc0399/cpc/internal/normalizer"
 )
 
 // Azure regions list - all available regions
@@ -95,6 +96,11 @@ func (p *ProgressTracker) getStatus() (int, int, int, map[int]string, time.Durat
 }
 
 func main() {
+	// Configure structured logging from LOG_LEVEL and LOG_FORMAT
+	if _, err := normalizer.SetupLogging(normalizer.LogConfigFromEnv()); err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+
 	log.Printf("🌍 Starting Azure all-regions data collection")
 
 	// Connect to database
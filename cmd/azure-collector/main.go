@@ -11,6 +11,7 @@ import (
 
 	"github.com/raulc0399/cpc/internal/azure"
 	"github.com/raulc0399/cpc/internal/database"
+	"github.com/raulc0399/cpc/internal/normalizer"
 )
 
 func main() {
@@ -27,8 +28,14 @@ func main() {
 		tracking   = flag.Bool("tracking", true, "Enable progress tracking")
 		listProfiles = flag.Bool("list-profiles", false, "List available profiles")
 	)
+	logConfig := normalizer.LogConfigFromEnv()
+	logConfig.RegisterFlags(flag.CommandLine)
 	flag.Parse()
 
+	if _, err := normalizer.SetupLogging(logConfig); err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+
 	// List available profiles
 	if *listProfiles {
 		profiles := azure.CreateProfileConfigs()
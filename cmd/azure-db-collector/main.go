@@ -13,6 +13,7 @@ import (
 
 	_ "github.com/lib/pq"
 	"github.com/raulc0399/cpc/internal/database"
+	"github.com/raulc0399/cpc/internal/normalizer"
 )
 
 // AzurePricingResponse represents the API response
@@ -51,6 +52,11 @@ type AzurePricingItem struct {
 }
 
 func main() {
+	// Configure structured logging from LOG_LEVEL and LOG_FORMAT
+	if _, err := normalizer.SetupLogging(normalizer.LogConfigFromEnv()); err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+
 	fmt.Println("Azure Database Collector - Fetching and Storing Pricing Data")
 	fmt.Println("===========================================================")
 	fmt.Println()
@@ -9,6 +9,8 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/raulc0399/cpc/internal/normalizer"
 )
 
 // AzurePricingResponse represents the API response
@@ -54,6 +56,11 @@ type ServiceExample struct {
 }
 
 func main() {
+	// Configure structured logging from LOG_LEVEL and LOG_FORMAT
+	if _, err := normalizer.SetupLogging(normalizer.LogConfigFromEnv()); err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+
 	fmt.Println("Azure Pricing API Explorer - Focused Query")
 	fmt.Println("==========================================")
 	fmt.Println()
@@ -9,6 +9,8 @@ import (
 	"net/url"
 	"os"
 	"time"
+
+	"github.com/raulc0399/cpc/internal/normalizer"
 )
 
 // AzurePricingResponse represents the API response
@@ -62,6 +64,11 @@ type ServiceSummary struct {
 }
 
 func main() {
+	// Configure structured logging from LOG_LEVEL and LOG_FORMAT
+	if _, err := normalizer.SetupLogging(normalizer.LogConfigFromEnv()); err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+
 	fmt.Println("Azure Full Pricing Collector - Complete Region Analysis")
 	fmt.Println("======================================================")
 	fmt.Println()
@@ -13,6 +13,7 @@ import (
 
 	_ "github.com/lib/pq"
 	"github.com/raulc0399/cpc/internal/database"
+	"github.com/raulc0399/cpc/internal/normalizer"
 )
 
 // AzureAPIResponse represents the Azure pricing API response
@@ -26,6 +27,11 @@ type AzureAPIResponse struct {
 }
 
 func main() {
+	// Configure structured logging from LOG_LEVEL and LOG_FORMAT
+	if _, err := normalizer.SetupLogging(normalizer.LogConfigFromEnv()); err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+
 	// Get region from command line arg or default to eastus
 	region := "eastus"
 	if len(os.Args) > 1 {
@@ -10,9 +10,15 @@ import (
 	_ "github.com/lib/pq"
 	"github.com/raulc0399/cpc/internal/database"
 	"github.com/raulc0399/cpc/internal/etl"
+	"github.com/raulc0399/cpc/internal/normalizer"
 )
 
 func main() {
+	// Configure structured logging from LOG_LEVEL and LOG_FORMAT
+	if _, err := normalizer.SetupLogging(normalizer.LogConfigFromEnv()); err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+
 	// Connect to database
 	connStr := os.Getenv("DATABASE_URL")
 	if connStr == "" {
@@ -1,22 +1,56 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	_ "github.com/lib/pq"
+	"github.com/raulc0399/cpc/internal/currency"
 	"github.com/raulc0399/cpc/internal/database"
 	"github.com/raulc0399/cpc/internal/etl"
 	"github.com/raulc0399/cpc/internal/graph"
+	"github.com/raulc0399/cpc/internal/normalizer"
+	"github.com/raulc0399/cpc/internal/snapshot"
 )
 
 const defaultPort = "8080"
 
 func main() {
+	// Configure structured logging from LOG_LEVEL/LOG_FORMAT or the -log-level/-log-format flags
+	logConfig := normalizer.LogConfigFromEnv()
+	logConfig.RegisterFlags(flag.CommandLine)
+	snapshotPath := flag.String("snapshot", os.Getenv("SNAPSHOT_PATH"), "serve the pricing read path from a snapshot file instead of Postgres")
+	flag.Parse()
+
+	logger, err := normalizer.SetupLogging(logConfig)
+	if err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+
+	// Get port from environment
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = defaultPort
+	}
+
+	if *snapshotPath != "" {
+		serveSnapshot(*snapshotPath, port, logger)
+		return
+	}
+
 	// Connect to database
 	dbConn, err := sql.Open("postgres", os.Getenv("DATABASE_URL"))
 	if err != nil {
@@ -32,15 +66,59 @@ func main() {
 	// Create database handler
 	db := database.New(dbConn)
 
+	// Refuse to run against a schema that is missing migrations
+	if err := db.CheckSchema(context.Background()); err != nil {
+		log.Fatalf("Schema check failed: %v", err)
+	}
+
 	// Create ETL pipeline
 	pipeline, err := etl.NewPipeline(db)
 	if err != nil {
 		log.Fatalf("Failed to create ETL pipeline: %v", err)
 	}
 
+	// Sync service and region mappings from the mapping config file, reloading it on change
+	if path := os.Getenv("MAPPING_CONFIG_FILE"); path != "" {
+		reloadInterval := 30 * time.Second
+		if value := os.Getenv("MAPPING_CONFIG_RELOAD_INTERVAL"); value != "" {
+			if reloadInterval, err = time.ParseDuration(value); err != nil {
+				log.Fatalf("Invalid MAPPING_CONFIG_RELOAD_INTERVAL: %v", err)
+			}
+		}
+		if _, err := pipeline.LoadMappingConfig(context.Background(), path, reloadInterval); err != nil {
+			log.Fatalf("Failed to load mapping config: %v", err)
+		}
+	}
+
+	// Recover ETL jobs interrupted by a previous shutdown
+	interrupted, err := pipeline.RecoverInterruptedJobs(context.Background())
+	if err != nil {
+		logger.Error("Failed to recover interrupted ETL jobs", normalizer.Field{Key: "error", Value: err})
+	}
+	for _, job := range interrupted {
+		if os.Getenv("ETL_AUTO_RESUME") == "true" {
+			if _, err := pipeline.ResumeJob(job.ID); err != nil {
+				logger.Error("Failed to resume ETL job", normalizer.Field{Key: normalizer.LogKeyJobID, Value: job.ID}, normalizer.Field{Key: "error", Value: err})
+				continue
+			}
+			logger.Info("Resumed interrupted ETL job", normalizer.Field{Key: normalizer.LogKeyJobID, Value: job.ID})
+		} else {
+			logger.Warn("ETL job was interrupted; resume it with the resumeETLJob mutation", normalizer.Field{Key: normalizer.LogKeyJobID, Value: job.ID})
+		}
+	}
+
+	// Create currency conversion service, loading exchange rates from a file if configured
+	currencyService := currency.NewService(db)
+	if path := os.Getenv("EXCHANGE_RATES_FILE"); path != "" {
+		if _, err := currencyService.Load(context.Background(), currency.NewFileRateSource(path)); err != nil {
+			logger.Error("Failed to load exchange rates", normalizer.Field{Key: "path", Value: path}, normalizer.Field{Key: "error", Value: err})
+		}
+	}
+
 	// Create resolver
 	resolver := &graph.Resolver{
-		DB: db,
+		DB:       db,
+		Currency: currencyService,
 	}
 	resolver.SetPipeline(pipeline)
 
@@ -50,6 +128,8 @@ func main() {
 	// Set up routes
 	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
 	http.Handle("/query", srv)
+	http.HandleFunc("/etl/dry-run-report", dryRunReportHandler(pipeline, logger))
+	registerPricingRoutes(db, currencyService, logger)
 
 	// Add the population endpoints from the original server
 	http.HandleFunc("/populate", populateHandler(db))
@@ -59,21 +139,633 @@ func main() {
 	http.HandleFunc("/aws-populate-comprehensive", awsPopulateComprehensiveHandler(db))
 	http.HandleFunc("/aws-populate-everything", awsPopulateEverythingHandler(db))
 
-	// Get port from environment
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = defaultPort
+	logger.Info("Starting GraphQL server",
+		normalizer.Field{Key: "url", Value: fmt.Sprintf("http://localhost:%s/", port)},
+		normalizer.Field{Key: "playground", Value: fmt.Sprintf("http://localhost:%s/", port)},
+		normalizer.Field{Key: "introspection", Value: fmt.Sprintf("http://localhost:%s/query", port)},
+	)
+
+	if err := http.ListenAndServe(":"+port, normalizer.LogRequests(logger, http.DefaultServeMux)); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+}
+
+// serveSnapshot serves the GraphQL API and the pricing endpoints from a pricing snapshot, for
+// offline analysis. ETL jobs, alerts and collection need Postgres and are not available.
+func serveSnapshot(path, port string, logger normalizer.Logger) {
+	store, err := snapshot.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open snapshot: %v", err)
+	}
+	defer store.Close()
+
+	if info, err := store.Info(context.Background()); err == nil {
+		logger.Info("Serving pricing snapshot", normalizer.Field{Key: "path", Value: path}, normalizer.Field{Key: "exportedAt", Value: info.CreatedAt.Format(time.RFC3339)})
 	}
 
-	log.Printf("Starting GraphQL server on http://localhost:%s/", port)
-	log.Printf("GraphQL playground available at http://localhost:%s/", port)
-	log.Printf("GraphQL introspection is enabled at http://localhost:%s/query", port)
+	currencyService := currency.NewService(store)
+	if path := os.Getenv("EXCHANGE_RATES_FILE"); path != "" {
+		if _, err := currencyService.Load(context.Background(), currency.NewFileRateSource(path)); err != nil {
+			logger.Error("Failed to load exchange rates", normalizer.Field{Key: "path", Value: path}, normalizer.Field{Key: "error", Value: err})
+		}
+	}
 
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
+	resolver := &graph.Resolver{
+		Pricing:  store,
+		Currency: currencyService,
+	}
+	srv := handler.NewDefaultServer(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
+
+	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
+	http.Handle("/query", srv)
+	registerPricingRoutes(store, currencyService, logger)
+
+	logger.Info("Starting GraphQL server", normalizer.Field{Key: "url", Value: fmt.Sprintf("http://localhost:%s/", port)})
+	if err := http.ListenAndServe(":"+port, normalizer.LogRequests(logger, http.DefaultServeMux)); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
 
+// registerPricingRoutes registers the REST endpoints of the pricing read path
+func registerPricingRoutes(repo database.PricingRepository, currencyService *currency.Service, logger normalizer.Logger) {
+	http.HandleFunc("/pricing/normalized", normalizedPricingHandler(repo, currencyService, logger))
+	http.HandleFunc("/pricing/timeline", priceTimelineHandler(repo, currencyService, logger))
+	http.HandleFunc("/pricing/search", searchHandler(repo, currencyService, logger))
+	http.HandleFunc("/pricing/compare", comparePricingHandler(repo, currencyService, logger))
+}
+
+// dryRunReportHandler serves the diff report of a dry-run ETL job as a downloadable JSON file
+func dryRunReportHandler(pipeline *etl.Pipeline, logger normalizer.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobID := r.URL.Query().Get("jobId")
+		if jobID == "" {
+			http.Error(w, "jobId parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		job, exists := pipeline.GetJob(jobID)
+		if !exists {
+			http.Error(w, fmt.Sprintf("job not found: %s", jobID), http.StatusNotFound)
+			return
+		}
+
+		if job.DryRunReport == nil {
+			http.Error(w, fmt.Sprintf("no dry-run report available for job %s", jobID), http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"dry-run-%s.json\"", jobID))
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(job.DryRunReport); err != nil {
+			normalizer.LoggerFromContext(r.Context(), logger).Error("Failed to encode dry-run report",
+				normalizer.Field{Key: normalizer.LogKeyJobID, Value: jobID}, normalizer.Field{Key: "error", Value: err})
+		}
+	}
+}
+
+// normalizedPricingItem is a normalized pricing record with the cost of the requested usage and
+// the exchange rate applied to its prices
+type normalizedPricingItem struct {
+	database.NormalizedPricing
+	EstimatedCost *float64             `json:"estimatedCost,omitempty"`
+	Conversion    *currency.Conversion `json:"conversion,omitempty"`
+}
+
+// normalizedPricingHandler serves normalized pricing records, including price tiers and amortized
+// commitment rates. The optional quantity parameter adds the estimated cost of that usage to each
+// record, and orderBy=effective_hourly_rate sorts commitments by their amortized rate. The
+// currency parameter converts prices to that currency; priceCurrency filters by stored currency.
+// Only prices valid at the asOf date (YYYY-MM-DD or RFC 3339, default now) are returned; an
+// explicit asOf returns the prices that were live at that date.
+func normalizedPricingHandler(repo database.PricingRepository, currencyService *currency.Service, logger normalizer.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		filter := database.PricingFilter{}
+
+		optional := func(name string) *string {
+			if value := params.Get(name); value != "" {
+				return &value
+			}
+			return nil
+		}
+		filter.Provider = optional("provider")
+		filter.ServiceCategory = optional("serviceCategory")
+		filter.ServiceFamily = optional("serviceFamily")
+		filter.ServiceType = optional("serviceType")
+		filter.NormalizedRegion = optional("region")
+		filter.PricingModel = optional("pricingModel")
+		filter.Currency = optional("priceCurrency")
+		filter.OperatingSystem = optional("operatingSystem")
+		filter.LicenseModel = optional("licenseModel")
+		filter.PreInstalledSoftware = optional("preInstalledSoftware")
+		filter.Tenancy = optional("tenancy")
+		filter.OrderBy = optional("orderBy")
+		filter.OrderDirection = optional("orderDirection")
+
+		// Comma-separated lists match any of their values
+		list := func(name string) []string {
+			var values []string
+			for _, value := range strings.Split(params.Get(name), ",") {
+				if value = strings.TrimSpace(value); value != "" {
+					values = append(values, value)
+				}
+			}
+			return values
+		}
+		filter.Providers = list("providers")
+		filter.NormalizedRegions = list("regions")
+		filter.PricingModels = list("pricingModels")
+
+		specs, err := parseSpecFilter(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.Specs = specs
+		if err := filter.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Only prices valid at asOf, by default now, so future-dated prices don't show up. An
+		// explicit asOf is a point-in-time query answered from the price history.
+		asOf := time.Now()
+		if value := params.Get("asOf"); value != "" {
+			parsed, err := database.ParseAsOf(value)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			asOf = parsed
+			filter.History = true
+		}
+		filter.AsOf = &asOf
+
+		limit := database.DefaultPricingQueryLimit
+		if value := params.Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 || parsed > database.MaxPricingQueryLimit {
+				http.Error(w, fmt.Sprintf("limit must be between 1 and %d", database.MaxPricingQueryLimit), http.StatusBadRequest)
+				return
+			}
+			limit = parsed
+		}
+		filter.Limit = &limit
+
+		if value := params.Get("offset"); value != "" {
+			offset, err := strconv.Atoi(value)
+			if err != nil || offset < 0 {
+				http.Error(w, "offset must be a non-negative integer", http.StatusBadRequest)
+				return
+			}
+			filter.Offset = &offset
+		}
+
+		var quantity *float64
+		if value := params.Get("quantity"); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil || parsed < 0 {
+				http.Error(w, "quantity must be a non-negative number", http.StatusBadRequest)
+				return
+			}
+			quantity = &parsed
+		}
+
+		targetCurrency, err := parseTargetCurrency(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		pricings, err := repo.QueryNormalizedPricing(filter)
+		if errors.Is(err, database.ErrPriceHistoryUnavailable) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			normalizer.LoggerFromContext(r.Context(), logger).Error("Failed to query normalized pricing", normalizer.Field{Key: "error", Value: err})
+			http.Error(w, "failed to query normalized pricing", http.StatusInternalServerError)
+			return
+		}
+
+		var conversions []*currency.Conversion
+		if targetCurrency != "" {
+			conversions, err = currencyService.ConvertPricings(r.Context(), pricings, targetCurrency, asOf)
+			if err != nil {
+				writeConversionError(w, r, logger, err)
+				return
+			}
+		}
+
+		items := make([]normalizedPricingItem, len(pricings))
+		for i, pricing := range pricings {
+			items[i] = normalizedPricingItem{NormalizedPricing: pricing}
+			if conversions != nil {
+				items[i].Conversion = conversions[i]
+			}
+			if quantity != nil {
+				cost := pricing.TotalCost(*quantity)
+				items[i].EstimatedCost = &cost
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{
+			"count": len(items),
+			"items": items,
+		}); err != nil {
+			normalizer.LoggerFromContext(r.Context(), logger).Error("Failed to encode normalized pricing", normalizer.Field{Key: "error", Value: err})
+		}
+	}
+}
+
+// parseSpecFilter reads the resource spec parameters of the pricing endpoint, returning nil
+// when none is set
+func parseSpecFilter(params url.Values) (*database.SpecFilter, error) {
+	specs := &database.SpecFilter{}
+	set := false
+
+	intParam := func(name string, target **int) error {
+		if value := params.Get(name); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%s must be an integer", name)
+			}
+			*target = &parsed
+			set = true
+		}
+		return nil
+	}
+	floatParam := func(name string, target **float64) error {
+		if value := params.Get(name); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("%s must be a number", name)
+			}
+			*target = &parsed
+			set = true
+		}
+		return nil
+	}
+
+	for _, err := range []error{
+		intParam("minVcpu", &specs.MinVCPU),
+		intParam("maxVcpu", &specs.MaxVCPU),
+		floatParam("minMemoryGb", &specs.MinMemoryGB),
+		floatParam("maxMemoryGb", &specs.MaxMemoryGB),
+		intParam("minGpuCount", &specs.MinGPUCount),
+		intParam("maxGpuCount", &specs.MaxGPUCount),
+		floatParam("minGpuMemoryGb", &specs.MinGPUMemoryGB),
+		floatParam("maxGpuMemoryGb", &specs.MaxGPUMemoryGB),
+		floatParam("minStorageGb", &specs.MinStorageGB),
+		floatParam("maxStorageGb", &specs.MaxStorageGB),
+		floatParam("minClockSpeedGhz", &specs.MinClockSpeedGHz),
+		floatParam("maxClockSpeedGhz", &specs.MaxClockSpeedGHz),
+	} {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if value := params.Get("architecture"); value != "" {
+		specs.Architecture = &value
+		set = true
+	}
+	if value := params.Get("processorVendor"); value != "" {
+		specs.ProcessorVendor = &value
+		set = true
+	}
+	if value := params.Get("burstable"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("burstable must be true or false")
+		}
+		specs.Burstable = &parsed
+		set = true
+	}
+
+	if !set {
+		return nil, nil
+	}
+	return specs, nil
+}
+
+// parseTargetCurrency returns the validated currency parameter that prices are converted to,
+// or an empty string when no conversion is requested
+func parseTargetCurrency(params url.Values) (string, error) {
+	target := params.Get("currency")
+	if target == "" {
+		return "", nil
+	}
+	if _, err := currency.NormalizeCode(target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// writeConversionError responds with 400 for an unsupported currency or a missing exchange
+// rate and 500 otherwise
+func writeConversionError(w http.ResponseWriter, r *http.Request, logger normalizer.Logger, err error) {
+	if currency.IsRequestError(err) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	normalizer.LoggerFromContext(r.Context(), logger).Error("Failed to convert prices", normalizer.Field{Key: "error", Value: err})
+	http.Error(w, "failed to convert prices", http.StatusInternalServerError)
+}
+
+// convertPricingRecords converts the pricing records of a response to the target currency in
+// place with the latest effective exchange rate, returning the conversion used for each
+func convertPricingRecords(ctx context.Context, currencyService *currency.Service, pricings []*database.NormalizedPricing, targetCurrency string) ([]*currency.Conversion, error) {
+	conversions := make([]*currency.Conversion, len(pricings))
+	asOf := time.Now()
+	for i, pricing := range pricings {
+		conversion, err := currencyService.ConvertPricing(ctx, pricing, targetCurrency, asOf)
+		if err != nil {
+			return nil, err
+		}
+		conversions[i] = conversion
+	}
+	return conversions, nil
+}
+
+// priceHistoryItem is a recorded price version with the exchange rate applied to its prices
+type priceHistoryItem struct {
+	database.PriceHistoryEntry
+	Conversion *currency.Conversion `json:"conversion,omitempty"`
+}
+
+// priceTimelineHandler serves every recorded version of the prices of a provider SKU, e.g.
+// /pricing/timeline?provider=aws&sku=ABC123&region=eu-west-1. The currency parameter converts
+// every version with the latest effective exchange rate, so only price changes show.
+func priceTimelineHandler(repo database.PricingRepository, currencyService *currency.Service, logger normalizer.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		provider := params.Get("provider")
+		sku := params.Get("sku")
+		if provider == "" || sku == "" {
+			http.Error(w, "provider and sku are required", http.StatusBadRequest)
+			return
+		}
+
+		var region *string
+		if value := params.Get("region"); value != "" {
+			region = &value
+		}
+
+		targetCurrency, err := parseTargetCurrency(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		entries, err := repo.GetPriceTimeline(r.Context(), provider, sku, region)
+		if err != nil {
+			normalizer.LoggerFromContext(r.Context(), logger).Error("Failed to query price timeline",
+				normalizer.Field{Key: normalizer.LogKeyProvider, Value: provider}, normalizer.Field{Key: "sku", Value: sku}, normalizer.Field{Key: "error", Value: err})
+			http.Error(w, "failed to query price timeline", http.StatusInternalServerError)
+			return
+		}
+
+		items := make([]priceHistoryItem, len(entries))
+		pricings := make([]*database.NormalizedPricing, len(entries))
+		for i, entry := range entries {
+			items[i] = priceHistoryItem{PriceHistoryEntry: entry}
+			pricings[i] = &items[i].NormalizedPricing
+		}
+		if targetCurrency != "" {
+			conversions, err := convertPricingRecords(r.Context(), currencyService, pricings, targetCurrency)
+			if err != nil {
+				writeConversionError(w, r, logger, err)
+				return
+			}
+			for i := range items {
+				items[i].Conversion = conversions[i]
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{
+			"count":   len(items),
+			"entries": items,
+		}); err != nil {
+			normalizer.LoggerFromContext(r.Context(), logger).Error("Failed to encode price timeline", normalizer.Field{Key: "error", Value: err})
+		}
+	}
+}
+
+// searchHitItem is a search hit with the exchange rate applied to its prices
+type searchHitItem struct {
+	database.PricingSearchHit
+	Conversion *currency.Conversion `json:"conversion,omitempty"`
+}
+
+// searchHandler serves ranked, typo-tolerant search of live normalized prices. q is required;
+// provider, region, category and pricingModel narrow the results and their facet counts, and
+// currency converts the prices of the hits.
+func searchHandler(repo database.PricingRepository, currencyService *currency.Service, logger normalizer.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		req := database.PricingSearchRequest{Query: strings.TrimSpace(params.Get("q"))}
+		if req.Query == "" {
+			http.Error(w, "q parameter is required", http.StatusBadRequest)
+			return
+		}
+
+		optional := func(name string) *string {
+			if value := params.Get(name); value != "" {
+				return &value
+			}
+			return nil
+		}
+		req.Provider = optional("provider")
+		req.NormalizedRegion = optional("region")
+		req.ServiceCategory = optional("category")
+		req.PricingModel = optional("pricingModel")
+
+		if value := params.Get("limit"); value != "" {
+			limit, err := strconv.Atoi(value)
+			if err != nil || limit <= 0 || limit > database.MaxSearchLimit {
+				http.Error(w, fmt.Sprintf("limit must be between 1 and %d", database.MaxSearchLimit), http.StatusBadRequest)
+				return
+			}
+			req.Limit = limit
+		}
+		if value := params.Get("offset"); value != "" {
+			offset, err := strconv.Atoi(value)
+			if err != nil || offset < 0 {
+				http.Error(w, "offset must be a non-negative integer", http.StatusBadRequest)
+				return
+			}
+			req.Offset = offset
+		}
+
+		targetCurrency, err := parseTargetCurrency(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := repo.SearchNormalizedPricing(r.Context(), req)
+		if err != nil {
+			normalizer.LoggerFromContext(r.Context(), logger).Error("Failed to search pricing",
+				normalizer.Field{Key: "query", Value: req.Query}, normalizer.Field{Key: "error", Value: err})
+			http.Error(w, "failed to search pricing", http.StatusInternalServerError)
+			return
+		}
+
+		hits := make([]searchHitItem, len(result.Hits))
+		pricings := make([]*database.NormalizedPricing, len(result.Hits))
+		for i, hit := range result.Hits {
+			hits[i] = searchHitItem{PricingSearchHit: hit}
+			pricings[i] = &hits[i].NormalizedPricing
+		}
+		if targetCurrency != "" {
+			conversions, err := convertPricingRecords(r.Context(), currencyService, pricings, targetCurrency)
+			if err != nil {
+				writeConversionError(w, r, logger, err)
+				return
+			}
+			for i := range hits {
+				hits[i].Conversion = conversions[i]
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{
+			"query":  result.Query,
+			"total":  result.Total,
+			"hits":   hits,
+			"facets": result.Facets,
+		}); err != nil {
+			normalizer.LoggerFromContext(r.Context(), logger).Error("Failed to encode search results", normalizer.Field{Key: "error", Value: err})
+		}
+	}
+}
+
+// pricingComparisonItem is a pricing comparison with the exchange rates applied to its offers
+type pricingComparisonItem struct {
+	database.PricingComparison
+	Conversions *currency.ComparisonConversions `json:"conversions,omitempty"`
+}
+
+// comparePricingHandler serves the AWS and Azure offers of a service type paired by resource
+// similarity, e.g. /pricing/compare?serviceType=Virtual%20Machines&region=us-east&pricingModel=on_demand.
+// The matching tolerances and weights default to database.DefaultMatchingConfig and can be set
+// with the parameters of the GraphQL MatchingConfigInput, e.g. &vcpuTolerance=0.5&topN=5. The
+// currency parameter converts both offers and the price difference.
+func comparePricingHandler(repo database.PricingRepository, currencyService *currency.Service, logger normalizer.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		serviceType := params.Get("serviceType")
+		region := params.Get("region")
+		if serviceType == "" || region == "" {
+			http.Error(w, "serviceType and region are required", http.StatusBadRequest)
+			return
+		}
+		pricingModel := params.Get("pricingModel")
+		if pricingModel == "" {
+			pricingModel = database.PricingModelOnDemand
+		}
+
+		config, err := matchingConfigFromQuery(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		targetCurrency, err := parseTargetCurrency(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		comparisons, err := repo.ComparePricingWithConfig(serviceType, region, pricingModel, config)
+		if err != nil {
+			normalizer.LoggerFromContext(r.Context(), logger).Error("Failed to compare pricing",
+				normalizer.Field{Key: "serviceType", Value: serviceType}, normalizer.Field{Key: normalizer.LogKeyRegion, Value: region}, normalizer.Field{Key: "error", Value: err})
+			http.Error(w, "failed to compare pricing", http.StatusInternalServerError)
+			return
+		}
+
+		var conversions []currency.ComparisonConversions
+		if targetCurrency != "" {
+			conversions, err = currencyService.ConvertComparisons(r.Context(), comparisons, targetCurrency, time.Now())
+			if err != nil {
+				writeConversionError(w, r, logger, err)
+				return
+			}
+		}
+
+		items := make([]pricingComparisonItem, len(comparisons))
+		for i, comparison := range comparisons {
+			items[i] = pricingComparisonItem{PricingComparison: comparison}
+			if conversions != nil {
+				items[i].Conversions = &conversions[i]
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]interface{}{
+			"count":       len(items),
+			"comparisons": items,
+		}); err != nil {
+			normalizer.LoggerFromContext(r.Context(), logger).Error("Failed to encode pricing comparison", normalizer.Field{Key: "error", Value: err})
+		}
+	}
+}
+
+// matchingConfigFromQuery applies the matching parameters of a compare request over the default
+// matching configuration
+func matchingConfigFromQuery(params url.Values) (database.MatchingConfig, error) {
+	config := database.DefaultMatchingConfig()
+
+	floats := map[string]*float64{
+		"vcpuTolerance":      &config.VCPUTolerance,
+		"memoryTolerance":    &config.MemoryTolerance,
+		"gpuTolerance":       &config.GPUTolerance,
+		"storageTolerance":   &config.StorageTolerance,
+		"vcpuWeight":         &config.VCPUWeight,
+		"memoryWeight":       &config.MemoryWeight,
+		"gpuWeight":          &config.GPUWeight,
+		"architectureWeight": &config.ArchitectureWeight,
+		"storageWeight":      &config.StorageWeight,
+		"minScore":           &config.MinScore,
+	}
+	for name, target := range floats {
+		if value := params.Get(name); value != "" {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return config, fmt.Errorf("%s must be a number", name)
+			}
+			*target = parsed
+		}
+	}
+
+	if value := params.Get("requireSameArchitecture"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return config, fmt.Errorf("requireSameArchitecture must be true or false")
+		}
+		config.RequireSameArchitecture = parsed
+	}
+	if value := params.Get("topN"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return config, fmt.Errorf("topN must be an integer")
+		}
+		config.TopN = parsed
+	}
+
+	if err := config.Validate(); err != nil {
+		return config, fmt.Errorf("invalid matching configuration: %w", err)
+	}
+	return config, nil
+}
+
 // Placeholder handlers - these would need to be implemented with the actual logic
 // from the original server
 
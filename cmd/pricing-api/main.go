@@ -1,29 +1,49 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/raulc0399/cpc/internal/currency"
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/raulc0399/cpc/internal/normalizer"
 )
 
 // PricingResponse represents pricing data for cloud services
 type PricingResponse struct {
-	Provider string                 `json:"provider"`
-	Region   string                 `json:"region"`
-	Compute  map[string]float64     `json:"compute"`
-	Storage  map[string]float64     `json:"storage"`
-	Transfer map[string]float64     `json:"transfer"`
-	Raw      map[string]interface{} `json:"raw,omitempty"`
+	Provider   string                 `json:"provider"`
+	Region     string                 `json:"region"`
+	Currency   string                 `json:"currency"`
+	Compute    map[string]float64     `json:"compute"`
+	Storage    map[string]float64     `json:"storage"`
+	Transfer   map[string]float64     `json:"transfer"`
+	Raw        map[string]interface{} `json:"raw,omitempty"`
+	Conversion *currency.Conversion   `json:"conversion,omitempty"`
 }
 
 var db *sql.DB
 
+// summaries reads the price summary tables the ETL refreshes after every job
+var summaries *database.DB
+
+// currencyService converts the USD prices of responses to a requested currency
+var currencyService *currency.Service
+
+// logger is the structured logger configured by LOG_LEVEL/LOG_FORMAT or the logging flags
+var logger normalizer.Logger = normalizer.NewDefaultLogger()
+
 // Valid region patterns
 var awsRegionPattern = regexp.MustCompile(`^[a-z]{2}-[a-z]+-\d+$`)
 var azureRegionPattern = regexp.MustCompile(`^[a-z]+[a-z0-9]*$`)
@@ -62,7 +82,7 @@ func authMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		
 		// Skip auth if no API key is configured (development mode)
 		if apiKey == "" {
-			log.Println("Warning: No API_KEY configured - running in development mode")
+			normalizer.LoggerFromContext(r.Context(), logger).Warn("No API_KEY configured - running in development mode")
 			next(w, r)
 			return
 		}
@@ -79,8 +99,18 @@ func authMiddleware(next http.HandlerFunc) http.HandlerFunc {
 }
 
 func main() {
+	// Configure structured logging from LOG_LEVEL/LOG_FORMAT or the -log-level/-log-format flags
+	logConfig := normalizer.LogConfigFromEnv()
+	logConfig.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	slogLogger, err := normalizer.SetupLogging(logConfig)
+	if err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+	logger = slogLogger
+
 	// Connect to database with connection pooling
-	var err error
 	db, err = sql.Open("postgres", os.Getenv("DATABASE_URL"))
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
@@ -97,13 +127,30 @@ func main() {
 		log.Fatalf("Failed to ping database: %v", err)
 	}
 
-	log.Println("Connected to database successfully!")
+	logger.Info("Connected to database")
+
+	summaries = database.New(db)
+
+	// Refuse to run against a schema that is missing migrations
+	if err := summaries.CheckSchema(context.Background()); err != nil {
+		log.Fatalf("Schema check failed: %v", err)
+	}
+
+	// Load exchange rates for the currency parameter
+	currencyService = currency.NewService(summaries)
+	if path := os.Getenv("EXCHANGE_RATES_FILE"); path != "" {
+		if _, err := currencyService.Load(context.Background(), currency.NewFileRateSource(path)); err != nil {
+			logger.Error("Failed to load exchange rates", normalizer.Field{Key: "path", Value: path}, normalizer.Field{Key: "error", Value: err})
+		}
+	}
 
 	// Set up routes with CORS and authentication
 	http.HandleFunc("/", corsMiddleware(homeHandler))
 	http.HandleFunc("/pricing/aws", corsMiddleware(authMiddleware(awsPricingHandler)))
 	http.HandleFunc("/pricing/azure", corsMiddleware(authMiddleware(azurePricingHandler)))
 	http.HandleFunc("/pricing/unified", corsMiddleware(authMiddleware(unifiedPricingHandler)))
+	http.HandleFunc("/pricing/compute-summary", corsMiddleware(authMiddleware(computeSummaryHandler)))
+	http.HandleFunc("/pricing/price-index", corsMiddleware(authMiddleware(priceIndexHandler)))
 	http.HandleFunc("/health", corsMiddleware(healthHandler))  // Health check doesn't need auth
 
 	// Start server
@@ -112,13 +159,19 @@ func main() {
 		port = "8080"
 	}
 
-	log.Printf("Starting pricing API server on http://localhost:%s/", port)
-	log.Printf("Endpoints:")
-	log.Printf("  GET /pricing/aws?region=us-east-1")
-	log.Printf("  GET /pricing/azure?region=eastus")
-	log.Printf("  GET /pricing/unified?aws_region=us-east-1&azure_region=eastus")
+	logger.Info("Starting pricing API server",
+		normalizer.Field{Key: "url", Value: fmt.Sprintf("http://localhost:%s/", port)},
+		normalizer.Field{Key: "endpoints", Value: []string{
+			"GET /pricing/aws?region=us-east-1",
+			"GET /pricing/azure?region=eastus",
+			"GET /pricing/unified?aws_region=us-east-1&azure_region=eastus",
+			"GET /pricing/compute-summary?provider=aws&region=us-east-1&min_vcpu=4&arch=arm64",
+			"GET /pricing/price-index?region=westeurope&category=Storage",
+		}},
+		normalizer.Field{Key: "hint", Value: "add currency=EUR to any pricing endpoint to convert prices"},
+	)
 
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
+	if err := http.ListenAndServe(":"+port, normalizer.LogRequests(logger, http.DefaultServeMux)); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
@@ -131,6 +184,8 @@ func homeHandler(w http.ResponseWriter, r *http.Request) {
 			"aws_pricing":     "/pricing/aws?region=us-east-1",
 			"azure_pricing":   "/pricing/azure?region=eastus",
 			"unified_pricing": "/pricing/unified?aws_region=us-east-1&azure_region=eastus",
+			"compute_summary": "/pricing/compute-summary?provider=aws&region=us-east-1&min_vcpu=4",
+			"price_index":     "/pricing/price-index?region=westeurope&category=Storage",
 			"health":          "/health",
 		},
 	}
@@ -182,6 +237,11 @@ func awsPricingHandler(w http.ResponseWriter, r *http.Request) {
 	response.Transfer["in"] = 0.0
 	response.Transfer["out"] = 0.09
 
+	if err := convertPricingResponse(r, &response); err != nil {
+		writeConversionError(w, r, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
@@ -222,6 +282,11 @@ func azurePricingHandler(w http.ResponseWriter, r *http.Request) {
 	response.Transfer["in"] = 0.0
 	response.Transfer["out"] = 0.0877
 
+	if err := convertPricingResponse(r, &response); err != nil {
+		writeConversionError(w, r, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
@@ -236,75 +301,287 @@ func unifiedPricingHandler(w http.ResponseWriter, r *http.Request) {
 		azureRegion = "eastus"
 	}
 
+	awsResponse := PricingResponse{
+		Provider: "aws",
+		Region:   awsRegion,
+		Compute:  getAWSComputePrices(awsRegion),
+		Storage:  getAWSStoragePrices(awsRegion),
+		Transfer: map[string]float64{"in": 0.0, "out": 0.09},
+	}
+	azureResponse := PricingResponse{
+		Provider: "azure",
+		Region:   azureRegion,
+		Compute:  getAzureComputePrices(azureRegion),
+		Storage:  getAzureStoragePrices(azureRegion),
+		Transfer: map[string]float64{"in": 0.0, "out": 0.0877},
+	}
+	if err := convertPricingResponse(r, &awsResponse, &azureResponse); err != nil {
+		writeConversionError(w, r, err)
+		return
+	}
+
 	response := map[string]interface{}{
-		"aws": PricingResponse{
-			Provider: "aws",
-			Region:   awsRegion,
-			Compute:  getAWSComputePrices(awsRegion),
-			Storage:  getAWSStoragePrices(awsRegion),
-			Transfer: map[string]float64{"in": 0.0, "out": 0.09},
-		},
-		"azure": PricingResponse{
-			Provider: "azure",
-			Region:   azureRegion,
-			Compute:  getAzureComputePrices(azureRegion),
-			Storage:  getAzureStoragePrices(azureRegion),
-			Transfer: map[string]float64{"in": 0.0, "out": 0.0877},
-		},
+		"aws":   awsResponse,
+		"azure": azureResponse,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// convertPricingResponse converts the USD prices of pricing responses to the currency
+// requested by the currency query parameter, if any
+func convertPricingResponse(r *http.Request, responses ...*PricingResponse) error {
+	for _, response := range responses {
+		response.Currency = currency.PivotCurrency
+	}
+
+	conversion, err := requestedConversion(r)
+	if err != nil || conversion == nil {
+		return err
+	}
+
+	for _, response := range responses {
+		conversion.ApplyAll(response.Compute)
+		conversion.ApplyAll(response.Storage)
+		conversion.ApplyAll(response.Transfer)
+		response.Currency = conversion.ToCurrency
+		response.Conversion = conversion
+	}
+	return nil
+}
+
+// requestedConversion returns the latest effective conversion of USD prices to the currency
+// requested by the currency query parameter, or nil when none is requested
+func requestedConversion(r *http.Request) (*currency.Conversion, error) {
+	target := r.URL.Query().Get("currency")
+	if target == "" {
+		return nil, nil
+	}
+	return currencyService.Conversion(r.Context(), currency.PivotCurrency, target, time.Now())
+}
+
+// writeConversionError responds with 400 for an unsupported currency and 500 otherwise
+func writeConversionError(w http.ResponseWriter, r *http.Request, err error) {
+	if currency.IsRequestError(err) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	normalizer.LoggerFromContext(r.Context(), logger).Error("Failed to convert prices", normalizer.Field{Key: "error", Value: err})
+	http.Error(w, "failed to convert prices", http.StatusInternalServerError)
+}
+
+// computeSummaryHandler returns the cheapest on-demand instance per region and shape from the
+// compute price summary. A requested currency converts the USD rows.
+func computeSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := database.ComputePriceSummaryFilter{
+		Providers:         splitParam(query.Get("provider")),
+		Regions:           splitParam(query.Get("region")),
+		CheapestPerRegion: query.Get("cheapest_per_region") == "true",
+	}
+
+	var err error
+	if filter.MinVCPU, err = intParam(query, "min_vcpu"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.MaxVCPU, err = intParam(query, "max_vcpu"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.MinMemoryGB, err = floatParam(query, "min_memory_gb"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.MaxMemoryGB, err = floatParam(query, "max_memory_gb"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Limit, err = intParam(query, "limit"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if arch := query.Get("arch"); arch != "" {
+		filter.Architecture = &arch
+	}
+	if operatingSystem := query.Get("os"); operatingSystem != "" {
+		filter.OperatingSystem = &operatingSystem
+	}
+
+	conversion, err := requestedConversion(r)
+	if err != nil {
+		writeConversionError(w, r, err)
+		return
+	}
+	if conversion != nil {
+		filter.Currency = &conversion.FromCurrency
+	}
+
+	items, err := summaries.GetComputePriceSummaries(r.Context(), filter)
+	if err != nil {
+		normalizer.LoggerFromContext(r.Context(), logger).Error("Failed to query compute price summary", normalizer.Field{Key: "error", Value: err})
+		http.Error(w, "failed to query compute price summary", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"count": len(items),
+		"items": items,
+	}
+	if conversion != nil {
+		for i := range items {
+			items[i].PricePerHour = conversion.Apply(items[i].PricePerHour)
+			items[i].Currency = conversion.ToCurrency
+		}
+		response["conversion"] = conversion
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// priceIndexHandler returns the price index of regions per category and unit. A requested
+// currency converts the prices of the USD rows; the index itself is a ratio.
+func priceIndexHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := database.RegionPriceIndexFilter{
+		Providers: splitParam(query.Get("provider")),
+		Regions:   splitParam(query.Get("region")),
+	}
+	if category := query.Get("category"); category != "" {
+		filter.ServiceCategory = &category
+	}
+	if unit := query.Get("unit"); unit != "" {
+		filter.Unit = &unit
+	}
+
+	conversion, err := requestedConversion(r)
+	if err != nil {
+		writeConversionError(w, r, err)
+		return
+	}
+	if conversion != nil {
+		filter.Currency = &conversion.FromCurrency
+	}
+
+	items, err := summaries.GetRegionPriceIndices(r.Context(), filter)
+	if err != nil {
+		normalizer.LoggerFromContext(r.Context(), logger).Error("Failed to query region price index", normalizer.Field{Key: "error", Value: err})
+		http.Error(w, "failed to query region price index", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]interface{}{
+		"count": len(items),
+		"items": items,
+	}
+	if conversion != nil {
+		for i := range items {
+			items[i].MedianPrice = conversion.Apply(items[i].MedianPrice)
+			items[i].MinPrice = conversion.Apply(items[i].MinPrice)
+			items[i].Currency = conversion.ToCurrency
+		}
+		response["conversion"] = conversion
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
+// splitParam splits a comma-separated query parameter
+func splitParam(value string) []string {
+	var values []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+func intParam(query url.Values, name string) (*int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", name, value)
+	}
+	return &parsed, nil
+}
+
+func floatParam(query url.Values, name string) (*float64, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", name, value)
+	}
+	return &parsed, nil
+}
+
 // Database query functions
 
-func getAWSComputePrices(region string) map[string]float64 {
+// getSummaryComputePrices returns the cheapest Linux on-demand instance of every shape in a
+// region from the compute price summary, keyed by instance name
+func getSummaryComputePrices(provider, region string, key func(resourceName string) string) map[string]float64 {
 	prices := make(map[string]float64)
-	
-	// Map region to AWS location name
-	locationMap := map[string]string{
-		"us-east-1":      "US East (N. Virginia)",
-		"us-west-2":      "US West (Oregon)",
-		"eu-west-1":      "EU (Ireland)",
-		"ap-southeast-1": "Asia Pacific (Singapore)",
-	}
-	
-	location, ok := locationMap[region]
-	if !ok {
-		location = region
+
+	operatingSystem := "linux"
+	currencyCode := currency.PivotCurrency
+	items, err := summaries.GetComputePriceSummaries(context.Background(), database.ComputePriceSummaryFilter{
+		Providers:       []string{provider},
+		Regions:         []string{region},
+		OperatingSystem: &operatingSystem,
+		Currency:        &currencyCode,
+	})
+	if err != nil {
+		logger.Error("Failed to query compute price summary",
+			normalizer.Field{Key: normalizer.LogKeyProvider, Value: provider}, normalizer.Field{Key: normalizer.LogKeyRegion, Value: region}, normalizer.Field{Key: "error", Value: err})
+		return prices
 	}
 
-	// Query for common instance types
-	instanceTypes := []string{"t3.micro", "t3.small", "t3.medium", "m5.large", "m5.xlarge", "c5.large", "c5.xlarge", "r5.large", "r5.xlarge"}
-	
-	for _, instanceType := range instanceTypes {
-		query := `
-			SELECT 
-				CAST(data->'terms'->'OnDemand'->jsonb_object_keys(data->'terms'->'OnDemand')->jsonb_object_keys(
-					data->'terms'->'OnDemand'->jsonb_object_keys(data->'terms'->'OnDemand')
-				)->'priceDimensions'->jsonb_object_keys(
-					data->'terms'->'OnDemand'->jsonb_object_keys(data->'terms'->'OnDemand')->jsonb_object_keys(
-						data->'terms'->'OnDemand'->jsonb_object_keys(data->'terms'->'OnDemand')
-					)->'priceDimensions'
-				)->'pricePerUnit'->>'USD' AS FLOAT)
-			FROM aws_pricing_raw
-			WHERE service_code = 'AmazonEC2'
-			AND data->>'productFamily' = 'Compute Instance'
-			AND data->'attributes'->>'instanceType' = $1
-			AND data->'attributes'->>'location' = $2
-			AND data->'terms'->'OnDemand' IS NOT NULL
-			LIMIT 1
-		`
-		
-		var price float64
-		err := db.QueryRow(query, instanceType, location).Scan(&price)
-		if err == nil && price > 0 {
-			// Convert instance type to key format
-			key := strings.Replace(instanceType, ".", "_", -1)
-			prices["ec2_"+key] = price
+	for _, item := range items {
+		name := key(item.ResourceName)
+		if _, ok := prices[name]; !ok {
+			prices[name] = item.PricePerHour
 		}
 	}
+	return prices
+}
+
+// getSummaryEgressPrice returns the median per-GB networking price of a region from the
+// region price index
+func getSummaryEgressPrice(provider, region string) (float64, bool) {
+	category := database.CategoryNetworking
+	unit := "gb"
+	currencyCode := currency.PivotCurrency
+	items, err := summaries.GetRegionPriceIndices(context.Background(), database.RegionPriceIndexFilter{
+		Providers:       []string{provider},
+		Regions:         []string{region},
+		ServiceCategory: &category,
+		Unit:            &unit,
+		Currency:        &currencyCode,
+	})
+	if err != nil {
+		logger.Error("Failed to query region price index",
+			normalizer.Field{Key: normalizer.LogKeyProvider, Value: provider}, normalizer.Field{Key: normalizer.LogKeyRegion, Value: region}, normalizer.Field{Key: "error", Value: err})
+		return 0, false
+	}
+	if len(items) == 0 || items[0].MedianPrice <= 0 {
+		return 0, false
+	}
+	return items[0].MedianPrice, true
+}
+
+func getAWSComputePrices(region string) map[string]float64 {
+	prices := getSummaryComputePrices("aws", region, func(resourceName string) string {
+		return "ec2_" + strings.Replace(resourceName, ".", "_", -1)
+	})
 
 	// Add fallback prices if nothing found
 	if len(prices) == 0 {
@@ -385,41 +662,7 @@ func getAWSStoragePrices(region string) map[string]float64 {
 }
 
 func getAWSEgressPricing(region string) float64 {
-	// Map region to AWS location name
-	locationMap := map[string]string{
-		"us-east-1":      "US East (N. Virginia)",
-		"us-west-2":      "US West (Oregon)",
-		"eu-west-1":      "EU (Ireland)",
-		"ap-southeast-1": "Asia Pacific (Singapore)",
-	}
-	
-	location, ok := locationMap[region]
-	if !ok {
-		location = region
-	}
-	
-	// Query for AWS data transfer out pricing
-	query := `
-		SELECT 
-			CAST(data->'terms'->'OnDemand'->jsonb_object_keys(data->'terms'->'OnDemand')->jsonb_object_keys(
-				data->'terms'->'OnDemand'->jsonb_object_keys(data->'terms'->'OnDemand')
-			)->'priceDimensions'->jsonb_object_keys(
-				data->'terms'->'OnDemand'->jsonb_object_keys(data->'terms'->'OnDemand')->jsonb_object_keys(
-					data->'terms'->'OnDemand'->jsonb_object_keys(data->'terms'->'OnDemand')
-				)->'priceDimensions'
-			)->'pricePerUnit'->>'USD' AS FLOAT)
-		FROM aws_pricing_raw
-		WHERE service_code = 'AmazonEC2'
-		AND data->>'productFamily' = 'Data Transfer'
-		AND data->'attributes'->>'transferType' = 'AWS Outbound'
-		AND data->'attributes'->>'location' = $1
-		AND data->'terms'->'OnDemand' IS NOT NULL
-		LIMIT 1
-	`
-	
-	var price float64
-	err := db.QueryRow(query, location).Scan(&price)
-	if err == nil && price > 0 {
+	if price, ok := getSummaryEgressPrice("aws", region); ok {
 		return price
 	}
 	
@@ -428,30 +671,9 @@ func getAWSEgressPricing(region string) float64 {
 }
 
 func getAzureComputePrices(region string) map[string]float64 {
-	prices := make(map[string]float64)
-	
-	// Query for common VM sizes
-	vmSizes := []string{"Standard_B1s", "Standard_B2s", "Standard_D2s_v3", "Standard_D4s_v3", "Standard_F2s_v2", "Standard_F4s_v2", "Standard_NC8as_T4_v3", "Standard_NC16as_T4_v3"}
-	
-	for _, vmSize := range vmSizes {
-		query := `
-			SELECT CAST(data->>'retailPrice' AS FLOAT)
-			FROM azure_pricing_raw
-			WHERE data->>'serviceName' = 'Virtual Machines'
-			AND data->>'armRegionName' = $1
-			AND data->>'armSkuName' = $2
-			AND data->>'type' = 'Consumption'
-			LIMIT 1
-		`
-		
-		var price float64
-		err := db.QueryRow(query, region, vmSize).Scan(&price)
-		if err == nil && price > 0 {
-			// Convert VM size to key format
-			key := strings.ToLower(strings.Replace(vmSize, "Standard_", "vm_", 1))
-			prices[key] = price
-		}
-	}
+	prices := getSummaryComputePrices("azure", region, func(resourceName string) string {
+		return strings.ToLower(strings.Replace(resourceName, "Standard_", "vm_", 1))
+	})
 
 	// Add fallback prices if nothing found
 	if len(prices) == 0 {
@@ -513,35 +735,7 @@ func getAzureStoragePrices(region string) map[string]float64 {
 }
 
 func getAzureEgressPricing(region string) float64 {
-	// Query for Azure bandwidth/egress pricing
-	query := `
-		SELECT CAST(data->>'retailPrice' AS FLOAT)
-		FROM azure_pricing_raw
-		WHERE data->>'serviceName' = 'Bandwidth'
-		AND data->>'armRegionName' = $1
-		AND data->>'meterName' = 'Standard Data Transfer Out'
-		AND data->>'type' = 'Consumption'
-		LIMIT 1
-	`
-	
-	var price float64
-	err := db.QueryRow(query, region).Scan(&price)
-	if err == nil && price > 0 {
-		return price
-	}
-	
-	// Try without region filter
-	query = `
-		SELECT CAST(data->>'retailPrice' AS FLOAT)
-		FROM azure_pricing_raw
-		WHERE data->>'serviceName' = 'Bandwidth'
-		AND data->>'meterName' = 'Standard Data Transfer Out'
-		AND data->>'type' = 'Consumption'
-		LIMIT 1
-	`
-	
-	err = db.QueryRow(query).Scan(&price)
-	if err == nil && price > 0 {
+	if price, ok := getSummaryEgressPrice("azure", region); ok {
 		return price
 	}
 	
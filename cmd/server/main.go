@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"log"
@@ -15,8 +17,11 @@ import (
 	"time"
 
 	"github.com/lib/pq"
+	"github.com/raulc0399/cpc/internal/currency"
 	"github.com/raulc0399/cpc/internal/database"
+	"github.com/raulc0399/cpc/internal/normalizer"
 	"github.com/raulc0399/cpc/internal/optimization"
+	"github.com/raulc0399/cpc/internal/snapshot"
 )
 
 // GraphQL request structure
@@ -63,6 +68,28 @@ type AzureAPIResponse struct {
 }
 
 func main() {
+	// Configure structured logging from LOG_LEVEL/LOG_FORMAT or the -log-level/-log-format flags
+	logConfig := normalizer.LogConfigFromEnv()
+	logConfig.RegisterFlags(flag.CommandLine)
+	snapshotPath := flag.String("snapshot", os.Getenv("SNAPSHOT_PATH"), "serve the region optimizer from a pricing snapshot file instead of Postgres")
+	flag.Parse()
+
+	logger, err := normalizer.SetupLogging(logConfig)
+	if err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+
+	// Get port from environment
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	if *snapshotPath != "" {
+		serveSnapshot(*snapshotPath, port, logger)
+		return
+	}
+
 	// Get database URL from environment
 	dbURL := os.Getenv("DATABASE_URL")
 	if dbURL == "" {
@@ -86,34 +113,41 @@ func main() {
 	// Create database handler
 	dbHandler := database.New(db)
 
-	// Get port from environment
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// Refuse to run against a schema that is missing migrations
+	if err := dbHandler.CheckSchema(context.Background()); err != nil {
+		log.Fatalf("Schema check failed: %v", err)
 	}
 
 	// Create region optimizer
 	regionOptimizer := optimization.NewRegionOptimizer(db)
 
+	// Create currency conversion service, loading exchange rates from a file if configured
+	currencyService := currency.NewService(dbHandler)
+	if path := os.Getenv("EXCHANGE_RATES_FILE"); path != "" {
+		if _, err := currencyService.Load(context.Background(), currency.NewFileRateSource(path)); err != nil {
+			log.Printf("Failed to load exchange rates: %v", err)
+		}
+	}
+
 	// Set up routes
 	http.HandleFunc("/", playgroundHandler)
-	http.HandleFunc("/query", graphQLHandler(dbHandler))
-	http.HandleFunc("/populate", populateHandler(dbHandler))
-	http.HandleFunc("/populate-all", populateAllHandler(dbHandler))
-	http.HandleFunc("/aws-populate", awsPopulateHandler(dbHandler))
-	http.HandleFunc("/aws-populate-all", awsPopulateAllHandler(dbHandler))
-	http.HandleFunc("/aws-populate-comprehensive", awsPopulateComprehensiveHandler(dbHandler))
-	http.HandleFunc("/aws-populate-everything", awsPopulateEverythingHandler(dbHandler))
+	http.HandleFunc("/query", graphQLHandler(dbHandler, logger))
+	http.HandleFunc("/populate", populateHandler(dbHandler, logger))
+	http.HandleFunc("/populate-all", populateAllHandler(dbHandler, logger))
+	http.HandleFunc("/aws-populate", awsPopulateHandler(dbHandler, logger))
+	http.HandleFunc("/aws-populate-all", awsPopulateAllHandler(dbHandler, logger))
+	http.HandleFunc("/aws-populate-comprehensive", awsPopulateComprehensiveHandler(dbHandler, logger))
+	http.HandleFunc("/aws-populate-everything", awsPopulateEverythingHandler(dbHandler, logger))
 	
 	// Region optimization endpoints
-	http.HandleFunc("/optimize-regions", optimizeRegionsHandler(regionOptimizer))
-	http.HandleFunc("/compare-regions", compareRegionsHandler(regionOptimizer))
+	http.HandleFunc("/optimize-regions", optimizeRegionsHandler(regionOptimizer, logger))
+	http.HandleFunc("/compare-regions", compareRegionsHandler(regionOptimizer, logger))
 
 	// Start the pricing API server on port 8082 for Funky integration
 	pricingMux := http.NewServeMux()
-	pricingMux.HandleFunc("/pricing/aws", awsPricingHandler(dbHandler))
-	pricingMux.HandleFunc("/pricing/azure", azurePricingHandler(dbHandler))
-	pricingMux.HandleFunc("/pricing/unified", unifiedPricingHandler(dbHandler))
+	pricingMux.HandleFunc("/pricing/aws", awsPricingHandler(dbHandler, currencyService, logger))
+	pricingMux.HandleFunc("/pricing/azure", azurePricingHandler(dbHandler, currencyService, logger))
+	pricingMux.HandleFunc("/pricing/unified", unifiedPricingHandler(dbHandler, currencyService, logger))
 	
 	go func() {
 		log.Printf("Starting pricing API server on http://localhost:8082/")
@@ -121,7 +155,7 @@ func main() {
 		log.Printf("  - http://localhost:8082/pricing/aws")
 		log.Printf("  - http://localhost:8082/pricing/azure")
 		log.Printf("  - http://localhost:8082/pricing/unified")
-		if err := http.ListenAndServe(":8082", pricingMux); err != nil {
+		if err := http.ListenAndServe(":8082", normalizer.LogRequests(logger, pricingMux)); err != nil {
 			log.Fatalf("Failed to start pricing API server: %v", err)
 		}
 	}()
@@ -130,13 +164,34 @@ func main() {
 	log.Printf("GraphQL playground available at http://localhost:%s/", port)
 	log.Printf("Population endpoint available at http://localhost:%s/populate", port)
 	
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
+	if err := http.ListenAndServe(":"+port, normalizer.LogRequests(logger, http.DefaultServeMux)); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+}
+
+// serveSnapshot serves the region optimizer from a pricing snapshot. Collection and the raw
+// pricing endpoints need Postgres and are not available.
+func serveSnapshot(path, port string, logger normalizer.Logger) {
+	store, err := snapshot.Open(path)
+	if err != nil {
+		log.Fatalf("Failed to open snapshot: %v", err)
+	}
+	defer store.Close()
+
+	regionOptimizer := optimization.NewRegionOptimizerWithRepository(store)
+	http.HandleFunc("/optimize-regions", optimizeRegionsHandler(regionOptimizer, logger))
+	http.HandleFunc("/compare-regions", compareRegionsHandler(regionOptimizer, logger))
+
+	log.Printf("Serving pricing snapshot %s on http://localhost:%s/", path, port)
+	log.Printf("Region optimization endpoints available at /optimize-regions and /compare-regions")
+
+	if err := http.ListenAndServe(":"+port, normalizer.LogRequests(logger, http.DefaultServeMux)); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
 
 // Population endpoint handler
-func populateHandler(db *database.DB) http.HandlerFunc {
+func populateHandler(db *database.DB, logger normalizer.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -153,7 +208,7 @@ func populateHandler(db *database.DB) http.HandlerFunc {
 			req.Region = "eastus"
 		}
 
-		log.Printf("Starting Azure data population for region: %s", req.Region)
+		logger.Info("Starting Azure data population", normalizer.Field{Key: normalizer.LogKeyRegion, Value: req.Region})
 
 		// Start collection in database
 		collectionID, err := db.StartAzureRawCollection(req.Region)
@@ -169,18 +224,22 @@ func populateHandler(db *database.DB) http.HandlerFunc {
 
 		// Run collector in background
 		go func() {
-			totalItems, err := collectAzureData(db, collectionID, req.Region)
+			totalItems, err := collectAzureData(db, logger, collectionID, req.Region)
 			if err != nil {
-				log.Printf("Collection failed for %s: %v", collectionID, err)
+				logger.Error("Azure collection failed", normalizer.Field{Key: normalizer.LogKeyCollectionID, Value: collectionID}, normalizer.Field{Key: "error", Value: err})
 				db.FailAzureRawCollection(collectionID, err.Error())
 				return
 			}
 			
 			// Complete collection
 			if err := db.CompleteAzureRawCollection(collectionID, totalItems); err != nil {
-				log.Printf("Failed to mark collection as completed: %v", err)
+				logger.Error("Failed to mark collection as completed", normalizer.Field{Key: normalizer.LogKeyCollectionID, Value: collectionID}, normalizer.Field{Key: "error", Value: err})
 			} else {
-				log.Printf("✅ Collection completed successfully! ID: %s, Region: %s, Items: %d", collectionID, req.Region, totalItems)
+				logger.Info("Azure collection completed",
+					normalizer.Field{Key: normalizer.LogKeyCollectionID, Value: collectionID},
+					normalizer.Field{Key: normalizer.LogKeyRegion, Value: req.Region},
+					normalizer.Field{Key: "items", Value: totalItems},
+				)
 			}
 		}()
 
@@ -195,7 +254,7 @@ func populateHandler(db *database.DB) http.HandlerFunc {
 }
 
 // Population all regions endpoint handler
-func populateAllHandler(db *database.DB) http.HandlerFunc {
+func populateAllHandler(db *database.DB, logger normalizer.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -212,36 +271,36 @@ func populateAllHandler(db *database.DB) http.HandlerFunc {
 			req.Concurrency = 3
 		}
 
-		log.Printf("Starting Azure all-regions data collection with concurrency: %d", req.Concurrency)
+		logger.Info("Starting Azure all-regions data collection", normalizer.Field{Key: "concurrency", Value: req.Concurrency})
 
 		// Run all-regions collector in background
 		go func() {
 			// For now, use a simple approach - collect a few major regions
 			regions := []string{"eastus", "westus", "eastus2", "westus2", "centralus", "northeurope", "westeurope"}
-			log.Printf("Starting collection for %d major regions with concurrency %d", len(regions), req.Concurrency)
+			logger.Info("Starting collection of major regions", normalizer.Field{Key: "regions", Value: len(regions)}, normalizer.Field{Key: "concurrency", Value: req.Concurrency})
 			
 			// Simple sequential collection for now
 			for _, region := range regions {
 				collectionID, err := db.StartAzureRawCollection(region)
 				if err != nil {
-					log.Printf("Failed to start collection for %s: %v", region, err)
+					logger.Error("Failed to start Azure collection", normalizer.Field{Key: normalizer.LogKeyRegion, Value: region}, normalizer.Field{Key: "error", Value: err})
 					continue
 				}
 				
-				totalItems, err := collectAzureData(db, collectionID, region)
+				totalItems, err := collectAzureData(db, logger, collectionID, region)
 				if err != nil {
-					log.Printf("Collection failed for %s: %v", region, err)
+					logger.Error("Azure collection failed", normalizer.Field{Key: normalizer.LogKeyRegion, Value: region}, normalizer.Field{Key: "error", Value: err})
 					db.FailAzureRawCollection(collectionID, err.Error())
 					continue
 				}
 				
 				if err := db.CompleteAzureRawCollection(collectionID, totalItems); err != nil {
-					log.Printf("Failed to mark collection as completed for %s: %v", region, err)
+					logger.Error("Failed to mark collection as completed", normalizer.Field{Key: normalizer.LogKeyRegion, Value: region}, normalizer.Field{Key: "error", Value: err})
 				} else {
-					log.Printf("✅ Completed %s: %d items", region, totalItems)
+					logger.Info("Azure collection completed", normalizer.Field{Key: normalizer.LogKeyRegion, Value: region}, normalizer.Field{Key: "items", Value: totalItems})
 				}
 			}
-			log.Printf("🎉 All-regions collection completed!")
+			logger.Info("Azure all-regions collection completed")
 		}()
 
 		response := populationResponse{
@@ -254,9 +313,9 @@ func populateAllHandler(db *database.DB) http.HandlerFunc {
 }
 
 // Simple GraphQL handler with raw data support
-func graphQLHandler(db *database.DB) http.HandlerFunc {
+func graphQLHandler(db *database.DB, logger normalizer.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("GraphQL request: Method=%s, ContentLength=%d", r.Method, r.ContentLength)
+		logger.Debug("GraphQL request", normalizer.Field{Key: "method", Value: r.Method}, normalizer.Field{Key: "contentLength", Value: r.ContentLength})
 		
 		// GraphQL endpoints should only accept POST requests
 		if r.Method != "POST" {
@@ -266,7 +325,7 @@ func graphQLHandler(db *database.DB) http.HandlerFunc {
 
 		// Check if request body is empty first
 		if r.ContentLength == 0 {
-			log.Printf("Detected empty ContentLength, returning friendly error")
+			logger.Debug("Rejecting GraphQL request with an empty body")
 			http.Error(w, "Request body is empty. Please provide a GraphQL query in JSON format: {\"query\": \"{ hello }\"}", http.StatusBadRequest)
 			return
 		}
@@ -1181,7 +1240,7 @@ func playgroundHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // collectAzureData collects pricing data for a specific region
-func collectAzureData(db *database.DB, collectionID string, region string) (int, error) {
+func collectAzureData(db *database.DB, logger normalizer.Logger, collectionID string, region string) (int, error) {
 	baseURL := "https://prices.azure.com/api/retail/prices"
 	
 	// Build filter for the specific region
@@ -1192,7 +1251,8 @@ func collectAzureData(db *database.DB, collectionID string, region string) (int,
 	pageCount := 0
 	estimatedTotalPages := 0
 	
-	log.Printf("🚀 Starting collection for region: %s", region)
+	logger = normalizer.WithFields(logger, normalizer.Field{Key: normalizer.LogKeyCollectionID, Value: collectionID}, normalizer.Field{Key: normalizer.LogKeyRegion, Value: region})
+	logger.Info("Starting Azure collection")
 	
 	for {
 		pageCount++
@@ -1201,10 +1261,10 @@ func collectAzureData(db *database.DB, collectionID string, region string) (int,
 		statusMsg := fmt.Sprintf("Fetching page %d for region %s", pageCount, region)
 		err := db.UpdateAzureRawCollectionProgress(collectionID, pageCount, estimatedTotalPages, totalItems, statusMsg)
 		if err != nil {
-			log.Printf("⚠️  Failed to update progress: %v", err)
+			logger.Warn("Failed to update collection progress", normalizer.Field{Key: "error", Value: err})
 		}
 		
-		log.Printf("📥 [%s] Fetching page %d (total items so far: %d)...", region, pageCount, totalItems)
+		logger.Debug("Fetching page", normalizer.Field{Key: "page", Value: pageCount}, normalizer.Field{Key: "items", Value: totalItems})
 		
 		// Build URL
 		var apiURL string
@@ -1226,7 +1286,7 @@ func collectAzureData(db *database.DB, collectionID string, region string) (int,
 			if resp != nil {
 				resp.Body.Close()
 			}
-			log.Printf("⚠️  [%s] API request failed (retry %d), retrying...", region, retries+1)
+			logger.Warn("Azure API request failed, retrying", normalizer.Field{Key: "retry", Value: retries+1})
 			time.Sleep(time.Duration(retries+1) * time.Second)
 		}
 		
@@ -1252,7 +1312,11 @@ func collectAzureData(db *database.DB, collectionID string, region string) (int,
 			return totalItems, fmt.Errorf("failed to parse JSON: %w", err)
 		}
 		
-		log.Printf("📊 [%s] Page %d: %d items (total: %d)", region, pageCount, len(apiResp.Items), totalItems+len(apiResp.Items))
+		logger.Info("Fetched page",
+			normalizer.Field{Key: "page", Value: pageCount},
+			normalizer.Field{Key: "pageItems", Value: len(apiResp.Items)},
+			normalizer.Field{Key: "items", Value: totalItems+len(apiResp.Items)},
+		)
 		
 		// Store raw data in database
 		if len(apiResp.Items) > 0 {
@@ -1275,7 +1339,7 @@ func collectAzureData(db *database.DB, collectionID string, region string) (int,
 		
 		// Check if there's more data
 		if apiResp.NextPageLink == "" || len(apiResp.Items) == 0 {
-			log.Printf("✅ [%s] Collection complete - no more pages", region)
+			logger.Debug("No more pages")
 			break
 		}
 		
@@ -1286,17 +1350,17 @@ func collectAzureData(db *database.DB, collectionID string, region string) (int,
 		
 		// Safety break to avoid infinite loops
 		if pageCount > 20 {
-			log.Printf("⚠️  [%s] Reached page limit (%d), stopping collection", region, pageCount)
+			logger.Warn("Reached page limit, stopping collection", normalizer.Field{Key: "page", Value: pageCount})
 			break
 		}
 	}
 	
-	log.Printf("🎉 [%s] Collection completed! Total items: %d, Pages: %d", region, totalItems, pageCount)
+	logger.Info("Fetched all pages", normalizer.Field{Key: "items", Value: totalItems}, normalizer.Field{Key: "pages", Value: pageCount})
 	return totalItems, nil
 }
 
 // AWS Population endpoint handler
-func awsPopulateHandler(db *database.DB) http.HandlerFunc {
+func awsPopulateHandler(db *database.DB, logger normalizer.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -1320,7 +1384,7 @@ func awsPopulateHandler(db *database.DB) http.HandlerFunc {
 			req.InstanceTypes = []string{"t3.micro", "t3.small", "t3.medium"}
 		}
 
-		log.Printf("Starting AWS data collection for services: %v, regions: %v", req.ServiceCodes, req.Regions)
+		logger.Info("Starting AWS data collection", normalizer.Field{Key: "services", Value: req.ServiceCodes}, normalizer.Field{Key: "regions", Value: req.Regions})
 
 		// Generate collection ID
 		collectionID := fmt.Sprintf("aws_%d", time.Now().Unix())
@@ -1341,10 +1405,10 @@ func awsPopulateHandler(db *database.DB) http.HandlerFunc {
 		go func() {
 			totalItems, err := collectAWSData(db, collectionID, req.ServiceCodes, req.Regions, req.InstanceTypes)
 			if err != nil {
-				log.Printf("AWS collection failed: %v", err)
+				logger.Error("AWS collection failed", normalizer.Field{Key: normalizer.LogKeyCollectionID, Value: collectionID}, normalizer.Field{Key: "error", Value: err})
 				updateAWSCollectionStatus(db, collectionID, "failed", 0, err.Error())
 			} else {
-				log.Printf("AWS collection completed successfully: %d items", totalItems)
+				logger.Info("AWS collection completed", normalizer.Field{Key: normalizer.LogKeyCollectionID, Value: collectionID}, normalizer.Field{Key: "items", Value: totalItems})
 				updateAWSCollectionStatus(db, collectionID, "completed", totalItems, "")
 			}
 		}()
@@ -1360,7 +1424,7 @@ func awsPopulateHandler(db *database.DB) http.HandlerFunc {
 }
 
 // AWS All Regions Population endpoint handler
-func awsPopulateAllHandler(db *database.DB) http.HandlerFunc {
+func awsPopulateAllHandler(db *database.DB, logger normalizer.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -1388,7 +1452,7 @@ func awsPopulateAllHandler(db *database.DB) http.HandlerFunc {
 			"ap-southeast-1", "ap-southeast-2", "ap-northeast-1",
 		}
 
-		log.Printf("Starting AWS all-regions collection for services: %v with %d concurrent workers", req.ServiceCodes, req.Concurrency)
+		logger.Info("Starting AWS all-regions collection", normalizer.Field{Key: "services", Value: req.ServiceCodes}, normalizer.Field{Key: "concurrency", Value: req.Concurrency})
 
 		// Generate collection ID
 		collectionID := fmt.Sprintf("aws_all_%d", time.Now().Unix())
@@ -1409,10 +1473,10 @@ func awsPopulateAllHandler(db *database.DB) http.HandlerFunc {
 		go func() {
 			totalItems, err := collectAWSDataConcurrent(db, collectionID, req.ServiceCodes, allRegions, req.Concurrency)
 			if err != nil {
-				log.Printf("AWS all-regions collection failed: %v", err)
+				logger.Error("AWS all-regions collection failed", normalizer.Field{Key: normalizer.LogKeyCollectionID, Value: collectionID}, normalizer.Field{Key: "error", Value: err})
 				updateAWSCollectionStatus(db, collectionID, "failed", 0, err.Error())
 			} else {
-				log.Printf("AWS all-regions collection completed successfully: %d items", totalItems)
+				logger.Info("AWS all-regions collection completed", normalizer.Field{Key: normalizer.LogKeyCollectionID, Value: collectionID}, normalizer.Field{Key: "items", Value: totalItems})
 				updateAWSCollectionStatus(db, collectionID, "completed", totalItems, "")
 			}
 		}()
@@ -1541,7 +1605,7 @@ func updateAWSCollectionStatus(db *database.DB, collectionID string, status stri
 }
 
 // AWS Comprehensive Collection endpoint - collects major AWS services
-func awsPopulateComprehensiveHandler(db *database.DB) http.HandlerFunc {
+func awsPopulateComprehensiveHandler(db *database.DB, logger normalizer.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -1567,8 +1631,7 @@ func awsPopulateComprehensiveHandler(db *database.DB) http.HandlerFunc {
 			req.Concurrency = 5 // Higher concurrency for comprehensive collection
 		}
 
-		log.Printf("🔥 Starting COMPREHENSIVE AWS collection for %d major services across %d regions", 
-			len(majorServices), len(req.Regions))
+		logger.Info("Starting comprehensive AWS collection", normalizer.Field{Key: "services", Value: len(majorServices)}, normalizer.Field{Key: "regions", Value: len(req.Regions)})
 
 		// Generate collection ID
 		collectionID := fmt.Sprintf("aws_comprehensive_%d", time.Now().Unix())
@@ -1589,10 +1652,10 @@ func awsPopulateComprehensiveHandler(db *database.DB) http.HandlerFunc {
 		go func() {
 			totalItems, err := collectAWSDataConcurrent(db, collectionID, req.ServiceCodes, req.Regions, req.Concurrency)
 			if err != nil {
-				log.Printf("Comprehensive AWS collection failed: %v", err)
+				logger.Error("Comprehensive AWS collection failed", normalizer.Field{Key: normalizer.LogKeyCollectionID, Value: collectionID}, normalizer.Field{Key: "error", Value: err})
 				updateAWSCollectionStatus(db, collectionID, "failed", 0, err.Error())
 			} else {
-				log.Printf("🎉 Comprehensive AWS collection completed successfully: %d items", totalItems)
+				logger.Info("Comprehensive AWS collection completed", normalizer.Field{Key: normalizer.LogKeyCollectionID, Value: collectionID}, normalizer.Field{Key: "items", Value: totalItems})
 				updateAWSCollectionStatus(db, collectionID, "completed", totalItems, "")
 			}
 		}()
@@ -1608,7 +1671,7 @@ func awsPopulateComprehensiveHandler(db *database.DB) http.HandlerFunc {
 }
 
 // AWS Everything Collection endpoint - collects ALL AWS services
-func awsPopulateEverythingHandler(db *database.DB) http.HandlerFunc {
+func awsPopulateEverythingHandler(db *database.DB, logger normalizer.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -1634,8 +1697,7 @@ func awsPopulateEverythingHandler(db *database.DB) http.HandlerFunc {
 			req.Concurrency = 3 // Conservative concurrency for massive collection
 		}
 
-		log.Printf("🌪️ Starting EVERYTHING AWS collection for %d services across %d regions - THIS WILL TAKE HOURS!", 
-			len(allServices), len(req.Regions))
+		logger.Info("Starting collection of all AWS services, this takes hours", normalizer.Field{Key: "services", Value: len(allServices)}, normalizer.Field{Key: "regions", Value: len(req.Regions)})
 
 		// Generate collection ID
 		collectionID := fmt.Sprintf("aws_everything_%d", time.Now().Unix())
@@ -1656,10 +1718,10 @@ func awsPopulateEverythingHandler(db *database.DB) http.HandlerFunc {
 		go func() {
 			totalItems, err := collectAWSDataConcurrent(db, collectionID, req.ServiceCodes, req.Regions, req.Concurrency)
 			if err != nil {
-				log.Printf("Everything AWS collection failed: %v", err)
+				logger.Error("Collection of all AWS services failed", normalizer.Field{Key: normalizer.LogKeyCollectionID, Value: collectionID}, normalizer.Field{Key: "error", Value: err})
 				updateAWSCollectionStatus(db, collectionID, "failed", 0, err.Error())
 			} else {
-				log.Printf("🎉 EVERYTHING AWS collection completed successfully: %d items", totalItems)
+				logger.Info("Collection of all AWS services completed", normalizer.Field{Key: normalizer.LogKeyCollectionID, Value: collectionID}, normalizer.Field{Key: "items", Value: totalItems})
 				updateAWSCollectionStatus(db, collectionID, "completed", totalItems, "")
 			}
 		}()
@@ -1675,7 +1737,7 @@ func awsPopulateEverythingHandler(db *database.DB) http.HandlerFunc {
 }
 
 // optimizeRegionsHandler handles region optimization requests
-func optimizeRegionsHandler(optimizer *optimization.RegionOptimizer) http.HandlerFunc {
+func optimizeRegionsHandler(optimizer *optimization.RegionOptimizer, logger normalizer.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -1691,7 +1753,7 @@ func optimizeRegionsHandler(optimizer *optimization.RegionOptimizer) http.Handle
 		ctx := r.Context()
 		results, err := optimizer.OptimizeRegions(ctx, workload)
 		if err != nil {
-			log.Printf("Region optimization error: %v", err)
+			logger.Error("Region optimization failed", normalizer.Field{Key: "error", Value: err})
 			http.Error(w, "Optimization failed: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -1705,7 +1767,7 @@ func optimizeRegionsHandler(optimizer *optimization.RegionOptimizer) http.Handle
 }
 
 // compareRegionsHandler handles region comparison requests
-func compareRegionsHandler(optimizer *optimization.RegionOptimizer) http.HandlerFunc {
+func compareRegionsHandler(optimizer *optimization.RegionOptimizer, logger normalizer.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "POST" {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -1725,7 +1787,7 @@ func compareRegionsHandler(optimizer *optimization.RegionOptimizer) http.Handler
 		ctx := r.Context()
 		results, err := optimizer.CompareRegions(ctx, request.Workload, request.Regions)
 		if err != nil {
-			log.Printf("Region comparison error: %v", err)
+			logger.Error("Region comparison failed", normalizer.Field{Key: "error", Value: err})
 			http.Error(w, "Comparison failed: "+err.Error(), http.StatusInternalServerError)
 			return
 		}
@@ -1740,16 +1802,55 @@ func compareRegionsHandler(optimizer *optimization.RegionOptimizer) http.Handler
 
 // PricingResponse represents pricing data for cloud services
 type PricingResponse struct {
-	Provider string                 `json:"provider"`
-	Region   string                 `json:"region"`
-	Compute  map[string]float64     `json:"compute"`
-	Storage  map[string]float64     `json:"storage"`
-	Transfer map[string]float64     `json:"transfer"`
-	Raw      map[string]interface{} `json:"raw,omitempty"`
+	Provider   string                 `json:"provider"`
+	Region     string                 `json:"region"`
+	Currency   string                 `json:"currency"`
+	Compute    map[string]float64     `json:"compute"`
+	Storage    map[string]float64     `json:"storage"`
+	Transfer   map[string]float64     `json:"transfer"`
+	Raw        map[string]interface{} `json:"raw,omitempty"`
+	Conversion *currency.Conversion   `json:"conversion,omitempty"`
+}
+
+// convertPricingResponse converts the USD prices of a pricing response to the currency
+// requested by the currency query parameter, if any
+func convertPricingResponse(r *http.Request, currencyService *currency.Service, responses ...*PricingResponse) error {
+	for _, response := range responses {
+		response.Currency = currency.PivotCurrency
+	}
+
+	target := r.URL.Query().Get("currency")
+	if target == "" {
+		return nil
+	}
+
+	conversion, err := currencyService.Conversion(r.Context(), currency.PivotCurrency, target, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, response := range responses {
+		conversion.ApplyAll(response.Compute)
+		conversion.ApplyAll(response.Storage)
+		conversion.ApplyAll(response.Transfer)
+		response.Currency = conversion.ToCurrency
+		response.Conversion = conversion
+	}
+	return nil
+}
+
+// writeConversionError responds with 400 for an unsupported currency and 500 otherwise
+func writeConversionError(w http.ResponseWriter, logger normalizer.Logger, err error) {
+	if currency.IsRequestError(err) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	logger.Error("Failed to convert prices", normalizer.Field{Key: "error", Value: err})
+	http.Error(w, "failed to convert prices", http.StatusInternalServerError)
 }
 
 // Pricing API handlers for Funky integration
-func awsPricingHandler(dbHandler *database.DB) http.HandlerFunc {
+func awsPricingHandler(dbHandler *database.DB, currencyService *currency.Service, logger normalizer.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		region := r.URL.Query().Get("region")
 		if region == "" {
@@ -1780,12 +1881,17 @@ func awsPricingHandler(dbHandler *database.DB) http.HandlerFunc {
 		response.Transfer["in"] = 0.0
 		response.Transfer["out"] = 0.09
 
+		if err := convertPricingResponse(r, currencyService, &response); err != nil {
+			writeConversionError(w, logger, err)
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(response)
 	}
 }
 
-func azurePricingHandler(dbHandler *database.DB) http.HandlerFunc {
+func azurePricingHandler(dbHandler *database.DB, currencyService *currency.Service, logger normalizer.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		region := r.URL.Query().Get("region")
 		if region == "" {
@@ -1816,12 +1922,17 @@ func azurePricingHandler(dbHandler *database.DB) http.HandlerFunc {
 		response.Transfer["in"] = 0.0
 		response.Transfer["out"] = 0.0877
 
+		if err := convertPricingResponse(r, currencyService, &response); err != nil {
+			writeConversionError(w, logger, err)
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(response)
 	}
 }
 
-func unifiedPricingHandler(dbHandler *database.DB) http.HandlerFunc {
+func unifiedPricingHandler(dbHandler *database.DB, currencyService *currency.Service, logger normalizer.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		awsRegion := r.URL.Query().Get("aws_region")
 		if awsRegion == "" {
@@ -1832,21 +1943,28 @@ func unifiedPricingHandler(dbHandler *database.DB) http.HandlerFunc {
 			azureRegion = "eastus"
 		}
 
+		awsResponse := PricingResponse{
+			Provider: "aws",
+			Region:   awsRegion,
+			Compute:  getAWSComputePrices(dbHandler, awsRegion),
+			Storage:  getAWSStoragePrices(dbHandler, awsRegion),
+			Transfer: map[string]float64{"in": 0.0, "out": 0.09},
+		}
+		azureResponse := PricingResponse{
+			Provider: "azure",
+			Region:   azureRegion,
+			Compute:  getAzureComputePrices(dbHandler, azureRegion),
+			Storage:  getAzureStoragePrices(dbHandler, azureRegion),
+			Transfer: map[string]float64{"in": 0.0, "out": 0.0877},
+		}
+		if err := convertPricingResponse(r, currencyService, &awsResponse, &azureResponse); err != nil {
+			writeConversionError(w, logger, err)
+			return
+		}
+
 		response := map[string]interface{}{
-			"aws": PricingResponse{
-				Provider: "aws",
-				Region:   awsRegion,
-				Compute:  getAWSComputePrices(dbHandler, awsRegion),
-				Storage:  getAWSStoragePrices(dbHandler, awsRegion),
-				Transfer: map[string]float64{"in": 0.0, "out": 0.09},
-			},
-			"azure": PricingResponse{
-				Provider: "azure",
-				Region:   azureRegion,
-				Compute:  getAzureComputePrices(dbHandler, azureRegion),
-				Storage:  getAzureStoragePrices(dbHandler, azureRegion),
-				Transfer: map[string]float64{"in": 0.0, "out": 0.0877},
-			},
+			"aws":   awsResponse,
+			"azure": azureResponse,
 		}
 
 		w.Header().Set("Content-Type", "application/json")
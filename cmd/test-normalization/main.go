@@ -14,6 +14,12 @@ import (
 )
 
 func main() {
+	// Configure structured logging from LOG_LEVEL and LOG_FORMAT
+	logger, err := normalizer.SetupLogging(normalizer.LogConfigFromEnv())
+	if err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+
 	// Connect to database
 	connStr := os.Getenv("DATABASE_URL")
 	if connStr == "" {
@@ -33,7 +39,6 @@ func main() {
 	db := database.New(sqlDB)
 
 	// Create normalizer components
-	logger := normalizer.NewSimpleLogger()
 	serviceMappingRepo := normalizer.NewServiceMappingRepository(db, logger)
 	regionMappingRepo := normalizer.NewRegionMappingRepository(db, logger)
 	unitNormalizer := normalizer.NewStandardUnitNormalizer()
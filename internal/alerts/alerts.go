@@ -0,0 +1,303 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/raulc0399/cpc/internal/normalizer"
+)
+
+// Headers sent with every webhook delivery. The signature is "sha256=" followed by the hex
+// HMAC-SHA256 of the timestamp, a dot and the body, keyed with the webhook secret.
+const (
+	HeaderEvent     = "X-CPC-Event"
+	HeaderDelivery  = "X-CPC-Delivery"
+	HeaderTimestamp = "X-CPC-Timestamp"
+	HeaderSignature = "X-CPC-Signature"
+)
+
+// EventPriceAlert is the event of the payloads posted when a rule fires
+const EventPriceAlert = "price_alert"
+
+// Repository stores alert rules, webhooks and the delivery log
+type Repository interface {
+	GetAlertRules(ctx context.Context, enabledOnly bool) ([]database.AlertRule, error)
+	GetAlertWebhooks(ctx context.Context) ([]database.AlertWebhook, error)
+	FindAlertMatches(ctx context.Context, rule database.AlertRule, jobID string) ([]database.AlertMatch, error)
+	CreateAlertDelivery(ctx context.Context, delivery *database.AlertDelivery) error
+	UpdateAlertDelivery(ctx context.Context, delivery *database.AlertDelivery) error
+}
+
+// Config sets how deliveries are attempted. A delivery is retried after transport errors,
+// 429 and 5xx responses, waiting InitialBackoff and doubling the wait after every attempt.
+// EvaluationTimeout bounds the whole evaluation including all deliveries, since it runs as
+// part of an ETL job; zero means no bound.
+type Config struct {
+	Timeout           time.Duration
+	MaxAttempts       int
+	InitialBackoff    time.Duration
+	EvaluationTimeout time.Duration
+}
+
+// DefaultConfig returns the delivery configuration used by the ETL pipeline
+func DefaultConfig() Config {
+	return Config{
+		Timeout:           10 * time.Second,
+		MaxAttempts:       4,
+		InitialBackoff:    2 * time.Second,
+		EvaluationTimeout: 2 * time.Minute,
+	}
+}
+
+// Payload is the JSON body posted to a webhook when a rule fires
+type Payload struct {
+	Event       string                `json:"event"`
+	DeliveryID  int                   `json:"deliveryId"`
+	ETLJobID    string                `json:"etlJobId"`
+	TriggeredAt time.Time             `json:"triggeredAt"`
+	Rule        database.AlertRule    `json:"rule"`
+	Changes     []database.AlertMatch `json:"changes"`
+}
+
+// EvaluationResult counts the rules that fired after a job and the outcome of their deliveries
+type EvaluationResult struct {
+	RulesEvaluated      int `json:"rulesEvaluated"`
+	RulesTriggered      int `json:"rulesTriggered"`
+	DeliveriesSucceeded int `json:"deliveriesSucceeded"`
+	DeliveriesFailed    int `json:"deliveriesFailed"`
+}
+
+// Notifier evaluates alert rules against the price changes of an ETL job and delivers the
+// matches to webhooks
+type Notifier struct {
+	repo       Repository
+	httpClient *http.Client
+	config     Config
+	logger     normalizer.Logger
+}
+
+// NewNotifier creates a notifier backed by an alert repository
+func NewNotifier(repo Repository, config Config, logger normalizer.Logger) *Notifier {
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	return &Notifier{
+		repo: repo,
+		httpClient: &http.Client{
+			Timeout: config.Timeout,
+		},
+		config: config,
+		logger: logger,
+	}
+}
+
+// Evaluate runs every enabled rule against the price changes the job recorded in the price
+// history and delivers one payload per triggered rule. Delivery failures are logged in the
+// delivery log and counted, not returned. Rules not evaluated before the evaluation timeout
+// are skipped and reported as an error.
+func (n *Notifier) Evaluate(ctx context.Context, jobID string) (*EvaluationResult, error) {
+	if n.config.EvaluationTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.config.EvaluationTimeout)
+		defer cancel()
+	}
+
+	rules, err := n.repo.GetAlertRules(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	result := &EvaluationResult{}
+	if len(rules) == 0 {
+		return result, nil
+	}
+
+	webhooks, err := n.repo.GetAlertWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	webhooksByID := make(map[int]database.AlertWebhook, len(webhooks))
+	for _, webhook := range webhooks {
+		webhooksByID[webhook.ID] = webhook
+	}
+
+	for _, rule := range rules {
+		if err := ctx.Err(); err != nil {
+			return result, fmt.Errorf("alert evaluation stopped before all rules were evaluated: %w", err)
+		}
+
+		webhook, ok := webhooksByID[rule.WebhookID]
+		if !ok || !webhook.Enabled {
+			continue
+		}
+		result.RulesEvaluated++
+
+		matches, err := n.repo.FindAlertMatches(ctx, rule, jobID)
+		if err != nil {
+			return result, err
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		result.RulesTriggered++
+
+		delivery, err := n.deliver(ctx, webhook, Payload{
+			Event:       EventPriceAlert,
+			ETLJobID:    jobID,
+			TriggeredAt: time.Now().UTC(),
+			Rule:        rule,
+			Changes:     matches,
+		})
+		if err != nil {
+			return result, err
+		}
+		if delivery.Status == database.DeliveryStatusDelivered {
+			result.DeliveriesSucceeded++
+		} else {
+			result.DeliveriesFailed++
+		}
+	}
+
+	return result, nil
+}
+
+// deliver logs a delivery and posts its payload until it succeeds, fails permanently or runs
+// out of attempts, recording every attempt in the delivery log
+func (n *Notifier) deliver(ctx context.Context, webhook database.AlertWebhook, payload Payload) (*database.AlertDelivery, error) {
+	delivery := &database.AlertDelivery{
+		RuleID:    payload.Rule.ID,
+		WebhookID: webhook.ID,
+		ETLJobID:  payload.ETLJobID,
+		Payload:   json.RawMessage("{}"),
+		Status:    database.DeliveryStatusPending,
+	}
+	if err := n.repo.CreateAlertDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	// The delivery ID is part of the payload, so the body is encoded once the row exists
+	payload.DeliveryID = delivery.ID
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode alert payload: %w", err)
+	}
+	delivery.Payload = body
+
+	backoff := n.config.InitialBackoff
+	for delivery.Attempts < n.config.MaxAttempts {
+		if delivery.Attempts > 0 {
+			select {
+			case <-ctx.Done():
+				return delivery, n.finish(delivery, database.DeliveryStatusFailed, ctx.Err())
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		delivery.Attempts++
+		status, retry, err := n.post(ctx, webhook, delivery.ID, body)
+		delivery.ResponseStatus = status
+		if err == nil {
+			return delivery, n.finish(delivery, database.DeliveryStatusDelivered, nil)
+		}
+
+		n.logger.Warn("Alert delivery attempt failed",
+			normalizer.Field{Key: "deliveryId", Value: delivery.ID},
+			normalizer.Field{Key: "webhook", Value: webhook.Name},
+			normalizer.Field{Key: "attempt", Value: delivery.Attempts},
+			normalizer.Field{Key: "error", Value: err},
+		)
+		if !retry || delivery.Attempts >= n.config.MaxAttempts {
+			return delivery, n.finish(delivery, database.DeliveryStatusFailed, err)
+		}
+		if err := n.record(delivery, database.DeliveryStatusPending, err); err != nil {
+			return delivery, err
+		}
+	}
+
+	return delivery, nil
+}
+
+// post sends one signed delivery attempt. It returns the response status, if any, and
+// whether a failed attempt may be retried.
+func (n *Notifier) post(ctx context.Context, webhook database.AlertWebhook, deliveryID int, body []byte) (*int, bool, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, EventPriceAlert)
+	req.Header.Set(HeaderDelivery, strconv.Itoa(deliveryID))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	status := resp.StatusCode
+	if status >= 200 && status < 300 {
+		return &status, false, nil
+	}
+
+	responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	retry := status == http.StatusTooManyRequests || status >= 500
+	return &status, retry, fmt.Errorf("webhook returned status %d: %s", status, string(responseBody))
+}
+
+// finish records the final outcome of a delivery
+func (n *Notifier) finish(delivery *database.AlertDelivery, status string, deliveryErr error) error {
+	if status == database.DeliveryStatusDelivered {
+		deliveredAt := time.Now()
+		delivery.DeliveredAt = &deliveredAt
+	}
+	return n.record(delivery, status, deliveryErr)
+}
+
+// record updates the delivery log. It uses a fresh context, so a cancelled job still records
+// why its deliveries stopped.
+func (n *Notifier) record(delivery *database.AlertDelivery, status string, deliveryErr error) error {
+	delivery.Status = status
+	delivery.Error = nil
+	if deliveryErr != nil {
+		message := deliveryErr.Error()
+		delivery.Error = &message
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return n.repo.UpdateAlertDelivery(ctx, delivery)
+}
+
+// Sign returns the signature header value of a payload. Receivers recompute it from the
+// timestamp header and the raw body and compare with hmac.Equal.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateSecret returns a random webhook secret
+func GenerateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
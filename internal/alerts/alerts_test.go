@@ -0,0 +1,250 @@
+package alerts
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/raulc0399/cpc/internal/normalizer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepository is an in-memory alert repository that keeps the latest state of each delivery
+type fakeRepository struct {
+	mu         sync.Mutex
+	rules      []database.AlertRule
+	webhooks   []database.AlertWebhook
+	matches    []database.AlertMatch
+	deliveries map[int]database.AlertDelivery
+}
+
+func newFakeRepository(webhookURL string) *fakeRepository {
+	return &fakeRepository{
+		rules: []database.AlertRule{
+			{ID: 1, Name: "compute drops", WebhookID: 7, ThresholdPercent: 5, Direction: "down", Enabled: true},
+		},
+		webhooks: []database.AlertWebhook{
+			{ID: 7, Name: "ops", URL: webhookURL, Secret: "s3cret", Enabled: true},
+		},
+		matches: []database.AlertMatch{
+			{NaturalKey: "aws|m5.large", Provider: database.ProviderAWS, OldPrice: 0.1, NewPrice: 0.09, ChangePercent: -10},
+		},
+		deliveries: make(map[int]database.AlertDelivery),
+	}
+}
+
+func (r *fakeRepository) GetAlertRules(ctx context.Context, enabledOnly bool) ([]database.AlertRule, error) {
+	return r.rules, nil
+}
+
+func (r *fakeRepository) GetAlertWebhooks(ctx context.Context) ([]database.AlertWebhook, error) {
+	return r.webhooks, nil
+}
+
+func (r *fakeRepository) FindAlertMatches(ctx context.Context, rule database.AlertRule, jobID string) ([]database.AlertMatch, error) {
+	return r.matches, ctx.Err()
+}
+
+func (r *fakeRepository) CreateAlertDelivery(ctx context.Context, delivery *database.AlertDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery.ID = len(r.deliveries) + 1
+	r.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (r *fakeRepository) UpdateAlertDelivery(ctx context.Context, delivery *database.AlertDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (r *fakeRepository) delivery(id int) database.AlertDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.deliveries[id]
+}
+
+// testConfig retries quickly so the backoff does not slow the tests down
+func testConfig() Config {
+	return Config{
+		Timeout:        time.Second,
+		MaxAttempts:    4,
+		InitialBackoff: time.Millisecond,
+	}
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"price_alert"}`)
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte("1700000000." + string(body)))
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	assert.Equal(t, expected, Sign("s3cret", "1700000000", body))
+	assert.NotEqual(t, expected, Sign("other", "1700000000", body), "the secret is part of the signature")
+	assert.NotEqual(t, expected, Sign("s3cret", "1700000001", body), "the timestamp is part of the signature")
+	assert.NotEqual(t, expected, Sign("s3cret", "1700000000", []byte(`{}`)), "the body is part of the signature")
+}
+
+func TestNotifier_Evaluate_Retries(t *testing.T) {
+	tests := []struct {
+		name              string
+		responses         []int
+		expectedAttempts  int
+		expectedStatus    string
+		expectedResponse  int
+		expectedSucceeded int
+		expectedFailed    int
+	}{
+		{
+			name:              "delivered on the first attempt",
+			responses:         []int{http.StatusOK},
+			expectedAttempts:  1,
+			expectedStatus:    database.DeliveryStatusDelivered,
+			expectedResponse:  http.StatusOK,
+			expectedSucceeded: 1,
+		},
+		{
+			name:              "429 is retried",
+			responses:         []int{http.StatusTooManyRequests, http.StatusNoContent},
+			expectedAttempts:  2,
+			expectedStatus:    database.DeliveryStatusDelivered,
+			expectedResponse:  http.StatusNoContent,
+			expectedSucceeded: 1,
+		},
+		{
+			name:              "5xx is retried",
+			responses:         []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			expectedAttempts:  3,
+			expectedStatus:    database.DeliveryStatusDelivered,
+			expectedResponse:  http.StatusOK,
+			expectedSucceeded: 1,
+		},
+		{
+			name:             "4xx fails without retrying",
+			responses:        []int{http.StatusBadRequest},
+			expectedAttempts: 1,
+			expectedStatus:   database.DeliveryStatusFailed,
+			expectedResponse: http.StatusBadRequest,
+			expectedFailed:   1,
+		},
+		{
+			name:             "gives up after the maximum attempts",
+			responses:        []int{http.StatusInternalServerError},
+			expectedAttempts: 4,
+			expectedStatus:   database.DeliveryStatusFailed,
+			expectedResponse: http.StatusInternalServerError,
+			expectedFailed:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var requests []*http.Request
+			var bodies [][]byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+
+				mu.Lock()
+				requests = append(requests, r)
+				bodies = append(bodies, body)
+				// The last response repeats once the list is exhausted
+				status := tt.responses[min(len(requests), len(tt.responses))-1]
+				mu.Unlock()
+
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			repo := newFakeRepository(server.URL)
+			notifier := NewNotifier(repo, testConfig(), normalizer.NewMockLogger())
+
+			result, err := notifier.Evaluate(context.Background(), "job-1")
+
+			require.NoError(t, err)
+			assert.Equal(t, 1, result.RulesEvaluated)
+			assert.Equal(t, 1, result.RulesTriggered)
+			assert.Equal(t, tt.expectedSucceeded, result.DeliveriesSucceeded)
+			assert.Equal(t, tt.expectedFailed, result.DeliveriesFailed)
+
+			delivery := repo.delivery(1)
+			assert.Equal(t, tt.expectedStatus, delivery.Status)
+			assert.Equal(t, tt.expectedAttempts, delivery.Attempts)
+			require.NotNil(t, delivery.ResponseStatus)
+			assert.Equal(t, tt.expectedResponse, *delivery.ResponseStatus)
+			assert.Equal(t, tt.expectedStatus == database.DeliveryStatusDelivered, delivery.DeliveredAt != nil)
+			assert.Equal(t, tt.expectedStatus == database.DeliveryStatusFailed, delivery.Error != nil)
+
+			// Every attempt is signed over its own timestamp and the unchanged body
+			require.Len(t, requests, tt.expectedAttempts)
+			for i, r := range requests {
+				assert.Equal(t, EventPriceAlert, r.Header.Get(HeaderEvent))
+				assert.Equal(t, strconv.Itoa(delivery.ID), r.Header.Get(HeaderDelivery))
+				assert.Equal(t, Sign("s3cret", r.Header.Get(HeaderTimestamp), bodies[i]), r.Header.Get(HeaderSignature))
+				assert.JSONEq(t, string(delivery.Payload), string(bodies[i]))
+			}
+		})
+	}
+}
+
+func TestNotifier_Evaluate_TransportErrorIsRetried(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	repo := newFakeRepository(url)
+	notifier := NewNotifier(repo, testConfig(), normalizer.NewMockLogger())
+
+	result, err := notifier.Evaluate(context.Background(), "job-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.DeliveriesFailed)
+
+	delivery := repo.delivery(1)
+	assert.Equal(t, database.DeliveryStatusFailed, delivery.Status)
+	assert.Equal(t, 4, delivery.Attempts)
+	assert.Nil(t, delivery.ResponseStatus)
+}
+
+func TestNotifier_Evaluate_EvaluationTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	repo := newFakeRepository(server.URL)
+	repo.rules = append(repo.rules, database.AlertRule{ID: 2, Name: "storage drops", WebhookID: 7, Enabled: true})
+
+	config := testConfig()
+	config.MaxAttempts = 100
+	config.InitialBackoff = 20 * time.Millisecond
+	config.EvaluationTimeout = 50 * time.Millisecond
+	notifier := NewNotifier(repo, config, normalizer.NewMockLogger())
+
+	start := time.Now()
+	result, err := notifier.Evaluate(context.Background(), "job-1")
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, 1, result.DeliveriesFailed)
+	assert.Equal(t, database.DeliveryStatusFailed, repo.delivery(1).Status)
+
+	// The second rule was never delivered
+	assert.Equal(t, database.AlertDelivery{}, repo.delivery(2))
+}
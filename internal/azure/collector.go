@@ -3,7 +3,9 @@ package azure
 import (
 	"context"
 	"fmt"
-	"log"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/raulc0399/cpc/internal/normalizer"
 )
 
 // Collector orchestrates Azure pricing data collection
@@ -13,6 +15,7 @@ type Collector struct {
 	outputHandler   OutputHandler
 	progressTracker ProgressTracker
 	client          *Client
+	logger          normalizer.Logger
 }
 
 // NewCollector creates a new Azure collector with injected dependencies
@@ -27,25 +30,39 @@ func NewCollector(regionHandler RegionHandler, dataStore DataStore,
 		outputHandler:   outputHandler,
 		progressTracker: tracker,
 		client:          client,
+		logger:          normalizer.WithFields(normalizer.NewDefaultLogger(), normalizer.Field{normalizer.LogKeyProvider, database.ProviderAzure}),
 	}
 }
 
+// SetLogger replaces the collector's logger
+func (c *Collector) SetLogger(logger normalizer.Logger) {
+	c.logger = normalizer.WithFields(logger, normalizer.Field{normalizer.LogKeyProvider, database.ProviderAzure})
+}
+
+// collectionLogger returns the logger with the region and collection ID of a collection
+func (c *Collector) collectionLogger(region, collectionID string) normalizer.Logger {
+	return normalizer.WithFields(c.logger,
+		normalizer.Field{normalizer.LogKeyRegion, region},
+		normalizer.Field{normalizer.LogKeyCollectionID, collectionID},
+	)
+}
+
 // Run executes the data collection process
 func (c *Collector) Run(ctx context.Context) error {
 	regions := c.regionHandler.GetRegions()
 	
-	log.Printf("Starting Azure data collection for %d regions", len(regions))
+	c.logger.Info("Starting Azure data collection", normalizer.Field{"regions", len(regions)})
 	c.progressTracker.Start(len(regions))
 	
 	for i, region := range regions {
-		log.Printf("Collecting region %d/%d: %s", i+1, len(regions), region)
-		
 		// Start collection tracking
 		collectionID, err := c.dataStore.StartCollection(region)
 		if err != nil {
 			c.progressTracker.Complete(region, false, 0)
 			return fmt.Errorf("failed to start collection for region %s: %w", region, err)
 		}
+		logger := c.collectionLogger(region, collectionID)
+		logger.Info("Collecting region", normalizer.Field{"index", i + 1}, normalizer.Field{"regions", len(regions)})
 		
 		c.progressTracker.Update(region, 0, "collecting")
 		
@@ -54,7 +71,7 @@ func (c *Collector) Run(ctx context.Context) error {
 		if err != nil {
 			c.progressTracker.Complete(region, false, 0)
 			if failErr := c.dataStore.FailCollection(collectionID, err.Error()); failErr != nil {
-				log.Printf("Failed to mark collection as failed: %v", failErr)
+				logger.Error("Failed to mark collection as failed", normalizer.Field{"error", failErr})
 			}
 			return fmt.Errorf("failed to collect data for region %s: %w", region, err)
 		}
@@ -63,14 +80,14 @@ func (c *Collector) Run(ctx context.Context) error {
 		if err := c.dataStore.Store(ctx, collectionID, region, data); err != nil {
 			c.progressTracker.Complete(region, false, len(data))
 			if failErr := c.dataStore.FailCollection(collectionID, err.Error()); failErr != nil {
-				log.Printf("Failed to mark collection as failed: %v", failErr)
+				logger.Error("Failed to mark collection as failed", normalizer.Field{"error", failErr})
 			}
 			return fmt.Errorf("failed to store data for region %s: %w", region, err)
 		}
 		
 		// Complete collection tracking
 		if err := c.dataStore.CompleteCollection(collectionID, len(data)); err != nil {
-			log.Printf("Warning: Failed to mark collection complete: %v", err)
+			logger.Warn("Failed to mark collection complete", normalizer.Field{"error", err})
 		}
 		
 		// Output results
@@ -80,17 +97,21 @@ func (c *Collector) Run(ctx context.Context) error {
 		}
 		
 		if err := c.outputHandler.Write(items); err != nil {
-			log.Printf("Warning: Failed to write output: %v", err)
+			logger.Warn("Failed to write output", normalizer.Field{"error", err})
 		}
 		
 		c.progressTracker.Complete(region, true, len(data))
-		log.Printf("✅ Completed region %s: %d items", region, len(data))
+		logger.Info("Completed region", normalizer.Field{"items", len(data)})
 	}
 	
 	// Final statistics
 	completed, failed, total, elapsed := c.progressTracker.GetStatus()
-	log.Printf("🎉 Collection completed! %d/%d regions successful (%d failed) in %v", 
-		completed, total, failed, elapsed)
+	c.logger.Info("Collection completed",
+		normalizer.Field{"completed", completed},
+		normalizer.Field{"failed", failed},
+		normalizer.Field{"regions", total},
+		normalizer.Field{"elapsed", elapsed.String()},
+	)
 	
 	return nil
 }
@@ -99,8 +120,10 @@ func (c *Collector) Run(ctx context.Context) error {
 func (c *Collector) RunConcurrent(ctx context.Context, concurrency int) error {
 	regions := c.regionHandler.GetRegions()
 	
-	log.Printf("Starting concurrent Azure data collection: %d regions, %d workers", 
-		len(regions), concurrency)
+	c.logger.Info("Starting concurrent Azure data collection",
+		normalizer.Field{"regions", len(regions)},
+		normalizer.Field{"workers", concurrency},
+	)
 	c.progressTracker.Start(len(regions))
 	
 	// Create worker pool
@@ -123,14 +146,18 @@ func (c *Collector) RunConcurrent(ctx context.Context, concurrency int) error {
 	for i := 0; i < len(regions); i++ {
 		if err := <-errorChan; err != nil {
 			errors = append(errors, err)
-			log.Printf("Worker error: %v", err)
+			c.logger.Error("Worker error", normalizer.Field{"error", err})
 		}
 	}
 	
 	// Final statistics
 	completed, failed, total, elapsed := c.progressTracker.GetStatus()
-	log.Printf("🎉 Concurrent collection completed! %d/%d regions successful (%d failed) in %v", 
-		completed, total, failed, elapsed)
+	c.logger.Info("Concurrent collection completed",
+		normalizer.Field{"completed", completed},
+		normalizer.Field{"failed", failed},
+		normalizer.Field{"regions", total},
+		normalizer.Field{"elapsed", elapsed.String()},
+	)
 	
 	if len(errors) > 0 {
 		return fmt.Errorf("collection completed with %d errors", len(errors))
@@ -151,6 +178,7 @@ func (c *Collector) worker(ctx context.Context, workerID int, regionChan <-chan
 			errorChan <- fmt.Errorf("worker %d failed to start collection for region %s: %w", workerID, region, err)
 			continue
 		}
+		logger := normalizer.WithFields(c.collectionLogger(region, collectionID), normalizer.Field{"worker", workerID})
 		
 		c.progressTracker.Update(region, 0, "collecting")
 		
@@ -160,7 +188,7 @@ func (c *Collector) worker(ctx context.Context, workerID int, regionChan <-chan
 			c.progressTracker.Complete(region, false, 0)
 			c.progressTracker.ClearWorking(workerID)
 			if failErr := c.dataStore.FailCollection(collectionID, err.Error()); failErr != nil {
-				log.Printf("Failed to mark collection as failed: %v", failErr)
+				logger.Error("Failed to mark collection as failed", normalizer.Field{"error", failErr})
 			}
 			errorChan <- fmt.Errorf("worker %d failed to collect data for region %s: %w", workerID, region, err)
 			continue
@@ -171,7 +199,7 @@ func (c *Collector) worker(ctx context.Context, workerID int, regionChan <-chan
 			c.progressTracker.Complete(region, false, len(data))
 			c.progressTracker.ClearWorking(workerID)
 			if failErr := c.dataStore.FailCollection(collectionID, err.Error()); failErr != nil {
-				log.Printf("Failed to mark collection as failed: %v", failErr)
+				logger.Error("Failed to mark collection as failed", normalizer.Field{"error", failErr})
 			}
 			errorChan <- fmt.Errorf("worker %d failed to store data for region %s: %w", workerID, region, err)
 			continue
@@ -179,12 +207,12 @@ func (c *Collector) worker(ctx context.Context, workerID int, regionChan <-chan
 		
 		// Complete collection tracking
 		if err := c.dataStore.CompleteCollection(collectionID, len(data)); err != nil {
-			log.Printf("Warning: Failed to mark collection complete: %v", err)
+			logger.Warn("Failed to mark collection complete", normalizer.Field{"error", err})
 		}
 		
 		c.progressTracker.Complete(region, true, len(data))
 		c.progressTracker.ClearWorking(workerID)
-		log.Printf("✅ Worker %d completed region %s: %d items", workerID, region, len(data))
+		logger.Info("Completed region", normalizer.Field{"items", len(data)})
 		
 		errorChan <- nil // Success
 	}
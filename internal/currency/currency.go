@@ -0,0 +1,333 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+)
+
+// PivotCurrency is the currency that cross rates are derived through when no direct or
+// inverse rate exists for a pair
+const PivotCurrency = "USD"
+
+var (
+	// ErrInvalidCurrency is returned for currency codes that are not 3-letter ISO codes
+	ErrInvalidCurrency = errors.New("invalid currency code")
+	// ErrRateNotFound is returned when no exchange rate for a currency pair is effective on
+	// the requested date
+	ErrRateNotFound = errors.New("exchange rate not found")
+)
+
+// RateRepository stores exchange rates
+type RateRepository interface {
+	UpsertExchangeRates(ctx context.Context, rates []database.ExchangeRate) (int, error)
+	GetExchangeRate(ctx context.Context, baseCurrency, quoteCurrency string, asOf time.Time) (*database.ExchangeRate, error)
+}
+
+// Conversion is the rate used to convert prices from one currency to another
+type Conversion struct {
+	FromCurrency string    `json:"fromCurrency"`
+	ToCurrency   string    `json:"toCurrency"`
+	Rate         float64   `json:"rate"`
+	RateDate     time.Time `json:"rateDate"`
+}
+
+// Apply converts an amount in the source currency to the target currency
+func (c Conversion) Apply(amount float64) float64 {
+	return amount * c.Rate
+}
+
+// ApplyAll converts each amount of a price map in place
+func (c Conversion) ApplyAll(prices map[string]float64) {
+	for key, amount := range prices {
+		prices[key] = c.Apply(amount)
+	}
+}
+
+// ConvertPricing converts all prices of a normalized pricing record, including price tiers
+// and amortized commitment rates, and sets its currency to the target currency
+func (c Conversion) ConvertPricing(pricing *database.NormalizedPricing) {
+	convert := func(amount *float64) *float64 {
+		if amount == nil {
+			return nil
+		}
+		converted := c.Apply(*amount)
+		return &converted
+	}
+
+	pricing.PricePerUnit = c.Apply(pricing.PricePerUnit)
+	pricing.PricingDetails.UpfrontCost = convert(pricing.PricingDetails.UpfrontCost)
+	pricing.PricingDetails.HourlyRate = convert(pricing.PricingDetails.HourlyRate)
+	pricing.PricingDetails.EffectiveHourlyRate = convert(pricing.PricingDetails.EffectiveHourlyRate)
+	pricing.PricingDetails.EffectiveMonthlyRate = convert(pricing.PricingDetails.EffectiveMonthlyRate)
+	for i := range pricing.PriceTiers {
+		pricing.PriceTiers[i].PricePerUnit = c.Apply(pricing.PriceTiers[i].PricePerUnit)
+	}
+	pricing.Currency = c.ToCurrency
+}
+
+// NormalizeCode validates an ISO 4217 currency code and returns it in upper case
+func NormalizeCode(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return "", fmt.Errorf("%w %q: must be a 3-letter ISO code", ErrInvalidCurrency, code)
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", fmt.Errorf("%w %q: must be a 3-letter ISO code", ErrInvalidCurrency, code)
+		}
+	}
+	return code, nil
+}
+
+// IsRequestError reports whether a conversion error is caused by the requested currency rather
+// than by the rate repository
+func IsRequestError(err error) bool {
+	return errors.Is(err, ErrInvalidCurrency) || errors.Is(err, ErrRateNotFound)
+}
+
+// maxCachedDays bounds the rate cache. Point-in-time queries can ask for any day, so the days
+// used least recently are evicted once more days are cached.
+const maxCachedDays = 32
+
+// Service converts prices between currencies using the exchange rates in a repository.
+// Rates are cached per currency pair and day until new rates are loaded.
+type Service struct {
+	repo  RateRepository
+	mu    sync.Mutex
+	cache map[string]*cachedDay
+	uses  uint64
+}
+
+// cachedDay holds the conversions of one day, keyed by currency pair
+type cachedDay struct {
+	conversions map[string]*Conversion
+	lastUsed    uint64
+}
+
+// NewService creates a conversion service backed by a rate repository
+func NewService(repo RateRepository) *Service {
+	return &Service{
+		repo:  repo,
+		cache: make(map[string]*cachedDay),
+	}
+}
+
+// cached returns the cached conversion of a currency pair on a day
+func (s *Service) cached(day, pair string) (*Conversion, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.cache[day]
+	if !ok {
+		return nil, false
+	}
+	s.uses++
+	entry.lastUsed = s.uses
+	conversion, ok := entry.conversions[pair]
+	return conversion, ok
+}
+
+// store caches the conversion of a currency pair on a day, evicting the least recently used
+// day when the cache is full
+func (s *Service) store(day, pair string, conversion *Conversion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.cache[day]
+	if !ok {
+		if len(s.cache) >= maxCachedDays {
+			var oldest string
+			for cachedDay, candidate := range s.cache {
+				if oldest == "" || candidate.lastUsed < s.cache[oldest].lastUsed {
+					oldest = cachedDay
+				}
+			}
+			delete(s.cache, oldest)
+		}
+		entry = &cachedDay{conversions: make(map[string]*Conversion)}
+		s.cache[day] = entry
+	}
+	s.uses++
+	entry.lastUsed = s.uses
+	entry.conversions[pair] = conversion
+}
+
+// Load stores the rates of a rate source and clears the rate cache
+func (s *Service) Load(ctx context.Context, source RateSource) (int, error) {
+	rates, err := source.FetchRates(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch exchange rates from %s: %w", source.Name(), err)
+	}
+
+	count, err := s.repo.UpsertExchangeRates(ctx, rates)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	s.cache = make(map[string]*cachedDay)
+	s.mu.Unlock()
+
+	return count, nil
+}
+
+// Conversion returns the rate for converting from one currency to another that is effective
+// on the given date. It uses a direct rate, the inverse of the reverse rate, or a cross rate
+// through PivotCurrency, in that order. A cross rate is dated by the older of its two legs.
+func (s *Service) Conversion(ctx context.Context, fromCurrency, toCurrency string, asOf time.Time) (*Conversion, error) {
+	from, err := NormalizeCode(fromCurrency)
+	if err != nil {
+		return nil, err
+	}
+	to, err := NormalizeCode(toCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	day := asOf.UTC().Truncate(24 * time.Hour)
+	if from == to {
+		return &Conversion{FromCurrency: from, ToCurrency: to, Rate: 1, RateDate: day}, nil
+	}
+
+	dayKey := day.Format("2006-01-02")
+	pairKey := from + "/" + to
+	if cached, ok := s.cached(dayKey, pairKey); ok {
+		return cached, nil
+	}
+
+	conversion, err := s.pairConversion(ctx, from, to, day)
+	if err != nil {
+		return nil, err
+	}
+	if conversion == nil && from != PivotCurrency && to != PivotCurrency {
+		conversion, err = s.crossConversion(ctx, from, to, day)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if conversion == nil {
+		return nil, fmt.Errorf("%w: %s/%s as of %s", ErrRateNotFound, from, to, dayKey)
+	}
+
+	s.store(dayKey, pairKey, conversion)
+	return conversion, nil
+}
+
+// pairConversion looks up the direct or inverse rate of a currency pair
+func (s *Service) pairConversion(ctx context.Context, from, to string, asOf time.Time) (*Conversion, error) {
+	rate, err := s.repo.GetExchangeRate(ctx, from, to, asOf)
+	if err != nil {
+		return nil, err
+	}
+	if rate != nil {
+		return &Conversion{FromCurrency: from, ToCurrency: to, Rate: rate.Rate, RateDate: rate.EffectiveDate}, nil
+	}
+
+	inverse, err := s.repo.GetExchangeRate(ctx, to, from, asOf)
+	if err != nil {
+		return nil, err
+	}
+	if inverse != nil && inverse.Rate > 0 {
+		return &Conversion{FromCurrency: from, ToCurrency: to, Rate: 1 / inverse.Rate, RateDate: inverse.EffectiveDate}, nil
+	}
+
+	return nil, nil
+}
+
+// crossConversion derives a rate through PivotCurrency
+func (s *Service) crossConversion(ctx context.Context, from, to string, asOf time.Time) (*Conversion, error) {
+	toPivot, err := s.pairConversion(ctx, from, PivotCurrency, asOf)
+	if err != nil || toPivot == nil {
+		return nil, err
+	}
+	fromPivot, err := s.pairConversion(ctx, PivotCurrency, to, asOf)
+	if err != nil || fromPivot == nil {
+		return nil, err
+	}
+
+	rateDate := toPivot.RateDate
+	if fromPivot.RateDate.Before(rateDate) {
+		rateDate = fromPivot.RateDate
+	}
+
+	return &Conversion{
+		FromCurrency: from,
+		ToCurrency:   to,
+		Rate:         toPivot.Rate * fromPivot.Rate,
+		RateDate:     rateDate,
+	}, nil
+}
+
+// ConvertPricing converts a normalized pricing record to the target currency in place and
+// returns the conversion used
+func (s *Service) ConvertPricing(ctx context.Context, pricing *database.NormalizedPricing, toCurrency string, asOf time.Time) (*Conversion, error) {
+	conversion, err := s.Conversion(ctx, pricing.Currency, toCurrency, asOf)
+	if err != nil {
+		return nil, err
+	}
+	conversion.ConvertPricing(pricing)
+	return conversion, nil
+}
+
+// ConvertPricings converts normalized pricing records to the target currency in place and
+// returns the conversion used for each record
+func (s *Service) ConvertPricings(ctx context.Context, pricings []database.NormalizedPricing, toCurrency string, asOf time.Time) ([]*Conversion, error) {
+	conversions := make([]*Conversion, len(pricings))
+	for i := range pricings {
+		conversion, err := s.ConvertPricing(ctx, &pricings[i], toCurrency, asOf)
+		if err != nil {
+			return nil, err
+		}
+		conversions[i] = conversion
+	}
+	return conversions, nil
+}
+
+// ComparisonConversions are the conversions used for the offers of a pricing comparison
+type ComparisonConversions struct {
+	AWS   *Conversion `json:"aws,omitempty"`
+	Azure *Conversion `json:"azure,omitempty"`
+}
+
+// ConvertComparisons converts the offers of pricing comparisons to the target currency,
+// recomputing the price difference of matched pairs, and returns the conversions used for
+// each comparison. Matching can pair an offer with several others, so each comparison gets a
+// converted copy of its offers.
+func (s *Service) ConvertComparisons(ctx context.Context, comparisons []database.PricingComparison, toCurrency string, asOf time.Time) ([]ComparisonConversions, error) {
+	conversions := make([]ComparisonConversions, len(comparisons))
+	for i := range comparisons {
+		comparison := &comparisons[i]
+		var err error
+		if comparison.AWS, conversions[i].AWS, err = s.convertOffer(ctx, comparison.AWS, toCurrency, asOf); err != nil {
+			return nil, err
+		}
+		if comparison.Azure, conversions[i].Azure, err = s.convertOffer(ctx, comparison.Azure, toCurrency, asOf); err != nil {
+			return nil, err
+		}
+		if comparison.PriceDifference != nil && comparison.AWS != nil && comparison.Azure != nil {
+			difference := comparison.AWS.GetHourlyPrice() - comparison.Azure.GetHourlyPrice()
+			comparison.PriceDifference = &difference
+		}
+	}
+	return conversions, nil
+}
+
+// convertOffer returns a converted copy of a comparison offer, or nil for a missing offer
+func (s *Service) convertOffer(ctx context.Context, offer *database.NormalizedPricing, toCurrency string, asOf time.Time) (*database.NormalizedPricing, *Conversion, error) {
+	if offer == nil {
+		return nil, nil, nil
+	}
+	converted := *offer
+	converted.PriceTiers = append([]database.PriceTier(nil), offer.PriceTiers...)
+	conversion, err := s.ConvertPricing(ctx, &converted, toCurrency, asOf)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &converted, conversion, nil
+}
@@ -0,0 +1,311 @@
+package currency
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRateRepository returns the latest stored rate of a pair effective on the requested date
+// and counts the lookups
+type fakeRateRepository struct {
+	mu      sync.Mutex
+	rates   []database.ExchangeRate
+	lookups int
+}
+
+func (r *fakeRateRepository) UpsertExchangeRates(ctx context.Context, rates []database.ExchangeRate) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rates = append(r.rates, rates...)
+	return len(rates), nil
+}
+
+func (r *fakeRateRepository) GetExchangeRate(ctx context.Context, baseCurrency, quoteCurrency string, asOf time.Time) (*database.ExchangeRate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lookups++
+	var latest *database.ExchangeRate
+	for i, rate := range r.rates {
+		if rate.BaseCurrency != baseCurrency || rate.QuoteCurrency != quoteCurrency || rate.EffectiveDate.After(asOf) {
+			continue
+		}
+		if latest == nil || rate.EffectiveDate.After(latest.EffectiveDate) {
+			latest = &r.rates[i]
+		}
+	}
+	return latest, nil
+}
+
+func (r *fakeRateRepository) lookupCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.lookups
+}
+
+// staticRateSource offers a fixed list of rates
+type staticRateSource []database.ExchangeRate
+
+func (s staticRateSource) Name() string { return "static" }
+
+func (s staticRateSource) FetchRates(ctx context.Context) ([]database.ExchangeRate, error) {
+	return s, nil
+}
+
+func date(value string) time.Time {
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func testRates() []database.ExchangeRate {
+	return []database.ExchangeRate{
+		{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: 0.9, EffectiveDate: date("2026-01-01")},
+		{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: 0.8, EffectiveDate: date("2026-06-01")},
+		{BaseCurrency: "GBP", QuoteCurrency: "USD", Rate: 1.25, EffectiveDate: date("2026-03-01")},
+		{BaseCurrency: "USD", QuoteCurrency: "JPY", Rate: 150, EffectiveDate: date("2026-02-01")},
+	}
+}
+
+func TestService_Conversion(t *testing.T) {
+	tests := []struct {
+		name             string
+		from, to         string
+		asOf             time.Time
+		expectedRate     float64
+		expectedRateDate time.Time
+		expectedErr      error
+	}{
+		{
+			name:             "same currency",
+			from:             "EUR",
+			to:               "eur",
+			asOf:             date("2025-01-01").Add(15 * time.Hour),
+			expectedRate:     1,
+			expectedRateDate: date("2025-01-01"),
+		},
+		{
+			name:             "direct rate",
+			from:             "USD",
+			to:               "EUR",
+			asOf:             date("2026-02-15"),
+			expectedRate:     0.9,
+			expectedRateDate: date("2026-01-01"),
+		},
+		{
+			name:             "latest direct rate effective on the date",
+			from:             "usd",
+			to:               "eur",
+			asOf:             date("2026-07-01"),
+			expectedRate:     0.8,
+			expectedRateDate: date("2026-06-01"),
+		},
+		{
+			name:             "inverse rate",
+			from:             "EUR",
+			to:               "USD",
+			asOf:             date("2026-02-15"),
+			expectedRate:     1 / 0.9,
+			expectedRateDate: date("2026-01-01"),
+		},
+		{
+			name:             "cross rate via USD dated by the older leg",
+			from:             "GBP",
+			to:               "EUR",
+			asOf:             date("2026-07-01"),
+			expectedRate:     1.25 * 0.8,
+			expectedRateDate: date("2026-03-01"),
+		},
+		{
+			name:             "cross rate via USD from inverse legs",
+			from:             "JPY",
+			to:               "GBP",
+			asOf:             date("2026-04-01"),
+			expectedRate:     1 / 150.0 / 1.25,
+			expectedRateDate: date("2026-02-01"),
+		},
+		{
+			name:        "no rate effective yet",
+			from:        "USD",
+			to:          "EUR",
+			asOf:        date("2025-12-31"),
+			expectedErr: ErrRateNotFound,
+		},
+		{
+			name:        "cross rate with a missing leg",
+			from:        "GBP",
+			to:          "EUR",
+			asOf:        date("2026-02-01"),
+			expectedErr: ErrRateNotFound,
+		},
+		{
+			name:        "unknown currency",
+			from:        "USD",
+			to:          "CHF",
+			asOf:        date("2026-07-01"),
+			expectedErr: ErrRateNotFound,
+		},
+		{
+			name:        "invalid currency code",
+			from:        "US",
+			to:          "EUR",
+			asOf:        date("2026-07-01"),
+			expectedErr: ErrInvalidCurrency,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(&fakeRateRepository{rates: testRates()})
+
+			conversion, err := service.Conversion(context.Background(), tt.from, tt.to, tt.asOf)
+
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				assert.True(t, IsRequestError(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expectedRate, conversion.Rate, 1e-12)
+			assert.Equal(t, tt.expectedRateDate, conversion.RateDate)
+		})
+	}
+}
+
+func TestService_Conversion_CachesPerDay(t *testing.T) {
+	repo := &fakeRateRepository{rates: testRates()}
+	service := NewService(repo)
+	ctx := context.Background()
+
+	first, err := service.Conversion(ctx, "USD", "EUR", date("2026-02-15").Add(time.Hour))
+	require.NoError(t, err)
+	lookups := repo.lookupCount()
+
+	// Any time of the same day is answered from the cache
+	second, err := service.Conversion(ctx, "USD", "EUR", date("2026-02-15").Add(20*time.Hour))
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Equal(t, lookups, repo.lookupCount())
+
+	// Another day and another pair are looked up
+	_, err = service.Conversion(ctx, "USD", "EUR", date("2026-02-16"))
+	require.NoError(t, err)
+	assert.Greater(t, repo.lookupCount(), lookups)
+
+	lookups = repo.lookupCount()
+	_, err = service.Conversion(ctx, "EUR", "USD", date("2026-02-15"))
+	require.NoError(t, err)
+	assert.Greater(t, repo.lookupCount(), lookups)
+
+	// Loading rates clears the cache, so new rates apply immediately
+	_, err = service.Load(ctx, staticRateSource{
+		{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: 0.95, EffectiveDate: date("2026-02-10")},
+	})
+	require.NoError(t, err)
+	reloaded, err := service.Conversion(ctx, "USD", "EUR", date("2026-02-15"))
+	require.NoError(t, err)
+	assert.Equal(t, 0.95, reloaded.Rate)
+}
+
+func TestService_Conversion_EvictsLeastRecentlyUsedDays(t *testing.T) {
+	repo := &fakeRateRepository{rates: testRates()}
+	service := NewService(repo)
+	ctx := context.Background()
+
+	start := date("2026-07-01")
+	for i := 0; i < maxCachedDays; i++ {
+		_, err := service.Conversion(ctx, "USD", "EUR", start.AddDate(0, 0, i))
+		require.NoError(t, err)
+	}
+
+	// The first day is used again, so the second one is the least recently used
+	_, err := service.Conversion(ctx, "USD", "EUR", start)
+	require.NoError(t, err)
+	_, err = service.Conversion(ctx, "USD", "EUR", start.AddDate(0, 0, maxCachedDays))
+	require.NoError(t, err)
+
+	assert.Len(t, service.cache, maxCachedDays)
+	assert.Contains(t, service.cache, "2026-07-01")
+	assert.NotContains(t, service.cache, "2026-07-02")
+
+	lookups := repo.lookupCount()
+	_, err = service.Conversion(ctx, "USD", "EUR", start)
+	require.NoError(t, err)
+	assert.Equal(t, lookups, repo.lookupCount(), "the first day is still cached")
+}
+
+func TestConversion_ConvertPricing(t *testing.T) {
+	upfront := 100.0
+	effectiveHourly := 0.05
+	pricing := database.NormalizedPricing{
+		PricePerUnit: 0.1,
+		Currency:     "USD",
+		PricingDetails: database.PricingDetails{
+			UpfrontCost:         &upfront,
+			EffectiveHourlyRate: &effectiveHourly,
+		},
+		PriceTiers: []database.PriceTier{{StartUnits: 0, PricePerUnit: 0.2}},
+	}
+
+	Conversion{FromCurrency: "USD", ToCurrency: "EUR", Rate: 0.5}.ConvertPricing(&pricing)
+
+	assert.Equal(t, "EUR", pricing.Currency)
+	assert.InDelta(t, 0.05, pricing.PricePerUnit, 1e-12)
+	assert.InDelta(t, 50, *pricing.PricingDetails.UpfrontCost, 1e-12)
+	assert.InDelta(t, 0.025, *pricing.PricingDetails.EffectiveHourlyRate, 1e-12)
+	assert.Nil(t, pricing.PricingDetails.HourlyRate)
+	assert.InDelta(t, 0.1, pricing.PriceTiers[0].PricePerUnit, 1e-12)
+}
+
+func TestService_ConvertComparisons(t *testing.T) {
+	service := NewService(&fakeRateRepository{rates: testRates()})
+	aws := &database.NormalizedPricing{
+		PricePerUnit: 0.2,
+		Currency:     "USD",
+		PriceTiers:   []database.PriceTier{{StartUnits: 0, PricePerUnit: 0.2}},
+	}
+	difference := 0.1
+	comparisons := []database.PricingComparison{
+		{
+			AWS:             aws,
+			Azure:           &database.NormalizedPricing{PricePerUnit: 0.1, Currency: "USD"},
+			PriceDifference: &difference,
+		},
+		{
+			AWS:             aws,
+			Azure:           &database.NormalizedPricing{PricePerUnit: 0.25, Currency: "GBP"},
+			PriceDifference: &difference,
+		},
+		{Azure: &database.NormalizedPricing{PricePerUnit: 0.3, Currency: "USD"}},
+	}
+
+	conversions, err := service.ConvertComparisons(context.Background(), comparisons, "EUR", date("2026-07-01"))
+	require.NoError(t, err)
+	require.Len(t, conversions, 3)
+
+	assert.InDelta(t, 0.16, comparisons[0].AWS.PricePerUnit, 1e-12)
+	assert.InDelta(t, 0.16, comparisons[1].AWS.PricePerUnit, 1e-12, "an offer in several pairs is converted once per pair")
+	assert.InDelta(t, 0.16, comparisons[1].AWS.PriceTiers[0].PricePerUnit, 1e-12)
+	assert.Equal(t, 0.2, aws.PricePerUnit, "the matched offer itself is left unchanged")
+	assert.Equal(t, 0.2, aws.PriceTiers[0].PricePerUnit)
+
+	assert.InDelta(t, 0.08, *comparisons[0].PriceDifference, 1e-12)
+	assert.InDelta(t, 0.16-0.25, *comparisons[1].PriceDifference, 1e-12, "GBP 0.25 is EUR 0.25 through USD")
+	assert.Equal(t, "EUR", comparisons[1].Azure.Currency)
+	assert.Equal(t, 1.0, conversions[1].Azure.Rate)
+
+	assert.Nil(t, comparisons[2].AWS)
+	assert.Nil(t, conversions[2].AWS)
+	assert.Nil(t, comparisons[2].PriceDifference)
+	assert.InDelta(t, 0.24, comparisons[2].Azure.PricePerUnit, 1e-12)
+}
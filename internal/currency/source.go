@@ -0,0 +1,158 @@
+package currency
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+)
+
+// RateSource provides exchange rates to load into the rate repository. Implementations
+// may read local files or query an external rates provider.
+type RateSource interface {
+	// Name identifies the source; it is stored with each rate
+	Name() string
+	// FetchRates returns the rates currently offered by the source
+	FetchRates(ctx context.Context) ([]database.ExchangeRate, error)
+}
+
+// FileRateSource reads exchange rates from a local CSV or JSON file, chosen by extension.
+//
+// CSV files have a header row with base_currency, quote_currency, rate and effective_date
+// columns. JSON files hold an array of objects with the same keys. Dates use YYYY-MM-DD.
+type FileRateSource struct {
+	Path string
+}
+
+// NewFileRateSource creates a rate source for a CSV or JSON file
+func NewFileRateSource(path string) *FileRateSource {
+	return &FileRateSource{Path: path}
+}
+
+// Name returns the source name stored with the loaded rates
+func (s *FileRateSource) Name() string {
+	return "file:" + filepath.Base(s.Path)
+}
+
+// fileRate is a single exchange rate entry of a rates file
+type fileRate struct {
+	BaseCurrency  string  `json:"base_currency"`
+	QuoteCurrency string  `json:"quote_currency"`
+	Rate          float64 `json:"rate"`
+	EffectiveDate string  `json:"effective_date"`
+}
+
+// FetchRates reads and validates all rates of the file
+func (s *FileRateSource) FetchRates(ctx context.Context) ([]database.ExchangeRate, error) {
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rates file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []fileRate
+	switch strings.ToLower(filepath.Ext(s.Path)) {
+	case ".csv":
+		entries, err = readCSVRates(file)
+	case ".json":
+		err = json.NewDecoder(file).Decode(&entries)
+	default:
+		return nil, fmt.Errorf("unsupported rates file format %q: use .csv or .json", filepath.Ext(s.Path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file %s: %w", s.Path, err)
+	}
+
+	rates := make([]database.ExchangeRate, 0, len(entries))
+	for i, entry := range entries {
+		rate, err := entry.toExchangeRate(s.Name())
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate %d in %s: %w", i+1, s.Path, err)
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+// readCSVRates parses a rates CSV file, locating columns by the header row
+func readCSVRates(r io.Reader) ([]fileRate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"base_currency", "quote_currency", "rate", "effective_date"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %s", name)
+		}
+	}
+
+	var entries []fileRate
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(record[columns["rate"]]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate %q: %w", record[columns["rate"]], err)
+		}
+		entries = append(entries, fileRate{
+			BaseCurrency:  record[columns["base_currency"]],
+			QuoteCurrency: record[columns["quote_currency"]],
+			Rate:          rate,
+			EffectiveDate: strings.TrimSpace(record[columns["effective_date"]]),
+		})
+	}
+
+	return entries, nil
+}
+
+// toExchangeRate validates a file entry and converts it to an exchange rate
+func (e fileRate) toExchangeRate(source string) (database.ExchangeRate, error) {
+	base, err := NormalizeCode(e.BaseCurrency)
+	if err != nil {
+		return database.ExchangeRate{}, err
+	}
+	quote, err := NormalizeCode(e.QuoteCurrency)
+	if err != nil {
+		return database.ExchangeRate{}, err
+	}
+	if base == quote {
+		return database.ExchangeRate{}, fmt.Errorf("base and quote currency are both %s", base)
+	}
+	if e.Rate <= 0 {
+		return database.ExchangeRate{}, fmt.Errorf("rate for %s/%s must be positive", base, quote)
+	}
+	effectiveDate, err := time.Parse("2006-01-02", e.EffectiveDate)
+	if err != nil {
+		return database.ExchangeRate{}, fmt.Errorf("invalid effective date %q: %w", e.EffectiveDate, err)
+	}
+
+	return database.ExchangeRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          e.Rate,
+		EffectiveDate: effectiveDate,
+		Source:        source,
+	}, nil
+}
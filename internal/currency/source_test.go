@@ -0,0 +1,145 @@
+package currency
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRatesFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestFileRateSource_FetchRates(t *testing.T) {
+	expected := []database.ExchangeRate{
+		{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: 0.92, EffectiveDate: date("2026-01-02")},
+		{BaseCurrency: "GBP", QuoteCurrency: "USD", Rate: 1.27, EffectiveDate: date("2026-01-03")},
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "csv",
+			file: "rates.csv",
+			content: "base_currency,quote_currency,rate,effective_date\n" +
+				"usd,eur,0.92,2026-01-02\n" +
+				"GBP,USD,1.27,2026-01-03\n",
+		},
+		{
+			name: "csv with reordered columns and spaces",
+			file: "rates.CSV",
+			content: "Effective_Date, rate, Quote_Currency, Base_Currency\n" +
+				"2026-01-02, 0.92, EUR, USD\n" +
+				" 2026-01-03 , 1.27 , USD, GBP\n",
+		},
+		{
+			name: "json",
+			file: "rates.json",
+			content: `[
+				{"base_currency": "USD", "quote_currency": "eur", "rate": 0.92, "effective_date": "2026-01-02"},
+				{"base_currency": "GBP", "quote_currency": "USD", "rate": 1.27, "effective_date": "2026-01-03"}
+			]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := NewFileRateSource(writeRatesFile(t, tt.file, tt.content))
+
+			rates, err := source.FetchRates(context.Background())
+
+			require.NoError(t, err)
+			require.Len(t, rates, len(expected))
+			for i, rate := range rates {
+				assert.Equal(t, "file:"+tt.file, rate.Source)
+				rate.Source = ""
+				assert.Equal(t, expected[i], rate)
+			}
+		})
+	}
+}
+
+func TestFileRateSource_FetchRates_Errors(t *testing.T) {
+	const header = "base_currency,quote_currency,rate,effective_date\n"
+
+	tests := []struct {
+		name          string
+		file          string
+		content       string
+		expectedError string
+	}{
+		{
+			name:          "missing column",
+			file:          "rates.csv",
+			content:       "base_currency,quote_currency,rate\nUSD,EUR,0.92\n",
+			expectedError: "missing column effective_date",
+		},
+		{
+			name:          "rate is not a number",
+			file:          "rates.csv",
+			content:       header + "USD,EUR,abc,2026-01-02\n",
+			expectedError: `invalid rate "abc"`,
+		},
+		{
+			name:          "same base and quote currency",
+			file:          "rates.csv",
+			content:       header + "USD,usd,1,2026-01-02\n",
+			expectedError: "base and quote currency are both USD",
+		},
+		{
+			name:          "rate not positive",
+			file:          "rates.json",
+			content:       `[{"base_currency": "USD", "quote_currency": "EUR", "rate": 0, "effective_date": "2026-01-02"}]`,
+			expectedError: "rate for USD/EUR must be positive",
+		},
+		{
+			name:          "invalid currency code",
+			file:          "rates.json",
+			content:       `[{"base_currency": "DOLLAR", "quote_currency": "EUR", "rate": 1, "effective_date": "2026-01-02"}]`,
+			expectedError: "invalid currency code",
+		},
+		{
+			name:          "invalid date",
+			file:          "rates.csv",
+			content:       header + "USD,EUR,0.92,02/01/2026\n",
+			expectedError: `invalid effective date "02/01/2026"`,
+		},
+		{
+			name:          "unsupported format",
+			file:          "rates.xml",
+			content:       "<rates/>",
+			expectedError: `unsupported rates file format ".xml"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := NewFileRateSource(writeRatesFile(t, tt.file, tt.content))
+
+			_, err := source.FetchRates(context.Background())
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
+
+func TestFileRateSource_FetchRates_MissingFile(t *testing.T) {
+	source := NewFileRateSource(filepath.Join(t.TempDir(), "missing.csv"))
+
+	_, err := source.FetchRates(context.Background())
+
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
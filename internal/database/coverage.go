@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// CoverageGroup counts the raw pricing records of a provider service in a region, and how many
+// of them reached normalized_pricing
+type CoverageGroup struct {
+	Provider          string `json:"provider"`
+	Service           string `json:"service"`
+	Region            string `json:"region"`
+	RawRecords        int    `json:"rawRecords"`
+	NormalizedRecords int    `json:"normalizedRecords"`
+	ServiceMapped     bool   `json:"serviceMapped"`
+	RegionMapped      bool   `json:"regionMapped"`
+}
+
+// rawCoverageSource describes how the ETL reads a provider's raw table: the columns used as
+// service code and region, and the normalized_pricing and normalized_regions columns matching them
+type rawCoverageSource struct {
+	table           string
+	serviceColumn   string
+	regionColumn    string
+	rawIDColumn     string
+	regionMapColumn string
+}
+
+var rawCoverageSources = map[string]rawCoverageSource{
+	ProviderAWS: {
+		table:           "aws_pricing_raw",
+		serviceColumn:   "service_code",
+		regionColumn:    "location",
+		rawIDColumn:     "aws_raw_id",
+		regionMapColumn: "aws_region",
+	},
+	ProviderAzure: {
+		table:           "azure_pricing_raw",
+		serviceColumn:   "service_name",
+		regionColumn:    "region",
+		rawIDColumn:     "azure_raw_id",
+		regionMapColumn: "azure_region",
+	},
+}
+
+// GetNormalizationCoverage counts raw records per provider service and region, along with the
+// records that were normalized and whether the service and region have mappings. Mappings are
+// matched the same way the normalizer repositories match them.
+func (db *DB) GetNormalizationCoverage(ctx context.Context, provider string) ([]CoverageGroup, error) {
+	source, ok := rawCoverageSources[provider]
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider: %s", provider)
+	}
+
+	query := fmt.Sprintf(`
+		WITH groups AS (
+			SELECT COALESCE(r.%[2]s, '') AS service, COALESCE(r.%[3]s, '') AS region,
+			       COUNT(*) AS raw_records,
+			       COUNT(*) FILTER (WHERE EXISTS (
+			           SELECT 1 FROM normalized_pricing np WHERE np.%[4]s = r.id
+			       )) AS normalized_records
+			FROM %[1]s r
+			GROUP BY 1, 2
+		)
+		SELECT g.service, g.region, g.raw_records, g.normalized_records,
+		       EXISTS (
+		           SELECT 1 FROM service_mappings sm
+		           WHERE sm.provider = $1
+		             AND (sm.provider_service_name = g.service OR sm.provider_service_code = g.service)
+		       ) AS service_mapped,
+		       EXISTS (
+		           SELECT 1 FROM normalized_regions nr WHERE nr.%[5]s = g.region
+		       ) AS region_mapped
+		FROM groups g
+		ORDER BY g.raw_records DESC, g.service, g.region`,
+		source.table, source.serviceColumn, source.regionColumn, source.rawIDColumn, source.regionMapColumn)
+
+	rows, err := db.conn.QueryContext(ctx, query, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s normalization coverage: %w", provider, err)
+	}
+	defer rows.Close()
+
+	var groups []CoverageGroup
+	for rows.Next() {
+		group := CoverageGroup{Provider: provider}
+		if err := rows.Scan(
+			&group.Service,
+			&group.Region,
+			&group.RawRecords,
+			&group.NormalizedRecords,
+			&group.ServiceMapped,
+			&group.RegionMapped,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan coverage group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating coverage groups: %w", err)
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ETLJobRecord represents the persisted state of an ETL job
+type ETLJobRecord struct {
+	ID            string          `json:"id" db:"id"`
+	JobType       string          `json:"jobType" db:"job_type"`
+	Provider      *string         `json:"provider" db:"provider"`
+	Status        string          `json:"status" db:"status"`
+	Configuration json.RawMessage `json:"configuration" db:"configuration"`
+	TargetTable   *string         `json:"targetTable" db:"target_table"`
+	Error         *string         `json:"error" db:"error"`
+	StartedAt     time.Time       `json:"startedAt" db:"started_at"`
+	CompletedAt   *time.Time      `json:"completedAt" db:"completed_at"`
+}
+
+// ETLBatchCheckpoint records a batch range that an ETL job has fully processed
+type ETLBatchCheckpoint struct {
+	JobID             string `json:"jobId" db:"job_id"`
+	Provider          string `json:"provider" db:"provider"`
+	BatchOffset       int    `json:"batchOffset" db:"batch_offset"`
+	BatchSize         int    `json:"batchSize" db:"batch_size"`
+	FirstRawID        *int   `json:"firstRawId" db:"first_raw_id"`
+	LastRawID         *int   `json:"lastRawId" db:"last_raw_id"`
+	ProcessedRecords  int    `json:"processedRecords" db:"processed_records"`
+	NormalizedRecords int    `json:"normalizedRecords" db:"normalized_records"`
+	SkippedRecords    int    `json:"skippedRecords" db:"skipped_records"`
+	ErrorRecords      int    `json:"errorRecords" db:"error_records"`
+}
+
+// SaveETLJob inserts or updates the persisted state of an ETL job
+func (db *DB) SaveETLJob(ctx context.Context, job ETLJobRecord) error {
+	query := `
+		INSERT INTO etl_jobs (
+			id, job_type, provider, status, configuration, target_table,
+			error, started_at, completed_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, CURRENT_TIMESTAMP)
+		ON CONFLICT (id) DO UPDATE SET
+			provider = EXCLUDED.provider,
+			status = EXCLUDED.status,
+			configuration = EXCLUDED.configuration,
+			target_table = EXCLUDED.target_table,
+			error = EXCLUDED.error,
+			completed_at = EXCLUDED.completed_at,
+			updated_at = CURRENT_TIMESTAMP`
+
+	_, err := db.conn.ExecContext(ctx, query,
+		job.ID,
+		job.JobType,
+		job.Provider,
+		job.Status,
+		string(job.Configuration),
+		job.TargetTable,
+		job.Error,
+		job.StartedAt,
+		job.CompletedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save ETL job %s: %w", job.ID, err)
+	}
+
+	return nil
+}
+
+// GetETLJobsByStatus retrieves persisted ETL jobs in any of the given statuses
+func (db *DB) GetETLJobsByStatus(ctx context.Context, statuses ...string) ([]ETLJobRecord, error) {
+	query := `
+		SELECT id, job_type, provider, status, configuration, target_table,
+		       error, started_at, completed_at
+		FROM etl_jobs
+		WHERE status = ANY($1)
+		ORDER BY started_at`
+
+	rows, err := db.conn.QueryContext(ctx, query, pq.Array(statuses))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ETL jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []ETLJobRecord
+	for rows.Next() {
+		var job ETLJobRecord
+		var configuration []byte
+		err := rows.Scan(
+			&job.ID,
+			&job.JobType,
+			&job.Provider,
+			&job.Status,
+			&configuration,
+			&job.TargetTable,
+			&job.Error,
+			&job.StartedAt,
+			&job.CompletedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ETL job: %w", err)
+		}
+		job.Configuration = json.RawMessage(configuration)
+		jobs = append(jobs, job)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ETL jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// GetETLJobCheckpoints retrieves all completed batch checkpoints of an ETL job
+func (db *DB) GetETLJobCheckpoints(ctx context.Context, jobID string) ([]ETLBatchCheckpoint, error) {
+	query := `
+		SELECT job_id, provider, batch_offset, batch_size, first_raw_id, last_raw_id,
+		       processed_records, normalized_records, skipped_records, error_records
+		FROM etl_job_checkpoints
+		WHERE job_id = $1
+		ORDER BY provider, batch_offset`
+
+	rows, err := db.conn.QueryContext(ctx, query, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ETL job checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var checkpoints []ETLBatchCheckpoint
+	for rows.Next() {
+		var checkpoint ETLBatchCheckpoint
+		err := rows.Scan(
+			&checkpoint.JobID,
+			&checkpoint.Provider,
+			&checkpoint.BatchOffset,
+			&checkpoint.BatchSize,
+			&checkpoint.FirstRawID,
+			&checkpoint.LastRawID,
+			&checkpoint.ProcessedRecords,
+			&checkpoint.NormalizedRecords,
+			&checkpoint.SkippedRecords,
+			&checkpoint.ErrorRecords,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ETL job checkpoint: %w", err)
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ETL job checkpoints: %w", err)
+	}
+
+	return checkpoints, nil
+}
+
+// DeleteETLJobCheckpoints removes all batch checkpoints of an ETL job
+func (db *DB) DeleteETLJobCheckpoints(ctx context.Context, jobID string) error {
+	_, err := db.conn.ExecContext(ctx, `DELETE FROM etl_job_checkpoints WHERE job_id = $1`, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to delete ETL job checkpoints: %w", err)
+	}
+
+	return nil
+}
+
+// BulkInsertNormalizedPricingWithCheckpoint inserts a batch of normalized pricing records and
+// records its checkpoint in the same transaction, so a batch is either fully committed or not at all
+func (db *DB) BulkInsertNormalizedPricingWithCheckpoint(table string, pricings []NormalizedPricing, checkpoint ETLBatchCheckpoint) error {
+	if table != NormalizedPricingTable && table != NormalizedPricingStagingTable {
+		return fmt.Errorf("unsupported normalized pricing table: %s", table)
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if len(pricings) > 0 {
+		if err := insertNormalizedPricingRecords(tx, table, pricings); err != nil {
+			return err
+		}
+	}
+
+	if err := insertETLBatchCheckpoint(tx, checkpoint); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if len(pricings) > 0 {
+		log.Printf("✅ Bulk inserted %d normalized pricing records into %s (checkpoint %s/%d)",
+			len(pricings), table, checkpoint.Provider, checkpoint.BatchOffset)
+	}
+	return nil
+}
+
+// insertETLBatchCheckpoint records a completed batch within an open transaction
+func insertETLBatchCheckpoint(tx *sql.Tx, checkpoint ETLBatchCheckpoint) error {
+	query := `
+		INSERT INTO etl_job_checkpoints (
+			job_id, provider, batch_offset, batch_size, first_raw_id, last_raw_id,
+			processed_records, normalized_records, skipped_records, error_records
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (job_id, provider, batch_offset) DO NOTHING`
+
+	_, err := tx.Exec(query,
+		checkpoint.JobID,
+		checkpoint.Provider,
+		checkpoint.BatchOffset,
+		checkpoint.BatchSize,
+		checkpoint.FirstRawID,
+		checkpoint.LastRawID,
+		checkpoint.ProcessedRecords,
+		checkpoint.NormalizedRecords,
+		checkpoint.SkippedRecords,
+		checkpoint.ErrorRecords,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save ETL batch checkpoint: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ExchangeRate is the price of one unit of the base currency in the quote currency,
+// valid from its effective date until the next rate for the same pair
+type ExchangeRate struct {
+	BaseCurrency  string    `json:"baseCurrency" db:"base_currency"`
+	QuoteCurrency string    `json:"quoteCurrency" db:"quote_currency"`
+	Rate          float64   `json:"rate" db:"rate"`
+	EffectiveDate time.Time `json:"effectiveDate" db:"effective_date"`
+	Source        string    `json:"source" db:"source"`
+}
+
+// UpsertExchangeRates inserts exchange rates, replacing existing rates for the same
+// currency pair and effective date
+func (db *DB) UpsertExchangeRates(ctx context.Context, rates []ExchangeRate) (int, error) {
+	if len(rates) == 0 {
+		return 0, nil
+	}
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin exchange rate transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate, effective_date, source, updated_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+		ON CONFLICT (base_currency, quote_currency, effective_date) DO UPDATE SET
+			rate = EXCLUDED.rate,
+			source = EXCLUDED.source,
+			updated_at = CURRENT_TIMESTAMP`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare exchange rate insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, rate := range rates {
+		if _, err := stmt.ExecContext(ctx,
+			rate.BaseCurrency,
+			rate.QuoteCurrency,
+			rate.Rate,
+			rate.EffectiveDate,
+			rate.Source,
+		); err != nil {
+			return 0, fmt.Errorf("failed to upsert exchange rate %s/%s: %w", rate.BaseCurrency, rate.QuoteCurrency, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit exchange rates: %w", err)
+	}
+
+	log.Printf("💱 Loaded %d exchange rates", len(rates))
+	return len(rates), nil
+}
+
+// GetExchangeRate retrieves the latest rate for a currency pair that is effective on the
+// given date. It returns nil if no such rate exists.
+func (db *DB) GetExchangeRate(ctx context.Context, baseCurrency, quoteCurrency string, asOf time.Time) (*ExchangeRate, error) {
+	query := `
+		SELECT base_currency, quote_currency, rate, effective_date, source
+		FROM exchange_rates
+		WHERE base_currency = $1 AND quote_currency = $2 AND effective_date <= $3
+		ORDER BY effective_date DESC
+		LIMIT 1`
+
+	var rate ExchangeRate
+	err := db.conn.QueryRowContext(ctx, query, baseCurrency, quoteCurrency, asOf).Scan(
+		&rate.BaseCurrency,
+		&rate.QuoteCurrency,
+		&rate.Rate,
+		&rate.EffectiveDate,
+		&rate.Source,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil // Not found
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate %s/%s: %w", baseCurrency, quoteCurrency, err)
+	}
+
+	return &rate, nil
+}
+
+// GetExchangeRates returns every stored exchange rate, oldest first per currency pair
+func (db *DB) GetExchangeRates(ctx context.Context) ([]ExchangeRate, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT base_currency, quote_currency, rate, effective_date, source
+		FROM exchange_rates
+		ORDER BY base_currency, quote_currency, effective_date`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query exchange rates: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []ExchangeRate
+	for rows.Next() {
+		var rate ExchangeRate
+		if err := rows.Scan(&rate.BaseCurrency, &rate.QuoteCurrency, &rate.Rate, &rate.EffectiveDate, &rate.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan exchange rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read exchange rates: %w", err)
+	}
+	return rates, nil
+}
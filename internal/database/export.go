@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// ExportNormalizedPricing returns up to limit normalized prices with ids above afterID in id
+// order, for copying the table in batches
+func (db *DB) ExportNormalizedPricing(ctx context.Context, afterID, limit int) ([]NormalizedPricing, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM normalized_pricing
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2`, normalizedPricingSelectColumns)
+
+	rows, err := db.conn.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export normalized pricing: %w", err)
+	}
+	defer rows.Close()
+
+	var pricings []NormalizedPricing
+	for rows.Next() {
+		pricing, err := scanNormalizedPricing(rows)
+		if err != nil {
+			return nil, err
+		}
+		pricings = append(pricings, pricing)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read normalized pricing: %w", err)
+	}
+	return pricings, nil
+}
+
+// ExportPriceHistory returns up to limit price history versions with history ids above
+// afterID in history id order, along with the last history id returned
+func (db *DB) ExportPriceHistory(ctx context.Context, afterID int64, limit int) ([]PriceHistoryEntry, int64, error) {
+	query := fmt.Sprintf(`
+		SELECT %s, natural_key, valid_from, valid_to, history_id
+		FROM normalized_pricing_history
+		WHERE history_id > $1
+		ORDER BY history_id
+		LIMIT $2`, normalizedPricingSelectColumns)
+
+	rows, err := db.conn.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, afterID, fmt.Errorf("failed to export price history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []PriceHistoryEntry
+	lastID := afterID
+	for rows.Next() {
+		var entry PriceHistoryEntry
+		var validTo sql.NullTime
+		entry.NormalizedPricing, err = scanNormalizedPricing(rows, &entry.NaturalKey, &entry.ValidFrom, &validTo, &lastID)
+		if err != nil {
+			return nil, afterID, err
+		}
+		if validTo.Valid {
+			entry.ValidTo = &validTo.Time
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, afterID, fmt.Errorf("failed to read price history: %w", err)
+	}
+	return entries, lastID, nil
+}
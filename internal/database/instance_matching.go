@@ -0,0 +1,245 @@
+package database
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// MatchingConfig configures how equivalent instances are matched across providers. Tolerances
+// are the largest accepted relative difference (0.25 accepts 8 GB against 6 to 10.7 GB); weights
+// set how much each dimension counts towards the similarity score.
+type MatchingConfig struct {
+	VCPUTolerance    float64 `json:"vcpuTolerance"`
+	MemoryTolerance  float64 `json:"memoryTolerance"`
+	GPUTolerance     float64 `json:"gpuTolerance"`
+	StorageTolerance float64 `json:"storageTolerance"`
+
+	VCPUWeight         float64 `json:"vcpuWeight"`
+	MemoryWeight       float64 `json:"memoryWeight"`
+	GPUWeight          float64 `json:"gpuWeight"`
+	ArchitectureWeight float64 `json:"architectureWeight"`
+	StorageWeight      float64 `json:"storageWeight"`
+
+	// RequireSameArchitecture rejects pairs whose known architectures differ
+	RequireSameArchitecture bool `json:"requireSameArchitecture"`
+	// TopN is the number of equivalents returned per instance
+	TopN int `json:"topN"`
+	// MinScore is the lowest similarity score of a returned equivalent
+	MinScore float64 `json:"minScore"`
+}
+
+// DefaultMatchingConfig returns the matching configuration used by ComparePricing
+func DefaultMatchingConfig() MatchingConfig {
+	return MatchingConfig{
+		VCPUTolerance:           0.25,
+		MemoryTolerance:         0.25,
+		GPUTolerance:            0,
+		StorageTolerance:        1,
+		VCPUWeight:              0.35,
+		MemoryWeight:            0.35,
+		GPUWeight:               0.1,
+		ArchitectureWeight:      0.1,
+		StorageWeight:           0.1,
+		RequireSameArchitecture: true,
+		TopN:                    3,
+	}
+}
+
+// Validate checks that tolerances are between 0 and 1, weights are not negative and at least
+// one equivalent is requested
+func (c MatchingConfig) Validate() error {
+	tolerances := map[string]float64{
+		"vcpu":    c.VCPUTolerance,
+		"memory":  c.MemoryTolerance,
+		"gpu":     c.GPUTolerance,
+		"storage": c.StorageTolerance,
+	}
+	for name, tolerance := range tolerances {
+		if tolerance < 0 || tolerance > 1 {
+			return fmt.Errorf("%s tolerance must be between 0 and 1, got %g", name, tolerance)
+		}
+	}
+
+	weights := map[string]float64{
+		"vcpu":         c.VCPUWeight,
+		"memory":       c.MemoryWeight,
+		"gpu":          c.GPUWeight,
+		"architecture": c.ArchitectureWeight,
+		"storage":      c.StorageWeight,
+	}
+	for name, weight := range weights {
+		if weight < 0 {
+			return fmt.Errorf("%s weight must not be negative, got %g", name, weight)
+		}
+	}
+
+	if c.TopN < 1 {
+		return fmt.Errorf("top N must be at least 1, got %d", c.TopN)
+	}
+	if c.MinScore < 0 || c.MinScore > 1 {
+		return fmt.Errorf("minimum score must be between 0 and 1, got %g", c.MinScore)
+	}
+	return nil
+}
+
+// InstanceMatch is a candidate equivalent of an instance with its similarity score
+type InstanceMatch struct {
+	Pricing NormalizedPricing `json:"pricing"`
+	Score   float64           `json:"score"`
+}
+
+// SimilarityScore scores how closely two resource specs match, from 0 to 1, as the weighted
+// mean of the per-dimension similarities. A dimension unknown on both sides counts as equal and
+// one known on one side only as half similar. The second result is false when a dimension is
+// outside its tolerance, or the architectures differ and the configuration requires them to
+// match.
+func SimilarityScore(a, b ResourceSpecs, config MatchingConfig) (float64, bool) {
+	var score, totalWeight float64
+	add := func(weight, similarity float64) {
+		score += weight * similarity
+		totalWeight += weight
+	}
+
+	vcpu, ok := quantitySimilarity(a.VCPU != nil, b.VCPU != nil, intValue(a.VCPU), intValue(b.VCPU), config.VCPUTolerance)
+	if !ok {
+		return 0, false
+	}
+	add(config.VCPUWeight, vcpu)
+
+	memory, ok := quantitySimilarity(a.MemoryGB != nil, b.MemoryGB != nil, floatValue(a.MemoryGB), floatValue(b.MemoryGB), config.MemoryTolerance)
+	if !ok {
+		return 0, false
+	}
+	add(config.MemoryWeight, memory)
+
+	// A missing GPU count means no GPUs, so a GPU instance never matches a CPU-only one unless
+	// the tolerance allows it
+	gpu, ok := quantitySimilarity(true, true, intValue(a.GPUCount), intValue(b.GPUCount), config.GPUTolerance)
+	if !ok {
+		return 0, false
+	}
+	add(config.GPUWeight, gpu)
+
+	architecture := 1.0
+	switch {
+	case a.Architecture != nil && b.Architecture != nil:
+		if NormalizeArchitecture(*a.Architecture) != NormalizeArchitecture(*b.Architecture) {
+			if config.RequireSameArchitecture {
+				return 0, false
+			}
+			architecture = 0
+		}
+	case a.Architecture != nil || b.Architecture != nil:
+		architecture = 0.5
+	}
+	add(config.ArchitectureWeight, architecture)
+
+	storage, ok := quantitySimilarity(a.StorageGB != nil, b.StorageGB != nil, floatValue(a.StorageGB), floatValue(b.StorageGB), config.StorageTolerance)
+	if !ok {
+		return 0, false
+	}
+	add(config.StorageWeight, storage)
+
+	if totalWeight == 0 {
+		return 1, true
+	}
+	return score / totalWeight, true
+}
+
+// quantitySimilarity is 1 minus the relative difference of two quantities
+func quantitySimilarity(aKnown, bKnown bool, a, b float64, tolerance float64) (float64, bool) {
+	switch {
+	case !aKnown && !bKnown:
+		return 1, true
+	case !aKnown || !bKnown:
+		return 0.5, true
+	case a == b:
+		return 1, true
+	}
+
+	difference := math.Abs(a-b) / math.Max(math.Abs(a), math.Abs(b))
+	if difference > tolerance {
+		return 0, false
+	}
+	return 1 - difference, true
+}
+
+// NormalizeArchitecture maps provider spellings of an architecture to one name
+func NormalizeArchitecture(architecture string) string {
+	switch strings.ToLower(strings.TrimSpace(architecture)) {
+	case "x86_64", "x64", "amd64", "x86", "i386":
+		return "x86_64"
+	case "arm64", "aarch64", "arm":
+		return "arm64"
+	default:
+		return strings.ToLower(strings.TrimSpace(architecture))
+	}
+}
+
+func intValue(value *int) float64 {
+	if value == nil {
+		return 0
+	}
+	return float64(*value)
+}
+
+func floatValue(value *float64) float64 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+// comparableOffers reports whether two records sell the same kind of offer, so only their
+// resources are left to match: software, licensing, tenancy and payment option must agree
+func comparableOffers(a, b NormalizedPricing) bool {
+	return sameOptional(a.OperatingSystem, b.OperatingSystem) &&
+		sameOptional(a.LicenseModel, b.LicenseModel) &&
+		sameOptional(a.PreInstalledSoftware, b.PreInstalledSoftware) &&
+		sameOptional(a.Tenancy, b.Tenancy) &&
+		(a.PricingDetails.PaymentOption == nil || b.PricingDetails.PaymentOption == nil ||
+			strings.EqualFold(*a.PricingDetails.PaymentOption, *b.PricingDetails.PaymentOption))
+}
+
+// sameOptional compares optional attributes case-insensitively, treating missing as empty
+func sameOptional(a, b *string) bool {
+	var left, right string
+	if a != nil {
+		left = *a
+	}
+	if b != nil {
+		right = *b
+	}
+	return strings.EqualFold(left, right)
+}
+
+// MatchInstances returns the best equivalents of an instance among the candidates, ordered by
+// similarity score and then by effective hourly rate
+func MatchInstances(instance NormalizedPricing, candidates []NormalizedPricing, config MatchingConfig) []InstanceMatch {
+	var matches []InstanceMatch
+	for _, candidate := range candidates {
+		if candidate.Provider == instance.Provider || !comparableOffers(instance, candidate) {
+			continue
+		}
+
+		score, ok := SimilarityScore(instance.ResourceSpecs, candidate.ResourceSpecs, config)
+		if !ok || score < config.MinScore {
+			continue
+		}
+		matches = append(matches, InstanceMatch{Pricing: candidate, Score: score})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Pricing.GetHourlyPrice() < matches[j].Pricing.GetHourlyPrice()
+	})
+
+	if len(matches) > config.TopN {
+		matches = matches[:config.TopN]
+	}
+	return matches
+}
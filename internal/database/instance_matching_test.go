@@ -0,0 +1,243 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(value int) *int           { return &value }
+func floatPtr(value float64) *float64 { return &value }
+
+func specs(vcpu int, memoryGB float64, architecture string) ResourceSpecs {
+	return ResourceSpecs{VCPU: intPtr(vcpu), MemoryGB: floatPtr(memoryGB), Architecture: stringPtr(architecture)}
+}
+
+func TestSimilarityScore(t *testing.T) {
+	defaults := DefaultMatchingConfig()
+
+	anyArchitecture := DefaultMatchingConfig()
+	anyArchitecture.RequireSameArchitecture = false
+
+	vcpuOnly := MatchingConfig{VCPUTolerance: 0.25, MemoryTolerance: 1, StorageTolerance: 1, VCPUWeight: 1, TopN: 1}
+
+	noWeights := DefaultMatchingConfig()
+	noWeights.VCPUWeight, noWeights.MemoryWeight, noWeights.GPUWeight = 0, 0, 0
+	noWeights.ArchitectureWeight, noWeights.StorageWeight = 0, 0
+
+	gpuTolerant := DefaultMatchingConfig()
+	gpuTolerant.GPUTolerance = 1
+
+	tests := []struct {
+		name          string
+		a, b          ResourceSpecs
+		config        MatchingConfig
+		expectedScore float64
+		expectedOK    bool
+	}{
+		{
+			name:          "identical specs",
+			a:             specs(2, 8, "x86_64"),
+			b:             specs(2, 8, "x86_64"),
+			config:        defaults,
+			expectedScore: 1,
+			expectedOK:    true,
+		},
+		{
+			name:          "vCPUs within tolerance",
+			a:             specs(4, 8, "x86_64"),
+			b:             specs(5, 8, "x86_64"),
+			config:        defaults,
+			expectedScore: 0.35*0.8 + 0.35 + 0.1 + 0.1 + 0.1,
+			expectedOK:    true,
+		},
+		{
+			name:          "vCPUs exactly at the tolerance",
+			a:             specs(6, 8, "x86_64"),
+			b:             specs(8, 8, "x86_64"),
+			config:        defaults,
+			expectedScore: 0.35*0.75 + 0.35 + 0.1 + 0.1 + 0.1,
+			expectedOK:    true,
+		},
+		{
+			name:       "vCPUs outside tolerance",
+			a:          specs(4, 8, "x86_64"),
+			b:          specs(8, 8, "x86_64"),
+			config:     defaults,
+			expectedOK: false,
+		},
+		{
+			name:       "memory outside tolerance",
+			a:          specs(2, 4, "x86_64"),
+			b:          specs(2, 8, "x86_64"),
+			config:     defaults,
+			expectedOK: false,
+		},
+		{
+			name:          "memory known on one side only",
+			a:             specs(2, 8, "x86_64"),
+			b:             ResourceSpecs{VCPU: intPtr(2), Architecture: stringPtr("x86_64")},
+			config:        defaults,
+			expectedScore: 0.35 + 0.35*0.5 + 0.1 + 0.1 + 0.1,
+			expectedOK:    true,
+		},
+		{
+			name:       "GPU instance against a CPU-only one",
+			a:          ResourceSpecs{VCPU: intPtr(8), MemoryGB: floatPtr(32), GPUCount: intPtr(1)},
+			b:          ResourceSpecs{VCPU: intPtr(8), MemoryGB: floatPtr(32)},
+			config:     defaults,
+			expectedOK: false,
+		},
+		{
+			name:          "GPU difference accepted by the tolerance",
+			a:             ResourceSpecs{VCPU: intPtr(8), MemoryGB: floatPtr(32), GPUCount: intPtr(1)},
+			b:             ResourceSpecs{VCPU: intPtr(8), MemoryGB: floatPtr(32)},
+			config:        gpuTolerant,
+			expectedScore: 0.35 + 0.35 + 0 + 0.1 + 0.1,
+			expectedOK:    true,
+		},
+		{
+			name:       "different architectures rejected",
+			a:          specs(2, 8, "arm64"),
+			b:          specs(2, 8, "x86_64"),
+			config:     defaults,
+			expectedOK: false,
+		},
+		{
+			name:          "different architectures scored when allowed",
+			a:             specs(2, 8, "arm64"),
+			b:             specs(2, 8, "x86_64"),
+			config:        anyArchitecture,
+			expectedScore: 0.35 + 0.35 + 0.1 + 0 + 0.1,
+			expectedOK:    true,
+		},
+		{
+			name:          "architecture spellings are normalized",
+			a:             specs(2, 8, "aarch64"),
+			b:             specs(2, 8, "Arm64"),
+			config:        defaults,
+			expectedScore: 1,
+			expectedOK:    true,
+		},
+		{
+			name:          "architecture known on one side only",
+			a:             specs(2, 8, "x86_64"),
+			b:             ResourceSpecs{VCPU: intPtr(2), MemoryGB: floatPtr(8)},
+			config:        defaults,
+			expectedScore: 0.35 + 0.35 + 0.1 + 0.1*0.5 + 0.1,
+			expectedOK:    true,
+		},
+		{
+			name:          "only weighted dimensions count",
+			a:             specs(4, 2, "x86_64"),
+			b:             specs(5, 8, "x86_64"),
+			config:        vcpuOnly,
+			expectedScore: 0.8,
+			expectedOK:    true,
+		},
+		{
+			name:          "no weights scores a match as equal",
+			a:             specs(4, 8, "x86_64"),
+			b:             specs(5, 8, "x86_64"),
+			config:        noWeights,
+			expectedScore: 1,
+			expectedOK:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, ok := SimilarityScore(tt.a, tt.b, tt.config)
+
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.InDelta(t, tt.expectedScore, score, 1e-9)
+
+			// The score does not depend on the order of the specs
+			reversed, reversedOK := SimilarityScore(tt.b, tt.a, tt.config)
+			assert.Equal(t, ok, reversedOK)
+			assert.InDelta(t, score, reversed, 1e-9)
+		})
+	}
+}
+
+func TestMatchInstances(t *testing.T) {
+	instance := NormalizedPricing{
+		ID: 1, Provider: ProviderAWS, ResourceName: "m5.xlarge", Unit: UnitHour, PricePerUnit: 0.192,
+		ResourceSpecs: specs(4, 16, "x86_64"), OperatingSystem: stringPtr("Linux"),
+	}
+	azure := func(id int, name string, price float64, resourceSpecs ResourceSpecs) NormalizedPricing {
+		return NormalizedPricing{
+			ID: id, Provider: ProviderAzure, ResourceName: name, Unit: UnitHour, PricePerUnit: price,
+			ResourceSpecs: resourceSpecs, OperatingSystem: stringPtr("Linux"),
+		}
+	}
+
+	candidates := []NormalizedPricing{
+		azure(2, "D4s v5", 0.192, specs(4, 16, "x86_64")),
+		azure(3, "D4as v5", 0.172, specs(4, 16, "x86_64")),
+		azure(4, "E4s v5", 0.252, specs(4, 20, "x86_64")),
+		azure(5, "D8s v5", 0.384, specs(8, 32, "x86_64")),
+		azure(6, "D4ps v5", 0.154, specs(4, 16, "arm64")),
+		// Same provider, and a different operating system, are never equivalents
+		{ID: 7, Provider: ProviderAWS, ResourceName: "m6i.xlarge", Unit: UnitHour, PricePerUnit: 0.192,
+			ResourceSpecs: specs(4, 16, "x86_64"), OperatingSystem: stringPtr("Linux")},
+		func() NormalizedPricing {
+			windows := azure(8, "D4s v5", 0.376, specs(4, 16, "x86_64"))
+			windows.OperatingSystem = stringPtr("Windows")
+			return windows
+		}(),
+	}
+
+	tests := []struct {
+		name        string
+		configure   func(config *MatchingConfig)
+		expectedIDs []int
+	}{
+		{
+			name:        "best matches first, cheaper first on equal scores",
+			expectedIDs: []int{3, 2, 4},
+		},
+		{
+			name:        "top N limits the equivalents",
+			configure:   func(config *MatchingConfig) { config.TopN = 1 },
+			expectedIDs: []int{3},
+		},
+		{
+			name:        "minimum score drops weaker matches",
+			configure:   func(config *MatchingConfig) { config.MinScore = 0.99 },
+			expectedIDs: []int{3, 2},
+		},
+		{
+			name: "wider tolerances and other architectures admit more equivalents",
+			configure: func(config *MatchingConfig) {
+				config.VCPUTolerance, config.MemoryTolerance = 0.5, 0.5
+				config.RequireSameArchitecture = false
+				config.TopN = 10
+			},
+			expectedIDs: []int{3, 2, 4, 6, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultMatchingConfig()
+			if tt.configure != nil {
+				tt.configure(&config)
+			}
+			require.NoError(t, config.Validate())
+
+			matches := MatchInstances(instance, candidates, config)
+
+			ids := make([]int, len(matches))
+			for i, match := range matches {
+				ids[i] = match.Pricing.ID
+				assert.GreaterOrEqual(t, match.Score, config.MinScore)
+				if i > 0 {
+					assert.LessOrEqual(t, match.Score, matches[i-1].Score)
+				}
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
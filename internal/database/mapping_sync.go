@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// MappingSyncResult counts the mappings written by a mapping configuration sync
+type MappingSyncResult struct {
+	ServiceMappings int `json:"serviceMappings"`
+	Regions         int `json:"regions"`
+}
+
+// SyncMappings upserts service and region mappings in a single transaction. Service mappings
+// are keyed by provider and service name, regions by normalized code. Rows that are not part
+// of the sync are kept, since normalized pricing records may still reference them.
+func (db *DB) SyncMappings(ctx context.Context, services []ServiceMapping, regions []NormalizedRegion) (*MappingSyncResult, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin mapping sync: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, mapping := range services {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO service_mappings (
+				provider, provider_service_name, provider_service_code,
+				normalized_service_type, service_category, service_family
+			) VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (provider, provider_service_name) DO UPDATE SET
+				provider_service_code = EXCLUDED.provider_service_code,
+				normalized_service_type = EXCLUDED.normalized_service_type,
+				service_category = EXCLUDED.service_category,
+				service_family = EXCLUDED.service_family`,
+			mapping.Provider,
+			mapping.ProviderServiceName,
+			mapping.ProviderServiceCode,
+			mapping.NormalizedServiceType,
+			mapping.ServiceCategory,
+			mapping.ServiceFamily,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sync service mapping %s/%s: %w", mapping.Provider, mapping.ProviderServiceName, err)
+		}
+	}
+
+	for _, region := range regions {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO normalized_regions (
+				normalized_code, aws_region, azure_region, display_name, country, continent
+			) VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (normalized_code) DO UPDATE SET
+				aws_region = EXCLUDED.aws_region,
+				azure_region = EXCLUDED.azure_region,
+				display_name = EXCLUDED.display_name,
+				country = EXCLUDED.country,
+				continent = EXCLUDED.continent`,
+			region.NormalizedCode,
+			region.AWSRegion,
+			region.AzureRegion,
+			region.DisplayName,
+			region.Country,
+			region.Continent,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sync region %s: %w", region.NormalizedCode, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit mapping sync: %w", err)
+	}
+
+	log.Printf("🗺️ Synced %d service mappings and %d regions", len(services), len(regions))
+	return &MappingSyncResult{ServiceMappings: len(services), Regions: len(regions)}, nil
+}
@@ -0,0 +1,165 @@
+-- Raw pricing collections: reference tables and the provider API responses stored as JSONB
+
+-- Create a simple messages table for testing
+CREATE TABLE IF NOT EXISTS messages (
+    id SERIAL PRIMARY KEY,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Insert a welcome message
+INSERT INTO messages (content) VALUES ('Welcome to Cloud Price Compare!');
+
+-- Create the main pricing tables (basic structure)
+CREATE TABLE IF NOT EXISTS providers (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS service_categories (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Insert initial providers
+INSERT INTO providers (name) VALUES ('AWS'), ('Azure')
+ON CONFLICT (name) DO NOTHING;
+
+-- Insert initial categories based on CLAUDE.md
+INSERT INTO service_categories (name, description) VALUES 
+    ('General', 'Core infrastructure and foundational services'),
+    ('Networking', 'Network connectivity, load balancing, CDN'),
+    ('Compute & Web', 'Virtual machines, containers, serverless compute'),
+    ('Containers', 'Container orchestration and management'),
+    ('Databases', 'Relational, NoSQL, and specialized databases'),
+    ('Storage', 'Object storage, file systems, backup solutions'),
+    ('AI & ML', 'Machine learning, cognitive services, AI tools'),
+    ('Analytics & IoT', 'Data analytics, streaming, IoT platforms'),
+    ('Virtual Desktop', 'Desktop virtualization and workspace solutions'),
+    ('Dev Tools', 'Development, CI/CD, and testing tools'),
+    ('Integration', 'API management, messaging, event services'),
+    ('Migration', 'Data migration and transfer services'),
+    ('Management', 'Monitoring, governance, security tools'),
+    ('Compute', 'Virtual machines, containers, serverless compute')
+ON CONFLICT (name) DO NOTHING;
+
+-- Azure Raw Pricing Data - Simplified approach
+CREATE TABLE IF NOT EXISTS azure_pricing_raw (
+    id SERIAL PRIMARY KEY,
+    region VARCHAR(100) NOT NULL,
+    service_name VARCHAR(100),
+    service_family VARCHAR(100),
+    data JSONB NOT NULL, -- Store entire Azure API response
+    collected_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    collection_id VARCHAR(50), -- For tracking collection batches
+    total_items INTEGER DEFAULT 0 -- Number of items in this batch
+);
+
+-- Collection tracking for raw data
+CREATE TABLE IF NOT EXISTS azure_collections (
+    id SERIAL PRIMARY KEY,
+    collection_id VARCHAR(50) NOT NULL UNIQUE,
+    region VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running', -- running, completed, failed
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    total_items INTEGER DEFAULT 0,
+    error_message TEXT,
+    metadata JSONB -- Store additional collection metadata
+);
+
+-- Create indexes for performance on raw data
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_raw_region ON azure_pricing_raw(region);
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_raw_service ON azure_pricing_raw(service_name);
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_raw_family ON azure_pricing_raw(service_family);
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_raw_collected ON azure_pricing_raw(collected_at);
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_raw_collection_id ON azure_pricing_raw(collection_id);
+
+-- JSONB indexes for querying inside the data
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_raw_data_gin ON azure_pricing_raw USING GIN (data);
+
+-- Collection indexes
+CREATE INDEX IF NOT EXISTS idx_azure_collections_region ON azure_collections(region);
+CREATE INDEX IF NOT EXISTS idx_azure_collections_status ON azure_collections(status);
+CREATE INDEX IF NOT EXISTS idx_azure_collections_started ON azure_collections(started_at);
+
+-- AWS Raw Pricing Data Tables - Raw JSONB approach (matching Azure)
+CREATE TABLE IF NOT EXISTS aws_pricing_raw (
+    id SERIAL PRIMARY KEY,
+    service_code VARCHAR(100) NOT NULL,
+    service_name VARCHAR(100),
+    location VARCHAR(100),
+    data JSONB NOT NULL, -- Store entire AWS API response (raw product JSON)
+    collected_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    collection_id VARCHAR(50), -- For tracking collection batches
+    total_items INTEGER DEFAULT 0 -- Number of items in this batch
+);
+
+-- AWS Collection tracking
+CREATE TABLE IF NOT EXISTS aws_collections (
+    id SERIAL PRIMARY KEY,
+    collection_id VARCHAR(50) NOT NULL UNIQUE,
+    service_codes TEXT[], -- Array of service codes
+    regions TEXT[], -- Array of regions
+    status VARCHAR(20) NOT NULL DEFAULT 'running', -- running, completed, failed
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    total_items INTEGER DEFAULT 0,
+    error_message TEXT,
+    metadata JSONB
+);
+
+-- AWS indexes for performance (matching Azure approach)
+CREATE INDEX IF NOT EXISTS idx_aws_pricing_raw_service_code ON aws_pricing_raw(service_code);
+CREATE INDEX IF NOT EXISTS idx_aws_pricing_raw_service_name ON aws_pricing_raw(service_name);
+CREATE INDEX IF NOT EXISTS idx_aws_pricing_raw_location ON aws_pricing_raw(location);
+CREATE INDEX IF NOT EXISTS idx_aws_pricing_raw_collected ON aws_pricing_raw(collected_at);
+CREATE INDEX IF NOT EXISTS idx_aws_pricing_raw_collection_id ON aws_pricing_raw(collection_id);
+
+-- JSONB indexes for querying inside the data
+CREATE INDEX IF NOT EXISTS idx_aws_pricing_raw_data_gin ON aws_pricing_raw USING GIN (data);
+
+-- Expression indexes for common query patterns (B-tree: text has no default GIN operator class)
+CREATE INDEX IF NOT EXISTS idx_aws_pricing_product_family ON aws_pricing_raw ((data->>'productFamily'));
+CREATE INDEX IF NOT EXISTS idx_aws_pricing_instance_type ON aws_pricing_raw ((data->'attributes'->>'instanceType'));
+CREATE INDEX IF NOT EXISTS idx_aws_pricing_location ON aws_pricing_raw ((data->'attributes'->>'location'));
+CREATE INDEX IF NOT EXISTS idx_aws_pricing_storage_class ON aws_pricing_raw ((data->'attributes'->>'storageClass'));
+CREATE INDEX IF NOT EXISTS idx_aws_pricing_ondemand ON aws_pricing_raw ((data->'terms'->'OnDemand'));
+
+-- Azure specific query pattern indexes
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_service_name ON azure_pricing_raw ((data->>'serviceName'));
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_arm_region ON azure_pricing_raw ((data->>'armRegionName'));
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_arm_sku ON azure_pricing_raw ((data->>'armSkuName'));
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_meter_name ON azure_pricing_raw ((data->>'meterName'));
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_type ON azure_pricing_raw ((data->>'type'));
+
+-- AWS collection indexes
+CREATE INDEX IF NOT EXISTS idx_aws_collections_status ON aws_collections(status);
+CREATE INDEX IF NOT EXISTS idx_aws_collections_started ON aws_collections(started_at);
+
+-- Price changes between a collection and the previous collection of the same scope
+CREATE TABLE IF NOT EXISTS price_changes (
+    id SERIAL PRIMARY KEY,
+    provider VARCHAR(20) NOT NULL, -- aws, azure
+    collection_id VARCHAR(50) NOT NULL,
+    previous_collection_id VARCHAR(50) NOT NULL,
+    price_key TEXT NOT NULL, -- Azure meterId|type|term|tier, AWS rate code
+    region VARCHAR(100),
+    service_name VARCHAR(255),
+    description TEXT,
+    change_type VARCHAR(20) NOT NULL, -- new, retired, increase, decrease
+    old_price DECIMAL(20,10),
+    new_price DECIMAL(20,10),
+    change_percent DECIMAL(12,4),
+    currency VARCHAR(10),
+    unit VARCHAR(100),
+    detected_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(provider, collection_id, price_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_changes_collection ON price_changes(provider, collection_id);
+CREATE INDEX IF NOT EXISTS idx_price_changes_type ON price_changes(change_type);
+CREATE INDEX IF NOT EXISTS idx_price_changes_detected ON price_changes(detected_at);
@@ -1,7 +1,6 @@
--- Azure Pricing Database Schema
--- Designed for efficient storage and querying of pricing data
+-- Azure pricing catalog: services, regions, products and SKUs referenced by the structured
+-- azure_pricing table and the collection runs that filled it
 
--- Core service reference tables
 CREATE TABLE IF NOT EXISTS azure_services (
     id SERIAL PRIMARY KEY,
     service_name VARCHAR(100) NOT NULL UNIQUE,
@@ -24,7 +23,7 @@ CREATE TABLE IF NOT EXISTS azure_products (
     product_name VARCHAR(200) NOT NULL,
     product_id VARCHAR(100) UNIQUE,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    
+
     UNIQUE(service_id, product_name)
 );
 
@@ -35,20 +34,20 @@ CREATE TABLE IF NOT EXISTS azure_skus (
     sku_id VARCHAR(100) UNIQUE,
     arm_sku_name VARCHAR(100),
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    
+
     UNIQUE(product_id, sku_name)
 );
 
 -- Main pricing table - optimized for queries
 CREATE TABLE IF NOT EXISTS azure_pricing (
     id BIGSERIAL PRIMARY KEY,
-    
+
     -- References to normalized tables
     service_id INTEGER NOT NULL REFERENCES azure_services(id),
     product_id INTEGER NOT NULL REFERENCES azure_products(id),
     sku_id INTEGER NOT NULL REFERENCES azure_skus(id),
     region_id INTEGER NOT NULL REFERENCES azure_regions(id),
-    
+
     -- Pricing details
     meter_id VARCHAR(100) NOT NULL,
     meter_name VARCHAR(200) NOT NULL,
@@ -57,31 +56,30 @@ CREATE TABLE IF NOT EXISTS azure_pricing (
     tier_minimum_units DECIMAL(15,6) DEFAULT 0,
     currency_code VARCHAR(3) NOT NULL DEFAULT 'USD',
     unit_of_measure VARCHAR(50) NOT NULL,
-    
+
     -- Pricing type and terms
     price_type VARCHAR(20) NOT NULL DEFAULT 'Consumption',
     reservation_term VARCHAR(20),
-    
+
     -- Temporal data
     effective_start_date DATE NOT NULL,
     is_primary_meter_region BOOLEAN DEFAULT false,
-    
+
     -- Metadata
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     collection_version INTEGER DEFAULT 1,
-    
-    -- Indexes for common queries
-    INDEX idx_pricing_service_region (service_id, region_id),
-    INDEX idx_pricing_product_region (product_id, region_id),
-    INDEX idx_pricing_meter (meter_id),
-    INDEX idx_pricing_effective_date (effective_start_date),
-    INDEX idx_pricing_price (retail_price),
-    INDEX idx_pricing_collection (collection_version, created_at),
-    
+
     -- Unique constraint to prevent duplicates
     UNIQUE(service_id, product_id, sku_id, region_id, meter_id, effective_start_date)
 );
 
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_service_region ON azure_pricing(service_id, region_id);
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_product_region ON azure_pricing(product_id, region_id);
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_meter ON azure_pricing(meter_id);
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_effective_date ON azure_pricing(effective_start_date);
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_price ON azure_pricing(retail_price);
+CREATE INDEX IF NOT EXISTS idx_azure_pricing_collection ON azure_pricing(collection_version, created_at);
+
 -- Collection tracking
 CREATE TABLE IF NOT EXISTS azure_collection_runs (
     id SERIAL PRIMARY KEY,
@@ -92,19 +90,8 @@ CREATE TABLE IF NOT EXISTS azure_collection_runs (
     total_items INTEGER DEFAULT 0,
     regions_collected TEXT[], -- array of region names
     error_message TEXT,
-    collection_metadata JSONB,
-    
-    INDEX idx_collection_version (version),
-    INDEX idx_collection_status (status),
-    INDEX idx_collection_date (started_at)
+    collection_metadata JSONB
 );
 
--- Update existing service_categories if needed
-INSERT INTO service_categories (name, description) VALUES 
-    ('Compute', 'Virtual machines, containers, serverless compute'),
-    ('Networking', 'Network connectivity, load balancing, CDN')
-ON CONFLICT (name) DO NOTHING;
-
--- Create indexes on existing tables
-CREATE INDEX IF NOT EXISTS idx_providers_name ON providers(name);
-CREATE INDEX IF NOT EXISTS idx_categories_name ON service_categories(name);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_azure_collection_runs_status ON azure_collection_runs(status);
+CREATE INDEX IF NOT EXISTS idx_azure_collection_runs_started ON azure_collection_runs(started_at);
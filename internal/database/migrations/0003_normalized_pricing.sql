@@ -0,0 +1,401 @@
+-- Normalized Cloud Pricing Database Schema
+-- This schema provides a unified view of AWS and Azure pricing data
+-- for easy querying and cross-provider comparisons
+
+-- Trigram matching for typo-tolerant search
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Region mapping table to standardize region codes across providers
+CREATE TABLE IF NOT EXISTS normalized_regions (
+    id SERIAL PRIMARY KEY,
+    normalized_code VARCHAR(50) NOT NULL UNIQUE, -- e.g., 'us-east', 'eu-west'
+    aws_region VARCHAR(50), -- e.g., 'us-east-1'
+    azure_region VARCHAR(50), -- e.g., 'eastus'
+    display_name VARCHAR(100) NOT NULL, -- e.g., 'US East (Virginia)'
+    country VARCHAR(50),
+    continent VARCHAR(50)
+);
+
+-- Service mapping table to map provider-specific services to normalized categories
+CREATE TABLE IF NOT EXISTS service_mappings (
+    id SERIAL PRIMARY KEY,
+    provider VARCHAR(10) NOT NULL CHECK (provider IN ('aws', 'azure')),
+    provider_service_name VARCHAR(200) NOT NULL, -- e.g., 'AmazonEC2', 'Virtual Machines'
+    provider_service_code VARCHAR(100), -- e.g., 'AmazonEC2' for AWS
+    normalized_service_type VARCHAR(100) NOT NULL, -- e.g., 'Virtual Machines'
+    service_category VARCHAR(50) NOT NULL, -- From our 13 categories
+    service_family VARCHAR(100) NOT NULL, -- e.g., 'Compute', 'Storage'
+    UNIQUE(provider, provider_service_name)
+);
+
+-- Main normalized pricing table
+CREATE TABLE IF NOT EXISTS normalized_pricing (
+    id SERIAL PRIMARY KEY,
+    
+    -- Provider information
+    provider VARCHAR(10) NOT NULL CHECK (provider IN ('aws', 'azure')),
+    provider_service_code VARCHAR(100) NOT NULL, -- Original service code
+    provider_sku VARCHAR(200), -- Provider-specific SKU/product ID
+    
+    -- Unified service categorization (foreign key to service_mappings)
+    service_mapping_id INTEGER REFERENCES service_mappings(id),
+    service_category VARCHAR(50) NOT NULL, -- Denormalized for query performance
+    service_family VARCHAR(100) NOT NULL, -- Denormalized for query performance
+    service_type VARCHAR(100) NOT NULL, -- Denormalized for query performance
+    
+    -- Location (foreign key to normalized_regions)
+    region_id INTEGER REFERENCES normalized_regions(id),
+    normalized_region VARCHAR(50) NOT NULL, -- Denormalized for query performance
+    provider_region VARCHAR(50) NOT NULL, -- Original provider region
+    
+    -- Resource specifications
+    resource_name VARCHAR(200) NOT NULL, -- e.g., 't3.medium', 'Standard_D4s_v3'
+    resource_description TEXT, -- Human-readable description
+    
+    -- Structured resource specifications as JSONB for flexibility
+    resource_specs JSONB NOT NULL DEFAULT '{}',
+    /* Expected structure:
+    {
+        "vcpu": 2,
+        "memory_gb": 4,
+        "storage_gb": 0,
+        "gpu_count": 0,
+        "gpu_memory_gb": 0,
+        "network_performance": "moderate",
+        "storage_type": "ssd",
+        "processor_type": "Intel Xeon",
+        "processor_features": ["avx", "avx2"]
+    }
+    */
+    
+    -- Software and placement dimensions (NULL when not applicable to the service)
+    operating_system VARCHAR(50), -- 'linux', 'windows', 'rhel', 'rhel_ha', 'suse', 'ubuntu_pro'
+    license_model VARCHAR(50), -- 'license_included', 'byol', 'none'
+    pre_installed_software VARCHAR(50), -- 'none', 'sql_web', 'sql_standard', 'sql_enterprise'
+    tenancy VARCHAR(50), -- 'shared', 'dedicated', 'host'
+    
+    -- Pricing information
+    price_per_unit DECIMAL(20,10) NOT NULL,
+    unit VARCHAR(50) NOT NULL, -- Normalized: 'hour', 'gb_month', 'request', 'transaction'
+    original_unit VARCHAR(100), -- Provider unit of measure before rescaling, e.g. '100 Hours', '10K'
+    unit_multiplier DECIMAL(20,6) DEFAULT 1, -- Quantity parsed from original_unit, e.g. 100 for '100 Hours'
+    currency VARCHAR(10) NOT NULL DEFAULT 'USD',
+    pricing_model VARCHAR(50) NOT NULL, -- 'on_demand', 'reserved_1yr', 'reserved_3yr', 'spot', 'savings_plan'
+    
+    -- Additional pricing details as JSONB
+    pricing_details JSONB DEFAULT '{}',
+    /* Expected structure for reserved/savings:
+    {
+        "term_length": "1yr",
+        "payment_option": "all_upfront",
+        "upfront_cost": 1234.56,
+        "hourly_rate": 0.123,
+        "effective_hourly_rate": 0.2639,  -- hourly_rate + upfront_cost / term hours
+        "effective_monthly_rate": 192.67  -- effective_hourly_rate * 730
+    }
+    */
+    
+    -- Volume tiers for tiered prices (NULL for flat prices)
+    price_tiers JSONB,
+    /* Expected structure, sorted by start_units:
+    [
+        {"start_units": 0, "end_units": 51200, "price_per_unit": 0.0184},
+        {"start_units": 51200, "price_per_unit": 0.0177}
+    ]
+    */
+    
+    -- Metadata
+    effective_date DATE,
+    expiration_date DATE, -- For limited-time offers
+    minimum_commitment INTEGER DEFAULT 1, -- Minimum units (e.g., minimum hours)
+    
+    -- References to raw data
+    aws_raw_id INTEGER, -- Reference to aws_pricing_raw.id
+    azure_raw_id INTEGER, -- Reference to azure_pricing_raw.id
+    
+    -- Search: weighted full-text document and lowercased text for trigram matching
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', COALESCE(resource_name, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(provider_sku, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(service_type, '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE(resource_description, '')), 'C')
+    ) STORED,
+    search_text TEXT GENERATED ALWAYS AS (
+        lower(COALESCE(resource_name, '') || ' ' || COALESCE(provider_sku, '') || ' ' ||
+              COALESCE(service_type, '') || ' ' || COALESCE(resource_description, ''))
+    ) STORED,
+    
+    -- Timestamps
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Databases created by the init_normalized.sql script that predates migrations already have
+-- normalized_pricing, so CREATE TABLE IF NOT EXISTS leaves out the columns added since then.
+-- Add them before the indexes and tables that depend on them.
+ALTER TABLE normalized_pricing
+    ADD COLUMN IF NOT EXISTS operating_system VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS license_model VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS pre_installed_software VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS tenancy VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS original_unit VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS unit_multiplier DECIMAL(20,6) DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS price_tiers JSONB,
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', COALESCE(resource_name, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(provider_sku, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(service_type, '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE(resource_description, '')), 'C')
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS search_text TEXT GENERATED ALWAYS AS (
+        lower(COALESCE(resource_name, '') || ' ' || COALESCE(provider_sku, '') || ' ' ||
+              COALESCE(service_type, '') || ' ' || COALESCE(resource_description, ''))
+    ) STORED;
+
+-- Create indexes for performance
+CREATE INDEX IF NOT EXISTS idx_provider_service ON normalized_pricing(provider, service_type);
+CREATE INDEX IF NOT EXISTS idx_category_region ON normalized_pricing(service_category, normalized_region);
+CREATE INDEX IF NOT EXISTS idx_resource_name ON normalized_pricing(resource_name);
+CREATE INDEX IF NOT EXISTS idx_pricing_model ON normalized_pricing(pricing_model);
+CREATE INDEX IF NOT EXISTS idx_price_per_unit ON normalized_pricing(price_per_unit);
+CREATE INDEX IF NOT EXISTS idx_price_validity ON normalized_pricing(effective_date, expiration_date);
+CREATE INDEX IF NOT EXISTS idx_effective_hourly_rate ON normalized_pricing ((
+    COALESCE((pricing_details->>'effective_hourly_rate')::numeric, CASE WHEN unit = 'hour' THEN price_per_unit END)
+));
+
+-- JSONB indexes for querying resource specifications
+CREATE INDEX IF NOT EXISTS idx_resource_specs_gin ON normalized_pricing USING GIN (resource_specs);
+CREATE INDEX IF NOT EXISTS idx_resource_vcpu ON normalized_pricing ((resource_specs->>'vcpu'));
+CREATE INDEX IF NOT EXISTS idx_resource_memory ON normalized_pricing ((resource_specs->>'memory_gb'));
+
+-- Search indexes: full-text, trigram word similarity over the search text, and trigram
+-- similarity of resource names for typos
+CREATE INDEX IF NOT EXISTS idx_search_vector ON normalized_pricing USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_search_text_trgm ON normalized_pricing USING GIN (search_text gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_resource_name_trgm ON normalized_pricing USING GIN (lower(resource_name) gin_trgm_ops);
+
+-- Indexes for tracing normalized records back to raw data (coverage reports, cleanup)
+CREATE INDEX IF NOT EXISTS idx_aws_raw_id ON normalized_pricing(aws_raw_id) WHERE aws_raw_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_azure_raw_id ON normalized_pricing(azure_raw_id) WHERE azure_raw_id IS NOT NULL;
+
+-- Index for filtering by software and placement dimensions
+CREATE INDEX IF NOT EXISTS idx_platform ON normalized_pricing(operating_system, license_model, pre_installed_software, tenancy);
+
+-- Composite indexes for common query patterns
+CREATE INDEX IF NOT EXISTS idx_vm_pricing ON normalized_pricing(service_type, normalized_region, pricing_model) 
+    WHERE service_type = 'Virtual Machines';
+CREATE INDEX IF NOT EXISTS idx_serverless_pricing ON normalized_pricing(service_type, normalized_region, pricing_model) 
+    WHERE service_family = 'Serverless';
+
+-- Function to automatically update the updated_at timestamp
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+-- Trigger to update the updated_at column
+CREATE OR REPLACE TRIGGER update_normalized_pricing_updated_at 
+    BEFORE UPDATE ON normalized_pricing 
+    FOR EACH ROW 
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Price history as a slowly changing dimension: one row per version of a price, identified
+-- across re-normalizations by its natural key (provider, region, SKU, resource, pricing model,
+-- unit, currency, platform and term). valid_from/valid_to bound when the ETL had the version
+-- live; the current version has no valid_to. Maintained by the ETL after every job.
+CREATE TABLE IF NOT EXISTS normalized_pricing_history (
+    LIKE normalized_pricing,
+    history_id BIGSERIAL PRIMARY KEY,
+    natural_key TEXT NOT NULL,
+    content_hash TEXT NOT NULL,
+    valid_from TIMESTAMP WITH TIME ZONE NOT NULL,
+    valid_to TIMESTAMP WITH TIME ZONE,
+    etl_job_id VARCHAR(100)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pricing_history_current ON normalized_pricing_history(natural_key) WHERE valid_to IS NULL;
+CREATE INDEX IF NOT EXISTS idx_pricing_history_key ON normalized_pricing_history(natural_key, valid_from);
+CREATE INDEX IF NOT EXISTS idx_pricing_history_sku ON normalized_pricing_history(provider, provider_sku, valid_from);
+CREATE INDEX IF NOT EXISTS idx_pricing_history_window ON normalized_pricing_history(service_type, normalized_region, valid_from, valid_to);
+CREATE INDEX IF NOT EXISTS idx_pricing_history_job ON normalized_pricing_history(etl_job_id);
+
+-- Summary tables rebuilt from the live on-demand prices after every ETL job, so the optimizer
+-- and the REST endpoints do not aggregate normalized_pricing per request. They are plain
+-- tables rather than materialized views because a full re-normalization swaps
+-- normalized_pricing by renaming tables.
+
+-- Cheapest on-demand instance per provider region, vCPU count, memory bucket (next power of
+-- two GB), architecture and OS
+CREATE TABLE IF NOT EXISTS compute_price_summary (
+    provider VARCHAR(10) NOT NULL,
+    provider_region VARCHAR(50) NOT NULL,
+    normalized_region VARCHAR(50) NOT NULL,
+    vcpu INTEGER NOT NULL,
+    memory_bucket_gb DECIMAL(10,3) NOT NULL,
+    architecture VARCHAR(20) NOT NULL, -- x86_64, arm64 or unknown
+    operating_system VARCHAR(50) NOT NULL, -- linux, windows, ... or unknown
+    currency VARCHAR(10) NOT NULL,
+    resource_name VARCHAR(200) NOT NULL, -- Cheapest instance of the shape
+    provider_sku VARCHAR(200),
+    memory_gb DECIMAL(10,3) NOT NULL,
+    price_per_hour DECIMAL(20,10) NOT NULL,
+    offer_count INTEGER NOT NULL, -- Instances of the shape in the region
+    refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (provider, provider_region, vcpu, memory_bucket_gb, architecture, operating_system, currency)
+);
+
+CREATE INDEX IF NOT EXISTS idx_compute_summary_shape ON compute_price_summary(vcpu, memory_bucket_gb, architecture, operating_system, price_per_hour);
+CREATE INDEX IF NOT EXISTS idx_compute_summary_region ON compute_price_summary(normalized_region);
+
+-- On-demand price level of each provider region, category and unit. price_index is 100 times
+-- the geometric mean of each item's price over its median across the provider's regions.
+CREATE TABLE IF NOT EXISTS region_price_index (
+    provider VARCHAR(10) NOT NULL,
+    provider_region VARCHAR(50) NOT NULL,
+    normalized_region VARCHAR(50) NOT NULL,
+    service_category VARCHAR(50) NOT NULL,
+    unit VARCHAR(50) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    price_index DECIMAL(12,4) NOT NULL,
+    item_count INTEGER NOT NULL,
+    median_price DECIMAL(20,10) NOT NULL,
+    min_price DECIMAL(20,10) NOT NULL,
+    refreshed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    PRIMARY KEY (provider, provider_region, service_category, unit, currency)
+);
+
+CREATE INDEX IF NOT EXISTS idx_region_price_index_category ON region_price_index(service_category, unit, price_index);
+CREATE INDEX IF NOT EXISTS idx_region_price_index_region ON region_price_index(normalized_region);
+
+-- ETL job state so interrupted jobs can be resumed after a restart
+CREATE TABLE IF NOT EXISTS etl_jobs (
+    id VARCHAR(100) PRIMARY KEY,
+    job_type VARCHAR(50) NOT NULL,
+    provider VARCHAR(10),
+    status VARCHAR(20) NOT NULL,
+    configuration JSONB NOT NULL DEFAULT '{}',
+    target_table VARCHAR(100), -- Staging table of a full re-normalization, if any
+    error TEXT,
+    started_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_etl_jobs_status ON etl_jobs(status);
+
+-- Completed batch ranges, committed in the same transaction as the batch inserts
+CREATE TABLE IF NOT EXISTS etl_job_checkpoints (
+    job_id VARCHAR(100) NOT NULL REFERENCES etl_jobs(id) ON DELETE CASCADE,
+    provider VARCHAR(10) NOT NULL,
+    batch_offset INTEGER NOT NULL,
+    batch_size INTEGER NOT NULL,
+    first_raw_id INTEGER,
+    last_raw_id INTEGER,
+    processed_records INTEGER NOT NULL DEFAULT 0,
+    normalized_records INTEGER NOT NULL DEFAULT 0,
+    skipped_records INTEGER NOT NULL DEFAULT 0,
+    error_records INTEGER NOT NULL DEFAULT 0,
+    completed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (job_id, provider, batch_offset)
+);
+
+-- Exchange rates for converting prices out of their stored currency. A rate applies from
+-- its effective date until the next rate for the same pair.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base_currency CHAR(3) NOT NULL, -- e.g., 'USD'
+    quote_currency CHAR(3) NOT NULL, -- e.g., 'EUR'
+    rate NUMERIC(20,10) NOT NULL CHECK (rate > 0), -- Units of quote currency per base currency unit
+    effective_date DATE NOT NULL,
+    source VARCHAR(100) NOT NULL, -- e.g., 'file:rates.csv'
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (base_currency, quote_currency, effective_date)
+);
+
+-- Webhooks price alerts are posted to; payloads are signed with HMAC-SHA256 of the secret
+CREATE TABLE IF NOT EXISTS alert_webhooks (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Alert rules: a filter over normalized prices and the change that triggers the webhook.
+-- Unset filter columns match any price. Evaluated against the price history after every job.
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    webhook_id INTEGER NOT NULL REFERENCES alert_webhooks(id) ON DELETE CASCADE,
+    provider VARCHAR(10),
+    service_category VARCHAR(50),
+    service_type VARCHAR(100),
+    resource_name VARCHAR(255),
+    provider_sku VARCHAR(255),
+    normalized_region VARCHAR(50),
+    pricing_model VARCHAR(50),
+    operating_system VARCHAR(50),
+    threshold_percent DECIMAL(10,4) NOT NULL DEFAULT 0 CHECK (threshold_percent >= 0),
+    direction VARCHAR(10) NOT NULL DEFAULT 'any' CHECK (direction IN ('any', 'increase', 'decrease')),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_rules_webhook ON alert_rules(webhook_id);
+
+-- Webhook delivery log: one row per triggered rule and job, updated after every attempt
+CREATE TABLE IF NOT EXISTS alert_deliveries (
+    id SERIAL PRIMARY KEY,
+    rule_id INTEGER NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    webhook_id INTEGER NOT NULL REFERENCES alert_webhooks(id) ON DELETE CASCADE,
+    etl_job_id VARCHAR(100),
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, delivered, failed
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_deliveries_rule ON alert_deliveries(rule_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_alert_deliveries_status ON alert_deliveries(status);
+
+-- Insert common region mappings
+INSERT INTO normalized_regions (normalized_code, aws_region, azure_region, display_name, country, continent) VALUES
+-- US Regions
+('us-east', 'us-east-1', 'eastus', 'US East (Virginia)', 'USA', 'North America'),
+('us-east-2', 'us-east-2', 'eastus2', 'US East (Ohio)', 'USA', 'North America'),
+('us-west', 'us-west-2', 'westus', 'US West (Oregon)', 'USA', 'North America'),
+('us-west-1', 'us-west-1', 'westus2', 'US West (California)', 'USA', 'North America'),
+('us-central', NULL, 'centralus', 'US Central (Iowa)', 'USA', 'North America'),
+('us-south-central', NULL, 'southcentralus', 'US South Central (Texas)', 'USA', 'North America'),
+
+-- Europe Regions
+('eu-west', 'eu-west-1', 'westeurope', 'Europe West (Ireland/Netherlands)', 'Ireland/Netherlands', 'Europe'),
+('eu-north', 'eu-north-1', 'northeurope', 'Europe North (Stockholm/Ireland)', 'Sweden/Ireland', 'Europe'),
+('eu-central', 'eu-central-1', 'germanywestcentral', 'Europe Central (Frankfurt)', 'Germany', 'Europe'),
+('uk-south', 'eu-west-2', 'uksouth', 'UK South (London)', 'UK', 'Europe'),
+
+-- Asia Pacific Regions
+('asia-southeast', 'ap-southeast-1', 'southeastasia', 'Asia Southeast (Singapore)', 'Singapore', 'Asia'),
+('asia-east', 'ap-northeast-1', 'eastasia', 'Asia East (Tokyo/Hong Kong)', 'Japan/Hong Kong', 'Asia'),
+('asia-northeast', 'ap-northeast-2', 'koreacentral', 'Asia Northeast (Seoul)', 'South Korea', 'Asia'),
+('australia-east', 'ap-southeast-2', 'australiaeast', 'Australia East (Sydney)', 'Australia', 'Oceania'),
+
+-- Other Regions
+('canada-central', 'ca-central-1', 'canadacentral', 'Canada Central', 'Canada', 'North America'),
+('brazil-south', 'sa-east-1', 'brazilsouth', 'Brazil South (São Paulo)', 'Brazil', 'South America'),
+('india-south', 'ap-south-1', 'centralindia', 'India South (Mumbai)', 'India', 'Asia')
+ON CONFLICT (normalized_code) DO NOTHING;
+
+-- Comments for documentation
+COMMENT ON TABLE normalized_pricing IS 'Unified pricing data from AWS and Azure in a normalized format for easy querying and comparison';
+COMMENT ON COLUMN normalized_pricing.resource_specs IS 'JSONB field containing normalized resource specifications like vCPU, memory, storage';
+COMMENT ON COLUMN normalized_pricing.pricing_model IS 'Standardized pricing model: on_demand, reserved_1yr, reserved_3yr, spot, savings_plan';
+COMMENT ON COLUMN normalized_pricing.unit IS 'Normalized billing unit: hour, gb_month, request, transaction, etc.';
@@ -1,8 +1,6 @@
 -- Service mappings from cloud_compute_services_mapping.md
--- Maps provider-specific services to normalized categories
-
--- Clear existing mappings
-DELETE FROM service_mappings;
+-- Maps provider-specific services to normalized categories. The mapping config file synced by
+-- the servers updates these rows; the seed never overwrites them.
 
 -- Virtual Machines & Basic Compute
 INSERT INTO service_mappings (provider, provider_service_name, provider_service_code, normalized_service_type, service_category, service_family) VALUES
@@ -96,13 +94,5 @@ INSERT INTO service_mappings (provider, provider_service_name, provider_service_
 
 -- IoT & Edge Services
 ('aws', 'AWS IoT Greengrass', 'AWSIoTGreengrass', 'IoT Edge Computing', 'Analytics & IoT', 'IoT Edge'),
-('azure', 'Azure IoT Edge', 'IoT Edge', 'IoT Edge Computing', 'Analytics & IoT', 'IoT Edge');
-
--- Check the results
-SELECT 
-    provider,
-    COUNT(*) as service_count,
-    array_agg(DISTINCT service_category) as categories
-FROM service_mappings 
-GROUP BY provider 
-ORDER BY provider;
\ No newline at end of file
+('azure', 'Azure IoT Edge', 'IoT Edge', 'IoT Edge Computing', 'Analytics & IoT', 'IoT Edge')
+ON CONFLICT (provider, provider_service_name) DO NOTHING;
@@ -0,0 +1,149 @@
+-- Typed resource spec columns generated from resource_specs, so spec range filters and
+-- per-vCPU/per-GB ordering use B-tree indexes instead of casting JSON text on every row.
+-- Specs that are missing or not numbers are NULL. Architecture is normalized to arm64/x86_64
+-- and the processor vendor (intel, amd, aws, ampere) is derived from the processor type.
+
+ALTER TABLE normalized_pricing
+    ADD COLUMN IF NOT EXISTS spec_vcpu INTEGER GENERATED ALWAYS AS (
+        CASE WHEN jsonb_typeof(resource_specs->'vcpu') = 'number' THEN (resource_specs->>'vcpu')::numeric::integer END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS spec_memory_gb NUMERIC GENERATED ALWAYS AS (
+        CASE WHEN jsonb_typeof(resource_specs->'memory_gb') = 'number' THEN (resource_specs->>'memory_gb')::numeric END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS spec_gpu_count INTEGER GENERATED ALWAYS AS (
+        CASE WHEN jsonb_typeof(resource_specs->'gpu_count') = 'number' THEN (resource_specs->>'gpu_count')::numeric::integer END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS spec_gpu_memory_gb NUMERIC GENERATED ALWAYS AS (
+        CASE WHEN jsonb_typeof(resource_specs->'gpu_memory_gb') = 'number' THEN (resource_specs->>'gpu_memory_gb')::numeric END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS spec_storage_gb NUMERIC GENERATED ALWAYS AS (
+        CASE WHEN jsonb_typeof(resource_specs->'storage_gb') = 'number' THEN (resource_specs->>'storage_gb')::numeric END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS spec_clock_speed_ghz NUMERIC GENERATED ALWAYS AS (
+        CASE WHEN jsonb_typeof(resource_specs->'clock_speed_ghz') = 'number' THEN (resource_specs->>'clock_speed_ghz')::numeric END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS spec_burstable BOOLEAN GENERATED ALWAYS AS (
+        CASE WHEN jsonb_typeof(resource_specs->'burstable') = 'boolean' THEN (resource_specs->>'burstable')::boolean END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS spec_architecture TEXT GENERATED ALWAYS AS (
+        CASE
+            WHEN lower(btrim(resource_specs->>'architecture')) IN ('arm64', 'aarch64', 'arm') THEN 'arm64'
+            WHEN lower(btrim(resource_specs->>'architecture')) IN ('x86_64', 'x64', 'amd64', 'x86', 'i386') THEN 'x86_64'
+            ELSE NULLIF(lower(btrim(resource_specs->>'architecture')), '')
+        END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS spec_processor_vendor TEXT GENERATED ALWAYS AS (
+        CASE
+            WHEN lower(resource_specs->>'processor_type') LIKE '%graviton%' THEN 'aws'
+            WHEN lower(resource_specs->>'processor_type') LIKE '%ampere%' THEN 'ampere'
+            WHEN lower(resource_specs->>'processor_type') LIKE '%amd%'
+              OR lower(resource_specs->>'processor_type') LIKE '%epyc%' THEN 'amd'
+            WHEN lower(resource_specs->>'processor_type') LIKE '%intel%'
+              OR lower(resource_specs->>'processor_type') LIKE '%xeon%' THEN 'intel'
+        END
+    ) STORED;
+
+-- The text expression indexes are replaced by indexes on the typed columns
+DROP INDEX IF EXISTS idx_resource_vcpu;
+DROP INDEX IF EXISTS idx_resource_memory;
+
+CREATE INDEX IF NOT EXISTS idx_spec_vcpu_memory ON normalized_pricing(spec_vcpu, spec_memory_gb);
+CREATE INDEX IF NOT EXISTS idx_spec_memory ON normalized_pricing(spec_memory_gb);
+CREATE INDEX IF NOT EXISTS idx_spec_gpu ON normalized_pricing(spec_gpu_count, spec_gpu_memory_gb) WHERE spec_gpu_count > 0;
+CREATE INDEX IF NOT EXISTS idx_spec_storage ON normalized_pricing(spec_storage_gb) WHERE spec_storage_gb IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_spec_clock_speed ON normalized_pricing(spec_clock_speed_ghz) WHERE spec_clock_speed_ghz IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_spec_platform ON normalized_pricing(spec_architecture, spec_processor_vendor, spec_burstable);
+
+-- Price per vCPU and per GB of memory on the effective hourly rate, matching the ORDER BY
+-- expressions of QueryNormalizedPricing
+CREATE INDEX IF NOT EXISTS idx_price_per_vcpu ON normalized_pricing ((
+    COALESCE((pricing_details->>'effective_hourly_rate')::numeric, CASE WHEN unit = 'hour' THEN price_per_unit END)
+        / NULLIF(spec_vcpu, 0)
+));
+CREATE INDEX IF NOT EXISTS idx_price_per_memory_gb ON normalized_pricing ((
+    COALESCE((pricing_details->>'effective_hourly_rate')::numeric, CASE WHEN unit = 'hour' THEN price_per_unit END)
+        / NULLIF(spec_memory_gb, 0)
+));
+
+-- Point-in-time queries filter the price history on the same columns
+ALTER TABLE normalized_pricing_history
+    ADD COLUMN IF NOT EXISTS spec_vcpu INTEGER GENERATED ALWAYS AS (
+        CASE WHEN jsonb_typeof(resource_specs->'vcpu') = 'number' THEN (resource_specs->>'vcpu')::numeric::integer END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS spec_memory_gb NUMERIC GENERATED ALWAYS AS (
+        CASE WHEN jsonb_typeof(resource_specs->'memory_gb') = 'number' THEN (resource_specs->>'memory_gb')::numeric END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS spec_gpu_count INTEGER GENERATED ALWAYS AS (
+        CASE WHEN jsonb_typeof(resource_specs->'gpu_count') = 'number' THEN (resource_specs->>'gpu_count')::numeric::integer END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS spec_gpu_memory_gb NUMERIC GENERATED ALWAYS AS (
+        CASE WHEN jsonb_typeof(resource_specs->'gpu_memory_gb') = 'number' THEN (resource_specs->>'gpu_memory_gb')::numeric END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS spec_storage_gb NUMERIC GENERATED ALWAYS AS (
+        CASE WHEN jsonb_typeof(resource_specs->'storage_gb') = 'number' THEN (resource_specs->>'storage_gb')::numeric END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS spec_clock_speed_ghz NUMERIC GENERATED ALWAYS AS (
+        CASE WHEN jsonb_typeof(resource_specs->'clock_speed_ghz') = 'number' THEN (resource_specs->>'clock_speed_ghz')::numeric END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS spec_burstable BOOLEAN GENERATED ALWAYS AS (
+        CASE WHEN jsonb_typeof(resource_specs->'burstable') = 'boolean' THEN (resource_specs->>'burstable')::boolean END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS spec_architecture TEXT GENERATED ALWAYS AS (
+        CASE
+            WHEN lower(btrim(resource_specs->>'architecture')) IN ('arm64', 'aarch64', 'arm') THEN 'arm64'
+            WHEN lower(btrim(resource_specs->>'architecture')) IN ('x86_64', 'x64', 'amd64', 'x86', 'i386') THEN 'x86_64'
+            ELSE NULLIF(lower(btrim(resource_specs->>'architecture')), '')
+        END
+    ) STORED,
+    ADD COLUMN IF NOT EXISTS spec_processor_vendor TEXT GENERATED ALWAYS AS (
+        CASE
+            WHEN lower(resource_specs->>'processor_type') LIKE '%graviton%' THEN 'aws'
+            WHEN lower(resource_specs->>'processor_type') LIKE '%ampere%' THEN 'ampere'
+            WHEN lower(resource_specs->>'processor_type') LIKE '%amd%'
+              OR lower(resource_specs->>'processor_type') LIKE '%epyc%' THEN 'amd'
+            WHEN lower(resource_specs->>'processor_type') LIKE '%intel%'
+              OR lower(resource_specs->>'processor_type') LIKE '%xeon%' THEN 'intel'
+        END
+    ) STORED;
+
+-- The staging and previous generations are swapped in as normalized_pricing, so they get the
+-- same columns, copied from normalized_pricing, and indexes when they exist
+DO $$
+DECLARE
+    generation TEXT;
+    spec_column RECORD;
+BEGIN
+    FOREACH generation IN ARRAY ARRAY['normalized_pricing_staging', 'normalized_pricing_previous'] LOOP
+        IF to_regclass(generation) IS NULL THEN
+            CONTINUE;
+        END IF;
+
+        FOR spec_column IN
+            SELECT a.attname, format_type(a.atttypid, a.atttypmod) AS type, pg_get_expr(d.adbin, d.adrelid) AS expression
+            FROM pg_attribute a
+            JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+            WHERE a.attrelid = 'normalized_pricing'::regclass AND a.attgenerated = 's' AND a.attname LIKE 'spec\_%'
+            ORDER BY a.attnum
+        LOOP
+            EXECUTE format('ALTER TABLE %I ADD COLUMN IF NOT EXISTS %I %s GENERATED ALWAYS AS (%s) STORED',
+                generation, spec_column.attname, spec_column.type, spec_column.expression);
+        END LOOP;
+
+        EXECUTE format('CREATE INDEX ON %I (spec_vcpu, spec_memory_gb)', generation);
+        EXECUTE format('CREATE INDEX ON %I (spec_memory_gb)', generation);
+        EXECUTE format('CREATE INDEX ON %I (spec_gpu_count, spec_gpu_memory_gb) WHERE spec_gpu_count > 0', generation);
+        EXECUTE format('CREATE INDEX ON %I (spec_storage_gb) WHERE spec_storage_gb IS NOT NULL', generation);
+        EXECUTE format('CREATE INDEX ON %I (spec_clock_speed_ghz) WHERE spec_clock_speed_ghz IS NOT NULL', generation);
+        EXECUTE format('CREATE INDEX ON %I (spec_architecture, spec_processor_vendor, spec_burstable)', generation);
+        EXECUTE format($sql$
+            CREATE INDEX ON %I ((
+                COALESCE((pricing_details->>'effective_hourly_rate')::numeric, CASE WHEN unit = 'hour' THEN price_per_unit END)
+                    / NULLIF(spec_vcpu, 0)
+            ))$sql$, generation);
+        EXECUTE format($sql$
+            CREATE INDEX ON %I ((
+                COALESCE((pricing_details->>'effective_hourly_rate')::numeric, CASE WHEN unit = 'hour' THEN price_per_unit END)
+                    / NULLIF(spec_memory_gb, 0)
+            ))$sql$, generation);
+    END LOOP;
+END $$;
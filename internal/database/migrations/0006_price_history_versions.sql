@@ -0,0 +1,9 @@
+-- A price can have several live versions with different effective dates, e.g. the current
+-- price and an announced future one. The price history keeps one current version per natural
+-- key and effective date instead of one per natural key.
+
+DROP INDEX IF EXISTS idx_pricing_history_current;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pricing_history_current
+    ON normalized_pricing_history(natural_key, COALESCE(effective_date, '-infinity'::date))
+    WHERE valid_to IS NULL;
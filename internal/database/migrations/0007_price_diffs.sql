@@ -0,0 +1,11 @@
+-- Collections already diffed against their previous collection. A diff without changes stores
+-- no price_changes rows, so the ETL records every diff here to find the collections that landed
+-- since the last diff.
+CREATE TABLE IF NOT EXISTS price_diffs (
+    provider VARCHAR(20) NOT NULL,
+    collection_id VARCHAR(50) NOT NULL,
+    previous_collection_id VARCHAR(50) NOT NULL,
+    change_count INTEGER NOT NULL,
+    diffed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, collection_id)
+);
@@ -0,0 +1,44 @@
+-- spec_vcpu was an integer, so fractional vCPUs (shared-core instances with 0.25 or 0.5 vCPU)
+-- rounded to 0 or 1 and broke vCPU filters and per-vCPU prices. The generated column cannot
+-- change its type in place, so it is recreated as NUMERIC together with the indexes using it.
+
+ALTER TABLE normalized_pricing DROP COLUMN IF EXISTS spec_vcpu;
+ALTER TABLE normalized_pricing
+    ADD COLUMN spec_vcpu NUMERIC GENERATED ALWAYS AS (
+        CASE WHEN jsonb_typeof(resource_specs->'vcpu') = 'number' THEN (resource_specs->>'vcpu')::numeric END
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_spec_vcpu_memory ON normalized_pricing(spec_vcpu, spec_memory_gb);
+CREATE INDEX IF NOT EXISTS idx_price_per_vcpu ON normalized_pricing ((
+    COALESCE((pricing_details->>'effective_hourly_rate')::numeric, CASE WHEN unit = 'hour' THEN price_per_unit END)
+        / NULLIF(spec_vcpu, 0)
+));
+
+ALTER TABLE normalized_pricing_history DROP COLUMN IF EXISTS spec_vcpu;
+ALTER TABLE normalized_pricing_history
+    ADD COLUMN spec_vcpu NUMERIC GENERATED ALWAYS AS (
+        CASE WHEN jsonb_typeof(resource_specs->'vcpu') = 'number' THEN (resource_specs->>'vcpu')::numeric END
+    ) STORED;
+
+-- The staging and previous generations are swapped in as normalized_pricing, so they get the
+-- same column and indexes when they exist
+DO $$
+DECLARE
+    generation TEXT;
+BEGIN
+    FOREACH generation IN ARRAY ARRAY['normalized_pricing_staging', 'normalized_pricing_previous'] LOOP
+        IF to_regclass(generation) IS NOT NULL THEN
+            EXECUTE format('ALTER TABLE %I DROP COLUMN IF EXISTS spec_vcpu', generation);
+            EXECUTE format($sql$
+                ALTER TABLE %I ADD COLUMN spec_vcpu NUMERIC GENERATED ALWAYS AS (
+                    CASE WHEN jsonb_typeof(resource_specs->'vcpu') = 'number' THEN (resource_specs->>'vcpu')::numeric END
+                ) STORED$sql$, generation);
+            EXECUTE format('CREATE INDEX ON %I (spec_vcpu, spec_memory_gb)', generation);
+            EXECUTE format($sql$
+                CREATE INDEX ON %I ((
+                    COALESCE((pricing_details->>'effective_hourly_rate')::numeric, CASE WHEN unit = 'hour' THEN price_per_unit END)
+                        / NULLIF(spec_vcpu, 0)
+                ))$sql$, generation);
+        END IF;
+    END LOOP;
+END $$;
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// Normalized pricing table generations used for atomic re-normalization
+const (
+	NormalizedPricingTable         = "normalized_pricing"
+	NormalizedPricingStagingTable  = "normalized_pricing_staging"
+	NormalizedPricingPreviousTable = "normalized_pricing_previous"
+)
+
+// CreateNormalizedPricingStaging creates an empty staging table with the same structure as normalized_pricing
+func (db *DB) CreateNormalizedPricingStaging(ctx context.Context) error {
+	statements := []string{
+		`DROP TABLE IF EXISTS normalized_pricing_staging`,
+		`CREATE TABLE normalized_pricing_staging (LIKE normalized_pricing INCLUDING ALL)`,
+		`ALTER TABLE normalized_pricing_staging
+			ADD FOREIGN KEY (service_mapping_id) REFERENCES service_mappings(id),
+			ADD FOREIGN KEY (region_id) REFERENCES normalized_regions(id)`,
+		`CREATE TRIGGER update_normalized_pricing_updated_at
+			BEFORE UPDATE ON normalized_pricing_staging
+			FOR EACH ROW
+			EXECUTE FUNCTION update_updated_at_column()`,
+	}
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to create staging table: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("✅ Created %s table", NormalizedPricingStagingTable)
+	return nil
+}
+
+// DropNormalizedPricingStaging discards the staging table, leaving live data untouched
+func (db *DB) DropNormalizedPricingStaging(ctx context.Context) error {
+	_, err := db.conn.ExecContext(ctx, `DROP TABLE IF EXISTS normalized_pricing_staging`)
+	if err != nil {
+		return fmt.Errorf("failed to drop staging table: %w", err)
+	}
+
+	log.Printf("🗑️ Dropped %s table", NormalizedPricingStagingTable)
+	return nil
+}
+
+// NormalizedPricingStagingExists reports whether a staging table is present
+func (db *DB) NormalizedPricingStagingExists(ctx context.Context) (bool, error) {
+	var exists bool
+	err := db.conn.QueryRowContext(ctx, `SELECT to_regclass('normalized_pricing_staging') IS NOT NULL`).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check staging table: %w", err)
+	}
+
+	return exists, nil
+}
+
+// SwapNormalizedPricingStaging atomically promotes the staging table to live and keeps
+// the current live table as the previous generation
+func (db *DB) SwapNormalizedPricingStaging(ctx context.Context) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Block readers only for the duration of the renames
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE normalized_pricing IN ACCESS EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("failed to lock normalized pricing: %w", err)
+	}
+
+	var sequence string
+	err = tx.QueryRowContext(ctx, `SELECT pg_get_serial_sequence('normalized_pricing', 'id')`).Scan(&sequence)
+	if err != nil {
+		return fmt.Errorf("failed to look up id sequence: %w", err)
+	}
+
+	statements := []string{
+		`DROP TABLE IF EXISTS normalized_pricing_previous`,
+		`ALTER TABLE normalized_pricing RENAME TO normalized_pricing_previous`,
+		`ALTER TABLE normalized_pricing_staging RENAME TO normalized_pricing`,
+		// The staging table shares the id sequence; move ownership so dropping old generations keeps it
+		fmt.Sprintf(`ALTER SEQUENCE %s OWNED BY normalized_pricing.id`, sequence),
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to swap staging table: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("✅ Promoted %s to %s", NormalizedPricingStagingTable, NormalizedPricingTable)
+	return nil
+}
+
+// RollbackNormalizedPricing atomically restores the previous generation as live data.
+// The replaced generation becomes the new previous generation, so a rollback can be undone.
+func (db *DB) RollbackNormalizedPricing(ctx context.Context) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRowContext(ctx, `SELECT to_regclass('normalized_pricing_previous') IS NOT NULL`).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check previous generation: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("no previous normalized pricing generation to roll back to")
+	}
+
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE normalized_pricing, normalized_pricing_previous IN ACCESS EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("failed to lock normalized pricing: %w", err)
+	}
+
+	var sequence string
+	err = tx.QueryRowContext(ctx, `SELECT pg_get_serial_sequence('normalized_pricing', 'id')`).Scan(&sequence)
+	if err != nil {
+		return fmt.Errorf("failed to look up id sequence: %w", err)
+	}
+
+	statements := []string{
+		`ALTER TABLE normalized_pricing RENAME TO normalized_pricing_rollback`,
+		`ALTER TABLE normalized_pricing_previous RENAME TO normalized_pricing`,
+		`ALTER TABLE normalized_pricing_rollback RENAME TO normalized_pricing_previous`,
+		fmt.Sprintf(`ALTER SEQUENCE %s OWNED BY normalized_pricing.id`, sequence),
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to roll back normalized pricing: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("⏪ Rolled back %s to the previous generation", NormalizedPricingTable)
+	return nil
+}
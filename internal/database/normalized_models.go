@@ -2,6 +2,9 @@ package database
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -21,11 +24,18 @@ type NormalizedPricing struct {
 	ResourceName         string                 `json:"resourceName" db:"resource_name"`
 	ResourceDescription  *string                `json:"resourceDescription,omitempty" db:"resource_description"`
 	ResourceSpecs        ResourceSpecs          `json:"resourceSpecs" db:"resource_specs"`
+	OperatingSystem      *string                `json:"operatingSystem,omitempty" db:"operating_system"`
+	LicenseModel         *string                `json:"licenseModel,omitempty" db:"license_model"`
+	PreInstalledSoftware *string                `json:"preInstalledSoftware,omitempty" db:"pre_installed_software"`
+	Tenancy              *string                `json:"tenancy,omitempty" db:"tenancy"`
 	PricePerUnit         float64                `json:"pricePerUnit" db:"price_per_unit"`
 	Unit                 string                 `json:"unit" db:"unit"`
+	OriginalUnit         *string                `json:"originalUnit,omitempty" db:"original_unit"`
+	UnitMultiplier       float64                `json:"unitMultiplier,omitempty" db:"unit_multiplier"`
 	Currency             string                 `json:"currency" db:"currency"`
 	PricingModel         string                 `json:"pricingModel" db:"pricing_model"`
 	PricingDetails       PricingDetails         `json:"pricingDetails,omitempty" db:"pricing_details"`
+	PriceTiers           []PriceTier            `json:"priceTiers,omitempty" db:"price_tiers"`
 	EffectiveDate        *time.Time             `json:"effectiveDate,omitempty" db:"effective_date"`
 	ExpirationDate       *time.Time             `json:"expirationDate,omitempty" db:"expiration_date"`
 	MinimumCommitment    int                    `json:"minimumCommitment" db:"minimum_commitment"`
@@ -37,27 +47,77 @@ type NormalizedPricing struct {
 
 // ResourceSpecs represents the standardized resource specifications
 type ResourceSpecs struct {
-	VCPU               *int     `json:"vcpu,omitempty"`
-	MemoryGB           *float64 `json:"memory_gb,omitempty"`
-	StorageGB          *float64 `json:"storage_gb,omitempty"`
-	GPUCount           *int     `json:"gpu_count,omitempty"`
-	GPUMemoryGB        *float64 `json:"gpu_memory_gb,omitempty"`
-	NetworkPerformance *string  `json:"network_performance,omitempty"`
-	StorageType        *string  `json:"storage_type,omitempty"`
-	ProcessorType      *string  `json:"processor_type,omitempty"`
-	ProcessorFeatures  []string `json:"processor_features,omitempty"`
-	Architecture       *string  `json:"architecture,omitempty"`
-	ClockSpeedGHz      *float64 `json:"clock_speed_ghz,omitempty"`
-	Burstable          *bool    `json:"burstable,omitempty"`
+	VCPU                  *int     `json:"vcpu,omitempty"`
+	MemoryGB              *float64 `json:"memory_gb,omitempty"`
+	StorageGB             *float64 `json:"storage_gb,omitempty"`
+	GPUCount              *int     `json:"gpu_count,omitempty"`
+	GPUMemoryGB           *float64 `json:"gpu_memory_gb,omitempty"`
+	GPUModel              *string  `json:"gpu_model,omitempty"`
+	NetworkPerformance    *string  `json:"network_performance,omitempty"`
+	StorageType           *string  `json:"storage_type,omitempty"`
+	ProcessorType         *string  `json:"processor_type,omitempty"`
+	ProcessorFeatures     []string `json:"processor_features,omitempty"`
+	Architecture          *string  `json:"architecture,omitempty"`
+	ClockSpeedGHz         *float64 `json:"clock_speed_ghz,omitempty"`
+	Burstable             *bool    `json:"burstable,omitempty"`
+	AcceleratedNetworking *bool    `json:"accelerated_networking,omitempty"`
 }
 
 // PricingDetails represents additional pricing information for reserved/savings plans
 type PricingDetails struct {
-	TermLength     *string  `json:"term_length,omitempty"`     // "1yr", "3yr"
-	PaymentOption  *string  `json:"payment_option,omitempty"`  // "all_upfront", "partial_upfront", "no_upfront"
+	TermLength     *string  `json:"term_length,omitempty"`    // "1yr", "3yr"
+	PaymentOption  *string  `json:"payment_option,omitempty"` // "all_upfront", "partial_upfront", "no_upfront"
 	UpfrontCost    *float64 `json:"upfront_cost,omitempty"`
 	HourlyRate     *float64 `json:"hourly_rate,omitempty"`
 	SavingsPercent *float64 `json:"savings_percent,omitempty"` // Compared to on-demand
+
+	// Amortized rates: upfront cost spread evenly over the term plus the recurring hourly rate
+	EffectiveHourlyRate  *float64 `json:"effective_hourly_rate,omitempty"`
+	EffectiveMonthlyRate *float64 `json:"effective_monthly_rate,omitempty"`
+}
+
+// Amortize records the upfront cost and recurring hourly rate of a commitment along with
+// the effective hourly and monthly rates over a term of termHours
+func (d *PricingDetails) Amortize(upfrontCost, hourlyRate, termHours float64) {
+	if termHours <= 0 {
+		return
+	}
+
+	effectiveHourly := hourlyRate + upfrontCost/termHours
+	effectiveMonthly := effectiveHourly * HoursPerMonth
+
+	d.UpfrontCost = &upfrontCost
+	d.HourlyRate = &hourlyRate
+	d.EffectiveHourlyRate = &effectiveHourly
+	d.EffectiveMonthlyRate = &effectiveMonthly
+}
+
+// TermHours returns the number of hours in a commitment term such as "1yr" or "3yr"
+func TermHours(termLength string) (float64, bool) {
+	years, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(termLength)), "yr")))
+	if err != nil || years <= 0 {
+		return 0, false
+	}
+	return float64(years) * HoursPerYear, true
+}
+
+// PricingModelTermHours returns the term length implied by a reserved pricing model
+func PricingModelTermHours(pricingModel string) (float64, bool) {
+	switch pricingModel {
+	case PricingModelReserved1Yr:
+		return HoursPerYear, true
+	case PricingModelReserved3Yr:
+		return 3 * HoursPerYear, true
+	}
+	return 0, false
+}
+
+// PriceTier represents one volume tier of a tiered price. Usage from StartUnits up to EndUnits
+// is charged at PricePerUnit; the last tier has no end.
+type PriceTier struct {
+	StartUnits   float64  `json:"start_units"`
+	EndUnits     *float64 `json:"end_units,omitempty"`
+	PricePerUnit float64  `json:"price_per_unit"`
 }
 
 // ServiceMapping represents the mapping between provider services and normalized categories
@@ -112,26 +172,68 @@ type PricingComparison struct {
 	PriceDifference  *float64                 `json:"priceDifference,omitempty"` // AWS - Azure
 	CheaperProvider  *string                  `json:"cheaperProvider,omitempty"`
 	SavingsPercent   *float64                 `json:"savingsPercent,omitempty"`
+	SimilarityScore  *float64                 `json:"similarityScore,omitempty"` // 0 to 1, set for matched pairs
 }
 
 // PricingFilter represents filters for normalized pricing queries
 type PricingFilter struct {
-	Provider         *string       `json:"provider,omitempty"`
-	ServiceCategory  *string       `json:"serviceCategory,omitempty"`
-	ServiceFamily    *string       `json:"serviceFamily,omitempty"`
-	ServiceType      *string       `json:"serviceType,omitempty"`
-	NormalizedRegion *string       `json:"normalizedRegion,omitempty"`
-	PricingModel     *string       `json:"pricingModel,omitempty"`
-	Currency         *string       `json:"currency,omitempty"`
-	ResourceSpecs    *ResourceSpecs `json:"resourceSpecs,omitempty"`
-	MaxPricePerUnit  *float64      `json:"maxPricePerUnit,omitempty"`
-	MinPricePerUnit  *float64      `json:"minPricePerUnit,omitempty"`
-	Limit            *int          `json:"limit,omitempty"`
-	Offset           *int          `json:"offset,omitempty"`
-	OrderBy          *string       `json:"orderBy,omitempty"` // "price_per_unit", "resource_name", etc.
-	OrderDirection   *string       `json:"orderDirection,omitempty"` // "ASC", "DESC"
+	Provider             *string        `json:"provider,omitempty"`
+	ServiceCategory      *string        `json:"serviceCategory,omitempty"`
+	ServiceFamily        *string        `json:"serviceFamily,omitempty"`
+	ServiceType          *string        `json:"serviceType,omitempty"`
+	NormalizedRegion     *string        `json:"normalizedRegion,omitempty"`
+	PricingModel         *string        `json:"pricingModel,omitempty"`
+	Currency             *string        `json:"currency,omitempty"`
+	OperatingSystem      *string        `json:"operatingSystem,omitempty"`
+	LicenseModel         *string        `json:"licenseModel,omitempty"`
+	PreInstalledSoftware *string        `json:"preInstalledSoftware,omitempty"`
+	Tenancy              *string        `json:"tenancy,omitempty"`
+	Providers            []string       `json:"providers,omitempty"`         // Any of these providers
+	NormalizedRegions    []string       `json:"normalizedRegions,omitempty"` // Any of these regions
+	PricingModels        []string       `json:"pricingModels,omitempty"`     // Any of these pricing models
+	ResourceSpecs        *ResourceSpecs `json:"resourceSpecs,omitempty"`
+	Specs                *SpecFilter    `json:"specs,omitempty"` // Spec ranges, architecture, burstable and processor vendor
+	MaxPricePerUnit      *float64       `json:"maxPricePerUnit,omitempty"`
+	MinPricePerUnit      *float64       `json:"minPricePerUnit,omitempty"`
+	Limit                *int           `json:"limit,omitempty"`
+	Offset               *int           `json:"offset,omitempty"`
+	OrderBy              *string        `json:"orderBy,omitempty"`        // "price_per_unit", "effective_hourly_rate", "resource_name", etc.
+	OrderDirection       *string        `json:"orderDirection,omitempty"` // "ASC", "DESC"
+	AsOf                 *time.Time     `json:"asOf,omitempty"`           // Only prices valid at this moment
+	History              bool           `json:"history,omitempty"`        // Prices the ETL had live at AsOf, from the price history
 }
 
+// ParseAsOf parses an asOf date given as YYYY-MM-DD or RFC 3339
+func ParseAsOf(value string) (time.Time, error) {
+	if asOf, err := time.Parse("2006-01-02", value); err == nil {
+		return asOf, nil
+	}
+	asOf, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid asOf date %q: expected YYYY-MM-DD or RFC 3339", value)
+	}
+	return asOf, nil
+}
+
+// IsValidAt reports whether the price applies at the given moment. Missing dates leave the
+// validity window open; the expiration date is the last day the price applies.
+func (np NormalizedPricing) IsValidAt(moment time.Time) bool {
+	day := moment.UTC().Format("2006-01-02")
+	if np.EffectiveDate != nil && np.EffectiveDate.UTC().Format("2006-01-02") > day {
+		return false
+	}
+	if np.ExpirationDate != nil && np.ExpirationDate.UTC().Format("2006-01-02") < day {
+		return false
+	}
+	return true
+}
+
+// Limits applied to normalized pricing queries served by the APIs
+const (
+	DefaultPricingQueryLimit = 100
+	MaxPricingQueryLimit     = 1000
+)
+
 // Constants for standardized values
 const (
 	// Providers
@@ -154,6 +256,42 @@ const (
 	UnitGB              = "gb"
 	UnitTB              = "tb"
 	UnitInstance        = "instance"
+	UnitCount           = "count" // Operations of a meter without a named unit, e.g. Azure "10K"
+
+	// Operating systems
+	OSLinux     = "linux"
+	OSWindows   = "windows"
+	OSRHEL      = "rhel"
+	OSRHELHA    = "rhel_ha"
+	OSSUSE      = "suse"
+	OSUbuntuPro = "ubuntu_pro"
+
+	// License models
+	LicenseModelIncluded = "license_included"
+	LicenseModelBYOL     = "byol"
+	LicenseModelNone     = "none"
+
+	// Pre-installed software
+	SoftwareNone          = "none"
+	SoftwareSQLWeb        = "sql_web"
+	SoftwareSQLStandard   = "sql_standard"
+	SoftwareSQLEnterprise = "sql_enterprise"
+
+	// Tenancies
+	TenancyShared    = "shared"
+	TenancyDedicated = "dedicated"
+	TenancyHost      = "host"
+
+	// Orderings for normalized pricing queries
+	OrderByPricePerUnit         = "price_per_unit"
+	OrderByEffectiveHourlyRate  = "effective_hourly_rate"
+	OrderByEffectiveMonthlyRate = "effective_monthly_rate"
+	OrderByPricePerVCPU         = "price_per_vcpu"      // Effective hourly rate per vCPU
+	OrderByPricePerMemoryGB     = "price_per_memory_gb" // Effective hourly rate per GB of memory
+
+	// Hours used to amortize commitments
+	HoursPerMonth = 730
+	HoursPerYear  = 8760
 
 	// Service Categories
 	CategoryGeneral         = "General"
@@ -249,6 +387,10 @@ func (np NormalizedPricing) IsSpot() bool {
 
 // GetHourlyPrice returns the effective hourly price considering upfront costs
 func (np NormalizedPricing) GetHourlyPrice() float64 {
+	if np.PricingDetails.EffectiveHourlyRate != nil {
+		return *np.PricingDetails.EffectiveHourlyRate
+	}
+
 	if np.Unit == UnitHour {
 		return np.PricePerUnit
 	}
@@ -259,4 +401,76 @@ func (np NormalizedPricing) GetHourlyPrice() float64 {
 	}
 	
 	return np.PricePerUnit
-}
\ No newline at end of file
+}
+
+// IsSavingsPlan returns true if this is savings plan pricing
+func (np NormalizedPricing) IsSavingsPlan() bool {
+	return np.PricingModel == PricingModelSavingsPlan
+}
+
+// IsTiered returns true if the price depends on the usage volume
+func (np NormalizedPricing) IsTiered() bool {
+	return len(np.PriceTiers) > 0
+}
+
+// TotalCost returns the cost of the given usage quantity, walking the price tiers when present
+func (np NormalizedPricing) TotalCost(quantity float64) float64 {
+	if !np.IsTiered() {
+		return quantity * np.PricePerUnit
+	}
+	return CalculateTieredCost(np.PriceTiers, quantity)
+}
+
+// CalculateTieredCost returns the cost of a usage quantity under tiers sorted by start. Usage
+// below the start of the first tier is free, as zero-priced tiers are not stored.
+func CalculateTieredCost(tiers []PriceTier, quantity float64) float64 {
+	var cost float64
+	for _, tier := range tiers {
+		if quantity <= tier.StartUnits {
+			break
+		}
+
+		units := quantity - tier.StartUnits
+		if tier.EndUnits != nil && quantity > *tier.EndUnits {
+			units = *tier.EndUnits - tier.StartUnits
+		}
+		cost += units * tier.PricePerUnit
+	}
+	return cost
+}
+
+// NaturalKey returns a key that identifies the same price point across normalization runs. It
+// matches the natural key the price history computes in SQL.
+func (np NormalizedPricing) NaturalKey() string {
+	return strings.Join([]string{
+		np.Provider,
+		np.ProviderRegion,
+		stringValue(np.ProviderSKU),
+		np.ResourceName,
+		np.PricingModel,
+		np.Unit,
+		np.Currency,
+		np.PlatformKey(),
+		stringValue(np.PricingDetails.TermLength),
+		stringValue(np.PricingDetails.PaymentOption),
+	}, "|")
+}
+
+// PlatformKey returns the operating system, license, pre-installed software and tenancy of the
+// price. Only prices with the same platform key are equivalent across providers.
+func (np NormalizedPricing) PlatformKey() string {
+	return strings.Join([]string{
+		stringValue(np.OperatingSystem),
+		stringValue(np.LicenseModel),
+		stringValue(np.PreInstalledSoftware),
+		stringValue(np.Tenancy),
+	}, "|")
+}
+
+// stringValue returns the value of an optional string, or an empty string when unset
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -5,7 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 // GetServiceMappings retrieves all service mappings
@@ -163,6 +167,11 @@ func (db *DB) InsertNormalizedPricing(pricing *NormalizedPricing) error {
 		return fmt.Errorf("failed to marshal pricing details: %w", err)
 	}
 
+	priceTiersJSON, err := marshalPriceTiers(pricing.PriceTiers)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO normalized_pricing (
 			provider, provider_service_code, provider_sku, service_mapping_id,
@@ -170,9 +179,11 @@ func (db *DB) InsertNormalizedPricing(pricing *NormalizedPricing) error {
 			normalized_region, provider_region, resource_name, resource_description,
 			resource_specs, price_per_unit, unit, currency, pricing_model,
 			pricing_details, effective_date, expiration_date, minimum_commitment,
-			aws_raw_id, azure_raw_id
+			aws_raw_id, azure_raw_id, price_tiers, operating_system, license_model,
+			pre_installed_software, tenancy, original_unit, unit_multiplier
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24,
+			$25, $26, $27, $28, $29, $30
 		) RETURNING id, created_at, updated_at`
 
 	err = db.conn.QueryRow(
@@ -200,6 +211,13 @@ func (db *DB) InsertNormalizedPricing(pricing *NormalizedPricing) error {
 		pricing.MinimumCommitment,
 		pricing.AWSRawID,
 		pricing.AzureRawID,
+		priceTiersJSON,
+		pricing.OperatingSystem,
+		pricing.LicenseModel,
+		pricing.PreInstalledSoftware,
+		pricing.Tenancy,
+		pricing.OriginalUnit,
+		unitMultiplier(pricing.UnitMultiplier),
 	).Scan(&pricing.ID, &pricing.CreatedAt, &pricing.UpdatedAt)
 
 	return err
@@ -207,6 +225,16 @@ func (db *DB) InsertNormalizedPricing(pricing *NormalizedPricing) error {
 
 // BulkInsertNormalizedPricing inserts multiple normalized pricing records
 func (db *DB) BulkInsertNormalizedPricing(pricings []NormalizedPricing) error {
+	return db.BulkInsertNormalizedPricingInto(NormalizedPricingTable, pricings)
+}
+
+// BulkInsertNormalizedPricingInto inserts multiple normalized pricing records into the given
+// normalized pricing generation table
+func (db *DB) BulkInsertNormalizedPricingInto(table string, pricings []NormalizedPricing) error {
+	if table != NormalizedPricingTable && table != NormalizedPricingStagingTable {
+		return fmt.Errorf("unsupported normalized pricing table: %s", table)
+	}
+
 	if len(pricings) == 0 {
 		return nil
 	}
@@ -218,18 +246,35 @@ func (db *DB) BulkInsertNormalizedPricing(pricings []NormalizedPricing) error {
 	}
 	defer tx.Rollback()
 
+	if err := insertNormalizedPricingRecords(tx, table, pricings); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("✅ Bulk inserted %d normalized pricing records into %s", len(pricings), table)
+	return nil
+}
+
+// insertNormalizedPricingRecords inserts normalized pricing records within an open transaction
+func insertNormalizedPricingRecords(tx *sql.Tx, table string, pricings []NormalizedPricing) error {
 	// Prepare statement
-	query := `
-		INSERT INTO normalized_pricing (
+	query := fmt.Sprintf(`
+		INSERT INTO %s (
 			provider, provider_service_code, provider_sku, service_mapping_id,
 			service_category, service_family, service_type, region_id,
 			normalized_region, provider_region, resource_name, resource_description,
 			resource_specs, price_per_unit, unit, currency, pricing_model,
 			pricing_details, effective_date, expiration_date, minimum_commitment,
-			aws_raw_id, azure_raw_id
+			aws_raw_id, azure_raw_id, price_tiers, operating_system, license_model,
+			pre_installed_software, tenancy, original_unit, unit_multiplier
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
-		)`
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24,
+			$25, $26, $27, $28, $29, $30
+		)`, table)
 
 	stmt, err := tx.Prepare(query)
 	if err != nil {
@@ -249,6 +294,11 @@ func (db *DB) BulkInsertNormalizedPricing(pricings []NormalizedPricing) error {
 			return fmt.Errorf("failed to marshal pricing details: %w", err)
 		}
 
+		priceTiersJSON, err := marshalPriceTiers(pricing.PriceTiers)
+		if err != nil {
+			return err
+		}
+
 		_, err = stmt.Exec(
 			pricing.Provider,
 			pricing.ProviderServiceCode,
@@ -273,35 +323,75 @@ func (db *DB) BulkInsertNormalizedPricing(pricings []NormalizedPricing) error {
 			pricing.MinimumCommitment,
 			pricing.AWSRawID,
 			pricing.AzureRawID,
+			priceTiersJSON,
+			pricing.OperatingSystem,
+			pricing.LicenseModel,
+			pricing.PreInstalledSoftware,
+			pricing.Tenancy,
+			pricing.OriginalUnit,
+			unitMultiplier(pricing.UnitMultiplier),
 		)
 		if err != nil {
 			return fmt.Errorf("failed to insert pricing record: %w", err)
 		}
 	}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	return nil
+}
+
+// unitMultiplier returns the unit multiplier to store, defaulting to 1 for records built
+// without a parsed unit of measure
+func unitMultiplier(multiplier float64) float64 {
+	if multiplier <= 0 {
+		return 1
+	}
+	return multiplier
+}
+
+// marshalPriceTiers serializes price tiers for the price_tiers column, which is NULL for flat prices
+func marshalPriceTiers(tiers []PriceTier) (interface{}, error) {
+	if len(tiers) == 0 {
+		return nil, nil
 	}
 
-	log.Printf("✅ Bulk inserted %d normalized pricing records", len(pricings))
-	return nil
+	priceTiersJSON, err := json.Marshal(tiers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal price tiers: %w", err)
+	}
+
+	return string(priceTiersJSON), nil
 }
 
 // QueryNormalizedPricing queries normalized pricing with filters
 func (db *DB) QueryNormalizedPricing(filter PricingFilter) ([]NormalizedPricing, error) {
-	query := `
-		SELECT id, provider, provider_service_code, provider_sku, service_mapping_id,
-		       service_category, service_family, service_type, region_id,
-		       normalized_region, provider_region, resource_name, resource_description,
-		       resource_specs, price_per_unit, unit, currency, pricing_model,
-		       pricing_details, effective_date, expiration_date, minimum_commitment,
-		       aws_raw_id, azure_raw_id, created_at, updated_at
-		FROM normalized_pricing WHERE 1=1`
+	if filter.Specs != nil {
+		if err := filter.Specs.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	table := NormalizedPricingTable
+	if filter.History {
+		if filter.AsOf == nil {
+			return nil, fmt.Errorf("history queries require an asOf date")
+		}
+		if err := db.checkPriceHistoryCovers(*filter.AsOf); err != nil {
+			return nil, err
+		}
+		table = NormalizedPricingHistoryTable
+	}
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE 1=1`, normalizedPricingSelectColumns, table)
 
 	args := []interface{}{}
 	argCount := 0
 
+	// Point-in-time queries read the price versions the ETL had live at that moment
+	if filter.History {
+		argCount++
+		query += fmt.Sprintf(" AND valid_from <= $%[1]d AND (valid_to IS NULL OR valid_to > $%[1]d)", argCount)
+		args = append(args, *filter.AsOf)
+	}
+
 	// Build WHERE clauses dynamically
 	if filter.Provider != nil {
 		argCount++
@@ -345,6 +435,42 @@ func (db *DB) QueryNormalizedPricing(filter PricingFilter) ([]NormalizedPricing,
 		args = append(args, *filter.Currency)
 	}
 
+	if filter.OperatingSystem != nil {
+		argCount++
+		query += fmt.Sprintf(" AND operating_system = $%d", argCount)
+		args = append(args, *filter.OperatingSystem)
+	}
+
+	if filter.LicenseModel != nil {
+		argCount++
+		query += fmt.Sprintf(" AND license_model = $%d", argCount)
+		args = append(args, *filter.LicenseModel)
+	}
+
+	if filter.PreInstalledSoftware != nil {
+		argCount++
+		query += fmt.Sprintf(" AND pre_installed_software = $%d", argCount)
+		args = append(args, *filter.PreInstalledSoftware)
+	}
+
+	if filter.Tenancy != nil {
+		argCount++
+		query += fmt.Sprintf(" AND tenancy = $%d", argCount)
+		args = append(args, *filter.Tenancy)
+	}
+
+	// Multi-value filters match any of their values
+	anyOf := func(column string, values []string) {
+		if len(values) > 0 {
+			argCount++
+			query += fmt.Sprintf(" AND %s = ANY($%d)", column, argCount)
+			args = append(args, pq.Array(values))
+		}
+	}
+	anyOf("provider", filter.Providers)
+	anyOf("normalized_region", filter.NormalizedRegions)
+	anyOf("pricing_model", filter.PricingModels)
+
 	if filter.MaxPricePerUnit != nil {
 		argCount++
 		query += fmt.Sprintf(" AND price_per_unit <= $%d", argCount)
@@ -357,25 +483,26 @@ func (db *DB) QueryNormalizedPricing(filter PricingFilter) ([]NormalizedPricing,
 		args = append(args, *filter.MinPricePerUnit)
 	}
 
-	// Resource specs filtering (example for vCPU)
-	if filter.ResourceSpecs != nil && filter.ResourceSpecs.VCPU != nil {
+	// Effective and expiration dates are stored as dates, so validity is decided per day
+	if filter.AsOf != nil {
 		argCount++
-		query += fmt.Sprintf(" AND resource_specs->>'vcpu' = $%d", argCount)
-		args = append(args, fmt.Sprintf("%d", *filter.ResourceSpecs.VCPU))
+		query += fmt.Sprintf(" AND (effective_date IS NULL OR effective_date <= $%[1]d::date) AND (expiration_date IS NULL OR expiration_date >= $%[1]d::date)", argCount)
+		args = append(args, filter.AsOf.UTC().Format("2006-01-02"))
 	}
 
-	// Add ORDER BY
-	orderBy := "price_per_unit"
-	if filter.OrderBy != nil {
-		orderBy = *filter.OrderBy
+	// Resource spec filters compare the typed spec columns generated from resource_specs
+	for _, predicate := range filter.SpecPredicates() {
+		argCount++
+		query += fmt.Sprintf(" AND %s %s $%d", predicate.Column, predicate.Operator, argCount)
+		args = append(args, predicate.Value)
 	}
-	
-	orderDirection := "ASC"
-	if filter.OrderDirection != nil {
-		orderDirection = strings.ToUpper(*filter.OrderDirection)
+
+	// Add ORDER BY
+	orderClause, err := filter.orderClause()
+	if err != nil {
+		return nil, err
 	}
-	
-	query += fmt.Sprintf(" ORDER BY %s %s", orderBy, orderDirection)
+	query += orderClause
 
 	// Add LIMIT and OFFSET
 	if filter.Limit != nil {
@@ -398,157 +525,259 @@ func (db *DB) QueryNormalizedPricing(filter PricingFilter) ([]NormalizedPricing,
 
 	var pricings []NormalizedPricing
 	for rows.Next() {
-		var pricing NormalizedPricing
-		var resourceSpecsJSON, pricingDetailsJSON string
-
-		err := rows.Scan(
-			&pricing.ID,
-			&pricing.Provider,
-			&pricing.ProviderServiceCode,
-			&pricing.ProviderSKU,
-			&pricing.ServiceMappingID,
-			&pricing.ServiceCategory,
-			&pricing.ServiceFamily,
-			&pricing.ServiceType,
-			&pricing.RegionID,
-			&pricing.NormalizedRegion,
-			&pricing.ProviderRegion,
-			&pricing.ResourceName,
-			&pricing.ResourceDescription,
-			&resourceSpecsJSON,
-			&pricing.PricePerUnit,
-			&pricing.Unit,
-			&pricing.Currency,
-			&pricing.PricingModel,
-			&pricingDetailsJSON,
-			&pricing.EffectiveDate,
-			&pricing.ExpirationDate,
-			&pricing.MinimumCommitment,
-			&pricing.AWSRawID,
-			&pricing.AzureRawID,
-			&pricing.CreatedAt,
-			&pricing.UpdatedAt,
-		)
+		pricing, err := scanNormalizedPricing(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan pricing record: %w", err)
+			return nil, err
 		}
+		pricings = append(pricings, pricing)
+	}
 
-		// Parse JSONB fields
-		if err := json.Unmarshal([]byte(resourceSpecsJSON), &pricing.ResourceSpecs); err != nil {
-			log.Printf("Warning: failed to unmarshal resource specs for ID %d: %v", pricing.ID, err)
-		}
+	return pricings, nil
+}
 
-		if err := json.Unmarshal([]byte(pricingDetailsJSON), &pricing.PricingDetails); err != nil {
-			log.Printf("Warning: failed to unmarshal pricing details for ID %d: %v", pricing.ID, err)
+// normalizedPricingSelectColumns are the columns read by scanNormalizedPricing
+const normalizedPricingSelectColumns = `id, provider, provider_service_code, provider_sku, service_mapping_id,
+		       service_category, service_family, service_type, region_id,
+		       normalized_region, provider_region, resource_name, resource_description,
+		       resource_specs, price_per_unit, unit, currency, pricing_model,
+		       pricing_details, effective_date, expiration_date, minimum_commitment,
+		       aws_raw_id, azure_raw_id, price_tiers, operating_system, license_model,
+		       pre_installed_software, tenancy, original_unit, COALESCE(unit_multiplier, 1),
+		       created_at, updated_at`
+
+// scanNormalizedPricing scans a row selected with normalizedPricingSelectColumns, followed by
+// any extra columns
+func scanNormalizedPricing(rows *sql.Rows, extra ...interface{}) (NormalizedPricing, error) {
+	var pricing NormalizedPricing
+	var resourceSpecsJSON, pricingDetailsJSON string
+	var priceTiersJSON sql.NullString
+
+	dest := []interface{}{
+		&pricing.ID,
+		&pricing.Provider,
+		&pricing.ProviderServiceCode,
+		&pricing.ProviderSKU,
+		&pricing.ServiceMappingID,
+		&pricing.ServiceCategory,
+		&pricing.ServiceFamily,
+		&pricing.ServiceType,
+		&pricing.RegionID,
+		&pricing.NormalizedRegion,
+		&pricing.ProviderRegion,
+		&pricing.ResourceName,
+		&pricing.ResourceDescription,
+		&resourceSpecsJSON,
+		&pricing.PricePerUnit,
+		&pricing.Unit,
+		&pricing.Currency,
+		&pricing.PricingModel,
+		&pricingDetailsJSON,
+		&pricing.EffectiveDate,
+		&pricing.ExpirationDate,
+		&pricing.MinimumCommitment,
+		&pricing.AWSRawID,
+		&pricing.AzureRawID,
+		&priceTiersJSON,
+		&pricing.OperatingSystem,
+		&pricing.LicenseModel,
+		&pricing.PreInstalledSoftware,
+		&pricing.Tenancy,
+		&pricing.OriginalUnit,
+		&pricing.UnitMultiplier,
+		&pricing.CreatedAt,
+		&pricing.UpdatedAt,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return pricing, fmt.Errorf("failed to scan pricing record: %w", err)
+	}
+
+	// Parse JSONB fields
+	if err := json.Unmarshal([]byte(resourceSpecsJSON), &pricing.ResourceSpecs); err != nil {
+		log.Printf("Warning: failed to unmarshal resource specs for ID %d: %v", pricing.ID, err)
+	}
+
+	if err := json.Unmarshal([]byte(pricingDetailsJSON), &pricing.PricingDetails); err != nil {
+		log.Printf("Warning: failed to unmarshal pricing details for ID %d: %v", pricing.ID, err)
+	}
+
+	if priceTiersJSON.Valid {
+		if err := json.Unmarshal([]byte(priceTiersJSON.String), &pricing.PriceTiers); err != nil {
+			log.Printf("Warning: failed to unmarshal price tiers for ID %d: %v", pricing.ID, err)
 		}
+	}
 
-		pricings = append(pricings, pricing)
+	return pricing, nil
+}
+
+// effectiveHourlyRateExpr returns the SQL for the amortized hourly rate of a record, falling
+// back to the price of hourly records without a commitment. Without an alias it matches the
+// idx_effective_hourly_rate expression index.
+func effectiveHourlyRateExpr(alias string) string {
+	if alias != "" {
+		alias += "."
 	}
+	return fmt.Sprintf("COALESCE((%[1]spricing_details->>'effective_hourly_rate')::numeric, CASE WHEN %[1]sunit = 'hour' THEN %[1]sprice_per_unit END)", alias)
+}
 
-	return pricings, nil
+// pricingOrderExpressions maps the supported PricingFilter.OrderBy values to SQL
+var pricingOrderExpressions = map[string]string{
+	OrderByPricePerUnit:         "price_per_unit",
+	OrderByEffectiveHourlyRate:  effectiveHourlyRateExpr(""),
+	OrderByEffectiveMonthlyRate: effectiveHourlyRateExpr(""), // monthly is a fixed multiple of hourly
+	"resource_name":             "resource_name",
+	"provider":                  "provider",
+	"service_type":              "service_type",
+	"normalized_region":         "normalized_region",
+	"pricing_model":             "pricing_model",
+	"created_at":                "created_at",
+	OrderByPricePerVCPU:         effectiveHourlyRateExpr("") + " / NULLIF(spec_vcpu, 0)",
+	OrderByPricePerMemoryGB:     effectiveHourlyRateExpr("") + " / NULLIF(spec_memory_gb, 0)",
+}
+
+// ValidateOrder checks that the ordering of the filter is supported
+func (filter PricingFilter) ValidateOrder() error {
+	_, err := filter.orderClause()
+	return err
+}
+
+// orderClause builds the ORDER BY clause of the filter, ordering by price per unit by default
+func (filter PricingFilter) orderClause() (string, error) {
+	orderBy := OrderByPricePerUnit
+	if filter.OrderBy != nil {
+		orderBy = *filter.OrderBy
+	}
+	orderExpr, ok := pricingOrderExpressions[orderBy]
+	if !ok {
+		return "", fmt.Errorf("unsupported order by: %s", orderBy)
+	}
+
+	orderDirection := "ASC"
+	if filter.OrderDirection != nil {
+		orderDirection = strings.ToUpper(*filter.OrderDirection)
+	}
+	if orderDirection != "ASC" && orderDirection != "DESC" {
+		return "", fmt.Errorf("unsupported order direction: %s", orderDirection)
+	}
+
+	return fmt.Sprintf(" ORDER BY %s %s NULLS LAST, id", orderExpr, orderDirection), nil
 }
 
-// ComparePricing compares equivalent services between providers
+// ComparePricing compares equivalent services between providers using the default matching
+// configuration
 func (db *DB) ComparePricing(serviceType, normalizedRegion, pricingModel string) ([]PricingComparison, error) {
-	query := `
-		SELECT 
-			p1.id, p1.provider, p1.resource_name, p1.resource_specs, p1.price_per_unit, p1.unit,
-			p2.id, p2.provider, p2.resource_name, p2.resource_specs, p2.price_per_unit, p2.unit
-		FROM normalized_pricing p1
-		FULL OUTER JOIN normalized_pricing p2 ON (
-			p1.service_type = p2.service_type 
-			AND p1.normalized_region = p2.normalized_region
-			AND p1.pricing_model = p2.pricing_model
-			AND p1.provider != p2.provider
-			AND p1.resource_specs = p2.resource_specs
-		)
-		WHERE 
-			COALESCE(p1.service_type, p2.service_type) = $1
-			AND COALESCE(p1.normalized_region, p2.normalized_region) = $2
-			AND COALESCE(p1.pricing_model, p2.pricing_model) = $3
-			AND (p1.provider = 'aws' OR p1.provider IS NULL)
-		ORDER BY COALESCE(p1.price_per_unit, p2.price_per_unit)`
-
-	rows, err := db.conn.Query(query, serviceType, normalizedRegion, pricingModel)
+	return db.ComparePricingWithConfig(serviceType, normalizedRegion, pricingModel, DefaultMatchingConfig())
+}
+
+// ComparePricingWithConfig pairs every AWS record with its best Azure equivalents by resource
+// similarity. AWS records without an equivalent, and Azure records that are no AWS record's
+// equivalent, are returned on their own.
+func (db *DB) ComparePricingWithConfig(serviceType, normalizedRegion, pricingModel string, config MatchingConfig) ([]PricingComparison, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid matching configuration: %w", err)
+	}
+
+	asOf := time.Now()
+	pricings, err := db.QueryNormalizedPricing(PricingFilter{
+		ServiceType:      &serviceType,
+		NormalizedRegion: &normalizedRegion,
+		PricingModel:     &pricingModel,
+		AsOf:             &asOf,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to compare pricing: %w", err)
 	}
-	defer rows.Close()
 
-	var comparisons []PricingComparison
-	for rows.Next() {
-		var comp PricingComparison
-		var aws, azure NormalizedPricing
-		var awsResourceSpecsJSON, azureResourceSpecsJSON sql.NullString
-		var awsID, azureID sql.NullInt64
-		var awsProvider, azureProvider, awsResourceName, azureResourceName sql.NullString
-		var awsPrice, azurePrice sql.NullFloat64
-		var awsUnit, azureUnit sql.NullString
+	return CompareOffers(pricings, serviceType, normalizedRegion, pricingModel, config), nil
+}
 
-		err := rows.Scan(
-			&awsID, &awsProvider, &awsResourceName, &awsResourceSpecsJSON, &awsPrice, &awsUnit,
-			&azureID, &azureProvider, &azureResourceName, &azureResourceSpecsJSON, &azurePrice, &azureUnit,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan comparison: %w", err)
+// CompareOffers pairs the AWS and Azure offers of a service type, region and pricing model
+// as described by ComparePricingWithConfig. The config must be valid.
+func CompareOffers(pricings []NormalizedPricing, serviceType, normalizedRegion, pricingModel string, config MatchingConfig) []PricingComparison {
+	var awsPricings, azurePricings []NormalizedPricing
+	for _, pricing := range pricings {
+		switch pricing.Provider {
+		case ProviderAWS:
+			awsPricings = append(awsPricings, pricing)
+		case ProviderAzure:
+			azurePricings = append(azurePricings, pricing)
 		}
+	}
+
+	// Order by effective rate so upfront commitments compare fairly with hourly ones
+	byHourlyPrice := func(pricings []NormalizedPricing) {
+		sort.SliceStable(pricings, func(i, j int) bool {
+			return pricings[i].GetHourlyPrice() < pricings[j].GetHourlyPrice()
+		})
+	}
+	byHourlyPrice(awsPricings)
+	byHourlyPrice(azurePricings)
 
-		comp.ServiceType = serviceType
-		comp.NormalizedRegion = normalizedRegion
-		comp.PricingModel = pricingModel
-
-		// Parse AWS data if present
-		if awsID.Valid {
-			aws.ID = int(awsID.Int64)
-			aws.Provider = awsProvider.String
-			aws.ResourceName = awsResourceName.String
-			aws.PricePerUnit = awsPrice.Float64
-			aws.Unit = awsUnit.String
-			
-			if awsResourceSpecsJSON.Valid {
-				json.Unmarshal([]byte(awsResourceSpecsJSON.String), &aws.ResourceSpecs)
-				comp.ResourceSpecs = aws.ResourceSpecs
-			}
-			comp.AWS = &aws
+	newComparison := func(aws, azure *NormalizedPricing, score *float64) PricingComparison {
+		comp := PricingComparison{
+			ServiceType:      serviceType,
+			NormalizedRegion: normalizedRegion,
+			PricingModel:     pricingModel,
+			AWS:              aws,
+			Azure:            azure,
+			SimilarityScore:  score,
+		}
+		if aws != nil {
+			comp.ResourceSpecs = aws.ResourceSpecs
+		} else {
+			comp.ResourceSpecs = azure.ResourceSpecs
 		}
+		comp.calculateSavings()
+		return comp
+	}
 
-		// Parse Azure data if present
-		if azureID.Valid {
-			azure.ID = int(azureID.Int64)
-			azure.Provider = azureProvider.String
-			azure.ResourceName = azureResourceName.String
-			azure.PricePerUnit = azurePrice.Float64
-			azure.Unit = azureUnit.String
-			
-			if azureResourceSpecsJSON.Valid {
-				json.Unmarshal([]byte(azureResourceSpecsJSON.String), &azure.ResourceSpecs)
-				if comp.ResourceSpecs.VCPU == nil {
-					comp.ResourceSpecs = azure.ResourceSpecs
-				}
-			}
-			comp.Azure = &azure
+	var comparisons []PricingComparison
+	matched := make(map[int]bool)
+	for i := range awsPricings {
+		aws := &awsPricings[i]
+		matches := MatchInstances(*aws, azurePricings, config)
+		if len(matches) == 0 {
+			comparisons = append(comparisons, newComparison(aws, nil, nil))
+			continue
 		}
 
-		// Calculate price difference and savings
-		if comp.AWS != nil && comp.Azure != nil {
-			priceDiff := comp.AWS.PricePerUnit - comp.Azure.PricePerUnit
-			comp.PriceDifference = &priceDiff
-			
-			if comp.AWS.PricePerUnit < comp.Azure.PricePerUnit {
-				cheaperProvider := ProviderAWS
-				comp.CheaperProvider = &cheaperProvider
-				savingsPercent := (comp.Azure.PricePerUnit - comp.AWS.PricePerUnit) / comp.Azure.PricePerUnit * 100
-				comp.SavingsPercent = &savingsPercent
-			} else if comp.Azure.PricePerUnit < comp.AWS.PricePerUnit {
-				cheaperProvider := ProviderAzure
-				comp.CheaperProvider = &cheaperProvider
-				savingsPercent := (comp.AWS.PricePerUnit - comp.Azure.PricePerUnit) / comp.AWS.PricePerUnit * 100
-				comp.SavingsPercent = &savingsPercent
-			}
+		for _, match := range matches {
+			azure := match.Pricing
+			score := match.Score
+			matched[azure.ID] = true
+			comparisons = append(comparisons, newComparison(aws, &azure, &score))
 		}
+	}
 
-		comparisons = append(comparisons, comp)
+	for i := range azurePricings {
+		if !matched[azurePricings[i].ID] {
+			comparisons = append(comparisons, newComparison(nil, &azurePricings[i], nil))
+		}
 	}
 
-	return comparisons, nil
-}
\ No newline at end of file
+	return comparisons
+}
+
+// calculateSavings sets the price difference and savings of a matched pair on effective
+// rates, so upfront commitments compare fairly with hourly ones
+func (comp *PricingComparison) calculateSavings() {
+	if comp.AWS == nil || comp.Azure == nil {
+		return
+	}
+
+	awsRate := comp.AWS.GetHourlyPrice()
+	azureRate := comp.Azure.GetHourlyPrice()
+	priceDiff := awsRate - azureRate
+	comp.PriceDifference = &priceDiff
+
+	if awsRate < azureRate {
+		cheaperProvider := ProviderAWS
+		comp.CheaperProvider = &cheaperProvider
+		savingsPercent := (azureRate - awsRate) / azureRate * 100
+		comp.SavingsPercent = &savingsPercent
+	} else if azureRate < awsRate {
+		cheaperProvider := ProviderAzure
+		comp.CheaperProvider = &cheaperProvider
+		savingsPercent := (awsRate - azureRate) / awsRate * 100
+		comp.SavingsPercent = &savingsPercent
+	}
+}
@@ -0,0 +1,457 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Directions of price changes an alert rule fires on
+const (
+	AlertDirectionAny      = "any"
+	AlertDirectionIncrease = "increase"
+	AlertDirectionDecrease = "decrease"
+)
+
+// Statuses of a webhook delivery
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed"
+)
+
+// AlertWebhook is an HTTP endpoint price alerts are posted to. Payloads are signed with the
+// secret, which is never returned by queries.
+type AlertWebhook struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// AlertRuleFilter selects the normalized prices an alert rule watches. Unset fields match
+// any price.
+type AlertRuleFilter struct {
+	Provider         *string `json:"provider,omitempty"`
+	ServiceCategory  *string `json:"serviceCategory,omitempty"`
+	ServiceType      *string `json:"serviceType,omitempty"`
+	ResourceName     *string `json:"resourceName,omitempty"`
+	ProviderSKU      *string `json:"providerSku,omitempty"`
+	NormalizedRegion *string `json:"normalizedRegion,omitempty"`
+	PricingModel     *string `json:"pricingModel,omitempty"`
+	OperatingSystem  *string `json:"operatingSystem,omitempty"`
+}
+
+// AlertRule fires when a watched price changes by at least ThresholdPercent in the rule's
+// direction
+type AlertRule struct {
+	ID               int             `json:"id"`
+	Name             string          `json:"name"`
+	WebhookID        int             `json:"webhookId"`
+	Filter           AlertRuleFilter `json:"filter"`
+	ThresholdPercent float64         `json:"thresholdPercent"`
+	Direction        string          `json:"direction"`
+	Enabled          bool            `json:"enabled"`
+	CreatedAt        time.Time       `json:"createdAt"`
+	UpdatedAt        time.Time       `json:"updatedAt"`
+}
+
+// Validate checks the threshold and direction of a rule
+func (r AlertRule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("alert rule name is required")
+	}
+	if r.ThresholdPercent < 0 {
+		return fmt.Errorf("threshold must not be negative, got %g", r.ThresholdPercent)
+	}
+	switch r.Direction {
+	case AlertDirectionAny, AlertDirectionIncrease, AlertDirectionDecrease:
+	default:
+		return fmt.Errorf("unsupported direction: %s", r.Direction)
+	}
+	return nil
+}
+
+// AlertMatch is a price change that triggered an alert rule
+type AlertMatch struct {
+	NaturalKey       string    `json:"naturalKey"`
+	Provider         string    `json:"provider"`
+	ProviderSKU      *string   `json:"providerSku,omitempty"`
+	ServiceType      string    `json:"serviceType"`
+	ResourceName     string    `json:"resourceName"`
+	NormalizedRegion string    `json:"normalizedRegion"`
+	PricingModel     string    `json:"pricingModel"`
+	Unit             string    `json:"unit"`
+	Currency         string    `json:"currency"`
+	OldPrice         float64   `json:"oldPrice"`
+	NewPrice         float64   `json:"newPrice"`
+	ChangePercent    float64   `json:"changePercent"`
+	ChangedAt        time.Time `json:"changedAt"`
+}
+
+// AlertDelivery is an entry of the webhook delivery log
+type AlertDelivery struct {
+	ID             int             `json:"id"`
+	RuleID         int             `json:"ruleId"`
+	WebhookID      int             `json:"webhookId"`
+	ETLJobID       string          `json:"etlJobId,omitempty"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus *int            `json:"responseStatus,omitempty"`
+	Error          *string         `json:"error,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+// CreateAlertWebhook stores a webhook and sets its ID and timestamps
+func (db *DB) CreateAlertWebhook(ctx context.Context, webhook *AlertWebhook) error {
+	err := db.conn.QueryRowContext(ctx, `
+		INSERT INTO alert_webhooks (name, url, secret, enabled)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at`,
+		webhook.Name, webhook.URL, webhook.Secret, webhook.Enabled,
+	).Scan(&webhook.ID, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create alert webhook: %w", err)
+	}
+	return nil
+}
+
+// DeleteAlertWebhook deletes a webhook with its rules and delivery log. It reports whether
+// the webhook existed.
+func (db *DB) DeleteAlertWebhook(ctx context.Context, id int) (bool, error) {
+	result, err := db.conn.ExecContext(ctx, `DELETE FROM alert_webhooks WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete alert webhook: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete alert webhook: %w", err)
+	}
+	return deleted > 0, nil
+}
+
+// GetAlertWebhooks returns all webhooks, including their secrets
+func (db *DB) GetAlertWebhooks(ctx context.Context) ([]AlertWebhook, error) {
+	rows, err := db.conn.QueryContext(ctx, `
+		SELECT id, name, url, secret, enabled, created_at, updated_at
+		FROM alert_webhooks
+		ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []AlertWebhook
+	for rows.Next() {
+		var webhook AlertWebhook
+		err := rows.Scan(&webhook.ID, &webhook.Name, &webhook.URL, &webhook.Secret,
+			&webhook.Enabled, &webhook.CreatedAt, &webhook.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read alert webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+const alertRuleColumns = `id, name, webhook_id, provider, service_category, service_type, resource_name,
+	provider_sku, normalized_region, pricing_model, operating_system, threshold_percent, direction,
+	enabled, created_at, updated_at`
+
+func scanAlertRule(scanner interface{ Scan(...interface{}) error }) (AlertRule, error) {
+	var rule AlertRule
+	err := scanner.Scan(&rule.ID, &rule.Name, &rule.WebhookID, &rule.Filter.Provider,
+		&rule.Filter.ServiceCategory, &rule.Filter.ServiceType, &rule.Filter.ResourceName,
+		&rule.Filter.ProviderSKU, &rule.Filter.NormalizedRegion, &rule.Filter.PricingModel,
+		&rule.Filter.OperatingSystem, &rule.ThresholdPercent, &rule.Direction, &rule.Enabled,
+		&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return rule, fmt.Errorf("failed to scan alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+// CreateAlertRule stores an alert rule and sets its ID and timestamps
+func (db *DB) CreateAlertRule(ctx context.Context, rule *AlertRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	err := db.conn.QueryRowContext(ctx, `
+		INSERT INTO alert_rules (
+			name, webhook_id, provider, service_category, service_type, resource_name, provider_sku,
+			normalized_region, pricing_model, operating_system, threshold_percent, direction, enabled
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at, updated_at`,
+		rule.Name, rule.WebhookID, rule.Filter.Provider, rule.Filter.ServiceCategory,
+		rule.Filter.ServiceType, rule.Filter.ResourceName, rule.Filter.ProviderSKU,
+		rule.Filter.NormalizedRegion, rule.Filter.PricingModel, rule.Filter.OperatingSystem,
+		rule.ThresholdPercent, rule.Direction, rule.Enabled,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create alert rule: %w", err)
+	}
+	return nil
+}
+
+// UpdateAlertRule replaces the settings of an alert rule. It reports whether the rule existed.
+func (db *DB) UpdateAlertRule(ctx context.Context, rule *AlertRule) (bool, error) {
+	if err := rule.Validate(); err != nil {
+		return false, err
+	}
+
+	err := db.conn.QueryRowContext(ctx, `
+		UPDATE alert_rules SET
+			name = $2, webhook_id = $3, provider = $4, service_category = $5, service_type = $6,
+			resource_name = $7, provider_sku = $8, normalized_region = $9, pricing_model = $10,
+			operating_system = $11, threshold_percent = $12, direction = $13, enabled = $14,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING created_at, updated_at`,
+		rule.ID, rule.Name, rule.WebhookID, rule.Filter.Provider, rule.Filter.ServiceCategory,
+		rule.Filter.ServiceType, rule.Filter.ResourceName, rule.Filter.ProviderSKU,
+		rule.Filter.NormalizedRegion, rule.Filter.PricingModel, rule.Filter.OperatingSystem,
+		rule.ThresholdPercent, rule.Direction, rule.Enabled,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	return true, nil
+}
+
+// DeleteAlertRule deletes an alert rule with its delivery log. It reports whether the rule
+// existed.
+func (db *DB) DeleteAlertRule(ctx context.Context, id int) (bool, error) {
+	result, err := db.conn.ExecContext(ctx, `DELETE FROM alert_rules WHERE id = $1`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	return deleted > 0, nil
+}
+
+// GetAlertRule returns an alert rule, or nil if it does not exist
+func (db *DB) GetAlertRule(ctx context.Context, id int) (*AlertRule, error) {
+	row := db.conn.QueryRowContext(ctx, fmt.Sprintf(`SELECT %s FROM alert_rules WHERE id = $1`, alertRuleColumns), id)
+	rule, err := scanAlertRule(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetAlertRules returns all alert rules, or only the enabled ones
+func (db *DB) GetAlertRules(ctx context.Context, enabledOnly bool) ([]AlertRule, error) {
+	query := fmt.Sprintf(`SELECT %s FROM alert_rules`, alertRuleColumns)
+	if enabledOnly {
+		query += ` WHERE enabled`
+	}
+	query += ` ORDER BY id`
+
+	rows, err := db.conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []AlertRule
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read alert rules: %w", err)
+	}
+	return rules, nil
+}
+
+// FindAlertMatches returns the price changes recorded in the price history by an ETL job that
+// trigger a rule: versions the job opened whose previous version had a price differing by at
+// least the rule's threshold. A price has a version per effective date, so the previous version
+// is the latest closed one with the same effective date, or the latest closed one of any
+// effective date when the effective date is new. Each opened version matches at most once.
+func (db *DB) FindAlertMatches(ctx context.Context, rule AlertRule, jobID string) ([]AlertMatch, error) {
+	query := `
+		SELECT cur.natural_key, cur.provider, cur.provider_sku, cur.service_type, cur.resource_name,
+		       cur.normalized_region, cur.pricing_model, cur.unit, cur.currency,
+		       prev.price_per_unit, cur.price_per_unit,
+		       (cur.price_per_unit - prev.price_per_unit) / prev.price_per_unit * 100 AS change_percent,
+		       cur.valid_from
+		FROM normalized_pricing_history cur
+		JOIN LATERAL (
+			SELECT h.price_per_unit
+			FROM normalized_pricing_history h
+			WHERE h.natural_key = cur.natural_key AND h.valid_to <= cur.valid_from
+			ORDER BY h.effective_date IS NOT DISTINCT FROM cur.effective_date DESC,
+			         h.valid_to DESC, h.effective_date DESC NULLS LAST, h.history_id DESC
+			LIMIT 1
+		) prev ON true
+		WHERE cur.etl_job_id = $1
+		  AND prev.price_per_unit > 0
+		  AND cur.price_per_unit <> prev.price_per_unit
+		  AND ABS(cur.price_per_unit - prev.price_per_unit) / prev.price_per_unit * 100 >= $2`
+	args := []interface{}{jobID, rule.ThresholdPercent}
+
+	switch rule.Direction {
+	case AlertDirectionIncrease:
+		query += " AND cur.price_per_unit > prev.price_per_unit"
+	case AlertDirectionDecrease:
+		query += " AND cur.price_per_unit < prev.price_per_unit"
+	}
+
+	addFilter := func(column string, value *string) {
+		if value != nil {
+			args = append(args, *value)
+			query += fmt.Sprintf(" AND cur.%s = $%d", column, len(args))
+		}
+	}
+	addFilter("provider", rule.Filter.Provider)
+	addFilter("service_category", rule.Filter.ServiceCategory)
+	addFilter("service_type", rule.Filter.ServiceType)
+	addFilter("resource_name", rule.Filter.ResourceName)
+	addFilter("provider_sku", rule.Filter.ProviderSKU)
+	addFilter("normalized_region", rule.Filter.NormalizedRegion)
+	addFilter("pricing_model", rule.Filter.PricingModel)
+	addFilter("operating_system", rule.Filter.OperatingSystem)
+
+	query += " ORDER BY ABS(cur.price_per_unit - prev.price_per_unit) / prev.price_per_unit DESC, cur.natural_key"
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []AlertMatch
+	for rows.Next() {
+		var match AlertMatch
+		var providerSKU sql.NullString
+		err := rows.Scan(&match.NaturalKey, &match.Provider, &providerSKU, &match.ServiceType,
+			&match.ResourceName, &match.NormalizedRegion, &match.PricingModel, &match.Unit,
+			&match.Currency, &match.OldPrice, &match.NewPrice, &match.ChangePercent, &match.ChangedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert match: %w", err)
+		}
+		if providerSKU.Valid {
+			match.ProviderSKU = &providerSKU.String
+		}
+		matches = append(matches, match)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read alert matches: %w", err)
+	}
+	return matches, nil
+}
+
+// CreateAlertDelivery logs a pending delivery and sets its ID and creation time
+func (db *DB) CreateAlertDelivery(ctx context.Context, delivery *AlertDelivery) error {
+	err := db.conn.QueryRowContext(ctx, `
+		INSERT INTO alert_deliveries (rule_id, webhook_id, etl_job_id, payload, status, attempts)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6)
+		RETURNING id, created_at`,
+		delivery.RuleID, delivery.WebhookID, delivery.ETLJobID, []byte(delivery.Payload),
+		delivery.Status, delivery.Attempts,
+	).Scan(&delivery.ID, &delivery.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create alert delivery: %w", err)
+	}
+	return nil
+}
+
+// UpdateAlertDelivery records the payload and the outcome of the latest attempt of a delivery
+func (db *DB) UpdateAlertDelivery(ctx context.Context, delivery *AlertDelivery) error {
+	_, err := db.conn.ExecContext(ctx, `
+		UPDATE alert_deliveries
+		SET status = $2, attempts = $3, response_status = $4, error = $5, delivered_at = $6, payload = $7
+		WHERE id = $1`,
+		delivery.ID, delivery.Status, delivery.Attempts, delivery.ResponseStatus, delivery.Error,
+		delivery.DeliveredAt, []byte(delivery.Payload))
+	if err != nil {
+		return fmt.Errorf("failed to update alert delivery: %w", err)
+	}
+	return nil
+}
+
+// GetAlertDeliveries returns the delivery log, newest first, optionally of one rule
+func (db *DB) GetAlertDeliveries(ctx context.Context, ruleID *int, limit int) ([]AlertDelivery, error) {
+	query := `
+		SELECT id, rule_id, webhook_id, COALESCE(etl_job_id, ''), payload, status, attempts,
+		       response_status, error, created_at, delivered_at
+		FROM alert_deliveries`
+	args := []interface{}{}
+	if ruleID != nil {
+		args = append(args, *ruleID)
+		query += " WHERE rule_id = $1"
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+	if limit > 0 {
+		args = append(args, limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []AlertDelivery
+	for rows.Next() {
+		var delivery AlertDelivery
+		var payload []byte
+		var responseStatus sql.NullInt64
+		var deliveryError sql.NullString
+		var deliveredAt sql.NullTime
+		err := rows.Scan(&delivery.ID, &delivery.RuleID, &delivery.WebhookID, &delivery.ETLJobID,
+			&payload, &delivery.Status, &delivery.Attempts, &responseStatus, &deliveryError,
+			&delivery.CreatedAt, &deliveredAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert delivery: %w", err)
+		}
+
+		delivery.Payload = payload
+		if responseStatus.Valid {
+			status := int(responseStatus.Int64)
+			delivery.ResponseStatus = &status
+		}
+		if deliveryError.Valid {
+			delivery.Error = &deliveryError.String
+		}
+		if deliveredAt.Valid {
+			delivery.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read alert deliveries: %w", err)
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindAlertMatches_PairsVersionsByEffectiveDate(t *testing.T) {
+	conn := openTestDatabase(t)
+	ctx := context.Background()
+
+	db := New(conn)
+	_, err := db.MigrateUp(ctx)
+	require.NoError(t, err)
+
+	// t4g.nano has a current and an announced price, t4g.micro one price
+	insert := func(sku, resourceName string, price float64, effectiveDate string) {
+		_, err := conn.Exec(`
+			INSERT INTO normalized_pricing (
+				provider, provider_service_code, provider_sku, service_category, service_family, service_type,
+				normalized_region, provider_region, resource_name, price_per_unit, unit, pricing_model, effective_date
+			) VALUES (
+				'aws', 'AmazonEC2', $1, 'Compute & Web', 'Virtual Machines', 'Virtual Machines',
+				'us-east', 'us-east-1', $2, $3, 'hour', 'on_demand', $4
+			)`, sku, resourceName, price, effectiveDate)
+		require.NoError(t, err)
+	}
+	insert("SKU1", "t4g.nano", 1.0, "2026-01-01")
+	insert("SKU1", "t4g.nano", 2.0, "2026-12-01")
+	insert("SKU2", "t4g.micro", 1.0, "2026-01-01")
+
+	firstSync := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, err = db.SyncNormalizedPricingHistory(ctx, "job-1", firstSync)
+	require.NoError(t, err)
+
+	// Both t4g.nano versions change, and t4g.micro is re-dated with a new price
+	_, err = conn.Exec(`UPDATE normalized_pricing SET price_per_unit = 1.1 WHERE provider_sku = 'SKU1' AND effective_date = '2026-01-01'`)
+	require.NoError(t, err)
+	_, err = conn.Exec(`UPDATE normalized_pricing SET price_per_unit = 2.4 WHERE provider_sku = 'SKU1' AND effective_date = '2026-12-01'`)
+	require.NoError(t, err)
+	_, err = conn.Exec(`UPDATE normalized_pricing SET price_per_unit = 1.5, effective_date = '2026-06-01' WHERE provider_sku = 'SKU2'`)
+	require.NoError(t, err)
+	_, err = db.SyncNormalizedPricingHistory(ctx, "job-2", firstSync.Add(time.Hour))
+	require.NoError(t, err)
+
+	matches, err := db.FindAlertMatches(ctx, AlertRule{ThresholdPercent: 5, Direction: AlertDirectionAny}, "job-2")
+	require.NoError(t, err)
+
+	type change struct {
+		resourceName string
+		oldPrice     float64
+		newPrice     float64
+	}
+	changes := make([]change, len(matches))
+	for i, match := range matches {
+		changes[i] = change{match.ResourceName, match.OldPrice, match.NewPrice}
+	}
+	assert.Equal(t, []change{
+		{"t4g.micro", 1.0, 1.5},
+		{"t4g.nano", 2.0, 2.4},
+		{"t4g.nano", 1.0, 1.1},
+	}, changes)
+}
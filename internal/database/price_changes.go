@@ -0,0 +1,443 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Kinds of price changes between two collections
+const (
+	PriceChangeNew      = "new"
+	PriceChangeRetired  = "retired"
+	PriceChangeIncrease = "increase"
+	PriceChangeDecrease = "decrease"
+)
+
+// PriceChange is a difference of one price between a collection and the previous collection of
+// the same scope. Prices are keyed by meterId (plus type, reservation term and tier) for Azure
+// and by rate code, which combines SKU, offer term and price dimension, for AWS.
+type PriceChange struct {
+	ID                   int       `json:"id"`
+	Provider             string    `json:"provider"`
+	CollectionID         string    `json:"collectionId"`
+	PreviousCollectionID string    `json:"previousCollectionId"`
+	PriceKey             string    `json:"priceKey"`
+	Region               string    `json:"region"`
+	ServiceName          string    `json:"serviceName"`
+	Description          string    `json:"description"`
+	ChangeType           string    `json:"changeType"`
+	OldPrice             *float64  `json:"oldPrice,omitempty"`
+	NewPrice             *float64  `json:"newPrice,omitempty"`
+	ChangePercent        *float64  `json:"changePercent,omitempty"`
+	Currency             string    `json:"currency"`
+	Unit                 string    `json:"unit"`
+	DetectedAt           time.Time `json:"detectedAt"`
+}
+
+// PriceChangeFilter selects stored price changes
+type PriceChangeFilter struct {
+	Provider     *string  `json:"provider,omitempty"`
+	CollectionID *string  `json:"collectionId,omitempty"`
+	Region       *string  `json:"region,omitempty"`
+	ServiceName  *string  `json:"serviceName,omitempty"`
+	ChangeTypes  []string `json:"changeTypes,omitempty"`
+	Limit        *int     `json:"limit,omitempty"`
+}
+
+// azureCollectionPricesQuery extracts the keyed prices of Azure collections. Retail items of a
+// meter differ by type, reservation term and tier.
+const azureCollectionPricesQuery = `
+	SELECT DISTINCT ON (collection_id, price_key)
+		collection_id,
+		concat_ws('|', data->>'meterId', COALESCE(data->>'type', ''), COALESCE(data->>'reservationTerm', ''),
+			COALESCE(data->>'tierMinimumUnits', '0')) AS price_key,
+		COALESCE(data->>'armRegionName', region) AS region,
+		COALESCE(data->>'serviceName', service_name, '') AS service_name,
+		concat_ws(' ', data->>'productName', data->>'skuName', data->>'meterName') AS description,
+		(data->>'retailPrice')::numeric AS price,
+		COALESCE(data->>'currencyCode', '') AS currency,
+		COALESCE(data->>'unitOfMeasure', '') AS unit
+	FROM azure_pricing_raw
+	WHERE collection_id IN ($1, $2) AND data ? 'meterId' AND data ? 'retailPrice'
+	ORDER BY collection_id, price_key, id DESC`
+
+// awsCollectionPricesQuery extracts the keyed prices of AWS collections, one per price
+// dimension of every term. The collector stores a product once per dimension, so products
+// are deduplicated by rate code.
+const awsCollectionPricesQuery = `
+	SELECT DISTINCT ON (r.collection_id, dim.value->>'rateCode')
+		r.collection_id,
+		dim.value->>'rateCode' AS price_key,
+		COALESCE(r.data->'product'->'attributes'->>'regionCode', r.location, '') AS region,
+		COALESCE(r.service_code, '') AS service_name,
+		concat_ws(' ', r.data->'product'->'attributes'->>'usagetype', term_type.name,
+			term.value->'termAttributes'->>'LeaseContractLength',
+			term.value->'termAttributes'->>'PurchaseOption', dim.value->>'unit') AS description,
+		COALESCE(dim.value->'pricePerUnit'->>'USD', dim.value->'pricePerUnit'->>'CNY')::numeric AS price,
+		CASE WHEN dim.value->'pricePerUnit' ? 'USD' THEN 'USD' ELSE 'CNY' END AS currency,
+		COALESCE(dim.value->>'unit', '') AS unit
+	FROM aws_pricing_raw r
+	CROSS JOIN LATERAL jsonb_each(r.data->'terms') AS term_type(name, terms)
+	CROSS JOIN LATERAL jsonb_each(term_type.terms) AS term(code, value)
+	CROSS JOIN LATERAL jsonb_each(term.value->'priceDimensions') AS dim(code, value)
+	WHERE r.collection_id IN ($1, $2)
+	  AND jsonb_typeof(r.data->'terms') = 'object'
+	  AND dim.value ? 'rateCode'
+	  AND (dim.value->'pricePerUnit' ? 'USD' OR dim.value->'pricePerUnit' ? 'CNY')
+	ORDER BY r.collection_id, dim.value->>'rateCode', r.id DESC`
+
+// PreviousCollectionID returns the latest completed collection that started before the given
+// collection and covers the same scope: the same region for Azure, the same service codes and
+// regions for AWS. It returns an empty ID when there is none.
+func (db *DB) PreviousCollectionID(ctx context.Context, provider, collectionID string) (string, error) {
+	var query string
+	switch provider {
+	case ProviderAzure:
+		query = `
+			SELECT p.collection_id
+			FROM azure_collections c
+			JOIN azure_collections p ON p.region = c.region AND p.status = 'completed' AND p.started_at < c.started_at
+			WHERE c.collection_id = $1
+			ORDER BY p.started_at DESC
+			LIMIT 1`
+	case ProviderAWS:
+		query = `
+			SELECT p.collection_id
+			FROM aws_collections c
+			JOIN aws_collections p ON p.status = 'completed' AND p.started_at < c.started_at
+				AND (SELECT array_agg(s ORDER BY s) FROM unnest(p.service_codes) s) IS NOT DISTINCT FROM
+					(SELECT array_agg(s ORDER BY s) FROM unnest(c.service_codes) s)
+				AND (SELECT array_agg(r ORDER BY r) FROM unnest(p.regions) r) IS NOT DISTINCT FROM
+					(SELECT array_agg(r ORDER BY r) FROM unnest(c.regions) r)
+			WHERE c.collection_id = $1
+			ORDER BY p.started_at DESC
+			LIMIT 1`
+	default:
+		return "", fmt.Errorf("unsupported provider: %s", provider)
+	}
+
+	var previousID string
+	err := db.conn.QueryRowContext(ctx, query, collectionID).Scan(&previousID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up previous collection: %w", err)
+	}
+	return previousID, nil
+}
+
+// LatestCollectionID returns the latest completed collection of a provider, optionally of an
+// Azure region. It returns an empty ID when there is none.
+func (db *DB) LatestCollectionID(ctx context.Context, provider string, region *string) (string, error) {
+	var query string
+	args := []interface{}{}
+	switch provider {
+	case ProviderAzure:
+		query = `SELECT collection_id FROM azure_collections WHERE status = 'completed'`
+		if region != nil {
+			query += ` AND region = $1`
+			args = append(args, *region)
+		}
+	case ProviderAWS:
+		query = `SELECT collection_id FROM aws_collections WHERE status = 'completed'`
+		if region != nil {
+			query += ` AND $1 = ANY(regions)`
+			args = append(args, *region)
+		}
+	default:
+		return "", fmt.Errorf("unsupported provider: %s", provider)
+	}
+	query += ` ORDER BY started_at DESC LIMIT 1`
+
+	var collectionID string
+	err := db.conn.QueryRowContext(ctx, query, args...).Scan(&collectionID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up latest collection: %w", err)
+	}
+	return collectionID, nil
+}
+
+// DiffCollections compares the raw prices of a collection with those of the previous
+// collection and returns the new, retired and changed prices. Unchanged prices are omitted.
+func (db *DB) DiffCollections(ctx context.Context, provider, previousID, collectionID string) ([]PriceChange, error) {
+	var pricesQuery string
+	switch provider {
+	case ProviderAzure:
+		pricesQuery = azureCollectionPricesQuery
+	case ProviderAWS:
+		pricesQuery = awsCollectionPricesQuery
+	default:
+		return nil, fmt.Errorf("unsupported provider: %s", provider)
+	}
+
+	query := fmt.Sprintf(`
+		WITH prices AS (%s)
+		SELECT
+			COALESCE(cur.price_key, prev.price_key),
+			COALESCE(cur.region, prev.region),
+			COALESCE(cur.service_name, prev.service_name),
+			COALESCE(cur.description, prev.description),
+			prev.price,
+			cur.price,
+			COALESCE(cur.currency, prev.currency),
+			COALESCE(cur.unit, prev.unit)
+		FROM (SELECT * FROM prices WHERE collection_id = $2) cur
+		FULL OUTER JOIN (SELECT * FROM prices WHERE collection_id = $1) prev ON prev.price_key = cur.price_key
+		WHERE prev.price_key IS NULL OR cur.price_key IS NULL OR prev.price <> cur.price
+		ORDER BY 3, 2, 1`, pricesQuery)
+
+	rows, err := db.conn.QueryContext(ctx, query, previousID, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff collections: %w", err)
+	}
+	defer rows.Close()
+
+	detectedAt := time.Now()
+	var changes []PriceChange
+	for rows.Next() {
+		change := PriceChange{
+			Provider:             provider,
+			CollectionID:         collectionID,
+			PreviousCollectionID: previousID,
+			DetectedAt:           detectedAt,
+		}
+		var oldPrice, newPrice sql.NullFloat64
+		err := rows.Scan(&change.PriceKey, &change.Region, &change.ServiceName, &change.Description,
+			&oldPrice, &newPrice, &change.Currency, &change.Unit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan price change: %w", err)
+		}
+
+		if oldPrice.Valid {
+			change.OldPrice = &oldPrice.Float64
+		}
+		if newPrice.Valid {
+			change.NewPrice = &newPrice.Float64
+		}
+		change.classify()
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read price changes: %w", err)
+	}
+	return changes, nil
+}
+
+// classify sets the change type and percentage from the old and new prices
+func (c *PriceChange) classify() {
+	switch {
+	case c.OldPrice == nil:
+		c.ChangeType = PriceChangeNew
+	case c.NewPrice == nil:
+		c.ChangeType = PriceChangeRetired
+	case *c.NewPrice > *c.OldPrice:
+		c.ChangeType = PriceChangeIncrease
+	default:
+		c.ChangeType = PriceChangeDecrease
+	}
+
+	if c.OldPrice != nil && c.NewPrice != nil && *c.OldPrice != 0 {
+		percent := (*c.NewPrice - *c.OldPrice) / *c.OldPrice * 100
+		c.ChangePercent = &percent
+	}
+}
+
+// UndiffedCollectionIDs returns the latest completed collections of a provider that have a
+// previous collection of the same scope but were never diffed against it, newest first
+func (db *DB) UndiffedCollectionIDs(ctx context.Context, provider string, limit int) ([]string, error) {
+	var query string
+	switch provider {
+	case ProviderAzure:
+		query = `
+			SELECT c.collection_id
+			FROM azure_collections c
+			WHERE c.status = 'completed'
+			  AND NOT EXISTS (SELECT 1 FROM price_diffs d WHERE d.provider = $1 AND d.collection_id = c.collection_id)
+			  AND EXISTS (
+				SELECT 1 FROM azure_collections p
+				WHERE p.region = c.region AND p.status = 'completed' AND p.started_at < c.started_at
+			  )
+			ORDER BY c.started_at DESC
+			LIMIT $2`
+	case ProviderAWS:
+		query = `
+			SELECT c.collection_id
+			FROM aws_collections c
+			WHERE c.status = 'completed'
+			  AND NOT EXISTS (SELECT 1 FROM price_diffs d WHERE d.provider = $1 AND d.collection_id = c.collection_id)
+			  AND EXISTS (
+				SELECT 1 FROM aws_collections p
+				WHERE p.status = 'completed' AND p.started_at < c.started_at
+				  AND (SELECT array_agg(s ORDER BY s) FROM unnest(p.service_codes) s) IS NOT DISTINCT FROM
+					(SELECT array_agg(s ORDER BY s) FROM unnest(c.service_codes) s)
+				  AND (SELECT array_agg(r ORDER BY r) FROM unnest(p.regions) r) IS NOT DISTINCT FROM
+					(SELECT array_agg(r ORDER BY r) FROM unnest(c.regions) r)
+			  )
+			ORDER BY c.started_at DESC
+			LIMIT $2`
+	default:
+		return nil, fmt.Errorf("unsupported provider: %s", provider)
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query, provider, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query undiffed collections: %w", err)
+	}
+	defer rows.Close()
+
+	var collectionIDs []string
+	for rows.Next() {
+		var collectionID string
+		if err := rows.Scan(&collectionID); err != nil {
+			return nil, fmt.Errorf("failed to scan collection ID: %w", err)
+		}
+		collectionIDs = append(collectionIDs, collectionID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read undiffed collections: %w", err)
+	}
+	return collectionIDs, nil
+}
+
+// SavePriceChanges replaces the stored changes of a collection with the given changes, so a
+// diff can be re-run, and records that the collection was diffed against previousID
+func (db *DB) SavePriceChanges(ctx context.Context, provider, collectionID, previousID string, changes []PriceChange) error {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM price_changes WHERE provider = $1 AND collection_id = $2`, provider, collectionID)
+	if err != nil {
+		return fmt.Errorf("failed to clear price changes: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO price_changes (
+			provider, collection_id, previous_collection_id, price_key, region, service_name,
+			description, change_type, old_price, new_price, change_percent, currency, unit, detected_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, change := range changes {
+		_, err := stmt.ExecContext(ctx,
+			change.Provider, change.CollectionID, change.PreviousCollectionID, change.PriceKey,
+			change.Region, change.ServiceName, change.Description, change.ChangeType,
+			change.OldPrice, change.NewPrice, change.ChangePercent, change.Currency, change.Unit,
+			change.DetectedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert price change: %w", err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO price_diffs (provider, collection_id, previous_collection_id, change_count, diffed_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		ON CONFLICT (provider, collection_id) DO UPDATE SET
+			previous_collection_id = EXCLUDED.previous_collection_id,
+			change_count = EXCLUDED.change_count,
+			diffed_at = EXCLUDED.diffed_at`,
+		provider, collectionID, previousID, len(changes))
+	if err != nil {
+		return fmt.Errorf("failed to record price diff: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetPriceChanges returns stored price changes, largest relative changes first
+func (db *DB) GetPriceChanges(ctx context.Context, filter PriceChangeFilter) ([]PriceChange, error) {
+	query := `
+		SELECT id, provider, collection_id, previous_collection_id, price_key, region, service_name,
+		       description, change_type, old_price, new_price, change_percent, currency, unit, detected_at
+		FROM price_changes WHERE 1=1`
+	args := []interface{}{}
+
+	addFilter := func(column string, value *string) {
+		if value != nil {
+			args = append(args, *value)
+			query += fmt.Sprintf(" AND %s = $%d", column, len(args))
+		}
+	}
+	addFilter("provider", filter.Provider)
+	addFilter("collection_id", filter.CollectionID)
+	addFilter("region", filter.Region)
+	addFilter("service_name", filter.ServiceName)
+
+	if len(filter.ChangeTypes) > 0 {
+		args = append(args, pq.Array(filter.ChangeTypes))
+		query += fmt.Sprintf(" AND change_type = ANY($%d)", len(args))
+	}
+
+	query += " ORDER BY detected_at DESC, ABS(change_percent) DESC NULLS LAST, service_name, price_key"
+
+	if filter.Limit != nil {
+		args = append(args, *filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []PriceChange
+	for rows.Next() {
+		var change PriceChange
+		var oldPrice, newPrice, changePercent sql.NullFloat64
+		err := rows.Scan(&change.ID, &change.Provider, &change.CollectionID, &change.PreviousCollectionID,
+			&change.PriceKey, &change.Region, &change.ServiceName, &change.Description, &change.ChangeType,
+			&oldPrice, &newPrice, &changePercent, &change.Currency, &change.Unit, &change.DetectedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan price change: %w", err)
+		}
+
+		if oldPrice.Valid {
+			change.OldPrice = &oldPrice.Float64
+		}
+		if newPrice.Valid {
+			change.NewPrice = &newPrice.Float64
+		}
+		if changePercent.Valid {
+			change.ChangePercent = &changePercent.Float64
+		}
+		changes = append(changes, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read price changes: %w", err)
+	}
+	return changes, nil
+}
+
+// ValidatePriceChangeTypes checks that every change type is known
+func ValidatePriceChangeTypes(changeTypes []string) error {
+	for _, changeType := range changeTypes {
+		switch strings.ToLower(changeType) {
+		case PriceChangeNew, PriceChangeRetired, PriceChangeIncrease, PriceChangeDecrease:
+		default:
+			return fmt.Errorf("unsupported change type: %s", changeType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// NormalizedPricingHistoryTable keeps every version of every normalized price
+const NormalizedPricingHistoryTable = "normalized_pricing_history"
+
+// ErrPriceHistoryUnavailable is returned by point-in-time queries for a moment before the first
+// history sync, when the prices live at that moment were never recorded
+var ErrPriceHistoryUnavailable = errors.New("no price history recorded at the requested asOf")
+
+// normalizedPricingColumns are the columns of normalized_pricing copied into the history
+const normalizedPricingColumns = `id, provider, provider_service_code, provider_sku, service_mapping_id,
+	service_category, service_family, service_type, region_id,
+	normalized_region, provider_region, resource_name, resource_description,
+	resource_specs, price_per_unit, unit, currency, pricing_model,
+	pricing_details, effective_date, expiration_date, minimum_commitment,
+	aws_raw_id, azure_raw_id, price_tiers, operating_system, license_model,
+	pre_installed_software, tenancy, original_unit, unit_multiplier,
+	created_at, updated_at`
+
+// pricingNaturalKeyExpr identifies a price across re-normalizations, which assign new ids. It
+// matches NormalizedPricing.NaturalKey. A key can have several live versions with different
+// effective dates, e.g. the current price and an announced future one.
+const pricingNaturalKeyExpr = `concat_ws('|', provider, provider_region, COALESCE(provider_sku, ''), resource_name,
+	pricing_model, unit, currency, COALESCE(operating_system, ''), COALESCE(license_model, ''),
+	COALESCE(pre_installed_software, ''), COALESCE(tenancy, ''),
+	COALESCE(pricing_details->>'term_length', ''), COALESCE(pricing_details->>'payment_option', ''))`
+
+// pricingContentHashExpr changes whenever the price of a version changes
+const pricingContentHashExpr = `md5(concat_ws('|', price_per_unit::text, COALESCE(unit_multiplier::text, ''),
+	COALESCE(price_tiers::text, ''), COALESCE(pricing_details::text, ''), COALESCE(resource_specs::text, ''),
+	COALESCE(effective_date::text, ''), COALESCE(expiration_date::text, '')))`
+
+// PriceHistorySyncResult counts the price versions opened and closed by a history sync.
+// A changed price closes its old version and opens a new one.
+type PriceHistorySyncResult struct {
+	Opened int64 `json:"opened"`
+	Closed int64 `json:"closed"`
+}
+
+// PriceHistoryEntry is one version of a price with the period it was live
+type PriceHistoryEntry struct {
+	NormalizedPricing
+	NaturalKey string     `json:"naturalKey"`
+	ValidFrom  time.Time  `json:"validFrom"`
+	ValidTo    *time.Time `json:"validTo,omitempty"`
+}
+
+// SyncNormalizedPricingHistory records the live normalized prices in the price history as a
+// slowly changing dimension. A version is identified by its natural key and effective date.
+// Versions whose price changed or disappeared are closed at observedAt, and new or changed
+// prices open a version valid from observedAt.
+func (db *DB) SyncNormalizedPricingHistory(ctx context.Context, jobID string, observedAt time.Time) (*PriceHistorySyncResult, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Serialize syncs, so two jobs finishing together cannot open the same version twice
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE normalized_pricing_history IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return nil, fmt.Errorf("failed to lock price history: %w", err)
+	}
+
+	snapshot := fmt.Sprintf(`
+		CREATE TEMP TABLE pricing_history_snapshot ON COMMIT DROP AS
+		SELECT DISTINCT ON (natural_key, effective_date) *
+		FROM (
+			SELECT %s, %s AS natural_key, %s AS content_hash
+			FROM normalized_pricing
+		) live
+		ORDER BY natural_key, effective_date, id`, normalizedPricingColumns, pricingNaturalKeyExpr, pricingContentHashExpr)
+	if _, err := tx.ExecContext(ctx, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to snapshot normalized pricing: %w", err)
+	}
+
+	result := &PriceHistorySyncResult{}
+
+	closed, err := tx.ExecContext(ctx, `
+		UPDATE normalized_pricing_history h
+		SET valid_to = $1
+		WHERE h.valid_to IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM pricing_history_snapshot s
+			WHERE s.natural_key = h.natural_key
+			  AND s.effective_date IS NOT DISTINCT FROM h.effective_date
+			  AND s.content_hash = h.content_hash
+		  )`, observedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close price history versions: %w", err)
+	}
+	if result.Closed, err = closed.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to count closed price history versions: %w", err)
+	}
+
+	opened, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		INSERT INTO normalized_pricing_history (%[1]s, natural_key, content_hash, valid_from, etl_job_id)
+		SELECT %[1]s, natural_key, content_hash, $1, NULLIF($2, '')
+		FROM pricing_history_snapshot s
+		WHERE NOT EXISTS (
+			SELECT 1 FROM normalized_pricing_history h
+			WHERE h.natural_key = s.natural_key
+			  AND h.effective_date IS NOT DISTINCT FROM s.effective_date
+			  AND h.valid_to IS NULL
+		)`, normalizedPricingColumns), observedAt, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open price history versions: %w", err)
+	}
+	if result.Opened, err = opened.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to count opened price history versions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("📜 Price history synced: %d versions opened, %d closed", result.Opened, result.Closed)
+	return result, nil
+}
+
+// checkPriceHistoryCovers returns ErrPriceHistoryUnavailable when asOf is before the first
+// history sync
+func (db *DB) checkPriceHistoryCovers(asOf time.Time) error {
+	var firstSync sql.NullTime
+	err := db.conn.QueryRow(`SELECT MIN(valid_from) FROM normalized_pricing_history`).Scan(&firstSync)
+	if err != nil {
+		return fmt.Errorf("failed to query price history start: %w", err)
+	}
+
+	if !firstSync.Valid {
+		return fmt.Errorf("%w: the price history is empty", ErrPriceHistoryUnavailable)
+	}
+	if asOf.Before(firstSync.Time) {
+		return fmt.Errorf("%w: the price history starts at %s", ErrPriceHistoryUnavailable, firstSync.Time.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// GetPriceTimeline returns every version of the prices of a provider SKU, optionally limited
+// to one normalized region, ordered by price and then by the time each version became live
+func (db *DB) GetPriceTimeline(ctx context.Context, provider, sku string, normalizedRegion *string) ([]PriceHistoryEntry, error) {
+	query := fmt.Sprintf(`
+		SELECT %s, natural_key, valid_from, valid_to
+		FROM normalized_pricing_history
+		WHERE provider = $1 AND provider_sku = $2
+		  AND ($3::text IS NULL OR normalized_region = $3)
+		ORDER BY natural_key, valid_from`, normalizedPricingSelectColumns)
+
+	var region sql.NullString
+	if normalizedRegion != nil {
+		region = sql.NullString{String: *normalizedRegion, Valid: true}
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query, provider, sku, region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price timeline: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []PriceHistoryEntry
+	for rows.Next() {
+		var entry PriceHistoryEntry
+		var validTo sql.NullTime
+		entry.NormalizedPricing, err = scanNormalizedPricing(rows, &entry.NaturalKey, &entry.ValidFrom, &validTo)
+		if err != nil {
+			return nil, err
+		}
+		if validTo.Valid {
+			entry.ValidTo = &validTo.Time
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read price timeline: %w", err)
+	}
+	return entries, nil
+}
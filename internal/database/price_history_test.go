@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncNormalizedPricingHistory_KeepsEveryLiveVersion(t *testing.T) {
+	conn := openTestDatabase(t)
+	ctx := context.Background()
+
+	db := New(conn)
+	_, err := db.MigrateUp(ctx)
+	require.NoError(t, err)
+
+	// The current price and an announced future one of the same instance
+	for _, version := range []struct {
+		price         float64
+		effectiveDate string
+	}{
+		{0.0042, "2026-01-01"},
+		{0.0038, "2026-12-01"},
+	} {
+		_, err := conn.Exec(`
+			INSERT INTO normalized_pricing (
+				provider, provider_service_code, provider_sku, service_category, service_family, service_type,
+				normalized_region, provider_region, resource_name, price_per_unit, unit, pricing_model, effective_date
+			) VALUES (
+				'aws', 'AmazonEC2', 'SKU1', 'Compute & Web', 'Virtual Machines', 'Virtual Machines',
+				'us-east', 'us-east-1', 't4g.nano', $1, 'hour', 'on_demand', $2
+			)`, version.price, version.effectiveDate)
+		require.NoError(t, err)
+	}
+
+	firstSync := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	result, err := db.SyncNormalizedPricingHistory(ctx, "job-1", firstSync)
+	require.NoError(t, err)
+	assert.Equal(t, &PriceHistorySyncResult{Opened: 2}, result)
+
+	// Unchanged versions stay open
+	result, err = db.SyncNormalizedPricingHistory(ctx, "job-2", firstSync.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, &PriceHistorySyncResult{}, result)
+
+	// A changed version is replaced without touching the other one
+	_, err = conn.Exec(`UPDATE normalized_pricing SET price_per_unit = 0.0036 WHERE effective_date = '2026-12-01'`)
+	require.NoError(t, err)
+	result, err = db.SyncNormalizedPricingHistory(ctx, "job-3", firstSync.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, &PriceHistorySyncResult{Opened: 1, Closed: 1}, result)
+
+	asOf := firstSync.Add(3 * time.Hour)
+	pricings, err := db.QueryNormalizedPricing(PricingFilter{History: true, AsOf: &asOf})
+	require.NoError(t, err)
+	prices := make([]float64, len(pricings))
+	for i, pricing := range pricings {
+		prices[i] = pricing.PricePerUnit
+	}
+	assert.ElementsMatch(t, []float64{0.0042, 0.0036}, prices)
+
+	// Prices before the first sync were never recorded
+	before := firstSync.Add(-time.Hour)
+	_, err = db.QueryNormalizedPricing(PricingFilter{History: true, AsOf: &before})
+	assert.ErrorIs(t, err, ErrPriceHistoryUnavailable)
+}
@@ -0,0 +1,360 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Summary dimension values for instances that do not state their architecture or OS
+const (
+	SummaryUnknownArchitecture    = "unknown"
+	SummaryUnknownOperatingSystem = "unknown"
+)
+
+// liveOnDemandPredicate selects the on-demand prices currently in effect
+const liveOnDemandPredicate = `pricing_model = 'on_demand'
+	AND (effective_date IS NULL OR effective_date <= CURRENT_DATE)
+	AND (expiration_date IS NULL OR expiration_date >= CURRENT_DATE)`
+
+// refreshComputePriceSummary rebuilds the cheapest on-demand instance per provider region,
+// vCPU count, memory bucket, architecture and OS. Memory is bucketed to the next power of two
+// (3.75 GB counts as 4 GB), so near-identical shapes of the two providers share a row.
+// Shared-core instances with a fractional vCPU count are left out rather than rounded into the
+// shape of a whole vCPU. Bring-your-own-license prices leave out the OS licence, so they are
+// not comparable with the prices that include it and are left out too.
+var refreshComputePriceSummary = fmt.Sprintf(`
+	INSERT INTO compute_price_summary (
+		provider, provider_region, normalized_region, vcpu, memory_bucket_gb, architecture,
+		operating_system, currency, resource_name, provider_sku, memory_gb, price_per_hour,
+		offer_count, refreshed_at
+	)
+	SELECT DISTINCT ON (provider, provider_region, vcpu, memory_bucket_gb, architecture, operating_system, currency)
+		provider, provider_region, normalized_region, vcpu, memory_bucket_gb, architecture,
+		operating_system, currency, resource_name, provider_sku, memory_gb, price_per_hour,
+		COUNT(*) OVER (PARTITION BY provider, provider_region, vcpu, memory_bucket_gb, architecture, operating_system, currency),
+		$1
+	FROM (
+		SELECT provider, provider_region, normalized_region, currency, resource_name, provider_sku,
+			spec_vcpu AS vcpu,
+			spec_memory_gb AS memory_gb,
+			power(2::numeric, ceil(log(2::numeric, spec_memory_gb))) AS memory_bucket_gb,
+			COALESCE(spec_architecture, '%[1]s') AS architecture,
+			COALESCE(operating_system, '%[2]s') AS operating_system,
+			COALESCE((pricing_details->>'effective_hourly_rate')::numeric, price_per_unit) AS price_per_hour
+		FROM normalized_pricing
+		WHERE %[3]s
+		  AND service_category = 'Compute & Web'
+		  AND unit = 'hour'
+		  AND price_per_unit > 0
+		  AND spec_vcpu > 0
+		  AND spec_vcpu = trunc(spec_vcpu)
+		  AND spec_memory_gb > 0
+		  AND COALESCE(license_model, '') <> '%[4]s'
+	) instances
+	ORDER BY provider, provider_region, vcpu, memory_bucket_gb, architecture, operating_system, currency,
+		price_per_hour, resource_name`,
+	SummaryUnknownArchitecture, SummaryUnknownOperatingSystem, liveOnDemandPredicate, LicenseModelBYOL)
+
+// refreshRegionPriceIndex rebuilds the price index of every provider region, category and
+// unit. Each on-demand item (service, resource and platform) is compared with its median
+// price across the provider's regions, and the index is the geometric mean of these ratios
+// times 100: 100 is a typically priced region, 110 is 10% more expensive.
+var refreshRegionPriceIndex = fmt.Sprintf(`
+	INSERT INTO region_price_index (
+		provider, provider_region, normalized_region, service_category, unit, currency,
+		price_index, item_count, median_price, min_price, refreshed_at
+	)
+	WITH items AS (
+		SELECT provider, provider_region, normalized_region, service_category, unit, currency,
+			concat_ws('|', service_type, resource_name, COALESCE(operating_system, ''),
+				COALESCE(license_model, ''), COALESCE(pre_installed_software, ''), COALESCE(tenancy, '')) AS item_key,
+			MIN(price_per_unit) AS price
+		FROM normalized_pricing
+		WHERE %s AND price_per_unit > 0
+		GROUP BY 1, 2, 3, 4, 5, 6, 7
+	), baselines AS (
+		SELECT provider, service_category, unit, currency, item_key,
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY price) AS baseline
+		FROM items
+		GROUP BY 1, 2, 3, 4, 5
+	)
+	SELECT i.provider, i.provider_region, i.normalized_region, i.service_category, i.unit, i.currency,
+		100 * exp(AVG(ln(i.price / b.baseline))),
+		COUNT(*),
+		percentile_cont(0.5) WITHIN GROUP (ORDER BY i.price),
+		MIN(i.price),
+		$1
+	FROM items i
+	JOIN baselines b USING (provider, service_category, unit, currency, item_key)
+	GROUP BY i.provider, i.provider_region, i.normalized_region, i.service_category, i.unit, i.currency`,
+	liveOnDemandPredicate)
+
+// PriceSummaryRefreshResult counts the rows of the rebuilt summary tables
+type PriceSummaryRefreshResult struct {
+	ComputeRows int64     `json:"computeRows"`
+	IndexRows   int64     `json:"indexRows"`
+	RefreshedAt time.Time `json:"refreshedAt"`
+}
+
+// ComputePriceSummary is the cheapest on-demand instance of a shape in a provider region
+type ComputePriceSummary struct {
+	Provider         string    `json:"provider"`
+	ProviderRegion   string    `json:"providerRegion"`
+	NormalizedRegion string    `json:"normalizedRegion"`
+	RegionName       string    `json:"regionName"`
+	VCPU             int       `json:"vcpu"`
+	MemoryBucketGB   float64   `json:"memoryBucketGb"`
+	Architecture     string    `json:"architecture"`
+	OperatingSystem  string    `json:"operatingSystem"`
+	Currency         string    `json:"currency"`
+	ResourceName     string    `json:"resourceName"`
+	ProviderSKU      *string   `json:"providerSku,omitempty"`
+	MemoryGB         float64   `json:"memoryGb"`
+	PricePerHour     float64   `json:"pricePerHour"`
+	OfferCount       int       `json:"offerCount"`
+	RefreshedAt      time.Time `json:"refreshedAt"`
+}
+
+// ComputePriceSummaryFilter selects compute summary rows. Regions match provider or
+// normalized region codes. CheapestPerRegion keeps only the cheapest matching row of each
+// provider region.
+type ComputePriceSummaryFilter struct {
+	Providers         []string `json:"providers,omitempty"`
+	Regions           []string `json:"regions,omitempty"`
+	MinVCPU           *int     `json:"minVcpu,omitempty"`
+	MaxVCPU           *int     `json:"maxVcpu,omitempty"`
+	MinMemoryGB       *float64 `json:"minMemoryGb,omitempty"`
+	MaxMemoryGB       *float64 `json:"maxMemoryGb,omitempty"`
+	Architecture      *string  `json:"architecture,omitempty"`
+	OperatingSystem   *string  `json:"operatingSystem,omitempty"`
+	Currency          *string  `json:"currency,omitempty"`
+	CheapestPerRegion bool     `json:"cheapestPerRegion"`
+	Limit             *int     `json:"limit,omitempty"`
+}
+
+// RegionPriceIndex compares the on-demand prices of a category in a provider region with
+// the same items in the provider's other regions
+type RegionPriceIndex struct {
+	Provider         string    `json:"provider"`
+	ProviderRegion   string    `json:"providerRegion"`
+	NormalizedRegion string    `json:"normalizedRegion"`
+	RegionName       string    `json:"regionName"`
+	ServiceCategory  string    `json:"serviceCategory"`
+	Unit             string    `json:"unit"`
+	Currency         string    `json:"currency"`
+	PriceIndex       float64   `json:"priceIndex"`
+	ItemCount        int       `json:"itemCount"`
+	MedianPrice      float64   `json:"medianPrice"`
+	MinPrice         float64   `json:"minPrice"`
+	RefreshedAt      time.Time `json:"refreshedAt"`
+}
+
+// RegionPriceIndexFilter selects price index rows. Regions match provider or normalized
+// region codes.
+type RegionPriceIndexFilter struct {
+	Providers       []string `json:"providers,omitempty"`
+	Regions         []string `json:"regions,omitempty"`
+	ServiceCategory *string  `json:"serviceCategory,omitempty"`
+	Unit            *string  `json:"unit,omitempty"`
+	Currency        *string  `json:"currency,omitempty"`
+}
+
+// MemoryBucketGB returns the memory bucket of the compute summary an amount of memory falls
+// in: the next power of two
+func MemoryBucketGB(memoryGB float64) float64 {
+	if memoryGB <= 0 {
+		return 0
+	}
+	return math.Pow(2, math.Ceil(math.Log2(memoryGB)))
+}
+
+// RefreshPriceSummaries rebuilds the compute price summary and the region price index from
+// the live normalized prices. Readers see the old summaries until the rebuild commits.
+func (db *DB) RefreshPriceSummaries(ctx context.Context) (*PriceSummaryRefreshResult, error) {
+	tx, err := db.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &PriceSummaryRefreshResult{RefreshedAt: time.Now()}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM compute_price_summary`); err != nil {
+		return nil, fmt.Errorf("failed to clear compute price summary: %w", err)
+	}
+	compute, err := tx.ExecContext(ctx, refreshComputePriceSummary, result.RefreshedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh compute price summary: %w", err)
+	}
+	if result.ComputeRows, err = compute.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to count compute price summary rows: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM region_price_index`); err != nil {
+		return nil, fmt.Errorf("failed to clear region price index: %w", err)
+	}
+	index, err := tx.ExecContext(ctx, refreshRegionPriceIndex, result.RefreshedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh region price index: %w", err)
+	}
+	if result.IndexRows, err = index.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to count region price index rows: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("📈 Price summaries refreshed: %d compute rows, %d price index rows", result.ComputeRows, result.IndexRows)
+	return result, nil
+}
+
+// GetComputePriceSummaries returns compute summary rows, cheapest first
+func (db *DB) GetComputePriceSummaries(ctx context.Context, filter ComputePriceSummaryFilter) ([]ComputePriceSummary, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+	addArg := func(value interface{}) int {
+		args = append(args, value)
+		return len(args)
+	}
+
+	if len(filter.Providers) > 0 {
+		where += fmt.Sprintf(" AND s.provider = ANY($%d)", addArg(pq.Array(filter.Providers)))
+	}
+	if len(filter.Regions) > 0 {
+		n := addArg(pq.Array(filter.Regions))
+		where += fmt.Sprintf(" AND (s.provider_region = ANY($%d) OR s.normalized_region = ANY($%d))", n, n)
+	}
+	if filter.MinVCPU != nil {
+		where += fmt.Sprintf(" AND s.vcpu >= $%d", addArg(*filter.MinVCPU))
+	}
+	if filter.MaxVCPU != nil {
+		where += fmt.Sprintf(" AND s.vcpu <= $%d", addArg(*filter.MaxVCPU))
+	}
+	if filter.MinMemoryGB != nil {
+		where += fmt.Sprintf(" AND s.memory_gb >= $%d", addArg(*filter.MinMemoryGB))
+	}
+	if filter.MaxMemoryGB != nil {
+		where += fmt.Sprintf(" AND s.memory_gb <= $%d", addArg(*filter.MaxMemoryGB))
+	}
+	if filter.Architecture != nil {
+		where += fmt.Sprintf(" AND s.architecture = $%d", addArg(NormalizeArchitecture(*filter.Architecture)))
+	}
+	if filter.OperatingSystem != nil {
+		where += fmt.Sprintf(" AND s.operating_system = $%d", addArg(*filter.OperatingSystem))
+	}
+	if filter.Currency != nil {
+		where += fmt.Sprintf(" AND s.currency = $%d", addArg(*filter.Currency))
+	}
+
+	columns := `s.provider, s.provider_region, s.normalized_region,
+		COALESCE(r.display_name, s.normalized_region), s.vcpu, s.memory_bucket_gb, s.architecture,
+		s.operating_system, s.currency, s.resource_name, s.provider_sku, s.memory_gb,
+		s.price_per_hour, s.offer_count, s.refreshed_at`
+	from := ` FROM compute_price_summary s
+		LEFT JOIN normalized_regions r ON r.normalized_code = s.normalized_region`
+
+	var query string
+	if filter.CheapestPerRegion {
+		query = fmt.Sprintf(`SELECT * FROM (
+			SELECT DISTINCT ON (s.provider, s.provider_region) %s%s%s
+			ORDER BY s.provider, s.provider_region, s.price_per_hour
+		) cheapest ORDER BY price_per_hour`, columns, from, where)
+	} else {
+		query = fmt.Sprintf(`SELECT %s%s%s ORDER BY s.price_per_hour, s.provider, s.provider_region`, columns, from, where)
+	}
+	if filter.Limit != nil {
+		query += fmt.Sprintf(" LIMIT $%d", addArg(*filter.Limit))
+	}
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query compute price summary: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []ComputePriceSummary
+	for rows.Next() {
+		var summary ComputePriceSummary
+		var providerSKU sql.NullString
+		err := rows.Scan(&summary.Provider, &summary.ProviderRegion, &summary.NormalizedRegion,
+			&summary.RegionName, &summary.VCPU, &summary.MemoryBucketGB, &summary.Architecture,
+			&summary.OperatingSystem, &summary.Currency, &summary.ResourceName, &providerSKU,
+			&summary.MemoryGB, &summary.PricePerHour, &summary.OfferCount, &summary.RefreshedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan compute price summary: %w", err)
+		}
+		if providerSKU.Valid {
+			summary.ProviderSKU = &providerSKU.String
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read compute price summary: %w", err)
+	}
+	return summaries, nil
+}
+
+// GetRegionPriceIndices returns price index rows, cheapest regions first
+func (db *DB) GetRegionPriceIndices(ctx context.Context, filter RegionPriceIndexFilter) ([]RegionPriceIndex, error) {
+	query := `
+		SELECT i.provider, i.provider_region, i.normalized_region, COALESCE(r.display_name, i.normalized_region),
+		       i.service_category, i.unit, i.currency, i.price_index, i.item_count, i.median_price,
+		       i.min_price, i.refreshed_at
+		FROM region_price_index i
+		LEFT JOIN normalized_regions r ON r.normalized_code = i.normalized_region
+		WHERE 1=1`
+	args := []interface{}{}
+
+	if len(filter.Providers) > 0 {
+		args = append(args, pq.Array(filter.Providers))
+		query += fmt.Sprintf(" AND i.provider = ANY($%d)", len(args))
+	}
+	if len(filter.Regions) > 0 {
+		args = append(args, pq.Array(filter.Regions))
+		query += fmt.Sprintf(" AND (i.provider_region = ANY($%[1]d) OR i.normalized_region = ANY($%[1]d))", len(args))
+	}
+	if filter.ServiceCategory != nil {
+		args = append(args, *filter.ServiceCategory)
+		query += fmt.Sprintf(" AND i.service_category = $%d", len(args))
+	}
+	if filter.Unit != nil {
+		args = append(args, *filter.Unit)
+		query += fmt.Sprintf(" AND i.unit = $%d", len(args))
+	}
+	if filter.Currency != nil {
+		args = append(args, *filter.Currency)
+		query += fmt.Sprintf(" AND i.currency = $%d", len(args))
+	}
+	query += " ORDER BY i.service_category, i.unit, i.price_index, i.provider, i.provider_region"
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query region price index: %w", err)
+	}
+	defer rows.Close()
+
+	var indices []RegionPriceIndex
+	for rows.Next() {
+		var index RegionPriceIndex
+		err := rows.Scan(&index.Provider, &index.ProviderRegion, &index.NormalizedRegion, &index.RegionName,
+			&index.ServiceCategory, &index.Unit, &index.Currency, &index.PriceIndex, &index.ItemCount,
+			&index.MedianPrice, &index.MinPrice, &index.RefreshedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan region price index: %w", err)
+		}
+		indices = append(indices, index)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read region price index: %w", err)
+	}
+	return indices, nil
+}
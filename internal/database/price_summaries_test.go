@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// summaryOffer is a 4 vCPU, 16 GB instance price of a compute price summary test
+type summaryOffer struct {
+	provider     string
+	sku          string
+	description  string
+	price        float64
+	pricingModel string
+	os           string
+	licenseModel string
+}
+
+func insertSummaryOffers(t *testing.T, conn *sql.DB, offers []summaryOffer) {
+	t.Helper()
+
+	for _, offer := range offers {
+		_, err := conn.Exec(`
+			INSERT INTO normalized_pricing (
+				provider, provider_service_code, provider_sku, service_category, service_family, service_type,
+				normalized_region, provider_region, resource_name, resource_description, price_per_unit, unit,
+				pricing_model, resource_specs, operating_system, license_model
+			) VALUES (
+				$1, 'VirtualMachines', $2, 'Compute & Web', 'Virtual Machines', 'Virtual Machines',
+				'us-east', 'us-east-1', 'xlarge', $3, $4, 'hour',
+				$5, '{"vcpu": 4, "memory_gb": 16, "architecture": "x86_64"}', $6, $7
+			)`, offer.provider, offer.sku, offer.description, offer.price, offer.pricingModel, offer.os, offer.licenseModel)
+		require.NoError(t, err)
+	}
+}
+
+func TestRefreshPriceSummaries(t *testing.T) {
+	tests := []struct {
+		name        string
+		offers      []summaryOffer
+		expectedSKU string
+	}{
+		{
+			name: "bring your own license is left out",
+			offers: []summaryOffer{
+				{ProviderAWS, "SKU-INCLUDED", "m5.xlarge Windows", 0.376, PricingModelOnDemand, OSWindows, LicenseModelIncluded},
+				{ProviderAWS, "SKU-BYOL", "m5.xlarge Windows BYOL", 0.192, PricingModelOnDemand, OSWindows, LicenseModelBYOL},
+			},
+			expectedSKU: "SKU-INCLUDED",
+		},
+		{
+			name: "low priority is left out",
+			offers: []summaryOffer{
+				{ProviderAzure, "SKU-REGULAR", "Dasv5-series Linux - D4as v5", 0.172, PricingModelOnDemand, OSLinux, LicenseModelNone},
+				{ProviderAzure, "SKU-LOW-PRIORITY", "Dasv5-series Linux - D4as v5 Low Priority", 0.0344, PricingModelSpot, OSLinux, LicenseModelNone},
+			},
+			expectedSKU: "SKU-REGULAR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := openTestDatabase(t)
+			ctx := context.Background()
+
+			db := New(conn)
+			_, err := db.MigrateUp(ctx)
+			require.NoError(t, err)
+			insertSummaryOffers(t, conn, tt.offers)
+
+			result, err := db.RefreshPriceSummaries(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), result.ComputeRows)
+
+			summaries, err := db.GetComputePriceSummaries(ctx, ComputePriceSummaryFilter{})
+			require.NoError(t, err)
+			require.Len(t, summaries, 1)
+			assert.Equal(t, tt.expectedSKU, *summaries[0].ProviderSKU)
+			assert.Equal(t, 1, summaries[0].OfferCount)
+		})
+	}
+}
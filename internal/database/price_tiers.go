@@ -0,0 +1,181 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// ConsolidateAzurePriceTiers merges Azure tier records into a single record per meter.
+//
+// The Azure retail API returns each tier of a meter as a separate item with its own
+// tierMinimumUnits, so the normalizer stores one record per tier. Records of the same meter
+// version (same SKU, region, pricing model, unit, description and effective date; a meter can
+// have a current and an announced future version) are merged into the record with the
+// lowest id, whose tiers are rebuilt in start order and whose price becomes the first non-zero
+// tier price. The other records are deleted. Running it again on merged data is a no-op.
+// Non-empty regions and services limit the merge to those provider regions and service codes.
+func (db *DB) ConsolidateAzurePriceTiers(ctx context.Context, table string, regions, services []string) (int64, error) {
+	if table != NormalizedPricingTable && table != NormalizedPricingStagingTable {
+		return 0, fmt.Errorf("unsupported normalized pricing table: %s", table)
+	}
+
+	scope := ""
+	args := []interface{}{}
+	if len(regions) > 0 {
+		args = append(args, pq.Array(regions))
+		scope += fmt.Sprintf(" AND provider_region = ANY($%d)", len(args))
+	}
+	if len(services) > 0 {
+		args = append(args, pq.Array(services))
+		scope += fmt.Sprintf(" AND provider_service_code = ANY($%d)", len(args))
+	}
+
+	query := fmt.Sprintf(`
+		WITH members AS (
+			SELECT id, price_per_unit, price_tiers,
+			       concat_ws('|', provider_sku, provider_region, pricing_model, unit,
+			                 resource_name, resource_description,
+			                 to_char(effective_date, 'YYYY-MM-DD')) AS group_key
+			FROM %[1]s
+			WHERE provider = 'azure'%[2]s
+		),
+		tiered AS (
+			SELECT group_key
+			FROM members
+			GROUP BY group_key
+			HAVING COUNT(*) > 1 AND bool_or(price_tiers IS NOT NULL)
+		),
+		tiers AS (
+			SELECT DISTINCT ON (m.group_key, start_units)
+			       m.group_key,
+			       COALESCE((t.tier->>'start_units')::float8, 0) AS start_units,
+			       COALESCE((t.tier->>'price_per_unit')::float8, m.price_per_unit) AS price
+			FROM members m
+			JOIN tiered USING (group_key)
+			LEFT JOIN LATERAL jsonb_array_elements(m.price_tiers) AS t(tier) ON true
+			ORDER BY m.group_key, start_units, m.id DESC
+		),
+		merged AS (
+			SELECT group_key,
+			       jsonb_agg(jsonb_build_object(
+			           'start_units', start_units,
+			           'end_units', end_units,
+			           'price_per_unit', price
+			       ) ORDER BY start_units) AS price_tiers,
+			       (array_agg(price ORDER BY start_units) FILTER (WHERE price > 0))[1] AS headline_price
+			FROM (
+				SELECT group_key, start_units, price,
+				       LEAD(start_units) OVER (PARTITION BY group_key ORDER BY start_units) AS end_units
+				FROM tiers
+			) ordered
+			GROUP BY group_key
+		),
+		keepers AS (
+			SELECT m.group_key, MIN(m.id) AS id
+			FROM members m
+			JOIN tiered USING (group_key)
+			GROUP BY m.group_key
+		),
+		updated AS (
+			UPDATE %[1]s p
+			SET price_tiers = merged.price_tiers,
+			    price_per_unit = COALESCE(merged.headline_price, p.price_per_unit)
+			FROM keepers
+			JOIN merged USING (group_key)
+			WHERE p.id = keepers.id
+			RETURNING p.id
+		)
+		DELETE FROM %[1]s p
+		USING members m
+		JOIN keepers k USING (group_key)
+		WHERE p.id = m.id AND p.id <> k.id`, table, scope)
+
+	result, err := db.conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to consolidate Azure price tiers: %w", err)
+	}
+
+	merged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count consolidated Azure price tiers: %w", err)
+	}
+
+	if merged > 0 {
+		log.Printf("✅ Merged %d Azure tier records into tiered prices in %s", merged, table)
+	}
+	return merged, nil
+}
+
+// PriceTierGroupKey returns the meter version a tier record belongs to, grouped like
+// ConsolidateAzurePriceTiers groups them
+func PriceTierGroupKey(record NormalizedPricing) string {
+	var effectiveDate *string
+	if record.EffectiveDate != nil {
+		date := record.EffectiveDate.UTC().Format("2006-01-02")
+		effectiveDate = &date
+	}
+
+	parts := []string{}
+	for _, part := range []*string{record.ProviderSKU, &record.ProviderRegion, &record.PricingModel,
+		&record.Unit, &record.ResourceName, record.ResourceDescription, effectiveDate} {
+		// concat_ws skips NULLs
+		if part != nil {
+			parts = append(parts, *part)
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+// MergePriceTiers merges the tier records of one meter, given in insertion order, the way
+// ConsolidateAzurePriceTiers does: a record without tiers is a tier starting at zero, later
+// records win for the same start, and the price is the first non-zero tier price. ok is false
+// when the records are not merged, because there is only one or none of them has tiers.
+func MergePriceTiers(members []NormalizedPricing) (tiers []PriceTier, price float64, ok bool) {
+	if len(members) < 2 {
+		return nil, 0, false
+	}
+
+	prices := make(map[float64]float64)
+	for _, member := range members {
+		if len(member.PriceTiers) > 0 {
+			ok = true
+		}
+		if len(member.PriceTiers) == 0 {
+			prices[0] = member.PricePerUnit
+			continue
+		}
+		for _, tier := range member.PriceTiers {
+			prices[tier.StartUnits] = tier.PricePerUnit
+		}
+	}
+	if !ok {
+		return nil, 0, false
+	}
+
+	starts := make([]float64, 0, len(prices))
+	for start := range prices {
+		starts = append(starts, start)
+	}
+	sort.Float64s(starts)
+
+	price = members[0].PricePerUnit
+	headline := false
+	tiers = make([]PriceTier, len(starts))
+	for i, start := range starts {
+		tiers[i] = PriceTier{StartUnits: start, PricePerUnit: prices[start]}
+		if i+1 < len(starts) {
+			end := starts[i+1]
+			tiers[i].EndUnits = &end
+		}
+		if !headline && prices[start] > 0 {
+			price = prices[start]
+			headline = true
+		}
+	}
+	return tiers, price, true
+}
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tierRecord(effectiveDate string, price float64, tiers ...PriceTier) NormalizedPricing {
+	record := NormalizedPricing{
+		Provider: ProviderAzure, ProviderSKU: stringPtr("DZH318Z0BQ4N/0003"), ProviderRegion: "eastus",
+		PricingModel: PricingModelOnDemand, Unit: UnitGB, ResourceName: "Standard LRS",
+		ResourceDescription: stringPtr("Blob Storage - LRS Data Stored"), PricePerUnit: price, PriceTiers: tiers,
+	}
+	if effectiveDate != "" {
+		date, err := time.Parse("2006-01-02", effectiveDate)
+		if err != nil {
+			panic(err)
+		}
+		record.EffectiveDate = &date
+	}
+	return record
+}
+
+func TestPriceTierGroupKey(t *testing.T) {
+	current := tierRecord("2026-01-01", 0.02)
+	sameVersion := tierRecord("2026-01-01", 0.019, PriceTier{StartUnits: 51200, PricePerUnit: 0.019})
+	future := tierRecord("2026-12-01", 0.018)
+	undated := tierRecord("", 0.02)
+
+	assert.Equal(t, PriceTierGroupKey(current), PriceTierGroupKey(sameVersion))
+	assert.NotEqual(t, PriceTierGroupKey(current), PriceTierGroupKey(future))
+	assert.NotEqual(t, PriceTierGroupKey(current), PriceTierGroupKey(undated))
+	assert.Equal(t, "DZH318Z0BQ4N/0003|eastus|on_demand|gb|Standard LRS|Blob Storage - LRS Data Stored|2026-01-01",
+		PriceTierGroupKey(current))
+}
+
+func TestMergePriceTiers(t *testing.T) {
+	tests := []struct {
+		name          string
+		members       []NormalizedPricing
+		expectedTiers []PriceTier
+		expectedPrice float64
+		expectedOK    bool
+	}{
+		{
+			name:    "single record",
+			members: []NormalizedPricing{tierRecord("", 0.02)},
+		},
+		{
+			name:    "records without tiers",
+			members: []NormalizedPricing{tierRecord("", 0.02), tierRecord("", 0.03)},
+		},
+		{
+			name: "tiers in start order with the first non-zero price",
+			members: []NormalizedPricing{
+				tierRecord("", 0.0184, PriceTier{StartUnits: 51200, PricePerUnit: 0.0184}),
+				tierRecord("", 0),
+				tierRecord("", 0.0192, PriceTier{StartUnits: 5, PricePerUnit: 0.0192}),
+			},
+			expectedTiers: []PriceTier{
+				{StartUnits: 0, EndUnits: floatPtr(5), PricePerUnit: 0},
+				{StartUnits: 5, EndUnits: floatPtr(51200), PricePerUnit: 0.0192},
+				{StartUnits: 51200, PricePerUnit: 0.0184},
+			},
+			expectedPrice: 0.0192,
+			expectedOK:    true,
+		},
+		{
+			name: "later records win for the same start",
+			members: []NormalizedPricing{
+				tierRecord("", 0.02),
+				tierRecord("", 0.019, PriceTier{StartUnits: 100, PricePerUnit: 0.019}),
+				tierRecord("", 0.017, PriceTier{StartUnits: 100, PricePerUnit: 0.017}),
+			},
+			expectedTiers: []PriceTier{
+				{StartUnits: 0, EndUnits: floatPtr(100), PricePerUnit: 0.02},
+				{StartUnits: 100, PricePerUnit: 0.017},
+			},
+			expectedPrice: 0.02,
+			expectedOK:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tiers, price, ok := MergePriceTiers(tt.members)
+
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expectedTiers, tiers)
+			assert.Equal(t, tt.expectedPrice, price)
+		})
+	}
+}
+
+func TestConsolidateAzurePriceTiers_KeepsEachVersion(t *testing.T) {
+	conn := openTestDatabase(t)
+	ctx := context.Background()
+
+	db := New(conn)
+	_, err := db.MigrateUp(ctx)
+	require.NoError(t, err)
+
+	// The tiers of the current meter version and of an announced future one
+	for _, tier := range []struct {
+		effectiveDate string
+		price         float64
+		tiers         *string
+	}{
+		{"2026-01-01", 0.02, nil},
+		{"2026-01-01", 0.019, stringPtr(`[{"start_units": 51200, "price_per_unit": 0.019}]`)},
+		{"2026-12-01", 0.018, nil},
+		{"2026-12-01", 0.017, stringPtr(`[{"start_units": 51200, "price_per_unit": 0.017}]`)},
+	} {
+		_, err := conn.Exec(`
+			INSERT INTO normalized_pricing (
+				provider, provider_service_code, provider_sku, service_category, service_family, service_type,
+				normalized_region, provider_region, resource_name, resource_description, price_per_unit, unit,
+				pricing_model, price_tiers, effective_date
+			) VALUES (
+				'azure', 'Storage', 'DZH318Z0BQ4N/0003', 'Storage', 'Object Storage', 'Object Storage',
+				'us-east', 'eastus', 'Standard LRS', 'Blob Storage - LRS Data Stored', $1, 'gb',
+				'on_demand', $2, $3
+			)`, tier.price, tier.tiers, tier.effectiveDate)
+		require.NoError(t, err)
+	}
+
+	merged, err := db.ConsolidateAzurePriceTiers(ctx, NormalizedPricingTable, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), merged)
+
+	rows, err := conn.Query(`
+		SELECT to_char(effective_date, 'YYYY-MM-DD'), price_per_unit, jsonb_array_length(price_tiers)
+		FROM normalized_pricing ORDER BY effective_date`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	type version struct {
+		effectiveDate string
+		price         float64
+		tierCount     int
+	}
+	var versions []version
+	for rows.Next() {
+		var v version
+		require.NoError(t, rows.Scan(&v.effectiveDate, &v.price, &v.tierCount))
+		versions = append(versions, v)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []version{{"2026-01-01", 0.02, 2}, {"2026-12-01", 0.018, 2}}, versions)
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// PricingRepository is the read path over normalized pricing served by the GraphQL and REST
+// servers and the optimizer. DB implements it on Postgres; the snapshot package implements it
+// on a single SQLite file for offline analysis.
+type PricingRepository interface {
+	// Normalized pricing
+	QueryNormalizedPricing(filter PricingFilter) ([]NormalizedPricing, error)
+	GetPriceTimeline(ctx context.Context, provider, sku string, normalizedRegion *string) ([]PriceHistoryEntry, error)
+	SearchNormalizedPricing(ctx context.Context, req PricingSearchRequest) (*PricingSearchResult, error)
+
+	// Mappings
+	GetServiceMappings() ([]ServiceMapping, error)
+	GetServiceMappingByProvider(provider, serviceName string) (*ServiceMapping, error)
+	GetNormalizedRegions() ([]NormalizedRegion, error)
+	GetNormalizedRegionByProvider(provider, providerRegion string) (*NormalizedRegion, error)
+
+	// Comparisons
+	ComparePricing(serviceType, normalizedRegion, pricingModel string) ([]PricingComparison, error)
+	ComparePricingWithConfig(serviceType, normalizedRegion, pricingModel string, config MatchingConfig) ([]PricingComparison, error)
+
+	// Optimizer price lookups
+	GetComputePriceSummaries(ctx context.Context, filter ComputePriceSummaryFilter) ([]ComputePriceSummary, error)
+	GetRegionPriceIndices(ctx context.Context, filter RegionPriceIndexFilter) ([]RegionPriceIndex, error)
+
+	// Exchange rates
+	GetExchangeRate(ctx context.Context, baseCurrency, quoteCurrency string, asOf time.Time) (*ExchangeRate, error)
+}
+
+var _ PricingRepository = (*DB)(nil)
@@ -0,0 +1,221 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Search result limits
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// Facets of pricing search results
+const (
+	SearchFacetProvider        = "provider"
+	SearchFacetRegion          = "normalized_region"
+	SearchFacetServiceCategory = "service_category"
+	SearchFacetPricingModel    = "pricing_model"
+)
+
+// PricingSearchRequest is a free-text search over live normalized prices, optionally narrowed
+// by facet values
+type PricingSearchRequest struct {
+	Query            string  `json:"query"`
+	Provider         *string `json:"provider,omitempty"`
+	NormalizedRegion *string `json:"normalizedRegion,omitempty"`
+	ServiceCategory  *string `json:"serviceCategory,omitempty"`
+	PricingModel     *string `json:"pricingModel,omitempty"`
+	Limit            int     `json:"limit"`
+	Offset           int     `json:"offset"`
+}
+
+// PricingSearchHit is a matching price with its relevance score
+type PricingSearchHit struct {
+	NormalizedPricing
+	Score float64 `json:"score"`
+}
+
+// SearchFacetValue is a facet value with the number of matching prices
+type SearchFacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// PricingSearchFacets counts all matches, not only the returned page, per facet value. A facet
+// is counted without the request's filter on it, so its other values stay visible.
+type PricingSearchFacets struct {
+	Providers         []SearchFacetValue `json:"providers"`
+	Regions           []SearchFacetValue `json:"regions"`
+	ServiceCategories []SearchFacetValue `json:"serviceCategories"`
+	PricingModels     []SearchFacetValue `json:"pricingModels"`
+}
+
+// PricingSearchResult is a page of ranked matches with the total and facet counts
+type PricingSearchResult struct {
+	Query  string              `json:"query"`
+	Total  int                 `json:"total"`
+	Hits   []PricingSearchHit  `json:"hits"`
+	Facets PricingSearchFacets `json:"facets"`
+}
+
+// searchTerms computes the full-text query and the lowercased term once per search
+const searchTerms = `(SELECT websearch_to_tsquery('simple', $1) AS tsq, lower($1) AS term) q`
+
+// searchMatch matches prices on full text (every word, any order), on trigram word similarity
+// to their search text, or on trigram similarity of their resource name, which tolerates
+// typos such as "d4sa_v5"
+const searchMatch = `(search_vector @@ q.tsq OR q.term <% search_text OR lower(resource_name) % q.term)`
+
+// searchScore ranks exact name matches first, then full-text rank and trigram similarity
+const searchScore = `(CASE WHEN lower(resource_name) = q.term OR lower(COALESCE(provider_sku, '')) = q.term THEN 10 ELSE 0 END
+	+ 2 * ts_rank_cd(search_vector, q.tsq)
+	+ 3 * similarity(lower(resource_name), q.term)
+	+ word_similarity(q.term, search_text))`
+
+// SearchNormalizedPricing ranks the live normalized prices matching a free-text query and
+// counts the matches per provider, region, category and pricing model
+func (db *DB) SearchNormalizedPricing(ctx context.Context, req PricingSearchRequest) (*PricingSearchResult, error) {
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Query == "" {
+		return nil, fmt.Errorf("search query is required")
+	}
+	if req.Limit <= 0 {
+		req.Limit = DefaultSearchLimit
+	}
+	if req.Limit > MaxSearchLimit {
+		req.Limit = MaxSearchLimit
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	matchWhere := fmt.Sprintf(`%s
+		AND (effective_date IS NULL OR effective_date <= CURRENT_DATE)
+		AND (expiration_date IS NULL OR expiration_date >= CURRENT_DATE)`, searchMatch)
+	args := []interface{}{req.Query}
+	filters := make(map[string]string)
+	addFilter := func(facet string, value *string) {
+		if value != nil {
+			args = append(args, *value)
+			filters[facet] = fmt.Sprintf("%s = $%d", facet, len(args))
+		}
+	}
+	addFilter(SearchFacetProvider, req.Provider)
+	addFilter(SearchFacetRegion, req.NormalizedRegion)
+	addFilter(SearchFacetServiceCategory, req.ServiceCategory)
+	addFilter(SearchFacetPricingModel, req.PricingModel)
+	where := matchWhere + facetFilters(filters, "")
+
+	result := &PricingSearchResult{
+		Query: req.Query,
+		Hits:  []PricingSearchHit{},
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s, %s AS score
+		FROM normalized_pricing CROSS JOIN %s
+		WHERE %s
+		ORDER BY score DESC, price_per_unit, id
+		LIMIT $%d OFFSET $%d`,
+		normalizedPricingSelectColumns, searchScore, searchTerms, where, len(args)+1, len(args)+2)
+
+	rows, err := db.conn.QueryContext(ctx, query, append(args, req.Limit, req.Offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search normalized pricing: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hit PricingSearchHit
+		hit.NormalizedPricing, err = scanNormalizedPricing(rows, &hit.Score)
+		if err != nil {
+			return nil, err
+		}
+		result.Hits = append(result.Hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read search results: %w", err)
+	}
+
+	if err := db.searchFacets(ctx, matchWhere, filters, args, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// searchFacetTotal labels the count of all matches in the facet query
+const searchFacetTotal = "total"
+
+// facetFilters joins the facet value conditions of a search, leaving out the one of a facet
+func facetFilters(filters map[string]string, except string) string {
+	where := ""
+	for _, facet := range []string{SearchFacetProvider, SearchFacetRegion, SearchFacetServiceCategory, SearchFacetPricingModel} {
+		if condition, ok := filters[facet]; ok && facet != except {
+			where += " AND " + condition
+		}
+	}
+	return where
+}
+
+// searchFacets counts the matches of a search per facet value, most frequent first. Each facet
+// is counted without its own filter, so the counts show what selecting another value of the
+// facet would return.
+func (db *DB) searchFacets(ctx context.Context, matchWhere string, filters map[string]string, args []interface{}, result *PricingSearchResult) error {
+	query := fmt.Sprintf(`
+		WITH matches AS (
+			SELECT provider, normalized_region, service_category, pricing_model
+			FROM normalized_pricing CROSS JOIN %s
+			WHERE %s
+		)
+		SELECT '%s', '', COUNT(*) FROM matches WHERE TRUE%s
+		UNION ALL
+		SELECT '%s', provider, COUNT(*) FROM matches WHERE TRUE%s GROUP BY provider
+		UNION ALL
+		SELECT '%s', normalized_region, COUNT(*) FROM matches WHERE TRUE%s GROUP BY normalized_region
+		UNION ALL
+		SELECT '%s', service_category, COUNT(*) FROM matches WHERE TRUE%s GROUP BY service_category
+		UNION ALL
+		SELECT '%s', pricing_model, COUNT(*) FROM matches WHERE TRUE%s GROUP BY pricing_model
+		ORDER BY 1, 3 DESC, 2`,
+		searchTerms, matchWhere,
+		searchFacetTotal, facetFilters(filters, ""),
+		SearchFacetProvider, facetFilters(filters, SearchFacetProvider),
+		SearchFacetRegion, facetFilters(filters, SearchFacetRegion),
+		SearchFacetServiceCategory, facetFilters(filters, SearchFacetServiceCategory),
+		SearchFacetPricingModel, facetFilters(filters, SearchFacetPricingModel))
+
+	rows, err := db.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to count search facets: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var facet string
+		var value SearchFacetValue
+		if err := rows.Scan(&facet, &value.Value, &value.Count); err != nil {
+			return fmt.Errorf("failed to scan search facet: %w", err)
+		}
+
+		switch facet {
+		case searchFacetTotal:
+			result.Total = value.Count
+		case SearchFacetProvider:
+			result.Facets.Providers = append(result.Facets.Providers, value)
+		case SearchFacetRegion:
+			result.Facets.Regions = append(result.Facets.Regions, value)
+		case SearchFacetServiceCategory:
+			result.Facets.ServiceCategories = append(result.Facets.ServiceCategories, value)
+		case SearchFacetPricingModel:
+			result.Facets.PricingModels = append(result.Facets.PricingModels, value)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read search facets: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Processor vendors derived from the processor type of an instance
+const (
+	ProcessorVendorIntel  = "intel"
+	ProcessorVendorAMD    = "amd"
+	ProcessorVendorAWS    = "aws" // Graviton
+	ProcessorVendorAmpere = "ampere"
+)
+
+// SpecFilter selects prices by their resource specs. Ranges are inclusive and unset bounds
+// are open; a price that does not state a constrained spec does not match. Instances that
+// state no GPU count have none, so MinGPUCount 1 asks for any GPU and MaxGPUCount 0 for
+// CPU-only instances.
+type SpecFilter struct {
+	MinVCPU          *int     `json:"minVcpu,omitempty"`
+	MaxVCPU          *int     `json:"maxVcpu,omitempty"`
+	MinMemoryGB      *float64 `json:"minMemoryGb,omitempty"`
+	MaxMemoryGB      *float64 `json:"maxMemoryGb,omitempty"`
+	MinGPUCount      *int     `json:"minGpuCount,omitempty"`
+	MaxGPUCount      *int     `json:"maxGpuCount,omitempty"`
+	MinGPUMemoryGB   *float64 `json:"minGpuMemoryGb,omitempty"`
+	MaxGPUMemoryGB   *float64 `json:"maxGpuMemoryGb,omitempty"`
+	MinStorageGB     *float64 `json:"minStorageGb,omitempty"`
+	MaxStorageGB     *float64 `json:"maxStorageGb,omitempty"`
+	MinClockSpeedGHz *float64 `json:"minClockSpeedGhz,omitempty"`
+	MaxClockSpeedGHz *float64 `json:"maxClockSpeedGhz,omitempty"`
+	Architecture     *string  `json:"architecture,omitempty"`    // arm64 or x86_64; aliases such as aarch64 are accepted
+	Burstable        *bool    `json:"burstable,omitempty"`       // Instances running on CPU credits
+	ProcessorVendor  *string  `json:"processorVendor,omitempty"` // intel, amd, aws or ampere
+}
+
+// specGPUCount is the GPU count of an instance. The spec extractors leave out a count of 0,
+// so an instance (a price with vCPUs) without a count has no GPU.
+const specGPUCount = "COALESCE(spec_gpu_count, CASE WHEN spec_vcpu IS NOT NULL THEN 0 END)"
+
+// SpecPredicate compares a typed spec column of normalized pricing, or an expression over the
+// spec columns, with a value. Both the Postgres and the snapshot tables have the spec columns.
+type SpecPredicate struct {
+	Column   string
+	Operator string
+	Value    interface{}
+}
+
+// Validate checks that the bounds are not negative and every minimum is at most its maximum
+func (f SpecFilter) Validate() error {
+	type bounds struct {
+		name     string
+		min, max *float64
+	}
+	intBound := func(value *int) *float64 {
+		if value == nil {
+			return nil
+		}
+		converted := float64(*value)
+		return &converted
+	}
+
+	for _, b := range []bounds{
+		{"vcpu", intBound(f.MinVCPU), intBound(f.MaxVCPU)},
+		{"memoryGb", f.MinMemoryGB, f.MaxMemoryGB},
+		{"gpuCount", intBound(f.MinGPUCount), intBound(f.MaxGPUCount)},
+		{"gpuMemoryGb", f.MinGPUMemoryGB, f.MaxGPUMemoryGB},
+		{"storageGb", f.MinStorageGB, f.MaxStorageGB},
+		{"clockSpeedGhz", f.MinClockSpeedGHz, f.MaxClockSpeedGHz},
+	} {
+		if (b.min != nil && *b.min < 0) || (b.max != nil && *b.max < 0) {
+			return fmt.Errorf("%s bounds must not be negative", b.name)
+		}
+		if b.min != nil && b.max != nil && *b.min > *b.max {
+			return fmt.Errorf("minimum %s %g is above the maximum %g", b.name, *b.min, *b.max)
+		}
+	}
+
+	if f.ProcessorVendor != nil {
+		switch strings.ToLower(*f.ProcessorVendor) {
+		case ProcessorVendorIntel, ProcessorVendorAMD, ProcessorVendorAWS, ProcessorVendorAmpere:
+		default:
+			return fmt.Errorf("unsupported processor vendor: %s", *f.ProcessorVendor)
+		}
+	}
+	return nil
+}
+
+// Validate checks the spec filter and the ordering of the filter
+func (filter PricingFilter) Validate() error {
+	if filter.Specs != nil {
+		if err := filter.Specs.Validate(); err != nil {
+			return err
+		}
+	}
+	return filter.ValidateOrder()
+}
+
+// SpecPredicates returns the comparisons of the filter on the spec columns: the exact vCPU
+// count of ResourceSpecs and the constraints of Specs
+func (filter PricingFilter) SpecPredicates() []SpecPredicate {
+	var predicates []SpecPredicate
+	add := func(column, operator string, value interface{}) {
+		predicates = append(predicates, SpecPredicate{Column: column, Operator: operator, Value: value})
+	}
+	addInt := func(column, operator string, value *int) {
+		if value != nil {
+			add(column, operator, *value)
+		}
+	}
+	addFloat := func(column, operator string, value *float64) {
+		if value != nil {
+			add(column, operator, *value)
+		}
+	}
+
+	if filter.ResourceSpecs != nil {
+		addInt("spec_vcpu", "=", filter.ResourceSpecs.VCPU)
+	}
+
+	specs := filter.Specs
+	if specs == nil {
+		return predicates
+	}
+	addInt("spec_vcpu", ">=", specs.MinVCPU)
+	addInt("spec_vcpu", "<=", specs.MaxVCPU)
+	addFloat("spec_memory_gb", ">=", specs.MinMemoryGB)
+	addFloat("spec_memory_gb", "<=", specs.MaxMemoryGB)
+	addInt(specGPUCount, ">=", specs.MinGPUCount)
+	addInt(specGPUCount, "<=", specs.MaxGPUCount)
+	addFloat("spec_gpu_memory_gb", ">=", specs.MinGPUMemoryGB)
+	addFloat("spec_gpu_memory_gb", "<=", specs.MaxGPUMemoryGB)
+	addFloat("spec_storage_gb", ">=", specs.MinStorageGB)
+	addFloat("spec_storage_gb", "<=", specs.MaxStorageGB)
+	addFloat("spec_clock_speed_ghz", ">=", specs.MinClockSpeedGHz)
+	addFloat("spec_clock_speed_ghz", "<=", specs.MaxClockSpeedGHz)
+	if specs.Architecture != nil {
+		add("spec_architecture", "=", NormalizeArchitecture(*specs.Architecture))
+	}
+	if specs.Burstable != nil {
+		add("spec_burstable", "=", *specs.Burstable)
+	}
+	if specs.ProcessorVendor != nil {
+		add("spec_processor_vendor", "=", strings.ToLower(*specs.ProcessorVendor))
+	}
+	return predicates
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpecFilter_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		filter        SpecFilter
+		expectedError string
+	}{
+		{
+			name:   "empty filter",
+			filter: SpecFilter{},
+		},
+		{
+			name: "valid ranges",
+			filter: SpecFilter{
+				MinVCPU:          intPtr(2),
+				MaxVCPU:          intPtr(8),
+				MinMemoryGB:      floatPtr(0.5),
+				MaxMemoryGB:      floatPtr(64),
+				MinGPUCount:      intPtr(1),
+				MinClockSpeedGHz: floatPtr(2.5),
+				MaxClockSpeedGHz: floatPtr(2.5),
+			},
+		},
+		{
+			name:          "negative minimum",
+			filter:        SpecFilter{MinMemoryGB: floatPtr(-1)},
+			expectedError: "memoryGb bounds must not be negative",
+		},
+		{
+			name:          "negative maximum",
+			filter:        SpecFilter{MaxGPUCount: intPtr(-1)},
+			expectedError: "gpuCount bounds must not be negative",
+		},
+		{
+			name:          "minimum above maximum",
+			filter:        SpecFilter{MinVCPU: intPtr(16), MaxVCPU: intPtr(8)},
+			expectedError: "minimum vcpu 16 is above the maximum 8",
+		},
+		{
+			name:          "fractional minimum above maximum",
+			filter:        SpecFilter{MinStorageGB: floatPtr(100.5), MaxStorageGB: floatPtr(100)},
+			expectedError: "minimum storageGb 100.5 is above the maximum 100",
+		},
+		{
+			name:   "processor vendor in any case",
+			filter: SpecFilter{ProcessorVendor: stringPtr("AMD")},
+		},
+		{
+			name:          "unsupported processor vendor",
+			filter:        SpecFilter{ProcessorVendor: stringPtr("arm")},
+			expectedError: "unsupported processor vendor: arm",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestPricingFilter_SpecPredicates(t *testing.T) {
+	burstable := false
+
+	tests := []struct {
+		name     string
+		filter   PricingFilter
+		expected []SpecPredicate
+	}{
+		{
+			name:   "no spec constraints",
+			filter: PricingFilter{},
+		},
+		{
+			name:   "exact vCPU count of the resource specs",
+			filter: PricingFilter{ResourceSpecs: &ResourceSpecs{VCPU: intPtr(4), MemoryGB: floatPtr(16)}},
+			expected: []SpecPredicate{
+				{Column: "spec_vcpu", Operator: "=", Value: 4},
+			},
+		},
+		{
+			name: "ranges in column order",
+			filter: PricingFilter{Specs: &SpecFilter{
+				MaxClockSpeedGHz: floatPtr(3.5),
+				MinVCPU:          intPtr(2),
+				MaxVCPU:          intPtr(8),
+				MinMemoryGB:      floatPtr(4),
+				MinGPUCount:      intPtr(1),
+				MaxGPUMemoryGB:   floatPtr(80),
+				MinStorageGB:     floatPtr(100),
+			}},
+			expected: []SpecPredicate{
+				{Column: "spec_vcpu", Operator: ">=", Value: 2},
+				{Column: "spec_vcpu", Operator: "<=", Value: 8},
+				{Column: "spec_memory_gb", Operator: ">=", Value: 4.0},
+				{Column: specGPUCount, Operator: ">=", Value: 1},
+				{Column: "spec_gpu_memory_gb", Operator: "<=", Value: 80.0},
+				{Column: "spec_storage_gb", Operator: ">=", Value: 100.0},
+				{Column: "spec_clock_speed_ghz", Operator: "<=", Value: 3.5},
+			},
+		},
+		{
+			name: "platform values are normalized",
+			filter: PricingFilter{Specs: &SpecFilter{
+				Architecture:    stringPtr(" AArch64 "),
+				Burstable:       &burstable,
+				ProcessorVendor: stringPtr("Intel"),
+			}},
+			expected: []SpecPredicate{
+				{Column: "spec_architecture", Operator: "=", Value: "arm64"},
+				{Column: "spec_burstable", Operator: "=", Value: false},
+				{Column: "spec_processor_vendor", Operator: "=", Value: "intel"},
+			},
+		},
+		{
+			name: "resource specs and spec ranges combine",
+			filter: PricingFilter{
+				ResourceSpecs: &ResourceSpecs{VCPU: intPtr(4)},
+				Specs:         &SpecFilter{Architecture: stringPtr("amd64")},
+			},
+			expected: []SpecPredicate{
+				{Column: "spec_vcpu", Operator: "=", Value: 4},
+				{Column: "spec_architecture", Operator: "=", Value: "x86_64"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.filter.SpecPredicates())
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package etl
+
+import (
+	"github.com/raulc0399/cpc/internal/normalizer"
+)
+
+// evaluatePriceAlerts runs the alert rules against the price changes the job recorded in the
+// price history and delivers the matches. Like the history sync, failures are logged without
+// failing the job. The notifier's evaluation timeout keeps slow webhooks from holding up the job.
+func (p *Pipeline) evaluatePriceAlerts(job *Job) {
+	if job.PriceHistory == nil || job.PriceHistory.Opened == 0 {
+		return
+	}
+	job.setStage("Evaluating price alerts")
+
+	result, err := p.notifier.Evaluate(job.ctx, job.ID)
+	if err != nil {
+		p.jobLogger(job).Error("Failed to evaluate price alerts",
+			normalizer.Field{Key: "error", Value: err},
+		)
+	}
+	if result == nil {
+		return
+	}
+
+	job.PriceAlerts = result
+	p.jobLogger(job).Info("Evaluated price alerts",
+		normalizer.Field{Key: "rulesEvaluated", Value: result.RulesEvaluated},
+		normalizer.Field{Key: "rulesTriggered", Value: result.RulesTriggered},
+		normalizer.Field{Key: "deliveriesSucceeded", Value: result.DeliveriesSucceeded},
+		normalizer.Field{Key: "deliveriesFailed", Value: result.DeliveriesFailed},
+	)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/raulc0399/cpc/internal/database"
 	"github.com/raulc0399/cpc/internal/normalizer"
@@ -13,26 +14,34 @@ import (
 
 // normalizeAWSData normalizes all AWS raw pricing data
 func (p *Pipeline) normalizeAWSData(job *Job) error {
-	job.Progress.CurrentStage = "Counting AWS raw records"
-	job.Progress.LastUpdated = now()
+	job.setProviderStage(database.ProviderAWS, "Counting AWS raw records")
 	
 	// Get total count for progress tracking
+	countStart := now()
 	totalCount, err := p.getAWSRawDataCount(job.ctx, job.Configuration)
 	if err != nil {
 		return fmt.Errorf("failed to count AWS raw data: %w", err)
 	}
+	job.recordStage(database.ProviderAWS, StageCount, totalCount, time.Since(countStart))
 	
-	job.Progress.TotalRecords = totalCount
-	job.Progress.CurrentStage = "Processing AWS raw data"
+	job.setProviderTotal(database.ProviderAWS, totalCount)
+	job.setProviderStage(database.ProviderAWS, "Processing AWS raw data")
 	
-	p.logger.Info("Starting AWS normalization",
+	p.providerLogger(job, database.ProviderAWS).Info("Starting AWS normalization",
 		normalizer.Field{"totalRecords", totalCount},
-		normalizer.Field{"batchSize", job.Configuration.BatchSize},
-		normalizer.Field{"workers", job.Configuration.ConcurrentWorkers},
+		normalizer.Field{"batchSize", job.Configuration.BatchSizeFor(database.ProviderAWS)},
+		normalizer.Field{"workers", job.Configuration.WorkersFor(database.ProviderAWS)},
 	)
 	
 	// Process data in batches with concurrent workers
-	return p.processAWSDataInBatches(job)
+	if err := p.processAWSDataInBatches(job); err != nil {
+		job.setProviderStage(database.ProviderAWS, "Failed")
+		return err
+	}
+	
+	job.setProviderStage(database.ProviderAWS, "Completed")
+	job.finishProvider(database.ProviderAWS)
+	return nil
 }
 
 // getAWSRawDataCount counts AWS raw pricing records matching the job configuration
@@ -52,7 +61,9 @@ func (p *Pipeline) getAWSRawDataCount(ctx context.Context, config JobConfigurati
 	}
 	
 	if !tableExists {
-		p.logger.Warn("AWS pricing raw table does not exist, skipping AWS normalization")
+		normalizer.LoggerFromContext(ctx, p.logger).Warn("AWS pricing raw table does not exist, skipping AWS normalization",
+			normalizer.Field{normalizer.LogKeyProvider, database.ProviderAWS},
+		)
 		return 0, nil
 	}
 	
@@ -89,13 +100,13 @@ func (p *Pipeline) getAWSRawDataCount(ctx context.Context, config JobConfigurati
 
 // processAWSDataInBatches processes AWS data in batches with concurrent workers
 func (p *Pipeline) processAWSDataInBatches(job *Job) error {
-	if job.Progress.TotalRecords == 0 {
-		p.logger.Info("No AWS data to process")
+	if job.providerTotal(database.ProviderAWS) == 0 {
+		p.providerLogger(job, database.ProviderAWS).Info("No AWS data to process")
 		return nil
 	}
 	
-	batchSize := job.Configuration.BatchSize
-	workerCount := job.Configuration.ConcurrentWorkers
+	batchSize := job.Configuration.BatchSizeFor(database.ProviderAWS)
+	workerCount := job.Configuration.WorkersFor(database.ProviderAWS)
 	
 	// Create worker pool
 	batchChan := make(chan *AWSBatch, workerCount*2)
@@ -126,6 +137,13 @@ func (p *Pipeline) processAWSDataInBatches(job *Job) error {
 		default:
 		}
 		
+		// Skip batches already committed before the job was interrupted
+		if job.isBatchCompleted(database.ProviderAWS, offset) {
+			offset += batchSize
+			continue
+		}
+		
+		fetchStart := now()
 		batch, err := p.getAWSBatch(job.ctx, job.Configuration, offset, batchSize)
 		if err != nil {
 			close(batchChan)
@@ -135,6 +153,8 @@ func (p *Pipeline) processAWSDataInBatches(job *Job) error {
 			return fmt.Errorf("failed to get AWS batch at offset %d: %w", offset, err)
 		}
 		
+		job.recordStage(database.ProviderAWS, StageFetch, len(batch.Records), time.Since(fetchStart))
+		
 		if len(batch.Records) == 0 {
 			break // No more data
 		}
@@ -250,11 +270,13 @@ func (p *Pipeline) awsWorker(job *Job, batchChan <-chan *AWSBatch, resultChan ch
 // processAWSBatch processes a single batch of AWS data
 func (p *Pipeline) processAWSBatch(job *Job, batch *AWSBatch) *BatchResult {
 	result := &BatchResult{
+		Provider:    database.ProviderAWS,
 		BatchOffset: batch.Offset,
 		Errors:      []string{},
 	}
 	
 	var normalizedRecords []database.NormalizedPricing
+	normalizeStart := now()
 	
 	for _, record := range batch.Records {
 		result.ProcessedRecords++
@@ -291,9 +313,22 @@ func (p *Pipeline) processAWSBatch(job *Job, batch *AWSBatch) *BatchResult {
 		result.NormalizedRecords += len(normResult.NormalizedRecords)
 	}
 	
-	// Insert normalized records if not a dry run
-	if !job.Configuration.DryRun && len(normalizedRecords) > 0 {
-		err := p.pricingRepo.BulkInsert(job.ctx, normalizedRecords)
+	job.recordStage(database.ProviderAWS, StageNormalize, result.ProcessedRecords, time.Since(normalizeStart))
+	
+	// Collect candidates for the diff report on dry runs
+	if job.dryRun != nil {
+		job.dryRun.Add(normalizedRecords)
+	}
+	
+	// Insert normalized records if not a dry run, checkpointing the batch when enabled
+	if !job.Configuration.DryRun && (len(normalizedRecords) > 0 || job.checkpointing) {
+		checkpoint := newBatchCheckpoint(job, database.ProviderAWS, batch.Offset, result)
+		checkpoint.FirstRawID = &batch.Records[0].ID
+		checkpoint.LastRawID = &batch.Records[len(batch.Records)-1].ID
+		
+		insertStart := now()
+		err := p.commitBatch(job, normalizedRecords, checkpoint)
+		job.recordStage(database.ProviderAWS, StageInsert, len(normalizedRecords), time.Since(insertStart))
 		if err != nil {
 			result.ErrorRecords += len(normalizedRecords)
 			result.NormalizedRecords -= len(normalizedRecords)
@@ -324,7 +359,7 @@ func (p *Pipeline) normalizeRegion(job *Job) error {
 		}
 		
 		job.Provider = provider
-		job.Progress.CurrentStage = fmt.Sprintf("Processing %s regions: %v", provider, job.Configuration.Regions)
+		job.setStage(fmt.Sprintf("Processing %s regions: %v", provider, job.Configuration.Regions))
 		
 		if err := p.normalizeProviderData(job, provider); err != nil {
 			return fmt.Errorf("failed to normalize %s regions: %w", provider, err)
@@ -354,7 +389,7 @@ func (p *Pipeline) normalizeService(job *Job) error {
 		}
 		
 		job.Provider = provider
-		job.Progress.CurrentStage = fmt.Sprintf("Processing %s services: %v", provider, job.Configuration.Services)
+		job.setStage(fmt.Sprintf("Processing %s services: %v", provider, job.Configuration.Services))
 		
 		if err := p.normalizeProviderData(job, provider); err != nil {
 			return fmt.Errorf("failed to normalize %s services: %w", provider, err)
@@ -366,8 +401,7 @@ func (p *Pipeline) normalizeService(job *Job) error {
 
 // cleanupNormalized removes old/invalid normalized data
 func (p *Pipeline) cleanupNormalized(job *Job) error {
-	job.Progress.CurrentStage = "Cleaning up normalized data"
-	job.Progress.LastUpdated = now()
+	job.setStage("Cleaning up normalized data")
 	
 	// Remove normalized records without corresponding raw data
 	query := `
@@ -383,7 +417,7 @@ func (p *Pipeline) cleanupNormalized(job *Job) error {
 	rowsAffected, _ := result.RowsAffected()
 	job.Progress.ProcessedRecords = int(rowsAffected)
 	
-	p.logger.Info("Cleaned up normalized data",
+	p.jobLogger(job).Info("Cleaned up normalized data",
 		normalizer.Field{"removedRecords", rowsAffected},
 	)
 	
@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/raulc0399/cpc/internal/database"
 	"github.com/raulc0399/cpc/internal/normalizer"
@@ -13,26 +15,87 @@ import (
 
 // normalizeAzureData normalizes all Azure raw pricing data
 func (p *Pipeline) normalizeAzureData(job *Job) error {
-	job.Progress.CurrentStage = "Counting Azure raw records"
-	job.Progress.LastUpdated = now()
+	job.setProviderStage(database.ProviderAzure, "Counting Azure raw records")
+	
+	// Only report the VM sizes seen by this job
+	p.azureNormalizer.ResetUnknownVMSizes()
 	
 	// Get total count for progress tracking
+	countStart := now()
 	totalCount, err := p.getAzureRawDataCount(job.ctx, job.Configuration)
 	if err != nil {
 		return fmt.Errorf("failed to count Azure raw data: %w", err)
 	}
+	job.recordStage(database.ProviderAzure, StageCount, totalCount, time.Since(countStart))
 	
-	job.Progress.TotalRecords = totalCount
-	job.Progress.CurrentStage = "Processing Azure raw data"
+	job.setProviderTotal(database.ProviderAzure, totalCount)
+	job.setProviderStage(database.ProviderAzure, "Processing Azure raw data")
 	
-	p.logger.Info("Starting Azure normalization",
+	p.providerLogger(job, database.ProviderAzure).Info("Starting Azure normalization",
 		normalizer.Field{"totalRecords", totalCount},
-		normalizer.Field{"batchSize", job.Configuration.BatchSize},
-		normalizer.Field{"workers", job.Configuration.ConcurrentWorkers},
+		normalizer.Field{"batchSize", job.Configuration.BatchSizeFor(database.ProviderAzure)},
+		normalizer.Field{"workers", job.Configuration.WorkersFor(database.ProviderAzure)},
 	)
 	
 	// Process data in batches with concurrent workers
-	return p.processAzureDataInBatches(job)
+	if err := p.processAzureDataInBatches(job); err != nil {
+		job.setProviderStage(database.ProviderAzure, "Failed")
+		return err
+	}
+	
+	p.reportUnknownAzureVMSizes(job)
+	
+	// Azure returns each price tier as a separate item; merge them once all batches are in.
+	// Dry runs merge their candidates when the diff report is built.
+	if !job.Configuration.DryRun {
+		job.setProviderStage(database.ProviderAzure, "Merging Azure price tiers")
+		if err := p.consolidateAzurePriceTiers(job); err != nil {
+			job.setProviderStage(database.ProviderAzure, "Failed")
+			return err
+		}
+	}
+	
+	job.setProviderStage(database.ProviderAzure, "Completed")
+	job.finishProvider(database.ProviderAzure)
+	return nil
+}
+
+// consolidateAzurePriceTiers merges the Azure tier records written to the job's target table,
+// limited to the regions and services of the job
+func (p *Pipeline) consolidateAzurePriceTiers(job *Job) error {
+	table := job.targetTable
+	if table == "" {
+		table = database.NormalizedPricingTable
+	}
+	
+	merged, err := p.db.ConsolidateAzurePriceTiers(job.ctx, table, job.Configuration.Regions, job.Configuration.Services)
+	if err != nil {
+		if job.targetTable != "" {
+			job.stagingFailed.Store(true)
+		}
+		return fmt.Errorf("failed to merge Azure price tiers: %w", err)
+	}
+	p.providerLogger(job, database.ProviderAzure).Info("Merged Azure price tiers", normalizer.Field{"mergedRecords", merged})
+	return nil
+}
+
+// reportUnknownAzureVMSizes logs the VM sizes that were missing from the size catalog
+func (p *Pipeline) reportUnknownAzureVMSizes(job *Job) {
+	unknown := p.azureNormalizer.UnknownVMSizes()
+	if len(unknown) == 0 {
+		return
+	}
+	
+	sizes := make([]string, 0, len(unknown))
+	for size := range unknown {
+		sizes = append(sizes, size)
+	}
+	sort.Strings(sizes)
+	
+	p.providerLogger(job, database.ProviderAzure).Warn("Azure VM sizes missing from size catalog",
+		normalizer.Field{"count", len(sizes)},
+		normalizer.Field{"sizes", strings.Join(sizes, ", ")},
+	)
 }
 
 // getAzureRawDataCount counts Azure raw pricing records matching the job configuration
@@ -70,8 +133,8 @@ func (p *Pipeline) getAzureRawDataCount(ctx context.Context, config JobConfigura
 
 // processAzureDataInBatches processes Azure data in batches with concurrent workers
 func (p *Pipeline) processAzureDataInBatches(job *Job) error {
-	batchSize := job.Configuration.BatchSize
-	workerCount := job.Configuration.ConcurrentWorkers
+	batchSize := job.Configuration.BatchSizeFor(database.ProviderAzure)
+	workerCount := job.Configuration.WorkersFor(database.ProviderAzure)
 	
 	// Create worker pool
 	batchChan := make(chan *AzureBatch, workerCount*2)
@@ -102,6 +165,13 @@ func (p *Pipeline) processAzureDataInBatches(job *Job) error {
 		default:
 		}
 		
+		// Skip batches already committed before the job was interrupted
+		if job.isBatchCompleted(database.ProviderAzure, offset) {
+			offset += batchSize
+			continue
+		}
+		
+		fetchStart := now()
 		batch, err := p.getAzureBatch(job.ctx, job.Configuration, offset, batchSize)
 		if err != nil {
 			close(batchChan)
@@ -111,6 +181,8 @@ func (p *Pipeline) processAzureDataInBatches(job *Job) error {
 			return fmt.Errorf("failed to get batch at offset %d: %w", offset, err)
 		}
 		
+		job.recordStage(database.ProviderAzure, StageFetch, len(batch.Records), time.Since(fetchStart))
+		
 		if len(batch.Records) == 0 {
 			break // No more data
 		}
@@ -146,6 +218,7 @@ type AzureRawRecord struct {
 
 // BatchResult represents the result of processing a batch
 type BatchResult struct {
+	Provider          string
 	BatchOffset       int
 	ProcessedRecords  int
 	NormalizedRecords int
@@ -236,11 +309,13 @@ func (p *Pipeline) azureWorker(job *Job, batchChan <-chan *AzureBatch, resultCha
 // processAzureBatch processes a single batch of Azure data
 func (p *Pipeline) processAzureBatch(job *Job, batch *AzureBatch) *BatchResult {
 	result := &BatchResult{
+		Provider:    database.ProviderAzure,
 		BatchOffset: batch.Offset,
 		Errors:      []string{},
 	}
 	
 	var normalizedRecords []database.NormalizedPricing
+	normalizeStart := now()
 	
 	for _, record := range batch.Records {
 		result.ProcessedRecords++
@@ -283,9 +358,22 @@ func (p *Pipeline) processAzureBatch(job *Job, batch *AzureBatch) *BatchResult {
 		result.NormalizedRecords += len(normResult.NormalizedRecords)
 	}
 	
-	// Insert normalized records if not a dry run
-	if !job.Configuration.DryRun && len(normalizedRecords) > 0 {
-		err := p.pricingRepo.BulkInsert(job.ctx, normalizedRecords)
+	job.recordStage(database.ProviderAzure, StageNormalize, result.ProcessedRecords, time.Since(normalizeStart))
+	
+	// Collect candidates for the diff report on dry runs
+	if job.dryRun != nil {
+		job.dryRun.Add(normalizedRecords)
+	}
+	
+	// Insert normalized records if not a dry run, checkpointing the batch when enabled
+	if !job.Configuration.DryRun && (len(normalizedRecords) > 0 || job.checkpointing) {
+		checkpoint := newBatchCheckpoint(job, database.ProviderAzure, batch.Offset, result)
+		checkpoint.FirstRawID = &batch.Records[0].ID
+		checkpoint.LastRawID = &batch.Records[len(batch.Records)-1].ID
+		
+		insertStart := now()
+		err := p.commitBatch(job, normalizedRecords, checkpoint)
+		job.recordStage(database.ProviderAzure, StageInsert, len(normalizedRecords), time.Since(insertStart))
 		if err != nil {
 			result.ErrorRecords += len(normalizedRecords)
 			result.NormalizedRecords -= len(normalizedRecords)
@@ -301,7 +389,8 @@ func (p *Pipeline) collectResults(job *Job, resultChan <-chan *BatchResult, wg *
 	defer wg.Done()
 	
 	for result := range resultChan {
-		p.updateJobProgress(job, 
+		processed := p.updateJobProgress(job,
+			result.Provider,
 			result.ProcessedRecords,
 			result.NormalizedRecords,
 			result.SkippedRecords,
@@ -310,16 +399,17 @@ func (p *Pipeline) collectResults(job *Job, resultChan <-chan *BatchResult, wg *
 		
 		// Log errors if any
 		for _, errMsg := range result.Errors {
-			p.logger.Error("Batch processing error", normalizer.Field{"error", errMsg})
+			p.providerLogger(job, result.Provider).Error("Batch processing error", normalizer.Field{"error", errMsg})
 		}
 		
 		// Log progress periodically
-		if job.Progress.ProcessedRecords%10000 == 0 {
-			p.logger.Info("Azure normalization progress",
-				normalizer.Field{"processed", job.Progress.ProcessedRecords},
-				normalizer.Field{"total", job.Progress.TotalRecords},
-				normalizer.Field{"normalized", job.Progress.NormalizedRecords},
-				normalizer.Field{"rate", job.Progress.Rate},
+		if processed%10000 == 0 {
+			progress := job.ProgressSnapshot()
+			p.providerLogger(job, result.Provider).Info("Normalization progress",
+				normalizer.Field{"processed", progress.ProcessedRecords},
+				normalizer.Field{"total", progress.TotalRecords},
+				normalizer.Field{"normalized", progress.NormalizedRecords},
+				normalizer.Field{"rate", progress.Rate},
 			)
 		}
 	}
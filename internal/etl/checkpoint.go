@@ -0,0 +1,367 @@
+package etl
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/raulc0399/cpc/internal/normalizer"
+)
+
+// batchKey identifies a batch of a provider's raw data within a job
+func batchKey(provider string, offset int) string {
+	return provider + "|" + strconv.Itoa(offset)
+}
+
+// isBatchCompleted reports whether a batch was committed before the job was interrupted
+func (j *Job) isBatchCompleted(provider string, offset int) bool {
+	return j.completedBatches[batchKey(provider, offset)]
+}
+
+// newBatchCheckpoint creates the checkpoint of a processed batch
+func newBatchCheckpoint(job *Job, provider string, offset int, result *BatchResult) database.ETLBatchCheckpoint {
+	return database.ETLBatchCheckpoint{
+		JobID:             job.ID,
+		Provider:          provider,
+		BatchOffset:       offset,
+		BatchSize:         job.Configuration.BatchSizeFor(provider),
+		ProcessedRecords:  result.ProcessedRecords,
+		NormalizedRecords: result.NormalizedRecords,
+		SkippedRecords:    result.SkippedRecords,
+		ErrorRecords:      result.ErrorRecords,
+	}
+}
+
+// commitBatch writes normalized records to the job's target table, together with the
+// batch checkpoint when checkpointing is enabled
+func (p *Pipeline) commitBatch(job *Job, records []database.NormalizedPricing, checkpoint database.ETLBatchCheckpoint) error {
+	table := job.targetTable
+	if table == "" {
+		table = database.NormalizedPricingTable
+	}
+
+	var err error
+	switch {
+	case job.checkpointing:
+		err = p.db.BulkInsertNormalizedPricingWithCheckpoint(table, records, checkpoint)
+	case job.targetTable != "":
+		err = p.db.BulkInsertNormalizedPricingInto(table, records)
+	default:
+		err = p.pricingRepo.BulkInsert(job.ctx, records)
+	}
+
+	if err != nil && job.targetTable != "" {
+		job.stagingFailed.Store(true)
+	}
+
+	return err
+}
+
+// persistJob saves the job state so it can be recovered after a restart
+func (p *Pipeline) persistJob(job *Job) error {
+	configuration, err := json.Marshal(job.Configuration)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job configuration: %w", err)
+	}
+
+	record := database.ETLJobRecord{
+		ID:            job.ID,
+		JobType:       string(job.Type),
+		Status:        string(job.Status),
+		Configuration: configuration,
+		StartedAt:     job.StartedAt,
+		CompletedAt:   job.CompletedAt,
+	}
+	if job.Provider != "" {
+		record.Provider = &job.Provider
+	}
+	if job.targetTable != "" {
+		record.TargetTable = &job.targetTable
+	}
+	if job.Error != "" {
+		record.Error = &job.Error
+	}
+
+	// The job context may already be cancelled when recording the final state
+	return p.db.SaveETLJob(context.Background(), record)
+}
+
+// saveJobState persists the job state of checkpointed jobs, logging failures
+func (p *Pipeline) saveJobState(job *Job) {
+	if !job.checkpointing {
+		return
+	}
+
+	if err := p.persistJob(job); err != nil {
+		p.jobLogger(job).Error("Failed to persist ETL job state",
+			normalizer.Field{"error", err},
+		)
+	}
+}
+
+// RecoverInterruptedJobs finds jobs that were pending or running when the process stopped and
+// marks them as interrupted. Recovered jobs can be resumed with ResumeJob.
+func (p *Pipeline) RecoverInterruptedJobs(ctx context.Context) ([]*Job, error) {
+	records, err := p.db.GetETLJobsByStatus(ctx, string(StatusPending), string(StatusRunning))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load unfinished ETL jobs: %w", err)
+	}
+
+	var recovered []*Job
+	for _, record := range records {
+		var config JobConfiguration
+		if err := json.Unmarshal(record.Configuration, &config); err != nil {
+			p.logger.Error("Skipping ETL job with invalid configuration",
+				normalizer.Field{normalizer.LogKeyJobID, record.ID},
+				normalizer.Field{"error", err},
+			)
+			continue
+		}
+
+		job := &Job{
+			ID:            record.ID,
+			Type:          JobType(record.JobType),
+			Status:        StatusInterrupted,
+			StartedAt:     record.StartedAt,
+			Configuration: config,
+			Progress: &JobProgress{
+				CurrentStage: "Interrupted",
+				LastUpdated:  now(),
+			},
+			checkpointing: true,
+		}
+		if record.Provider != nil {
+			job.Provider = *record.Provider
+		}
+		if record.TargetTable != nil {
+			job.targetTable = *record.TargetTable
+		}
+
+		if err := p.restoreCheckpoints(ctx, job); err != nil {
+			return nil, err
+		}
+
+		p.saveJobState(job)
+
+		p.mu.Lock()
+		if _, running := p.runningJobs[job.ID]; !running {
+			p.retainFinishedJob(job)
+			recovered = append(recovered, job)
+		}
+		p.mu.Unlock()
+
+		p.jobLogger(job).Info("Recovered interrupted ETL job",
+			normalizer.Field{"completedBatches", len(job.completedBatches)},
+			normalizer.Field{"processed", job.Progress.ProcessedRecords},
+		)
+	}
+
+	return recovered, nil
+}
+
+// restoreCheckpoints loads the committed batches of a job and restores its progress counters
+func (p *Pipeline) restoreCheckpoints(ctx context.Context, job *Job) error {
+	checkpoints, err := p.db.GetETLJobCheckpoints(ctx, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoints of job %s: %w", job.ID, err)
+	}
+
+	p.applyCheckpoints(job, checkpoints)
+	return nil
+}
+
+// applyCheckpoints marks the checkpointed batches of a job as completed, so resuming skips
+// them, and adds their counts to the job progress
+func (p *Pipeline) applyCheckpoints(job *Job, checkpoints []database.ETLBatchCheckpoint) {
+	job.completedBatches = make(map[string]bool, len(checkpoints))
+	for _, checkpoint := range checkpoints {
+		job.completedBatches[batchKey(checkpoint.Provider, checkpoint.BatchOffset)] = true
+		p.updateJobProgress(job,
+			checkpoint.Provider,
+			checkpoint.ProcessedRecords,
+			checkpoint.NormalizedRecords,
+			checkpoint.SkippedRecords,
+			checkpoint.ErrorRecords,
+		)
+	}
+}
+
+// ResumeJob restarts an interrupted job from its last committed batches
+func (p *Pipeline) ResumeJob(jobID string) (*Job, error) {
+	p.mu.Lock()
+	job, exists := p.finishedJobs[jobID]
+	if !exists {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("job not found: %s", jobID)
+	}
+	if job.Status != StatusInterrupted {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("cannot resume job in status: %s", job.Status)
+	}
+	if err := p.checkRenormalizationConflict(job); err != nil {
+		p.mu.Unlock()
+		return nil, err
+	}
+	delete(p.finishedJobs, jobID)
+	p.mu.Unlock()
+
+	// Reload the checkpoints so the counters match what was committed
+	job.Progress = &JobProgress{
+		CurrentStage: "Resuming",
+		LastUpdated:  now(),
+	}
+	if err := p.restoreCheckpoints(context.Background(), job); err != nil {
+		p.mu.Lock()
+		p.retainFinishedJob(job)
+		p.mu.Unlock()
+		return nil, err
+	}
+
+	// A staging table lost during the restart cannot be resumed into
+	restart := false
+	if job.targetTable != "" {
+		exists, err := p.db.NormalizedPricingStagingExists(context.Background())
+		if err != nil || !exists {
+			p.jobLogger(job).Warn("Staging table missing, restarting re-normalization from scratch")
+			restart = true
+		}
+	}
+
+	// Raw data collected or removed since the interruption shifts the batch offsets, so the
+	// committed batches no longer identify the raw records they covered
+	if !restart {
+		valid, err := p.checkpointsMatchRawData(context.Background(), job)
+		if err != nil {
+			p.mu.Lock()
+			p.retainFinishedJob(job)
+			p.mu.Unlock()
+			return nil, err
+		}
+		if !valid {
+			p.jobLogger(job).Warn("Raw data changed since the job was interrupted, restarting it from scratch")
+			restart = true
+		}
+	}
+
+	if restart {
+		if err := p.db.DeleteETLJobCheckpoints(context.Background(), job.ID); err != nil {
+			p.mu.Lock()
+			p.retainFinishedJob(job)
+			p.mu.Unlock()
+			return nil, err
+		}
+		job.targetTable = ""
+		job.completedBatches = nil
+		job.Progress = &JobProgress{CurrentStage: "Resuming", LastUpdated: now()}
+	}
+
+	job.ctx, job.cancel = context.WithCancel(jobLogContext(job.ID))
+	job.Status = StatusPending
+	job.Error = ""
+	job.CompletedAt = nil
+	job.resumed = true
+
+	p.mu.Lock()
+	p.runningJobs[job.ID] = job
+	p.mu.Unlock()
+
+	p.saveJobState(job)
+
+	go p.executeJob(job)
+
+	p.jobLogger(job).Info("Resumed ETL job",
+		normalizer.Field{"completedBatches", len(job.completedBatches)},
+	)
+
+	return job, nil
+}
+
+// checkpointsMatchRawData reports whether every committed batch of a job still starts and ends at
+// the raw records it was read from
+func (p *Pipeline) checkpointsMatchRawData(ctx context.Context, job *Job) (bool, error) {
+	checkpoints, err := p.db.GetETLJobCheckpoints(ctx, job.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to load checkpoints of job %s: %w", job.ID, err)
+	}
+
+	for _, checkpoint := range checkpoints {
+		// Checkpoints of empty batches carry no bounds
+		if checkpoint.FirstRawID == nil || checkpoint.LastRawID == nil {
+			continue
+		}
+
+		firstID, lastID, err := p.rawBatchBounds(ctx, checkpoint.Provider, job.Configuration, checkpoint.BatchOffset, checkpoint.BatchSize)
+		if err != nil {
+			return false, err
+		}
+		if !checkpointMatchesBounds(checkpoint, firstID, lastID) {
+			p.providerLogger(job, checkpoint.Provider).Info("Checkpointed batch no longer matches raw data",
+				normalizer.Field{Key: "batchOffset", Value: checkpoint.BatchOffset},
+				normalizer.Field{Key: "firstRawId", Value: *checkpoint.FirstRawID},
+				normalizer.Field{Key: "lastRawId", Value: *checkpoint.LastRawID},
+			)
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// checkpointMatchesBounds reports whether a checkpointed batch still starts and ends at the
+// raw records it was read from. Checkpoints of empty batches carry no bounds and always match.
+func checkpointMatchesBounds(checkpoint database.ETLBatchCheckpoint, firstID, lastID sql.NullInt64) bool {
+	if checkpoint.FirstRawID == nil || checkpoint.LastRawID == nil {
+		return true
+	}
+	return firstID.Valid && lastID.Valid &&
+		int(firstID.Int64) == *checkpoint.FirstRawID && int(lastID.Int64) == *checkpoint.LastRawID
+}
+
+// rawBatchBounds returns the first and last raw record IDs of a batch, selected the same way
+// getAWSBatch and getAzureBatch select it
+func (p *Pipeline) rawBatchBounds(ctx context.Context, provider string, config JobConfiguration, offset, limit int) (sql.NullInt64, sql.NullInt64, error) {
+	var table, regionColumn, serviceColumn string
+	switch provider {
+	case database.ProviderAWS:
+		table, regionColumn, serviceColumn = "aws_pricing_raw", "location", "service_code"
+	case database.ProviderAzure:
+		table, regionColumn, serviceColumn = "azure_pricing_raw", "region", "service_name"
+	default:
+		return sql.NullInt64{}, sql.NullInt64{}, fmt.Errorf("unsupported provider: %s", provider)
+	}
+
+	query := fmt.Sprintf("SELECT id FROM %s WHERE 1=1", table)
+	args := []interface{}{}
+
+	addInFilter := func(column string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		placeholders := make([]string, len(values))
+		for i, value := range values {
+			placeholders[i] = fmt.Sprintf("$%d", len(args)+1)
+			args = append(args, value)
+		}
+		query += fmt.Sprintf(" AND %s IN (%s)", column, strings.Join(placeholders, ","))
+	}
+
+	addInFilter(regionColumn, config.Regions)
+	addInFilter(serviceColumn, config.Services)
+
+	query += fmt.Sprintf(" ORDER BY id LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	var firstID, lastID sql.NullInt64
+	err := p.db.GetConn().QueryRowContext(ctx,
+		fmt.Sprintf("SELECT MIN(id), MAX(id) FROM (%s) batch", query), args...,
+	).Scan(&firstID, &lastID)
+	if err != nil {
+		return sql.NullInt64{}, sql.NullInt64{}, fmt.Errorf("failed to query %s batch bounds: %w", provider, err)
+	}
+
+	return firstID, lastID, nil
+}
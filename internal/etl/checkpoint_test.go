@@ -0,0 +1,129 @@
+package etl
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func TestPipeline_ApplyCheckpoints(t *testing.T) {
+	job := &Job{
+		ID:        "normalize_all-1",
+		StartedAt: time.Now().Add(-time.Minute),
+		Progress:  &JobProgress{},
+	}
+	p := &Pipeline{}
+
+	p.applyCheckpoints(job, []database.ETLBatchCheckpoint{
+		{Provider: database.ProviderAWS, BatchOffset: 0, ProcessedRecords: 1000, NormalizedRecords: 900, SkippedRecords: 100},
+		{Provider: database.ProviderAWS, BatchOffset: 1000, ProcessedRecords: 1000, NormalizedRecords: 950, ErrorRecords: 50},
+		{Provider: database.ProviderAzure, BatchOffset: 500, ProcessedRecords: 500, NormalizedRecords: 500},
+	})
+
+	// Resuming skips exactly the committed batches
+	assert.True(t, job.isBatchCompleted(database.ProviderAWS, 0))
+	assert.True(t, job.isBatchCompleted(database.ProviderAWS, 1000))
+	assert.False(t, job.isBatchCompleted(database.ProviderAWS, 2000))
+	assert.False(t, job.isBatchCompleted(database.ProviderAzure, 0))
+	assert.True(t, job.isBatchCompleted(database.ProviderAzure, 500))
+	assert.False(t, job.isBatchCompleted(database.ProviderAzure, 1000), "offsets are per provider")
+
+	// The counters continue from what was committed
+	assert.Equal(t, 2500, job.Progress.ProcessedRecords)
+	assert.Equal(t, 2350, job.Progress.NormalizedRecords)
+	assert.Equal(t, 100, job.Progress.SkippedRecords)
+	assert.Equal(t, 50, job.Progress.ErrorRecords)
+	assert.Equal(t, 2000, job.providerProgress(database.ProviderAWS).ProcessedRecords)
+	assert.Equal(t, 500, job.providerProgress(database.ProviderAzure).ProcessedRecords)
+}
+
+func TestPipeline_ApplyCheckpoints_ReplacesCompletedBatches(t *testing.T) {
+	job := &Job{
+		Progress:         &JobProgress{},
+		completedBatches: map[string]bool{batchKey(database.ProviderAWS, 0): true},
+	}
+
+	(&Pipeline{}).applyCheckpoints(job, nil)
+
+	assert.False(t, job.isBatchCompleted(database.ProviderAWS, 0))
+}
+
+func TestNewBatchCheckpoint(t *testing.T) {
+	job := &Job{
+		ID: "normalize_all-1",
+		Configuration: JobConfiguration{
+			BatchSize:         1000,
+			ProviderBatchSize: map[string]int{database.ProviderAzure: 250},
+		},
+	}
+	result := &BatchResult{ProcessedRecords: 250, NormalizedRecords: 240, SkippedRecords: 6, ErrorRecords: 4}
+
+	assert.Equal(t, database.ETLBatchCheckpoint{
+		JobID:             "normalize_all-1",
+		Provider:          database.ProviderAzure,
+		BatchOffset:       500,
+		BatchSize:         250,
+		ProcessedRecords:  250,
+		NormalizedRecords: 240,
+		SkippedRecords:    6,
+		ErrorRecords:      4,
+	}, newBatchCheckpoint(job, database.ProviderAzure, 500, result))
+	assert.Equal(t, 1000, newBatchCheckpoint(job, database.ProviderAWS, 0, result).BatchSize)
+}
+
+func TestCheckpointMatchesBounds(t *testing.T) {
+	bounded := database.ETLBatchCheckpoint{FirstRawID: intPtr(101), LastRawID: intPtr(200)}
+	id := func(value int64) sql.NullInt64 {
+		return sql.NullInt64{Int64: value, Valid: true}
+	}
+
+	tests := []struct {
+		name       string
+		checkpoint database.ETLBatchCheckpoint
+		firstID    sql.NullInt64
+		lastID     sql.NullInt64
+		expected   bool
+	}{
+		{
+			name:       "unchanged raw data",
+			checkpoint: bounded,
+			firstID:    id(101),
+			lastID:     id(200),
+			expected:   true,
+		},
+		{
+			name:       "records removed before the batch shift it",
+			checkpoint: bounded,
+			firstID:    id(111),
+			lastID:     id(210),
+		},
+		{
+			name:       "records added inside the batch",
+			checkpoint: bounded,
+			firstID:    id(101),
+			lastID:     id(190),
+		},
+		{
+			name:       "batch no longer has records",
+			checkpoint: bounded,
+		},
+		{
+			name:       "empty batch carries no bounds",
+			checkpoint: database.ETLBatchCheckpoint{},
+			expected:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, checkpointMatchesBounds(tt.checkpoint, tt.firstID, tt.lastID))
+		})
+	}
+}
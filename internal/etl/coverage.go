@@ -0,0 +1,152 @@
+package etl
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+)
+
+// CoverageCounts splits raw pricing records into records that reached normalized_pricing
+// (mapped), records without a service or region mapping (unmapped), and records whose service
+// and region are mapped but that were skipped or failed normalization (failed)
+type CoverageCounts struct {
+	RawRecords      int     `json:"rawRecords"`
+	MappedRecords   int     `json:"mappedRecords"`
+	UnmappedRecords int     `json:"unmappedRecords"`
+	FailedRecords   int     `json:"failedRecords"`
+	CoveragePercent float64 `json:"coveragePercent"`
+}
+
+// add accumulates the counts of a coverage group
+func (c *CoverageCounts) add(other CoverageCounts) {
+	c.RawRecords += other.RawRecords
+	c.MappedRecords += other.MappedRecords
+	c.UnmappedRecords += other.UnmappedRecords
+	c.FailedRecords += other.FailedRecords
+	c.CoveragePercent = 0
+	if c.RawRecords > 0 {
+		c.CoveragePercent = float64(c.MappedRecords) / float64(c.RawRecords) * 100
+	}
+}
+
+// CoverageEntry is the coverage of a provider service in a region
+type CoverageEntry struct {
+	Provider      string `json:"provider"`
+	Service       string `json:"service"`
+	Region        string `json:"region"`
+	ServiceMapped bool   `json:"serviceMapped"`
+	RegionMapped  bool   `json:"regionMapped"`
+	CoverageCounts
+}
+
+// ProviderCoverage is the coverage of all raw records of a provider
+type ProviderCoverage struct {
+	Provider string `json:"provider"`
+	CoverageCounts
+}
+
+// UnmappedService is a provider service with unmapped raw records. Either the service has no
+// mapping, or it is mapped but some of its regions are not.
+type UnmappedService struct {
+	Provider        string   `json:"provider"`
+	Service         string   `json:"service"`
+	ServiceMapped   bool     `json:"serviceMapped"`
+	UnmappedRecords int      `json:"unmappedRecords"`
+	Regions         []string `json:"regions"`
+}
+
+// CoverageReport summarizes how much raw pricing reaches normalized_pricing
+type CoverageReport struct {
+	GeneratedAt      time.Time           `json:"generatedAt"`
+	Summary          CoverageCounts      `json:"summary"`
+	Providers        []*ProviderCoverage `json:"providers"`
+	UnmappedServices []*UnmappedService  `json:"unmappedServices"`
+	Entries          []*CoverageEntry    `json:"entries"`
+}
+
+// CoverageReport analyzes the raw records of the given providers, or of all providers if none
+// are given
+func (p *Pipeline) CoverageReport(ctx context.Context, providers []string) (*CoverageReport, error) {
+	if len(providers) == 0 {
+		providers = []string{database.ProviderAWS, database.ProviderAzure}
+	}
+
+	var groups []database.CoverageGroup
+	for _, provider := range providers {
+		providerGroups, err := p.db.GetNormalizationCoverage(ctx, provider)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, providerGroups...)
+	}
+
+	return BuildCoverageReport(groups, time.Now()), nil
+}
+
+// BuildCoverageReport classifies coverage groups and ranks unmapped services by the number of
+// unmapped records
+func BuildCoverageReport(groups []database.CoverageGroup, generatedAt time.Time) *CoverageReport {
+	report := &CoverageReport{GeneratedAt: generatedAt}
+	providers := make(map[string]*ProviderCoverage)
+	unmapped := make(map[string]*UnmappedService)
+
+	for _, group := range groups {
+		entry := &CoverageEntry{
+			Provider:      group.Provider,
+			Service:       group.Service,
+			Region:        group.Region,
+			ServiceMapped: group.ServiceMapped,
+			RegionMapped:  group.RegionMapped,
+		}
+
+		// Records normalized before a mapping was removed still count as mapped
+		counts := CoverageCounts{RawRecords: group.RawRecords, MappedRecords: group.NormalizedRecords}
+		remaining := group.RawRecords - group.NormalizedRecords
+		if remaining < 0 {
+			remaining = 0
+		}
+		if group.ServiceMapped && group.RegionMapped {
+			counts.FailedRecords = remaining
+		} else {
+			counts.UnmappedRecords = remaining
+		}
+		entry.add(counts)
+		report.Entries = append(report.Entries, entry)
+		report.Summary.add(counts)
+
+		provider, ok := providers[group.Provider]
+		if !ok {
+			provider = &ProviderCoverage{Provider: group.Provider}
+			providers[group.Provider] = provider
+			report.Providers = append(report.Providers, provider)
+		}
+		provider.add(counts)
+
+		if counts.UnmappedRecords > 0 {
+			key := group.Provider + ":" + group.Service
+			service, ok := unmapped[key]
+			if !ok {
+				service = &UnmappedService{
+					Provider:      group.Provider,
+					Service:       group.Service,
+					ServiceMapped: group.ServiceMapped,
+				}
+				unmapped[key] = service
+				report.UnmappedServices = append(report.UnmappedServices, service)
+			}
+			service.UnmappedRecords += counts.UnmappedRecords
+			service.Regions = append(service.Regions, group.Region)
+		}
+	}
+
+	sort.SliceStable(report.UnmappedServices, func(i, j int) bool {
+		return report.UnmappedServices[i].UnmappedRecords > report.UnmappedServices[j].UnmappedRecords
+	})
+	for _, service := range report.UnmappedServices {
+		sort.Strings(service.Regions)
+	}
+
+	return report
+}
@@ -0,0 +1,68 @@
+package etl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCoverageReport(t *testing.T) {
+	generatedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	groups := []database.CoverageGroup{
+		{Provider: "aws", Service: "AmazonEC2", Region: "US East (N. Virginia)", RawRecords: 100, NormalizedRecords: 90, ServiceMapped: true, RegionMapped: true},
+		{Provider: "aws", Service: "AmazonEC2", Region: "South America (Sao Paulo)", RawRecords: 30, ServiceMapped: true},
+		{Provider: "aws", Service: "AmazonEC2", Region: "Asia Pacific (Hyderabad)", RawRecords: 10, ServiceMapped: true},
+		{Provider: "aws", Service: "AWSIoT", Region: "US East (N. Virginia)", RawRecords: 25, RegionMapped: true},
+		{Provider: "azure", Service: "Virtual Machines", Region: "eastus", RawRecords: 40, NormalizedRecords: 40, ServiceMapped: true, RegionMapped: true},
+		// Two records were normalized before the service mapping was removed
+		{Provider: "azure", Service: "Redis Cache", Region: "eastus", RawRecords: 12, NormalizedRecords: 2, RegionMapped: true},
+		{Provider: "azure", Service: "Storage", Region: "westus", RawRecords: 5, NormalizedRecords: 8, ServiceMapped: true, RegionMapped: true},
+	}
+
+	report := BuildCoverageReport(groups, generatedAt)
+
+	assert.Equal(t, generatedAt, report.GeneratedAt)
+	assert.Equal(t, CoverageCounts{
+		RawRecords:      222,
+		MappedRecords:   140,
+		UnmappedRecords: 75,
+		FailedRecords:   10,
+		CoveragePercent: float64(140) / 222 * 100,
+	}, report.Summary)
+
+	require.Len(t, report.Providers, 2)
+	assert.Equal(t, &ProviderCoverage{Provider: "aws", CoverageCounts: CoverageCounts{
+		RawRecords: 165, MappedRecords: 90, UnmappedRecords: 65, FailedRecords: 10, CoveragePercent: float64(90) / 165 * 100,
+	}}, report.Providers[0])
+	assert.Equal(t, &ProviderCoverage{Provider: "azure", CoverageCounts: CoverageCounts{
+		RawRecords: 57, MappedRecords: 50, UnmappedRecords: 10, CoveragePercent: float64(50) / 57 * 100,
+	}}, report.Providers[1])
+
+	// Unmapped services rank by unmapped records, with their regions sorted
+	assert.Equal(t, []*UnmappedService{
+		{Provider: "aws", Service: "AmazonEC2", ServiceMapped: true, UnmappedRecords: 40,
+			Regions: []string{"Asia Pacific (Hyderabad)", "South America (Sao Paulo)"}},
+		{Provider: "aws", Service: "AWSIoT", UnmappedRecords: 25, Regions: []string{"US East (N. Virginia)"}},
+		{Provider: "azure", Service: "Redis Cache", UnmappedRecords: 10, Regions: []string{"eastus"}},
+	}, report.UnmappedServices)
+
+	require.Len(t, report.Entries, len(groups))
+	ec2 := report.Entries[0]
+	assert.Equal(t, 10, ec2.FailedRecords, "mapped records that were not normalized failed")
+	assert.Zero(t, ec2.UnmappedRecords)
+	assert.Equal(t, 90.0, ec2.CoveragePercent)
+	storage := report.Entries[6]
+	assert.Zero(t, storage.FailedRecords, "normalized records beyond the raw count do not go negative")
+	assert.Equal(t, 8, storage.MappedRecords)
+}
+
+func TestBuildCoverageReport_Empty(t *testing.T) {
+	report := BuildCoverageReport(nil, time.Time{})
+
+	assert.Equal(t, CoverageCounts{}, report.Summary)
+	assert.Empty(t, report.Providers)
+	assert.Empty(t, report.UnmappedServices)
+}
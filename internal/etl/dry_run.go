@@ -0,0 +1,390 @@
+package etl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/raulc0399/cpc/internal/normalizer"
+)
+
+// maxDiffSamplesPerGroup limits how many example changes are kept per service/region group
+const maxDiffSamplesPerGroup = 10
+
+// DiffChangeType describes how a normalized record differs from the live data
+type DiffChangeType string
+
+const (
+	DiffChangeNew          DiffChangeType = "new"
+	DiffChangeRemoved      DiffChangeType = "removed"
+	DiffChangePriceChanged DiffChangeType = "price_changed"
+)
+
+// DryRunReport describes what a dry-run normalization would change in normalized_pricing
+type DryRunReport struct {
+	JobID       string      `json:"jobId"`
+	GeneratedAt time.Time   `json:"generatedAt"`
+	Summary     DiffSummary `json:"summary"`
+	Groups      []DiffGroup `json:"groups"`
+}
+
+// DiffSummary holds the overall change counts of a dry-run report
+type DiffSummary struct {
+	CandidateRecords int `json:"candidateRecords"`
+	ExistingRecords  int `json:"existingRecords"`
+	NewRecords       int `json:"newRecords"`
+	RemovedRecords   int `json:"removedRecords"`
+	PriceChanged     int `json:"priceChanged"`
+	Unchanged        int `json:"unchanged"`
+}
+
+// DiffGroup holds the change counts and samples for one provider/service/region
+type DiffGroup struct {
+	Provider         string       `json:"provider"`
+	ServiceType      string       `json:"serviceType"`
+	NormalizedRegion string       `json:"normalizedRegion"`
+	NewRecords       int          `json:"newRecords"`
+	RemovedRecords   int          `json:"removedRecords"`
+	PriceChanged     int          `json:"priceChanged"`
+	Unchanged        int          `json:"unchanged"`
+	Samples          []DiffChange `json:"samples"`
+}
+
+// DiffChange is a single sampled difference between candidate and live data
+type DiffChange struct {
+	Type          DiffChangeType `json:"type"`
+	ResourceName  string         `json:"resourceName"`
+	ProviderSKU   string         `json:"providerSku,omitempty"`
+	PricingModel  string         `json:"pricingModel"`
+	Unit          string         `json:"unit"`
+	OldPrice      *float64       `json:"oldPrice,omitempty"`
+	NewPrice      *float64       `json:"newPrice,omitempty"`
+	ChangePercent *float64       `json:"changePercent,omitempty"`
+}
+
+// diffRecord is the subset of a normalized record needed for diffing
+type diffRecord struct {
+	Provider         string
+	ProviderSKU      string
+	ServiceType      string
+	NormalizedRegion string
+	ResourceName     string
+	PricingModel     string
+	Unit             string
+	PricePerUnit     float64
+}
+
+// dryRunCollector accumulates candidate records produced by dry-run workers
+type dryRunCollector struct {
+	mu         sync.Mutex
+	candidates map[string]diffRecord
+
+	// Azure tier records per meter, merged like a real run merges them in the database
+	tierGroups map[string]*tierGroup
+}
+
+// tierGroup holds the tier records of one Azure meter in the order they were normalized
+type tierGroup struct {
+	naturalKey string
+	members    []database.NormalizedPricing
+}
+
+// newDryRunCollector creates an empty dry-run collector
+func newDryRunCollector() *dryRunCollector {
+	return &dryRunCollector{
+		candidates: make(map[string]diffRecord),
+		tierGroups: make(map[string]*tierGroup),
+	}
+}
+
+// Add records candidate normalized records, keyed by their natural key
+func (c *dryRunCollector) Add(records []database.NormalizedPricing) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, record := range records {
+		key := record.NaturalKey()
+		c.candidates[key] = newDiffRecord(record)
+
+		if record.Provider != database.ProviderAzure {
+			continue
+		}
+		groupKey := database.PriceTierGroupKey(record)
+		group, exists := c.tierGroups[groupKey]
+		if !exists {
+			group = &tierGroup{naturalKey: key}
+			c.tierGroups[groupKey] = group
+		}
+		group.members = append(group.members, database.NormalizedPricing{
+			PricePerUnit: record.PricePerUnit,
+			PriceTiers:   record.PriceTiers,
+		})
+	}
+}
+
+// Candidates returns the candidate records with the Azure price tiers merged into one record
+// per meter at its headline price, as ConsolidateAzurePriceTiers leaves them after a real run
+func (c *dryRunCollector) Candidates() map[string]diffRecord {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	candidates := make(map[string]diffRecord, len(c.candidates))
+	for key, record := range c.candidates {
+		candidates[key] = record
+	}
+	for _, group := range c.tierGroups {
+		_, price, merged := database.MergePriceTiers(group.members)
+		if !merged {
+			continue
+		}
+		record := candidates[group.naturalKey]
+		record.PricePerUnit = price
+		candidates[group.naturalKey] = record
+	}
+	return candidates
+}
+
+// newDiffRecord converts a normalized record into its diff representation
+func newDiffRecord(record database.NormalizedPricing) diffRecord {
+	var sku string
+	if record.ProviderSKU != nil {
+		sku = *record.ProviderSKU
+	}
+
+	return diffRecord{
+		Provider:         record.Provider,
+		ProviderSKU:      sku,
+		ServiceType:      record.ServiceType,
+		NormalizedRegion: record.NormalizedRegion,
+		ResourceName:     record.ResourceName,
+		PricingModel:     record.PricingModel,
+		Unit:             record.Unit,
+		PricePerUnit:     record.PricePerUnit,
+	}
+}
+
+// buildDryRunReport compares the collected candidates against the live normalized_pricing rows
+func (p *Pipeline) buildDryRunReport(job *Job) (*DryRunReport, error) {
+	job.setStage("Building dry-run diff report")
+
+	existing, err := p.loadExistingForDiff(job.ctx, job.Configuration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing normalized data: %w", err)
+	}
+
+	candidates := job.dryRun.Candidates()
+
+	report := diffNormalizedRecords(candidates, existing)
+	report.JobID = job.ID
+	report.GeneratedAt = now()
+
+	p.jobLogger(job).Info("Built dry-run diff report",
+		normalizer.Field{"new", report.Summary.NewRecords},
+		normalizer.Field{"removed", report.Summary.RemovedRecords},
+		normalizer.Field{"priceChanged", report.Summary.PriceChanged},
+	)
+
+	return report, nil
+}
+
+// loadExistingForDiff loads the live normalized records within the job's scope
+func (p *Pipeline) loadExistingForDiff(ctx context.Context, config JobConfiguration) (map[string]diffRecord, error) {
+	query := `
+		SELECT provider, provider_region, provider_sku, service_type, normalized_region, resource_name,
+		       pricing_model, unit, currency, price_per_unit, pricing_details->>'term_length',
+		       pricing_details->>'payment_option', operating_system, license_model,
+		       pre_installed_software, tenancy
+		FROM normalized_pricing
+		WHERE 1=1`
+	args := []interface{}{}
+
+	addInFilter := func(column string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		placeholders := make([]string, len(values))
+		for i, value := range values {
+			placeholders[i] = fmt.Sprintf("$%d", len(args)+1)
+			args = append(args, value)
+		}
+		query += fmt.Sprintf(" AND %s IN (%s)", column, strings.Join(placeholders, ","))
+	}
+
+	addInFilter("provider", config.Providers)
+	addInFilter("provider_region", config.Regions)
+	addInFilter("provider_service_code", config.Services)
+
+	rows, err := p.db.GetConn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query normalized pricing: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]diffRecord)
+	for rows.Next() {
+		var sku, termLength, paymentOption sql.NullString
+		var record database.NormalizedPricing
+		err := rows.Scan(
+			&record.Provider,
+			&record.ProviderRegion,
+			&sku,
+			&record.ServiceType,
+			&record.NormalizedRegion,
+			&record.ResourceName,
+			&record.PricingModel,
+			&record.Unit,
+			&record.Currency,
+			&record.PricePerUnit,
+			&termLength,
+			&paymentOption,
+			&record.OperatingSystem,
+			&record.LicenseModel,
+			&record.PreInstalledSoftware,
+			&record.Tenancy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan normalized pricing: %w", err)
+		}
+
+		if sku.Valid {
+			record.ProviderSKU = &sku.String
+		}
+		if termLength.Valid {
+			record.PricingDetails.TermLength = &termLength.String
+		}
+		if paymentOption.Valid {
+			record.PricingDetails.PaymentOption = &paymentOption.String
+		}
+
+		existing[record.NaturalKey()] = newDiffRecord(record)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating normalized pricing: %w", err)
+	}
+
+	return existing, nil
+}
+
+// diffNormalizedRecords computes new, removed and price-changed records grouped by service and region
+func diffNormalizedRecords(candidates, existing map[string]diffRecord) *DryRunReport {
+	report := &DryRunReport{
+		Summary: DiffSummary{
+			CandidateRecords: len(candidates),
+			ExistingRecords:  len(existing),
+		},
+	}
+
+	groups := make(map[string]*DiffGroup)
+	groupFor := func(record diffRecord) *DiffGroup {
+		key := record.Provider + "|" + record.ServiceType + "|" + record.NormalizedRegion
+		group, exists := groups[key]
+		if !exists {
+			group = &DiffGroup{
+				Provider:         record.Provider,
+				ServiceType:      record.ServiceType,
+				NormalizedRegion: record.NormalizedRegion,
+				Samples:          []DiffChange{},
+			}
+			groups[key] = group
+		}
+		return group
+	}
+
+	addSample := func(group *DiffGroup, change DiffChange) {
+		if len(group.Samples) < maxDiffSamplesPerGroup {
+			group.Samples = append(group.Samples, change)
+		}
+	}
+
+	// Iterate keys in sorted order so samples are stable between runs
+	for _, key := range sortedKeys(candidates) {
+		candidate := candidates[key]
+		group := groupFor(candidate)
+		newPrice := candidate.PricePerUnit
+
+		current, exists := existing[key]
+		if !exists {
+			report.Summary.NewRecords++
+			group.NewRecords++
+			addSample(group, newDiffChange(DiffChangeNew, candidate, nil, &newPrice))
+			continue
+		}
+
+		if current.PricePerUnit == candidate.PricePerUnit {
+			report.Summary.Unchanged++
+			group.Unchanged++
+			continue
+		}
+
+		oldPrice := current.PricePerUnit
+		report.Summary.PriceChanged++
+		group.PriceChanged++
+		addSample(group, newDiffChange(DiffChangePriceChanged, candidate, &oldPrice, &newPrice))
+	}
+
+	for _, key := range sortedKeys(existing) {
+		if _, exists := candidates[key]; exists {
+			continue
+		}
+
+		current := existing[key]
+		group := groupFor(current)
+		oldPrice := current.PricePerUnit
+		report.Summary.RemovedRecords++
+		group.RemovedRecords++
+		addSample(group, newDiffChange(DiffChangeRemoved, current, &oldPrice, nil))
+	}
+
+	report.Groups = make([]DiffGroup, 0, len(groups))
+	for _, group := range groups {
+		report.Groups = append(report.Groups, *group)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.ServiceType != b.ServiceType {
+			return a.ServiceType < b.ServiceType
+		}
+		return a.NormalizedRegion < b.NormalizedRegion
+	})
+
+	return report
+}
+
+// newDiffChange builds a sampled change entry
+func newDiffChange(changeType DiffChangeType, record diffRecord, oldPrice, newPrice *float64) DiffChange {
+	change := DiffChange{
+		Type:         changeType,
+		ResourceName: record.ResourceName,
+		ProviderSKU:  record.ProviderSKU,
+		PricingModel: record.PricingModel,
+		Unit:         record.Unit,
+		OldPrice:     oldPrice,
+		NewPrice:     newPrice,
+	}
+
+	if oldPrice != nil && newPrice != nil && *oldPrice != 0 {
+		changePercent := (*newPrice - *oldPrice) / *oldPrice * 100
+		change.ChangePercent = &changePercent
+	}
+
+	return change
+}
+
+// sortedKeys returns the keys of a diff record map in sorted order
+func sortedKeys(records map[string]diffRecord) []string {
+	keys := make([]string, 0, len(records))
+	for key := range records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,178 @@
+package etl
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func float64Ptr(value float64) *float64 {
+	return &value
+}
+
+func testDiffRecord(provider, region, resourceName string, price float64) diffRecord {
+	return diffRecord{
+		Provider:         provider,
+		ServiceType:      "Virtual Machines",
+		NormalizedRegion: region,
+		ResourceName:     resourceName,
+		PricingModel:     database.PricingModelOnDemand,
+		Unit:             database.UnitHour,
+		PricePerUnit:     price,
+	}
+}
+
+func TestDiffNormalizedRecords(t *testing.T) {
+	candidates := map[string]diffRecord{
+		"aws|m5.large":   testDiffRecord(database.ProviderAWS, "us-east", "m5.large", 0.096),
+		"aws|m5.xlarge":  testDiffRecord(database.ProviderAWS, "us-east", "m5.xlarge", 0.2),
+		"aws|c5.large":   testDiffRecord(database.ProviderAWS, "us-east", "c5.large", 0.085),
+		"azure|D2s_v5":   testDiffRecord(database.ProviderAzure, "eu-west", "D2s_v5", 0.11),
+		"azure|B1s_spot": testDiffRecord(database.ProviderAzure, "eu-west", "B1s", 0),
+	}
+	existing := map[string]diffRecord{
+		"aws|m5.large":   testDiffRecord(database.ProviderAWS, "us-east", "m5.large", 0.096),
+		"aws|m5.xlarge":  testDiffRecord(database.ProviderAWS, "us-east", "m5.xlarge", 0.192),
+		"aws|t3.micro":   testDiffRecord(database.ProviderAWS, "us-east", "t3.micro", 0.0104),
+		"azure|B1s_spot": testDiffRecord(database.ProviderAzure, "eu-west", "B1s", 0.002),
+	}
+
+	report := diffNormalizedRecords(candidates, existing)
+	oldPrice, newPrice := 0.192, 0.2
+	changePercent := (newPrice - oldPrice) / oldPrice * 100
+
+	assert.Equal(t, DiffSummary{
+		CandidateRecords: 5,
+		ExistingRecords:  4,
+		NewRecords:       2,
+		RemovedRecords:   1,
+		PriceChanged:     2,
+		Unchanged:        1,
+	}, report.Summary)
+
+	require.Len(t, report.Groups, 2)
+	aws, azure := report.Groups[0], report.Groups[1]
+
+	assert.Equal(t, database.ProviderAWS, aws.Provider)
+	assert.Equal(t, "us-east", aws.NormalizedRegion)
+	assert.Equal(t, 1, aws.NewRecords)
+	assert.Equal(t, 1, aws.RemovedRecords)
+	assert.Equal(t, 1, aws.PriceChanged)
+	assert.Equal(t, 1, aws.Unchanged)
+	assert.Equal(t, []DiffChange{
+		{Type: DiffChangeNew, ResourceName: "c5.large", PricingModel: database.PricingModelOnDemand, Unit: database.UnitHour,
+			NewPrice: float64Ptr(0.085)},
+		{Type: DiffChangePriceChanged, ResourceName: "m5.xlarge", PricingModel: database.PricingModelOnDemand, Unit: database.UnitHour,
+			OldPrice: float64Ptr(0.192), NewPrice: float64Ptr(0.2), ChangePercent: &changePercent},
+		{Type: DiffChangeRemoved, ResourceName: "t3.micro", PricingModel: database.PricingModelOnDemand, Unit: database.UnitHour,
+			OldPrice: float64Ptr(0.0104)},
+	}, aws.Samples)
+
+	assert.Equal(t, database.ProviderAzure, azure.Provider)
+	assert.Equal(t, 1, azure.NewRecords)
+	assert.Equal(t, 1, azure.PriceChanged)
+	require.Len(t, azure.Samples, 2)
+	assert.Equal(t, DiffChangePriceChanged, azure.Samples[0].Type)
+	assert.InDelta(t, -100, *azure.Samples[0].ChangePercent, 1e-9)
+}
+
+func TestDiffNormalizedRecords_LimitsSamplesPerGroup(t *testing.T) {
+	candidates := make(map[string]diffRecord)
+	for i := 0; i < maxDiffSamplesPerGroup+5; i++ {
+		key := fmt.Sprintf("aws|m5.%02d", i)
+		candidates[key] = testDiffRecord(database.ProviderAWS, "us-east", key, 0.1)
+	}
+
+	report := diffNormalizedRecords(candidates, map[string]diffRecord{})
+
+	require.Len(t, report.Groups, 1)
+	assert.Equal(t, maxDiffSamplesPerGroup+5, report.Groups[0].NewRecords)
+	assert.Len(t, report.Groups[0].Samples, maxDiffSamplesPerGroup)
+	assert.Equal(t, "aws|m5.00", report.Groups[0].Samples[0].ResourceName, "samples follow key order")
+}
+
+func TestDiffNormalizedRecords_Empty(t *testing.T) {
+	report := diffNormalizedRecords(map[string]diffRecord{}, map[string]diffRecord{})
+
+	assert.Equal(t, DiffSummary{}, report.Summary)
+	assert.Empty(t, report.Groups)
+}
+
+// azureTierRecord is one tier record of the Blob storage meter, as the Azure normalizer emits it
+func azureTierRecord(price float64, tiers ...database.PriceTier) database.NormalizedPricing {
+	sku := "DZH318Z0BQ4N/0003"
+	description := "Blob Storage - LRS Data Stored"
+	return database.NormalizedPricing{
+		Provider:            database.ProviderAzure,
+		ProviderSKU:         &sku,
+		ProviderRegion:      "eastus",
+		NormalizedRegion:    "us-east",
+		ServiceType:         "Object Storage",
+		ResourceName:        "Standard LRS",
+		ResourceDescription: &description,
+		PricingModel:        database.PricingModelOnDemand,
+		Unit:                database.UnitGB,
+		Currency:            "USD",
+		PricePerUnit:        price,
+		PriceTiers:          tiers,
+	}
+}
+
+func TestDryRunCollector_Candidates(t *testing.T) {
+	awsSKU := "JRTCKXETXF"
+	aws := database.NormalizedPricing{
+		Provider:         database.ProviderAWS,
+		ProviderSKU:      &awsSKU,
+		ProviderRegion:   "us-east-1",
+		NormalizedRegion: "us-east",
+		ServiceType:      "Virtual Machines",
+		ResourceName:     "m5.large",
+		PricingModel:     database.PricingModelOnDemand,
+		Unit:             database.UnitHour,
+		Currency:         "USD",
+		PricePerUnit:     0.096,
+	}
+
+	collector := newDryRunCollector()
+	// Workers add the tier records of a meter in separate batches
+	collector.Add([]database.NormalizedPricing{aws, azureTierRecord(0.0208)})
+	collector.Add([]database.NormalizedPricing{
+		azureTierRecord(0.02, database.PriceTier{StartUnits: 51200, PricePerUnit: 0.02}),
+		azureTierRecord(0.0192, database.PriceTier{StartUnits: 512000, PricePerUnit: 0.0192}),
+	})
+
+	candidates := collector.Candidates()
+
+	require.Len(t, candidates, 2)
+	assert.Equal(t, diffRecord{
+		Provider:         database.ProviderAWS,
+		ProviderSKU:      awsSKU,
+		ServiceType:      "Virtual Machines",
+		NormalizedRegion: "us-east",
+		ResourceName:     "m5.large",
+		PricingModel:     database.PricingModelOnDemand,
+		Unit:             database.UnitHour,
+		PricePerUnit:     0.096,
+	}, candidates[aws.NaturalKey()])
+
+	// The tier records merge into one record at the price of the first tier, not the last
+	// record normalized
+	blob := candidates[azureTierRecord(0).NaturalKey()]
+	assert.Equal(t, "DZH318Z0BQ4N/0003", blob.ProviderSKU)
+	assert.Equal(t, 0.0208, blob.PricePerUnit)
+}
+
+func TestDryRunCollector_Candidates_SingleTierRecord(t *testing.T) {
+	collector := newDryRunCollector()
+	collector.Add([]database.NormalizedPricing{
+		azureTierRecord(0.02, database.PriceTier{StartUnits: 51200, PricePerUnit: 0.02}),
+	})
+
+	candidates := collector.Candidates()
+
+	require.Len(t, candidates, 1)
+	assert.Equal(t, 0.02, candidates[azureTierRecord(0).NaturalKey()].PricePerUnit, "a lone record is not merged")
+}
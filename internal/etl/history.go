@@ -0,0 +1,27 @@
+package etl
+
+import (
+	"time"
+
+	"github.com/raulc0399/cpc/internal/normalizer"
+)
+
+// syncPriceHistory records the prices live after a job in the price history. The job's data is
+// already written, so a failed sync is logged without failing the job; the next sync catches up.
+func (p *Pipeline) syncPriceHistory(job *Job) {
+	job.setStage("Recording price history")
+
+	result, err := p.db.SyncNormalizedPricingHistory(job.ctx, job.ID, time.Now())
+	if err != nil {
+		p.jobLogger(job).Error("Failed to sync price history",
+			normalizer.Field{Key: "error", Value: err},
+		)
+		return
+	}
+
+	job.PriceHistory = result
+	p.jobLogger(job).Info("Synced price history",
+		normalizer.Field{Key: "opened", Value: result.Opened},
+		normalizer.Field{Key: "closed", Value: result.Closed},
+	)
+}
@@ -3,11 +3,15 @@ package etl
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/raulc0399/cpc/internal/alerts"
 	"github.com/raulc0399/cpc/internal/database"
 	"github.com/raulc0399/cpc/internal/normalizer"
+	"golang.org/x/sync/errgroup"
 )
 
 // Pipeline manages the ETL process for normalizing raw pricing data
@@ -20,23 +24,40 @@ type Pipeline struct {
 	unitNormalizer     normalizer.UnitNormalizer
 	pricingRepo        normalizer.NormalizedPricingRepository
 	logger             normalizer.Logger
+	notifier           *alerts.Notifier
 	mu                 sync.RWMutex
 	runningJobs        map[string]*Job
+	finishedJobs       map[string]*Job
 }
 
+// maxFinishedJobs limits how many completed jobs are kept for status and report lookups
+const maxFinishedJobs = 100
+
 // Job represents an ETL job
 type Job struct {
-	ID               string                    `json:"id"`
-	Type             JobType                   `json:"type"`
-	Provider         string                    `json:"provider"`
-	Status           JobStatus                 `json:"status"`
-	Progress         *JobProgress              `json:"progress"`
-	StartedAt        time.Time                 `json:"startedAt"`
-	CompletedAt      *time.Time                `json:"completedAt"`
-	Error            string                    `json:"error,omitempty"`
-	Configuration    JobConfiguration          `json:"configuration"`
+	ID               string                              `json:"id"`
+	Type             JobType                             `json:"type"`
+	Provider         string                              `json:"provider"`
+	Status           JobStatus                           `json:"status"`
+	Progress         *JobProgress                        `json:"progress"`
+	StartedAt        time.Time                           `json:"startedAt"`
+	CompletedAt      *time.Time                          `json:"completedAt"`
+	Error            string                              `json:"error,omitempty"`
+	Configuration    JobConfiguration                    `json:"configuration"`
+	DryRunReport     *DryRunReport                       `json:"dryRunReport,omitempty"`
+	PriceHistory     *database.PriceHistorySyncResult    `json:"priceHistory,omitempty"`
+	PriceSummaries   *database.PriceSummaryRefreshResult `json:"priceSummaries,omitempty"`
+	PriceDiffs       []CollectionDiffResult              `json:"priceDiffs,omitempty"`
+	PriceAlerts      *alerts.EvaluationResult            `json:"priceAlerts,omitempty"`
 	ctx              context.Context
 	cancel           context.CancelFunc
+	dryRun           *dryRunCollector
+	targetTable      string
+	stagingFailed    atomic.Bool
+	checkpointing    bool
+	completedBatches map[string]bool
+	resumed          bool
+	progressMu       sync.Mutex
 }
 
 // JobType represents the type of ETL job
@@ -54,39 +75,43 @@ const (
 type JobStatus string
 
 const (
-	StatusPending    JobStatus = "pending"
-	StatusRunning    JobStatus = "running"
-	StatusCompleted  JobStatus = "completed"
-	StatusFailed     JobStatus = "failed"
-	StatusCancelled  JobStatus = "cancelled"
+	StatusPending     JobStatus = "pending"
+	StatusRunning     JobStatus = "running"
+	StatusCompleted   JobStatus = "completed"
+	StatusFailed      JobStatus = "failed"
+	StatusCancelled   JobStatus = "cancelled"
+	StatusInterrupted JobStatus = "interrupted"
 )
 
 // JobProgress tracks the progress of an ETL job
 type JobProgress struct {
-	TotalRecords      int       `json:"totalRecords"`
-	ProcessedRecords  int       `json:"processedRecords"`
-	NormalizedRecords int       `json:"normalizedRecords"`
-	SkippedRecords    int       `json:"skippedRecords"`
-	ErrorRecords      int       `json:"errorRecords"`
-	CurrentStage      string    `json:"currentStage"`
-	LastUpdated       time.Time `json:"lastUpdated"`
-	Rate              float64   `json:"rate"` // records per second
+	TotalRecords      int                 `json:"totalRecords"`
+	ProcessedRecords  int                 `json:"processedRecords"`
+	NormalizedRecords int                 `json:"normalizedRecords"`
+	SkippedRecords    int                 `json:"skippedRecords"`
+	ErrorRecords      int                 `json:"errorRecords"`
+	CurrentStage      string              `json:"currentStage"`
+	LastUpdated       time.Time           `json:"lastUpdated"`
+	Rate              float64             `json:"rate"`                // records per second
+	Providers         []*ProviderProgress `json:"providers,omitempty"` // Per-provider breakdown
 }
 
 // JobConfiguration holds configuration for ETL jobs
 type JobConfiguration struct {
-	Providers         []string `json:"providers,omitempty"`         // AWS, Azure
-	Regions           []string `json:"regions,omitempty"`           // Specific regions
-	Services          []string `json:"services,omitempty"`          // Specific services
-	BatchSize         int      `json:"batchSize"`                   // Records per batch
-	ConcurrentWorkers int      `json:"concurrentWorkers"`           // Parallel workers
-	ClearExisting     bool     `json:"clearExisting"`               // Clear existing normalized data
-	DryRun            bool     `json:"dryRun"`                      // Don't actually insert
+	Providers         []string       `json:"providers,omitempty"`         // AWS, Azure
+	Regions           []string       `json:"regions,omitempty"`           // Specific regions
+	Services          []string       `json:"services,omitempty"`          // Specific services
+	BatchSize         int            `json:"batchSize"`                   // Records per batch
+	ConcurrentWorkers int            `json:"concurrentWorkers"`           // Parallel workers
+	ClearExisting     bool           `json:"clearExisting"`               // Clear existing normalized data
+	DryRun            bool           `json:"dryRun"`                      // Don't actually insert
+	ProviderWorkers   map[string]int `json:"providerWorkers,omitempty"`   // Worker budget per provider
+	ProviderBatchSize map[string]int `json:"providerBatchSize,omitempty"` // Batch size per provider
 }
 
 // NewPipeline creates a new ETL pipeline
 func NewPipeline(db *database.DB) (*Pipeline, error) {
-	logger := normalizer.NewSimpleLogger()
+	logger := normalizer.NewDefaultLogger()
 	
 	// Create repositories
 	serviceMappingRepo := normalizer.NewServiceMappingRepository(db, logger)
@@ -121,10 +146,38 @@ func NewPipeline(db *database.DB) (*Pipeline, error) {
 		unitNormalizer:     unitNormalizer,
 		pricingRepo:        pricingRepo,
 		logger:             logger,
+		notifier:           alerts.NewNotifier(db, alerts.DefaultConfig(), logger),
 		runningJobs:        make(map[string]*Job),
+		finishedJobs:       make(map[string]*Job),
 	}, nil
 }
 
+// LoadMappingConfig syncs a YAML or JSON mapping configuration file to the mapping tables,
+// applies its exclusions to the normalizers, and reloads it every reloadInterval when the file
+// changes until ctx is cancelled. A zero reloadInterval disables reloading.
+func (p *Pipeline) LoadMappingConfig(ctx context.Context, path string, reloadInterval time.Duration) (*normalizer.MappingConfigLoader, error) {
+	var caches []normalizer.CacheInvalidator
+	for _, repo := range []interface{}{p.serviceMappingRepo, p.regionMappingRepo} {
+		if cache, ok := repo.(normalizer.CacheInvalidator); ok {
+			caches = append(caches, cache)
+		}
+	}
+
+	loader := normalizer.NewMappingConfigLoader(path, p.db, p.logger, caches...)
+	if _, err := loader.Load(ctx); err != nil {
+		return nil, err
+	}
+
+	p.awsNormalizer.SetMappingExclusions(loader)
+	p.azureNormalizer.SetMappingExclusions(loader)
+
+	if reloadInterval > 0 {
+		go loader.Watch(ctx, reloadInterval)
+	}
+
+	return loader, nil
+}
+
 // StartJob starts a new ETL job
 func (p *Pipeline) StartJob(jobType JobType, config JobConfiguration) (*Job, error) {
 	jobID := fmt.Sprintf("%s-%d", jobType, time.Now().Unix())
@@ -137,7 +190,7 @@ func (p *Pipeline) StartJob(jobType JobType, config JobConfiguration) (*Job, err
 		config.ConcurrentWorkers = 4
 	}
 	
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(jobLogContext(jobID))
 	
 	job := &Job{
 		ID:            jobID,
@@ -154,14 +207,31 @@ func (p *Pipeline) StartJob(jobType JobType, config JobConfiguration) (*Job, err
 	}
 	
 	p.mu.Lock()
+	if err := p.checkRenormalizationConflict(job); err != nil {
+		p.mu.Unlock()
+		cancel()
+		return nil, err
+	}
 	p.runningJobs[jobID] = job
 	p.mu.Unlock()
 	
+	if config.DryRun {
+		job.dryRun = newDryRunCollector()
+	} else {
+		// Checkpoint batches so the job can be resumed after a restart
+		if err := p.persistJob(job); err != nil {
+			p.jobLogger(job).Warn("ETL job state not persisted, job cannot be resumed",
+				normalizer.Field{"error", err},
+			)
+		} else {
+			job.checkpointing = true
+		}
+	}
+	
 	// Start job in goroutine
 	go p.executeJob(job)
 	
-	p.logger.Info("Started ETL job",
-		normalizer.Field{"jobId", jobID},
+	p.jobLogger(job).Info("Started ETL job",
 		normalizer.Field{"type", jobType},
 		normalizer.Field{"config", config},
 	)
@@ -169,6 +239,45 @@ func (p *Pipeline) StartJob(jobType JobType, config JobConfiguration) (*Job, err
 	return job, nil
 }
 
+// renormalizing reports whether a job fills the staging table, or will once it runs
+func (job *Job) renormalizing() bool {
+	return job.targetTable != "" ||
+		(job.Type == JobTypeNormalizeAll && job.Configuration.ClearExisting && !job.Configuration.DryRun)
+}
+
+// checkRenormalizationConflict rejects a re-normalization while another one is running: there
+// is one staging table, so the second job would drop the table the first one fills. The caller
+// holds p.mu.
+func (p *Pipeline) checkRenormalizationConflict(job *Job) error {
+	if !job.renormalizing() {
+		return nil
+	}
+	for _, running := range p.runningJobs {
+		if running.ID != job.ID && running.renormalizing() {
+			return fmt.Errorf("cannot start a re-normalization while job %s is re-normalizing", running.ID)
+		}
+	}
+	return nil
+}
+
+// jobLogContext returns the base context of a job, carrying its ID as log field
+func jobLogContext(jobID string) context.Context {
+	return normalizer.ContextWithLogFields(context.Background(), normalizer.Field{normalizer.LogKeyJobID, jobID})
+}
+
+// jobLogger returns the pipeline logger with the job ID
+func (p *Pipeline) jobLogger(job *Job) normalizer.Logger {
+	if job.ctx == nil {
+		return normalizer.LoggerFromContext(jobLogContext(job.ID), p.logger)
+	}
+	return normalizer.LoggerFromContext(job.ctx, p.logger)
+}
+
+// providerLogger returns the pipeline logger with the job ID and provider
+func (p *Pipeline) providerLogger(job *Job, provider string) normalizer.Logger {
+	return normalizer.WithFields(p.jobLogger(job), normalizer.Field{normalizer.LogKeyProvider, provider})
+}
+
 // executeJob executes an ETL job
 func (p *Pipeline) executeJob(job *Job) {
 	defer func() {
@@ -181,13 +290,16 @@ func (p *Pipeline) executeJob(job *Job) {
 		
 		p.mu.Lock()
 		delete(p.runningJobs, job.ID)
+		p.retainFinishedJob(job)
 		p.mu.Unlock()
+		
+		p.saveJobState(job)
 	}()
 	
 	job.Status = StatusRunning
+	p.saveJobState(job)
 	
-	p.logger.Info("Executing ETL job",
-		normalizer.Field{"jobId", job.ID},
+	p.jobLogger(job).Info("Executing ETL job",
 		normalizer.Field{"type", job.Type},
 	)
 	
@@ -207,20 +319,32 @@ func (p *Pipeline) executeJob(job *Job) {
 		err = fmt.Errorf("unknown job type: %s", job.Type)
 	}
 	
+	// Compare the candidate records with the live data for dry runs
+	if err == nil && job.dryRun != nil {
+		job.DryRunReport, err = p.buildDryRunReport(job)
+	}
+	
+	// Record the prices the job left live as new versions in the price history and diff the
+	// collections that landed since the last job
+	if err == nil && job.dryRun == nil {
+		p.syncPriceHistory(job)
+		p.refreshPriceSummaries(job)
+		p.diffLandedCollections(job)
+		p.evaluatePriceAlerts(job)
+	}
+	
 	completedAt := time.Now()
 	job.CompletedAt = &completedAt
 	
 	if err != nil {
 		job.Status = StatusFailed
 		job.Error = err.Error()
-		p.logger.Error("ETL job failed",
-			normalizer.Field{"jobId", job.ID},
+		p.jobLogger(job).Error("ETL job failed",
 			normalizer.Field{"error", err},
 		)
 	} else {
 		job.Status = StatusCompleted
-		p.logger.Info("ETL job completed",
-			normalizer.Field{"jobId", job.ID},
+		p.jobLogger(job).Info("ETL job completed",
 			normalizer.Field{"processed", job.Progress.ProcessedRecords},
 			normalizer.Field{"normalized", job.Progress.NormalizedRecords},
 			normalizer.Field{"skipped", job.Progress.SkippedRecords},
@@ -231,41 +355,62 @@ func (p *Pipeline) executeJob(job *Job) {
 
 // normalizeAll normalizes all raw pricing data
 func (p *Pipeline) normalizeAll(job *Job) error {
-	job.Progress.CurrentStage = "Preparing normalization"
-	job.Progress.LastUpdated = time.Now()
-	
-	// Clear existing normalized data if requested
-	if job.Configuration.ClearExisting {
-		job.Progress.CurrentStage = "Clearing existing normalized data"
-		if err := p.clearNormalizedData(job.ctx); err != nil {
-			return fmt.Errorf("failed to clear existing data: %w", err)
+	job.setStage("Preparing normalization")
+	
+	// Re-normalize into a staging table that replaces live data only on success
+	if job.Configuration.ClearExisting && !job.Configuration.DryRun {
+		// A resumed job keeps filling the staging table it was interrupted on
+		if !job.resumed || job.targetTable == "" {
+			if err := p.prepareStaging(job); err != nil {
+				return fmt.Errorf("failed to prepare staging table: %w", err)
+			}
+		}
+		
+		promoted := false
+		defer func() {
+			if !promoted {
+				p.discardStaging(job)
+			}
+		}()
+		
+		if err := p.normalizeProviders(job); err != nil {
+			return err
 		}
+		
+		if err := p.promoteStaging(job); err != nil {
+			return err
+		}
+		promoted = true
+		return nil
 	}
 	
+	return p.normalizeProviders(job)
+}
+
+// normalizeProviders normalizes the configured providers in parallel, each with its own worker budget
+func (p *Pipeline) normalizeProviders(job *Job) error {
 	// Determine providers to process
 	providers := job.Configuration.Providers
 	if len(providers) == 0 {
 		providers = []string{database.ProviderAWS, database.ProviderAzure}
 	}
 	
-	// Process each provider
+	job.Provider = strings.Join(providers, ",")
+	job.setStage(fmt.Sprintf("Processing %s data", job.Provider))
+	
+	// The providers share the job context, so a failing provider stops the others
+	var group errgroup.Group
 	for _, provider := range providers {
-		select {
-		case <-job.ctx.Done():
-			return fmt.Errorf("job cancelled")
-		default:
-		}
-		
-		job.Provider = provider
-		job.Progress.CurrentStage = fmt.Sprintf("Processing %s data", provider)
-		job.Progress.LastUpdated = time.Now()
-		
-		if err := p.normalizeProviderData(job, provider); err != nil {
-			return fmt.Errorf("failed to normalize %s data: %w", provider, err)
-		}
+		group.Go(func() error {
+			if err := p.normalizeProviderData(job, provider); err != nil {
+				job.cancel()
+				return fmt.Errorf("failed to normalize %s data: %w", provider, err)
+			}
+			return nil
+		})
 	}
 	
-	return nil
+	return group.Wait()
 }
 
 // normalizeProvider normalizes data for a specific provider
@@ -297,7 +442,11 @@ func (p *Pipeline) GetJob(jobID string) (*Job, bool) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 	
-	job, exists := p.runningJobs[jobID]
+	if job, exists := p.runningJobs[jobID]; exists {
+		return job, true
+	}
+	
+	job, exists := p.finishedJobs[jobID]
 	return job, exists
 }
 
@@ -306,14 +455,33 @@ func (p *Pipeline) GetAllJobs() []*Job {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 	
-	jobs := make([]*Job, 0, len(p.runningJobs))
+	jobs := make([]*Job, 0, len(p.runningJobs)+len(p.finishedJobs))
 	for _, job := range p.runningJobs {
 		jobs = append(jobs, job)
 	}
+	for _, job := range p.finishedJobs {
+		jobs = append(jobs, job)
+	}
 	
 	return jobs
 }
 
+// retainFinishedJob keeps a finished job available for lookups, evicting the oldest when full.
+// Callers must hold p.mu.
+func (p *Pipeline) retainFinishedJob(job *Job) {
+	if len(p.finishedJobs) >= maxFinishedJobs {
+		var oldest *Job
+		for _, finished := range p.finishedJobs {
+			if oldest == nil || finished.StartedAt.Before(oldest.StartedAt) {
+				oldest = finished
+			}
+		}
+		delete(p.finishedJobs, oldest.ID)
+	}
+	
+	p.finishedJobs[job.ID] = job
+}
+
 // CancelJob cancels a running job
 func (p *Pipeline) CancelJob(jobID string) error {
 	p.mu.Lock()
@@ -333,25 +501,18 @@ func (p *Pipeline) CancelJob(jobID string) error {
 	completedAt := time.Now()
 	job.CompletedAt = &completedAt
 	
-	p.logger.Info("Cancelled ETL job", normalizer.Field{"jobId", jobID})
+	p.saveJobState(job)
 	
-	return nil
-}
-
-// clearNormalizedData clears all normalized pricing data
-func (p *Pipeline) clearNormalizedData(ctx context.Context) error {
-	query := `DELETE FROM normalized_pricing`
-	_, err := p.db.GetConn().ExecContext(ctx, query)
-	if err != nil {
-		return fmt.Errorf("failed to clear normalized data: %w", err)
-	}
+	p.jobLogger(job).Info("Cancelled ETL job")
 	
-	p.logger.Info("Cleared existing normalized data")
 	return nil
 }
 
-// updateJobProgress updates job progress
-func (p *Pipeline) updateJobProgress(job *Job, processed, normalized, skipped, errors int) {
+// updateJobProgress updates overall and per-provider job progress and returns the overall processed count
+func (p *Pipeline) updateJobProgress(job *Job, provider string, processed, normalized, skipped, errors int) int {
+	job.progressMu.Lock()
+	defer job.progressMu.Unlock()
+	
 	job.Progress.ProcessedRecords += processed
 	job.Progress.NormalizedRecords += normalized
 	job.Progress.SkippedRecords += skipped
@@ -363,4 +524,17 @@ func (p *Pipeline) updateJobProgress(job *Job, processed, normalized, skipped, e
 	if duration > 0 {
 		job.Progress.Rate = float64(job.Progress.ProcessedRecords) / duration
 	}
-}
\ No newline at end of file
+	
+	providerProgress := job.providerProgress(provider)
+	providerProgress.ProcessedRecords += processed
+	providerProgress.NormalizedRecords += normalized
+	providerProgress.SkippedRecords += skipped
+	providerProgress.ErrorRecords += errors
+	
+	providerDuration := time.Since(providerProgress.StartedAt).Seconds()
+	if providerDuration > 0 {
+		providerProgress.Rate = float64(providerProgress.ProcessedRecords) / providerDuration
+	}
+	
+	return job.Progress.ProcessedRecords
+}
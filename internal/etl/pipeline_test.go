@@ -0,0 +1,91 @@
+package etl
+
+import (
+	"testing"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJob_Renormalizing(t *testing.T) {
+	tests := []struct {
+		name     string
+		job      *Job
+		expected bool
+	}{
+		{
+			name:     "full re-normalization",
+			job:      &Job{Type: JobTypeNormalizeAll, Configuration: JobConfiguration{ClearExisting: true}},
+			expected: true,
+		},
+		{
+			name:     "dry run of a full re-normalization",
+			job:      &Job{Type: JobTypeNormalizeAll, Configuration: JobConfiguration{ClearExisting: true, DryRun: true}},
+			expected: false,
+		},
+		{
+			name:     "incremental normalization",
+			job:      &Job{Type: JobTypeNormalizeAll},
+			expected: false,
+		},
+		{
+			name:     "resumed job filling the staging table",
+			job:      &Job{Type: JobTypeNormalizeAll, targetTable: database.NormalizedPricingStagingTable},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.job.renormalizing())
+		})
+	}
+}
+
+func TestPipeline_StartJob_RejectsSecondRenormalization(t *testing.T) {
+	running := &Job{
+		ID:            "normalize_all-1",
+		Type:          JobTypeNormalizeAll,
+		Status:        StatusRunning,
+		Configuration: JobConfiguration{ClearExisting: true},
+	}
+	p := &Pipeline{
+		runningJobs:  map[string]*Job{running.ID: running},
+		finishedJobs: make(map[string]*Job),
+	}
+
+	job, err := p.StartJob(JobTypeNormalizeAll, JobConfiguration{ClearExisting: true})
+
+	require.Error(t, err)
+	assert.Nil(t, job)
+	assert.Contains(t, err.Error(), "job normalize_all-1 is re-normalizing")
+	assert.Len(t, p.runningJobs, 1)
+}
+
+func TestPipeline_ResumeJob_RejectsSecondRenormalization(t *testing.T) {
+	running := &Job{
+		ID:            "normalize_all-2",
+		Type:          JobTypeNormalizeAll,
+		Status:        StatusRunning,
+		Configuration: JobConfiguration{ClearExisting: true},
+	}
+	interrupted := &Job{
+		ID:            "normalize_all-1",
+		Type:          JobTypeNormalizeAll,
+		Status:        StatusInterrupted,
+		Configuration: JobConfiguration{ClearExisting: true},
+		targetTable:   database.NormalizedPricingStagingTable,
+	}
+	p := &Pipeline{
+		runningJobs:  map[string]*Job{running.ID: running},
+		finishedJobs: map[string]*Job{interrupted.ID: interrupted},
+	}
+
+	job, err := p.ResumeJob(interrupted.ID)
+
+	require.Error(t, err)
+	assert.Nil(t, job)
+	assert.Contains(t, err.Error(), "job normalize_all-2 is re-normalizing")
+	assert.Contains(t, p.finishedJobs, interrupted.ID, "the interrupted job can be resumed later")
+}
@@ -0,0 +1,183 @@
+package etl
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/raulc0399/cpc/internal/normalizer"
+)
+
+// PriceDiffSummary counts the price changes of a diff by kind
+type PriceDiffSummary struct {
+	New       int `json:"new"`
+	Retired   int `json:"retired"`
+	Increased int `json:"increased"`
+	Decreased int `json:"decreased"`
+}
+
+// PriceDiffReport lists what changed in the prices of a collection since the previous
+// collection of the same scope
+type PriceDiffReport struct {
+	Provider             string                 `json:"provider"`
+	CollectionID         string                 `json:"collectionId"`
+	PreviousCollectionID string                 `json:"previousCollectionId"`
+	GeneratedAt          time.Time              `json:"generatedAt"`
+	Summary              PriceDiffSummary       `json:"summary"`
+	Changes              []database.PriceChange `json:"changes"`
+}
+
+// maxCollectionDiffsPerJob limits how many landed collections a job diffs, newest first; the
+// older ones are diffed by the following jobs
+const maxCollectionDiffsPerJob = 10
+
+// CollectionDiffResult counts the price changes of a collection diffed after a job
+type CollectionDiffResult struct {
+	Provider             string           `json:"provider"`
+	CollectionID         string           `json:"collectionId"`
+	PreviousCollectionID string           `json:"previousCollectionId"`
+	Summary              PriceDiffSummary `json:"summary"`
+}
+
+// diffLandedCollections diffs the collections that landed since the last diff against the
+// previous collection of their scope. The raw data is already stored, so a failed diff is
+// logged without failing the job; the next job retries it.
+func (p *Pipeline) diffLandedCollections(job *Job) {
+	job.setStage("Diffing new collections")
+
+	providers := job.Configuration.Providers
+	if len(providers) == 0 {
+		providers = []string{database.ProviderAWS, database.ProviderAzure}
+	}
+
+	for _, provider := range providers {
+		collectionIDs, err := p.db.UndiffedCollectionIDs(job.ctx, provider, maxCollectionDiffsPerJob)
+		if err != nil {
+			p.providerLogger(job, provider).Error("Failed to find collections to diff",
+				normalizer.Field{Key: "error", Value: err},
+			)
+			continue
+		}
+
+		for _, collectionID := range collectionIDs {
+			report, err := p.PriceDiffReport(job.ctx, provider, collectionID, "")
+			if err != nil {
+				p.providerLogger(job, provider).Error("Failed to diff collection",
+					normalizer.Field{Key: "collectionId", Value: collectionID},
+					normalizer.Field{Key: "error", Value: err},
+				)
+				continue
+			}
+
+			job.PriceDiffs = append(job.PriceDiffs, CollectionDiffResult{
+				Provider:             report.Provider,
+				CollectionID:         report.CollectionID,
+				PreviousCollectionID: report.PreviousCollectionID,
+				Summary:              report.Summary,
+			})
+		}
+	}
+}
+
+// PriceDiffReport diffs a collection against a previous collection and stores the changes in
+// price_changes. An empty collectionID selects the latest completed collection of the provider
+// and an empty previousID the collection before it.
+func (p *Pipeline) PriceDiffReport(ctx context.Context, provider, collectionID, previousID string) (*PriceDiffReport, error) {
+	if provider != database.ProviderAWS && provider != database.ProviderAzure {
+		return nil, fmt.Errorf("unsupported provider: %s", provider)
+	}
+
+	var err error
+	if collectionID == "" {
+		if collectionID, err = p.db.LatestCollectionID(ctx, provider, nil); err != nil {
+			return nil, err
+		}
+		if collectionID == "" {
+			return nil, fmt.Errorf("no completed %s collection found", provider)
+		}
+	}
+	if previousID == "" {
+		if previousID, err = p.db.PreviousCollectionID(ctx, provider, collectionID); err != nil {
+			return nil, err
+		}
+		if previousID == "" {
+			return nil, fmt.Errorf("no previous %s collection found for %s", provider, collectionID)
+		}
+	}
+	if previousID == collectionID {
+		return nil, fmt.Errorf("cannot diff collection %s against itself", collectionID)
+	}
+
+	changes, err := p.db.DiffCollections(ctx, provider, previousID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.db.SavePriceChanges(ctx, provider, collectionID, previousID, changes); err != nil {
+		return nil, err
+	}
+
+	report := &PriceDiffReport{
+		Provider:             provider,
+		CollectionID:         collectionID,
+		PreviousCollectionID: previousID,
+		GeneratedAt:          time.Now(),
+		Summary:              SummarizePriceChanges(changes),
+		Changes:              changes,
+	}
+	sortPriceChanges(report.Changes)
+
+	p.logger.Info("Price diff completed",
+		normalizer.Field{"provider", provider},
+		normalizer.Field{"collectionId", collectionID},
+		normalizer.Field{"previousCollectionId", previousID},
+		normalizer.Field{"new", report.Summary.New},
+		normalizer.Field{"retired", report.Summary.Retired},
+		normalizer.Field{"increased", report.Summary.Increased},
+		normalizer.Field{"decreased", report.Summary.Decreased},
+	)
+	return report, nil
+}
+
+// SummarizePriceChanges counts price changes by kind
+func SummarizePriceChanges(changes []database.PriceChange) PriceDiffSummary {
+	var summary PriceDiffSummary
+	for _, change := range changes {
+		switch change.ChangeType {
+		case database.PriceChangeNew:
+			summary.New++
+		case database.PriceChangeRetired:
+			summary.Retired++
+		case database.PriceChangeIncrease:
+			summary.Increased++
+		case database.PriceChangeDecrease:
+			summary.Decreased++
+		}
+	}
+	return summary
+}
+
+// sortPriceChanges orders changes by kind and then by the size of the change, largest first
+func sortPriceChanges(changes []database.PriceChange) {
+	rank := map[string]int{
+		database.PriceChangeIncrease: 0,
+		database.PriceChangeDecrease: 1,
+		database.PriceChangeNew:      2,
+		database.PriceChangeRetired:  3,
+	}
+	magnitude := func(change database.PriceChange) float64 {
+		if change.ChangePercent == nil {
+			return 0
+		}
+		return math.Abs(*change.ChangePercent)
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if rank[changes[i].ChangeType] != rank[changes[j].ChangeType] {
+			return rank[changes[i].ChangeType] < rank[changes[j].ChangeType]
+		}
+		return magnitude(changes[i]) > magnitude(changes[j])
+	})
+}
@@ -0,0 +1,173 @@
+package etl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Pipeline stages timed per provider
+const (
+	StageCount     = "count"
+	StageFetch     = "fetch"
+	StageNormalize = "normalize"
+	StageInsert    = "insert"
+)
+
+// trackedStages lists the timed stages in pipeline order
+var trackedStages = []string{StageCount, StageFetch, StageNormalize, StageInsert}
+
+// ProviderProgress tracks the progress of a single provider within a job
+type ProviderProgress struct {
+	Provider          string         `json:"provider"`
+	CurrentStage      string         `json:"currentStage"`
+	BatchSize         int            `json:"batchSize"`
+	ConcurrentWorkers int            `json:"concurrentWorkers"`
+	TotalRecords      int            `json:"totalRecords"`
+	ProcessedRecords  int            `json:"processedRecords"`
+	NormalizedRecords int            `json:"normalizedRecords"`
+	SkippedRecords    int            `json:"skippedRecords"`
+	ErrorRecords      int            `json:"errorRecords"`
+	Rate              float64        `json:"rate"` // records per second
+	StartedAt         time.Time      `json:"startedAt"`
+	CompletedAt       *time.Time     `json:"completedAt,omitempty"`
+	Stages            []*StageTiming `json:"stages"`
+}
+
+// StageTiming accumulates the time spent in a pipeline stage. Durations are summed across
+// workers, so for parallel stages they can exceed the provider's wall-clock time.
+type StageTiming struct {
+	Stage      string  `json:"stage"`
+	Calls      int     `json:"calls"`
+	Records    int     `json:"records"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+// WorkersFor returns the worker budget for a provider
+func (c JobConfiguration) WorkersFor(provider string) int {
+	if workers := c.ProviderWorkers[provider]; workers > 0 {
+		return workers
+	}
+	return c.ConcurrentWorkers
+}
+
+// BatchSizeFor returns the batch size for a provider
+func (c JobConfiguration) BatchSizeFor(provider string) int {
+	if batchSize := c.ProviderBatchSize[provider]; batchSize > 0 {
+		return batchSize
+	}
+	return c.BatchSize
+}
+
+// providerProgress returns the progress entry of a provider, creating it if needed.
+// Callers must hold j.progressMu.
+func (j *Job) providerProgress(provider string) *ProviderProgress {
+	for _, progress := range j.Progress.Providers {
+		if progress.Provider == provider {
+			return progress
+		}
+	}
+
+	progress := &ProviderProgress{
+		Provider:          provider,
+		BatchSize:         j.Configuration.BatchSizeFor(provider),
+		ConcurrentWorkers: j.Configuration.WorkersFor(provider),
+		StartedAt:         now(),
+		Stages:            make([]*StageTiming, 0, len(trackedStages)),
+	}
+	for _, stage := range trackedStages {
+		progress.Stages = append(progress.Stages, &StageTiming{Stage: stage})
+	}
+	j.Progress.Providers = append(j.Progress.Providers, progress)
+
+	return progress
+}
+
+// setStage updates the overall stage of the job
+func (j *Job) setStage(stage string) {
+	j.progressMu.Lock()
+	defer j.progressMu.Unlock()
+
+	j.Progress.CurrentStage = stage
+	j.Progress.LastUpdated = now()
+}
+
+// setProviderStage updates the stage of a provider and summarizes all provider stages in the job stage
+func (j *Job) setProviderStage(provider, stage string) {
+	j.progressMu.Lock()
+	defer j.progressMu.Unlock()
+
+	j.providerProgress(provider).CurrentStage = stage
+
+	stages := make([]string, 0, len(j.Progress.Providers))
+	for _, progress := range j.Progress.Providers {
+		stages = append(stages, fmt.Sprintf("%s: %s", progress.Provider, progress.CurrentStage))
+	}
+	j.Progress.CurrentStage = strings.Join(stages, "; ")
+	j.Progress.LastUpdated = now()
+}
+
+// setProviderTotal records a provider's record count and updates the job total
+func (j *Job) setProviderTotal(provider string, total int) {
+	j.progressMu.Lock()
+	defer j.progressMu.Unlock()
+
+	j.providerProgress(provider).TotalRecords = total
+
+	j.Progress.TotalRecords = 0
+	for _, progress := range j.Progress.Providers {
+		j.Progress.TotalRecords += progress.TotalRecords
+	}
+}
+
+// providerTotal returns the record count of a provider
+func (j *Job) providerTotal(provider string) int {
+	j.progressMu.Lock()
+	defer j.progressMu.Unlock()
+
+	return j.providerProgress(provider).TotalRecords
+}
+
+// recordStage adds the duration and record count of one stage call for a provider
+func (j *Job) recordStage(provider, stage string, records int, elapsed time.Duration) {
+	j.progressMu.Lock()
+	defer j.progressMu.Unlock()
+
+	for _, timing := range j.providerProgress(provider).Stages {
+		if timing.Stage == stage {
+			timing.Calls++
+			timing.Records += records
+			timing.DurationMs += float64(elapsed) / float64(time.Millisecond)
+			return
+		}
+	}
+}
+
+// finishProvider marks a provider as completed
+func (j *Job) finishProvider(provider string) {
+	j.progressMu.Lock()
+	defer j.progressMu.Unlock()
+
+	completedAt := now()
+	j.providerProgress(provider).CompletedAt = &completedAt
+}
+
+// ProgressSnapshot returns a consistent copy of the job progress
+func (j *Job) ProgressSnapshot() JobProgress {
+	j.progressMu.Lock()
+	defer j.progressMu.Unlock()
+
+	snapshot := *j.Progress
+	snapshot.Providers = make([]*ProviderProgress, 0, len(j.Progress.Providers))
+	for _, progress := range j.Progress.Providers {
+		providerCopy := *progress
+		providerCopy.Stages = make([]*StageTiming, 0, len(progress.Stages))
+		for _, timing := range progress.Stages {
+			timingCopy := *timing
+			providerCopy.Stages = append(providerCopy.Stages, &timingCopy)
+		}
+		snapshot.Providers = append(snapshot.Providers, &providerCopy)
+	}
+
+	return snapshot
+}
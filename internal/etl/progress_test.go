@@ -0,0 +1,121 @@
+package etl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProgressJob() *Job {
+	return &Job{
+		StartedAt: time.Now().Add(-time.Minute),
+		Progress:  &JobProgress{},
+		Configuration: JobConfiguration{
+			BatchSize:         1000,
+			ConcurrentWorkers: 4,
+			ProviderWorkers:   map[string]int{database.ProviderAzure: 8},
+			ProviderBatchSize: map[string]int{database.ProviderAzure: 500},
+		},
+	}
+}
+
+func TestJobConfiguration_ProviderOverrides(t *testing.T) {
+	config := newProgressJob().Configuration
+
+	assert.Equal(t, 4, config.WorkersFor(database.ProviderAWS))
+	assert.Equal(t, 8, config.WorkersFor(database.ProviderAzure))
+	assert.Equal(t, 1000, config.BatchSizeFor(database.ProviderAWS))
+	assert.Equal(t, 500, config.BatchSizeFor(database.ProviderAzure))
+}
+
+func TestPipeline_UpdateJobProgress_AggregatesProviders(t *testing.T) {
+	job := newProgressJob()
+	p := &Pipeline{}
+
+	p.updateJobProgress(job, database.ProviderAWS, 100, 90, 6, 4)
+	p.updateJobProgress(job, database.ProviderAzure, 50, 50, 0, 0)
+	processed := p.updateJobProgress(job, database.ProviderAWS, 10, 10, 0, 0)
+
+	assert.Equal(t, 160, processed)
+	assert.Equal(t, 160, job.Progress.ProcessedRecords)
+	assert.Equal(t, 150, job.Progress.NormalizedRecords)
+	assert.Equal(t, 6, job.Progress.SkippedRecords)
+	assert.Equal(t, 4, job.Progress.ErrorRecords)
+	assert.Greater(t, job.Progress.Rate, 0.0)
+
+	require.Len(t, job.Progress.Providers, 2)
+	aws, azure := job.Progress.Providers[0], job.Progress.Providers[1]
+	assert.Equal(t, database.ProviderAWS, aws.Provider)
+	assert.Equal(t, 110, aws.ProcessedRecords)
+	assert.Equal(t, 100, aws.NormalizedRecords)
+	assert.Equal(t, 6, aws.SkippedRecords)
+	assert.Equal(t, 4, aws.ErrorRecords)
+	assert.Equal(t, 1000, aws.BatchSize)
+	assert.Equal(t, 4, aws.ConcurrentWorkers)
+	assert.Equal(t, database.ProviderAzure, azure.Provider)
+	assert.Equal(t, 50, azure.ProcessedRecords)
+	assert.Equal(t, 500, azure.BatchSize)
+	assert.Equal(t, 8, azure.ConcurrentWorkers)
+}
+
+func TestJob_SetProviderTotal(t *testing.T) {
+	job := newProgressJob()
+
+	job.setProviderTotal(database.ProviderAWS, 1000)
+	job.setProviderTotal(database.ProviderAzure, 500)
+	assert.Equal(t, 1500, job.Progress.TotalRecords)
+
+	// A recount replaces the provider's total
+	job.setProviderTotal(database.ProviderAWS, 800)
+	assert.Equal(t, 1300, job.Progress.TotalRecords)
+	assert.Equal(t, 800, job.providerTotal(database.ProviderAWS))
+}
+
+func TestJob_SetProviderStage(t *testing.T) {
+	job := newProgressJob()
+
+	job.setProviderStage(database.ProviderAWS, StageFetch)
+	job.setProviderStage(database.ProviderAzure, StageNormalize)
+	job.setProviderStage(database.ProviderAWS, StageInsert)
+
+	assert.Equal(t, "aws: insert; azure: normalize", job.Progress.CurrentStage)
+}
+
+func TestJob_RecordStage(t *testing.T) {
+	job := newProgressJob()
+
+	job.recordStage(database.ProviderAWS, StageFetch, 1000, 200*time.Millisecond)
+	job.recordStage(database.ProviderAWS, StageFetch, 500, 100*time.Millisecond)
+	job.recordStage(database.ProviderAWS, "unknown", 10, time.Second)
+
+	stages := job.providerProgress(database.ProviderAWS).Stages
+	require.Len(t, stages, len(trackedStages))
+	for _, timing := range stages {
+		if timing.Stage != StageFetch {
+			assert.Zero(t, timing.Calls, timing.Stage)
+			continue
+		}
+		assert.Equal(t, 2, timing.Calls)
+		assert.Equal(t, 1500, timing.Records)
+		assert.InDelta(t, 300, timing.DurationMs, 1e-9)
+	}
+}
+
+func TestJob_ProgressSnapshot(t *testing.T) {
+	job := newProgressJob()
+	p := &Pipeline{}
+	p.updateJobProgress(job, database.ProviderAWS, 100, 100, 0, 0)
+	job.recordStage(database.ProviderAWS, StageInsert, 100, time.Millisecond)
+
+	snapshot := job.ProgressSnapshot()
+	p.updateJobProgress(job, database.ProviderAWS, 50, 50, 0, 0)
+	job.recordStage(database.ProviderAWS, StageInsert, 50, time.Millisecond)
+
+	assert.Equal(t, 100, snapshot.ProcessedRecords)
+	assert.Equal(t, 100, snapshot.Providers[0].ProcessedRecords)
+	assert.Equal(t, 1, snapshot.Providers[0].Stages[3].Calls, "stage timings are copied")
+	assert.Equal(t, 150, job.Progress.Providers[0].ProcessedRecords)
+}
@@ -0,0 +1,94 @@
+package etl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/raulc0399/cpc/internal/normalizer"
+)
+
+// prepareStaging creates an empty staging table that receives all inserts of a full re-normalization
+func (p *Pipeline) prepareStaging(job *Job) error {
+	job.setStage("Preparing staging table")
+
+	if err := p.db.CreateNormalizedPricingStaging(job.ctx); err != nil {
+		return err
+	}
+
+	job.targetTable = database.NormalizedPricingStagingTable
+	p.saveJobState(job)
+	p.jobLogger(job).Info("Normalizing into staging table")
+	return nil
+}
+
+// promoteStaging swaps the staging table in as live data once the job has finished successfully
+func (p *Pipeline) promoteStaging(job *Job) error {
+	// Cancellation may race with the last batches, so never promote a cancelled job
+	if job.ctx.Err() != nil {
+		return fmt.Errorf("job cancelled")
+	}
+
+	// A staging table with missing batches must not replace complete live data
+	if job.stagingFailed.Load() {
+		return fmt.Errorf("staging table is incomplete because some batches failed to insert")
+	}
+
+	job.setStage("Swapping staging table into place")
+
+	if err := p.db.SwapNormalizedPricingStaging(job.ctx); err != nil {
+		return fmt.Errorf("failed to promote staging table: %w", err)
+	}
+
+	job.targetTable = ""
+	p.saveJobState(job)
+	p.jobLogger(job).Info("Promoted staging table to live normalized data")
+	return nil
+}
+
+// discardStaging drops the staging table of a failed or cancelled job
+func (p *Pipeline) discardStaging(job *Job) {
+	// The job context may already be cancelled at this point
+	if err := p.db.DropNormalizedPricingStaging(context.Background()); err != nil {
+		p.jobLogger(job).Error("Failed to discard staging table",
+			normalizer.Field{"error", err},
+		)
+		return
+	}
+
+	job.targetTable = ""
+	p.saveJobState(job)
+	p.jobLogger(job).Info("Discarded staging table, live data left untouched")
+}
+
+// RollbackNormalization restores the previous generation of normalized pricing data
+func (p *Pipeline) RollbackNormalization(ctx context.Context) error {
+	p.mu.RLock()
+	for _, job := range p.runningJobs {
+		if job.renormalizing() {
+			p.mu.RUnlock()
+			return fmt.Errorf("cannot roll back while job %s is re-normalizing", job.ID)
+		}
+	}
+	p.mu.RUnlock()
+
+	if err := p.db.RollbackNormalizedPricing(ctx); err != nil {
+		return err
+	}
+
+	p.logger.Info("Rolled back normalized data to the previous generation")
+
+	// The restored prices are live again, so they become the current versions in the history
+	if _, err := p.db.SyncNormalizedPricingHistory(ctx, "", time.Now()); err != nil {
+		p.logger.Error("Failed to sync price history after rollback",
+			normalizer.Field{"error", err},
+		)
+	}
+	if _, err := p.db.RefreshPriceSummaries(ctx); err != nil {
+		p.logger.Error("Failed to refresh price summaries after rollback",
+			normalizer.Field{"error", err},
+		)
+	}
+	return nil
+}
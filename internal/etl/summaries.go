@@ -0,0 +1,26 @@
+package etl
+
+import (
+	"github.com/raulc0399/cpc/internal/normalizer"
+)
+
+// refreshPriceSummaries rebuilds the price summary tables from the prices the job left live.
+// Stale summaries only degrade the optimizer, so a failed refresh is logged without failing
+// the job.
+func (p *Pipeline) refreshPriceSummaries(job *Job) {
+	job.setStage("Refreshing price summaries")
+
+	result, err := p.db.RefreshPriceSummaries(job.ctx)
+	if err != nil {
+		p.jobLogger(job).Error("Failed to refresh price summaries",
+			normalizer.Field{"error", err},
+		)
+		return
+	}
+
+	job.PriceSummaries = result
+	p.jobLogger(job).Info("Refreshed price summaries",
+		normalizer.Field{"computeRows", result.ComputeRows},
+		normalizer.Field{"indexRows", result.IndexRows},
+	)
+}
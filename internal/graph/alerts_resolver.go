@@ -0,0 +1,272 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/alerts"
+	"github.com/raulc0399/cpc/internal/database"
+)
+
+// AlertWebhooks returns the configured alert webhooks without their secrets
+func (r *queryResolver) AlertWebhooks(ctx context.Context) ([]*AlertWebhook, error) {
+	if r.DB == nil {
+		return nil, errPostgresRequired
+	}
+	webhooks, err := r.DB.GetAlertWebhooks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert webhooks: %w", err)
+	}
+
+	result := make([]*AlertWebhook, len(webhooks))
+	for i, webhook := range webhooks {
+		result[i] = convertAlertWebhook(webhook)
+	}
+	return result, nil
+}
+
+// AlertRules returns all alert rules
+func (r *queryResolver) AlertRules(ctx context.Context) ([]*AlertRule, error) {
+	if r.DB == nil {
+		return nil, errPostgresRequired
+	}
+	rules, err := r.DB.GetAlertRules(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert rules: %w", err)
+	}
+
+	result := make([]*AlertRule, len(rules))
+	for i, rule := range rules {
+		result[i] = convertAlertRule(rule)
+	}
+	return result, nil
+}
+
+// AlertDeliveries returns the webhook delivery log, optionally of one rule
+func (r *queryResolver) AlertDeliveries(ctx context.Context, ruleID *string, limit *int) ([]*AlertDelivery, error) {
+	if r.DB == nil {
+		return nil, errPostgresRequired
+	}
+	var rule *int
+	if ruleID != nil {
+		id, err := parseAlertID(*ruleID)
+		if err != nil {
+			return nil, err
+		}
+		rule = &id
+	}
+
+	maxDeliveries := 100
+	if limit != nil {
+		maxDeliveries = *limit
+	}
+
+	deliveries, err := r.DB.GetAlertDeliveries(ctx, rule, maxDeliveries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert deliveries: %w", err)
+	}
+
+	result := make([]*AlertDelivery, len(deliveries))
+	for i, delivery := range deliveries {
+		result[i] = &AlertDelivery{
+			ID:             strconv.Itoa(delivery.ID),
+			RuleID:         strconv.Itoa(delivery.RuleID),
+			WebhookID:      strconv.Itoa(delivery.WebhookID),
+			Payload:        string(delivery.Payload),
+			Status:         delivery.Status,
+			Attempts:       delivery.Attempts,
+			ResponseStatus: delivery.ResponseStatus,
+			Error:          delivery.Error,
+			CreatedAt:      delivery.CreatedAt.Format(time.RFC3339),
+		}
+		if delivery.ETLJobID != "" {
+			jobID := delivery.ETLJobID
+			result[i].EtlJobID = &jobID
+		}
+		if delivery.DeliveredAt != nil {
+			deliveredAt := delivery.DeliveredAt.Format(time.RFC3339)
+			result[i].DeliveredAt = &deliveredAt
+		}
+	}
+	return result, nil
+}
+
+// CreateAlertWebhook stores a webhook and returns it with its secret
+func (r *mutationResolver) CreateAlertWebhook(ctx context.Context, input AlertWebhookInput) (*AlertWebhook, error) {
+	if r.DB == nil {
+		return nil, errPostgresRequired
+	}
+	target, err := url.Parse(input.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("invalid webhook URL: %s", input.URL)
+	}
+
+	webhook := database.AlertWebhook{
+		Name:    strings.TrimSpace(input.Name),
+		URL:     input.URL,
+		Enabled: true,
+	}
+	if webhook.Name == "" {
+		return nil, fmt.Errorf("webhook name is required")
+	}
+	if input.Enabled != nil {
+		webhook.Enabled = *input.Enabled
+	}
+	if input.Secret != nil && *input.Secret != "" {
+		webhook.Secret = *input.Secret
+	} else if webhook.Secret, err = alerts.GenerateSecret(); err != nil {
+		return nil, err
+	}
+
+	if err := r.DB.CreateAlertWebhook(ctx, &webhook); err != nil {
+		return nil, err
+	}
+
+	result := convertAlertWebhook(webhook)
+	result.Secret = &webhook.Secret
+	return result, nil
+}
+
+// DeleteAlertWebhook deletes a webhook with its rules and delivery log
+func (r *mutationResolver) DeleteAlertWebhook(ctx context.Context, id string) (bool, error) {
+	if r.DB == nil {
+		return false, errPostgresRequired
+	}
+	webhookID, err := parseAlertID(id)
+	if err != nil {
+		return false, err
+	}
+	return r.DB.DeleteAlertWebhook(ctx, webhookID)
+}
+
+// CreateAlertRule stores an alert rule
+func (r *mutationResolver) CreateAlertRule(ctx context.Context, input AlertRuleInput) (*AlertRule, error) {
+	if r.DB == nil {
+		return nil, errPostgresRequired
+	}
+	rule, err := alertRuleFromInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.DB.CreateAlertRule(ctx, &rule); err != nil {
+		return nil, err
+	}
+	return convertAlertRule(rule), nil
+}
+
+// UpdateAlertRule replaces the settings of an alert rule
+func (r *mutationResolver) UpdateAlertRule(ctx context.Context, id string, input AlertRuleInput) (*AlertRule, error) {
+	if r.DB == nil {
+		return nil, errPostgresRequired
+	}
+	rule, err := alertRuleFromInput(input)
+	if err != nil {
+		return nil, err
+	}
+	if rule.ID, err = parseAlertID(id); err != nil {
+		return nil, err
+	}
+
+	found, err := r.DB.UpdateAlertRule(ctx, &rule)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("alert rule not found: %s", id)
+	}
+	return convertAlertRule(rule), nil
+}
+
+// DeleteAlertRule deletes an alert rule with its delivery log
+func (r *mutationResolver) DeleteAlertRule(ctx context.Context, id string) (bool, error) {
+	if r.DB == nil {
+		return false, errPostgresRequired
+	}
+	ruleID, err := parseAlertID(id)
+	if err != nil {
+		return false, err
+	}
+	return r.DB.DeleteAlertRule(ctx, ruleID)
+}
+
+func alertRuleFromInput(input AlertRuleInput) (database.AlertRule, error) {
+	webhookID, err := parseAlertID(input.WebhookID)
+	if err != nil {
+		return database.AlertRule{}, err
+	}
+
+	rule := database.AlertRule{
+		Name:             strings.TrimSpace(input.Name),
+		WebhookID:        webhookID,
+		ThresholdPercent: input.ThresholdPercent,
+		Direction:        database.AlertDirectionAny,
+		Enabled:          true,
+	}
+	if input.Direction != nil {
+		rule.Direction = strings.ToLower(*input.Direction)
+	}
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+	if input.Filter != nil {
+		rule.Filter = database.AlertRuleFilter{
+			Provider:         input.Filter.Provider,
+			ServiceCategory:  input.Filter.ServiceCategory,
+			ServiceType:      input.Filter.ServiceType,
+			ResourceName:     input.Filter.ResourceName,
+			ProviderSKU:      input.Filter.ProviderSku,
+			NormalizedRegion: input.Filter.NormalizedRegion,
+			PricingModel:     input.Filter.PricingModel,
+			OperatingSystem:  input.Filter.OperatingSystem,
+		}
+	}
+
+	return rule, rule.Validate()
+}
+
+func parseAlertID(id string) (int, error) {
+	value, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ID: %s", id)
+	}
+	return value, nil
+}
+
+func convertAlertWebhook(webhook database.AlertWebhook) *AlertWebhook {
+	return &AlertWebhook{
+		ID:        strconv.Itoa(webhook.ID),
+		Name:      webhook.Name,
+		URL:       webhook.URL,
+		Enabled:   webhook.Enabled,
+		CreatedAt: webhook.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: webhook.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func convertAlertRule(rule database.AlertRule) *AlertRule {
+	return &AlertRule{
+		ID:        strconv.Itoa(rule.ID),
+		Name:      rule.Name,
+		WebhookID: strconv.Itoa(rule.WebhookID),
+		Filter: &AlertRuleFilter{
+			Provider:         rule.Filter.Provider,
+			ServiceCategory:  rule.Filter.ServiceCategory,
+			ServiceType:      rule.Filter.ServiceType,
+			ResourceName:     rule.Filter.ResourceName,
+			ProviderSku:      rule.Filter.ProviderSKU,
+			NormalizedRegion: rule.Filter.NormalizedRegion,
+			PricingModel:     rule.Filter.PricingModel,
+			OperatingSystem:  rule.Filter.OperatingSystem,
+		},
+		ThresholdPercent: rule.ThresholdPercent,
+		Direction:        rule.Direction,
+		Enabled:          rule.Enabled,
+		CreatedAt:        rule.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:        rule.UpdatedAt.Format(time.RFC3339),
+	}
+}
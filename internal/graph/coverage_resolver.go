@@ -0,0 +1,73 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/etl"
+)
+
+// NormalizationCoverage reports how many raw records reach normalized_pricing per provider
+// service and region
+func (r *queryResolver) NormalizationCoverage(ctx context.Context, providers []string, top *int) (*CoverageReport, error) {
+	if r.pipeline == nil {
+		return nil, fmt.Errorf("ETL pipeline not initialized")
+	}
+
+	report, err := r.pipeline.CoverageReport(ctx, providers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build coverage report: %w", err)
+	}
+
+	result := &CoverageReport{
+		GeneratedAt:      report.GeneratedAt.Format(time.RFC3339),
+		Summary:          convertCoverageCounts(report.Summary),
+		Providers:        make([]*ProviderCoverage, len(report.Providers)),
+		UnmappedServices: make([]*UnmappedService, 0, len(report.UnmappedServices)),
+		Entries:          make([]*CoverageEntry, len(report.Entries)),
+	}
+
+	for i, provider := range report.Providers {
+		result.Providers[i] = &ProviderCoverage{
+			Provider: provider.Provider,
+			Counts:   convertCoverageCounts(provider.CoverageCounts),
+		}
+	}
+
+	for _, service := range report.UnmappedServices {
+		if top != nil && *top >= 0 && len(result.UnmappedServices) >= *top {
+			break
+		}
+		result.UnmappedServices = append(result.UnmappedServices, &UnmappedService{
+			Provider:        service.Provider,
+			Service:         service.Service,
+			ServiceMapped:   service.ServiceMapped,
+			UnmappedRecords: service.UnmappedRecords,
+			Regions:         service.Regions,
+		})
+	}
+
+	for i, entry := range report.Entries {
+		result.Entries[i] = &CoverageEntry{
+			Provider:      entry.Provider,
+			Service:       entry.Service,
+			Region:        entry.Region,
+			ServiceMapped: entry.ServiceMapped,
+			RegionMapped:  entry.RegionMapped,
+			Counts:        convertCoverageCounts(entry.CoverageCounts),
+		}
+	}
+
+	return result, nil
+}
+
+func convertCoverageCounts(counts etl.CoverageCounts) *CoverageCounts {
+	return &CoverageCounts{
+		RawRecords:      counts.RawRecords,
+		MappedRecords:   counts.MappedRecords,
+		UnmappedRecords: counts.UnmappedRecords,
+		FailedRecords:   counts.FailedRecords,
+		CoveragePercent: counts.CoveragePercent,
+	}
+}
@@ -3,7 +3,6 @@ package graph
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/raulc0399/cpc/internal/etl"
@@ -75,6 +74,20 @@ func (r *mutationResolver) StartNormalization(ctx context.Context, config Normal
 	if len(config.Services) > 0 {
 		etlConfig.Services = config.Services
 	}
+	for _, budget := range config.ProviderBudgets {
+		if budget.ConcurrentWorkers != nil {
+			if etlConfig.ProviderWorkers == nil {
+				etlConfig.ProviderWorkers = make(map[string]int)
+			}
+			etlConfig.ProviderWorkers[budget.Provider] = *budget.ConcurrentWorkers
+		}
+		if budget.BatchSize != nil {
+			if etlConfig.ProviderBatchSize == nil {
+				etlConfig.ProviderBatchSize = make(map[string]int)
+			}
+			etlConfig.ProviderBatchSize[budget.Provider] = *budget.BatchSize
+		}
+	}
 	
 	// Convert job type
 	jobType := convertGraphQLJobType(config.Type)
@@ -102,6 +115,34 @@ func (r *mutationResolver) CancelETLJob(ctx context.Context, id string) (bool, e
 	return true, nil
 }
 
+// ResumeETLJob resumes an interrupted ETL job from its last committed batch
+func (r *mutationResolver) ResumeETLJob(ctx context.Context, id string) (*ETLJob, error) {
+	if r.pipeline == nil {
+		return nil, fmt.Errorf("ETL pipeline not initialized")
+	}
+	
+	job, err := r.pipeline.ResumeJob(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume job: %w", err)
+	}
+	
+	return convertJobToGraphQL(job), nil
+}
+
+// RollbackNormalization restores the previous generation of normalized pricing data
+func (r *mutationResolver) RollbackNormalization(ctx context.Context) (bool, error) {
+	if r.pipeline == nil {
+		return false, fmt.Errorf("ETL pipeline not initialized")
+	}
+	
+	err := r.pipeline.RollbackNormalization(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to roll back normalization: %w", err)
+	}
+	
+	return true, nil
+}
+
 // Helper functions
 
 // convertJobToGraphQL converts an ETL job to GraphQL format
@@ -140,16 +181,111 @@ func convertJobToGraphQL(job *etl.Job) *ETLJob {
 	}
 	
 	if job.Progress != nil {
+		progress := job.ProgressSnapshot()
 		result.Progress = &ETLJobProgress{
-			TotalRecords:      job.Progress.TotalRecords,
-			ProcessedRecords:  job.Progress.ProcessedRecords,
-			NormalizedRecords: job.Progress.NormalizedRecords,
-			SkippedRecords:    job.Progress.SkippedRecords,
-			ErrorRecords:      job.Progress.ErrorRecords,
-			CurrentStage:      job.Progress.CurrentStage,
-			LastUpdated:       job.Progress.LastUpdated.Format(time.RFC3339),
-			Rate:              job.Progress.Rate,
+			TotalRecords:      progress.TotalRecords,
+			ProcessedRecords:  progress.ProcessedRecords,
+			NormalizedRecords: progress.NormalizedRecords,
+			SkippedRecords:    progress.SkippedRecords,
+			ErrorRecords:      progress.ErrorRecords,
+			CurrentStage:      progress.CurrentStage,
+			LastUpdated:       progress.LastUpdated.Format(time.RFC3339),
+			Rate:              progress.Rate,
+			Providers:         make([]*ETLProviderProgress, 0, len(progress.Providers)),
+		}
+		
+		for _, providerProgress := range progress.Providers {
+			result.Progress.Providers = append(result.Progress.Providers, convertProviderProgressToGraphQL(providerProgress))
+		}
+	}
+	
+	if job.DryRunReport != nil {
+		result.DryRunReport = convertDryRunReportToGraphQL(job.DryRunReport)
+	}
+	
+	return result
+}
+
+// convertProviderProgressToGraphQL converts per-provider job progress to GraphQL format
+func convertProviderProgressToGraphQL(progress *etl.ProviderProgress) *ETLProviderProgress {
+	result := &ETLProviderProgress{
+		Provider:          progress.Provider,
+		CurrentStage:      progress.CurrentStage,
+		BatchSize:         progress.BatchSize,
+		ConcurrentWorkers: progress.ConcurrentWorkers,
+		TotalRecords:      progress.TotalRecords,
+		ProcessedRecords:  progress.ProcessedRecords,
+		NormalizedRecords: progress.NormalizedRecords,
+		SkippedRecords:    progress.SkippedRecords,
+		ErrorRecords:      progress.ErrorRecords,
+		Rate:              progress.Rate,
+		StartedAt:         progress.StartedAt.Format(time.RFC3339),
+		Stages:            make([]*ETLStageTiming, 0, len(progress.Stages)),
+	}
+	
+	if progress.CompletedAt != nil {
+		completedAt := progress.CompletedAt.Format(time.RFC3339)
+		result.CompletedAt = &completedAt
+	}
+	
+	for _, timing := range progress.Stages {
+		result.Stages = append(result.Stages, &ETLStageTiming{
+			Stage:      timing.Stage,
+			Calls:      timing.Calls,
+			Records:    timing.Records,
+			DurationMs: timing.DurationMs,
+		})
+	}
+	
+	return result
+}
+
+// convertDryRunReportToGraphQL converts a dry-run diff report to GraphQL format
+func convertDryRunReportToGraphQL(report *etl.DryRunReport) *DryRunReport {
+	result := &DryRunReport{
+		JobID:       report.JobID,
+		GeneratedAt: report.GeneratedAt.Format(time.RFC3339),
+		Summary: &DiffSummary{
+			CandidateRecords: report.Summary.CandidateRecords,
+			ExistingRecords:  report.Summary.ExistingRecords,
+			NewRecords:       report.Summary.NewRecords,
+			RemovedRecords:   report.Summary.RemovedRecords,
+			PriceChanged:     report.Summary.PriceChanged,
+			Unchanged:        report.Summary.Unchanged,
+		},
+		Groups: make([]*DiffGroup, 0, len(report.Groups)),
+	}
+	
+	for _, group := range report.Groups {
+		gqlGroup := &DiffGroup{
+			Provider:         group.Provider,
+			ServiceType:      group.ServiceType,
+			NormalizedRegion: group.NormalizedRegion,
+			NewRecords:       group.NewRecords,
+			RemovedRecords:   group.RemovedRecords,
+			PriceChanged:     group.PriceChanged,
+			Unchanged:        group.Unchanged,
+			Samples:          make([]*DiffChange, 0, len(group.Samples)),
+		}
+		
+		for _, sample := range group.Samples {
+			change := &DiffChange{
+				Type:          string(sample.Type),
+				ResourceName:  sample.ResourceName,
+				PricingModel:  sample.PricingModel,
+				Unit:          sample.Unit,
+				OldPrice:      sample.OldPrice,
+				NewPrice:      sample.NewPrice,
+				ChangePercent: sample.ChangePercent,
+			}
+			if sample.ProviderSKU != "" {
+				sku := sample.ProviderSKU
+				change.ProviderSku = &sku
+			}
+			gqlGroup.Samples = append(gqlGroup.Samples, change)
 		}
+		
+		result.Groups = append(result.Groups, gqlGroup)
 	}
 	
 	return result
@@ -204,6 +340,8 @@ func convertJobStatusToGraphQL(status etl.JobStatus) ETLJobStatus {
 		return ETLJobStatusFailed
 	case etl.StatusCancelled:
 		return ETLJobStatusCancelled
+	case etl.StatusInterrupted:
+		return ETLJobStatusInterrupted
 	default:
 		return ETLJobStatusPending
 	}
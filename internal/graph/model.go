@@ -1,4 +1,79 @@
 package graph
 
-// This file contains any custom model types that are not auto-generated.
-// Auto-generated types are now in models_gen.go
\ No newline at end of file
+import "github.com/raulc0399/cpc/internal/currency"
+
+// This file contains the model types that are not auto-generated. gqlgen binds their fields to
+// the methods in resolver.go; the other types are generated in models_gen.go.
+
+// AWSProvider resolves the AWS pricing of a region
+type AWSProvider struct {
+	resolver *Resolver
+}
+
+// AWSCompute resolves EC2 instance prices in a region
+type AWSCompute struct {
+	priceConversion
+	resolver *Resolver
+	region   string
+}
+
+// AWSStorage resolves S3 storage prices in a region
+type AWSStorage struct {
+	priceConversion
+	resolver *Resolver
+	region   string
+}
+
+// AWSDataTransfer resolves AWS data transfer prices in a region
+type AWSDataTransfer struct {
+	priceConversion
+	resolver *Resolver
+	region   string
+}
+
+// AzureProvider resolves the Azure pricing of a region
+type AzureProvider struct {
+	resolver *Resolver
+}
+
+// AzureCompute resolves Azure VM prices in a region
+type AzureCompute struct {
+	priceConversion
+	resolver *Resolver
+	region   string
+}
+
+// AzureStorage resolves Azure Blob storage prices in a region
+type AzureStorage struct {
+	priceConversion
+	resolver *Resolver
+	region   string
+}
+
+// AzureDataTransfer resolves Azure data transfer prices in a region
+type AzureDataTransfer struct {
+	priceConversion
+	resolver *Resolver
+	region   string
+}
+
+// priceConversion converts the USD prices of a provider field to the requested currency
+type priceConversion struct {
+	conversion *currency.Conversion
+}
+
+// convert converts a USD price, returning it unchanged when no currency was requested
+func (c priceConversion) convert(price float64) float64 {
+	if c.conversion == nil {
+		return price
+	}
+	return c.conversion.Apply(price)
+}
+
+// Conversion resolves the exchange rate applied to the prices
+func (c priceConversion) Conversion() *CurrencyConversion {
+	if c.conversion == nil {
+		return nil
+	}
+	return convertConversionToGraphQL(c.conversion)
+}
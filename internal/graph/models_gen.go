@@ -3,23 +3,11 @@
 package graph
 
 import (
-	"bytes"
 	"fmt"
 	"io"
 	"strconv"
 )
 
-type AWSCompute struct {
-	InstancePrice float64        `json:"instancePrice"`
-	Instances     []*AWSInstance `json:"instances"`
-}
-
-type AWSDataTransfer struct {
-	PricePerGb float64 `json:"pricePerGB"`
-	Inbound    float64 `json:"inbound"`
-	Outbound   float64 `json:"outbound"`
-}
-
 type AWSInstance struct {
 	Type         string  `json:"type"`
 	Vcpu         int     `json:"vcpu"`
@@ -29,43 +17,84 @@ type AWSInstance struct {
 	Burstable    *bool   `json:"burstable,omitempty"`
 }
 
-type AWSProvider struct {
-	Compute      *AWSCompute      `json:"compute"`
-	Storage      *AWSStorage      `json:"storage"`
-	DataTransfer *AWSDataTransfer `json:"dataTransfer"`
-}
-
-type AWSStorage struct {
-	PricePerGb float64           `json:"pricePerGB"`
-	Tiers      []*AWSStorageTier `json:"tiers"`
-}
-
 type AWSStorageTier struct {
 	Name        string  `json:"name"`
 	PricePerGb  float64 `json:"pricePerGB"`
 	Description *string `json:"description,omitempty"`
 }
 
-type AzureCompute struct {
-	VMPrice float64    `json:"vmPrice"`
-	Vms     []*AzureVM `json:"vms"`
-}
-
-type AzureDataTransfer struct {
-	PricePerGb float64 `json:"pricePerGB"`
-	Inbound    float64 `json:"inbound"`
-	Outbound   float64 `json:"outbound"`
-}
-
-type AzureProvider struct {
-	Compute      *AzureCompute      `json:"compute"`
-	Storage      *AzureStorage      `json:"storage"`
-	DataTransfer *AzureDataTransfer `json:"dataTransfer"`
-}
-
-type AzureStorage struct {
-	PricePerGb float64             `json:"pricePerGB"`
-	Tiers      []*AzureStorageTier `json:"tiers"`
+type AlertDelivery struct {
+	ID             string  `json:"id"`
+	RuleID         string  `json:"ruleId"`
+	WebhookID      string  `json:"webhookId"`
+	EtlJobID       *string `json:"etlJobId,omitempty"`
+	Payload        string  `json:"payload"`
+	Status         string  `json:"status"`
+	Attempts       int     `json:"attempts"`
+	ResponseStatus *int    `json:"responseStatus,omitempty"`
+	Error          *string `json:"error,omitempty"`
+	CreatedAt      string  `json:"createdAt"`
+	DeliveredAt    *string `json:"deliveredAt,omitempty"`
+}
+
+type AlertRule struct {
+	ID               string           `json:"id"`
+	Name             string           `json:"name"`
+	WebhookID        string           `json:"webhookId"`
+	Filter           *AlertRuleFilter `json:"filter"`
+	ThresholdPercent float64          `json:"thresholdPercent"`
+	Direction        string           `json:"direction"`
+	Enabled          bool             `json:"enabled"`
+	CreatedAt        string           `json:"createdAt"`
+	UpdatedAt        string           `json:"updatedAt"`
+}
+
+type AlertRuleFilter struct {
+	Provider         *string `json:"provider,omitempty"`
+	ServiceCategory  *string `json:"serviceCategory,omitempty"`
+	ServiceType      *string `json:"serviceType,omitempty"`
+	ResourceName     *string `json:"resourceName,omitempty"`
+	ProviderSku      *string `json:"providerSku,omitempty"`
+	NormalizedRegion *string `json:"normalizedRegion,omitempty"`
+	PricingModel     *string `json:"pricingModel,omitempty"`
+	OperatingSystem  *string `json:"operatingSystem,omitempty"`
+}
+
+type AlertRuleFilterInput struct {
+	Provider         *string `json:"provider,omitempty"`
+	ServiceCategory  *string `json:"serviceCategory,omitempty"`
+	ServiceType      *string `json:"serviceType,omitempty"`
+	ResourceName     *string `json:"resourceName,omitempty"`
+	ProviderSku      *string `json:"providerSku,omitempty"`
+	NormalizedRegion *string `json:"normalizedRegion,omitempty"`
+	PricingModel     *string `json:"pricingModel,omitempty"`
+	OperatingSystem  *string `json:"operatingSystem,omitempty"`
+}
+
+type AlertRuleInput struct {
+	Name             string                `json:"name"`
+	WebhookID        string                `json:"webhookId"`
+	Filter           *AlertRuleFilterInput `json:"filter,omitempty"`
+	ThresholdPercent float64               `json:"thresholdPercent"`
+	Direction        *string               `json:"direction,omitempty"`
+	Enabled          *bool                 `json:"enabled,omitempty"`
+}
+
+type AlertWebhook struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	URL       string  `json:"url"`
+	Enabled   bool    `json:"enabled"`
+	Secret    *string `json:"secret,omitempty"`
+	CreatedAt string  `json:"createdAt"`
+	UpdatedAt string  `json:"updatedAt"`
+}
+
+type AlertWebhookInput struct {
+	Name    string  `json:"name"`
+	URL     string  `json:"url"`
+	Secret  *string `json:"secret,omitempty"`
+	Enabled *bool   `json:"enabled,omitempty"`
 }
 
 type AzureStorageTier struct {
@@ -90,6 +119,76 @@ type Category struct {
 	CreatedAt   string  `json:"createdAt"`
 }
 
+type CoverageCounts struct {
+	RawRecords      int     `json:"rawRecords"`
+	MappedRecords   int     `json:"mappedRecords"`
+	UnmappedRecords int     `json:"unmappedRecords"`
+	FailedRecords   int     `json:"failedRecords"`
+	CoveragePercent float64 `json:"coveragePercent"`
+}
+
+type CoverageEntry struct {
+	Provider      string          `json:"provider"`
+	Service       string          `json:"service"`
+	Region        string          `json:"region"`
+	ServiceMapped bool            `json:"serviceMapped"`
+	RegionMapped  bool            `json:"regionMapped"`
+	Counts        *CoverageCounts `json:"counts"`
+}
+
+type CoverageReport struct {
+	GeneratedAt      string              `json:"generatedAt"`
+	Summary          *CoverageCounts     `json:"summary"`
+	Providers        []*ProviderCoverage `json:"providers"`
+	UnmappedServices []*UnmappedService  `json:"unmappedServices"`
+	Entries          []*CoverageEntry    `json:"entries"`
+}
+
+type CurrencyConversion struct {
+	FromCurrency string  `json:"fromCurrency"`
+	ToCurrency   string  `json:"toCurrency"`
+	Rate         float64 `json:"rate"`
+	RateDate     string  `json:"rateDate"`
+}
+
+type DiffChange struct {
+	Type          string   `json:"type"`
+	ResourceName  string   `json:"resourceName"`
+	ProviderSku   *string  `json:"providerSku,omitempty"`
+	PricingModel  string   `json:"pricingModel"`
+	Unit          string   `json:"unit"`
+	OldPrice      *float64 `json:"oldPrice,omitempty"`
+	NewPrice      *float64 `json:"newPrice,omitempty"`
+	ChangePercent *float64 `json:"changePercent,omitempty"`
+}
+
+type DiffGroup struct {
+	Provider         string        `json:"provider"`
+	ServiceType      string        `json:"serviceType"`
+	NormalizedRegion string        `json:"normalizedRegion"`
+	NewRecords       int           `json:"newRecords"`
+	RemovedRecords   int           `json:"removedRecords"`
+	PriceChanged     int           `json:"priceChanged"`
+	Unchanged        int           `json:"unchanged"`
+	Samples          []*DiffChange `json:"samples"`
+}
+
+type DiffSummary struct {
+	CandidateRecords int `json:"candidateRecords"`
+	ExistingRecords  int `json:"existingRecords"`
+	NewRecords       int `json:"newRecords"`
+	RemovedRecords   int `json:"removedRecords"`
+	PriceChanged     int `json:"priceChanged"`
+	Unchanged        int `json:"unchanged"`
+}
+
+type DryRunReport struct {
+	JobID       string       `json:"jobId"`
+	GeneratedAt string       `json:"generatedAt"`
+	Summary     *DiffSummary `json:"summary"`
+	Groups      []*DiffGroup `json:"groups"`
+}
+
 type ETLJob struct {
 	ID            string               `json:"id"`
 	Type          ETLJobType           `json:"type"`
@@ -100,6 +199,7 @@ type ETLJob struct {
 	CompletedAt   *string              `json:"completedAt,omitempty"`
 	Error         *string              `json:"error,omitempty"`
 	Configuration *ETLJobConfiguration `json:"configuration"`
+	DryRunReport  *DryRunReport        `json:"dryRunReport,omitempty"`
 }
 
 type ETLJobConfiguration struct {
@@ -113,14 +213,53 @@ type ETLJobConfiguration struct {
 }
 
 type ETLJobProgress struct {
-	TotalRecords      int     `json:"totalRecords"`
-	ProcessedRecords  int     `json:"processedRecords"`
-	NormalizedRecords int     `json:"normalizedRecords"`
-	SkippedRecords    int     `json:"skippedRecords"`
-	ErrorRecords      int     `json:"errorRecords"`
-	CurrentStage      string  `json:"currentStage"`
-	LastUpdated       string  `json:"lastUpdated"`
-	Rate              float64 `json:"rate"`
+	TotalRecords      int                    `json:"totalRecords"`
+	ProcessedRecords  int                    `json:"processedRecords"`
+	NormalizedRecords int                    `json:"normalizedRecords"`
+	SkippedRecords    int                    `json:"skippedRecords"`
+	ErrorRecords      int                    `json:"errorRecords"`
+	CurrentStage      string                 `json:"currentStage"`
+	LastUpdated       string                 `json:"lastUpdated"`
+	Rate              float64                `json:"rate"`
+	Providers         []*ETLProviderProgress `json:"providers"`
+}
+
+type ETLProviderProgress struct {
+	Provider          string            `json:"provider"`
+	CurrentStage      string            `json:"currentStage"`
+	BatchSize         int               `json:"batchSize"`
+	ConcurrentWorkers int               `json:"concurrentWorkers"`
+	TotalRecords      int               `json:"totalRecords"`
+	ProcessedRecords  int               `json:"processedRecords"`
+	NormalizedRecords int               `json:"normalizedRecords"`
+	SkippedRecords    int               `json:"skippedRecords"`
+	ErrorRecords      int               `json:"errorRecords"`
+	Rate              float64           `json:"rate"`
+	StartedAt         string            `json:"startedAt"`
+	CompletedAt       *string           `json:"completedAt,omitempty"`
+	Stages            []*ETLStageTiming `json:"stages"`
+}
+
+type ETLStageTiming struct {
+	Stage      string  `json:"stage"`
+	Calls      int     `json:"calls"`
+	Records    int     `json:"records"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+type MatchingConfigInput struct {
+	VcpuTolerance           *float64 `json:"vcpuTolerance,omitempty"`
+	MemoryTolerance         *float64 `json:"memoryTolerance,omitempty"`
+	GpuTolerance            *float64 `json:"gpuTolerance,omitempty"`
+	StorageTolerance        *float64 `json:"storageTolerance,omitempty"`
+	VcpuWeight              *float64 `json:"vcpuWeight,omitempty"`
+	MemoryWeight            *float64 `json:"memoryWeight,omitempty"`
+	GpuWeight               *float64 `json:"gpuWeight,omitempty"`
+	ArchitectureWeight      *float64 `json:"architectureWeight,omitempty"`
+	StorageWeight           *float64 `json:"storageWeight,omitempty"`
+	RequireSameArchitecture *bool    `json:"requireSameArchitecture,omitempty"`
+	TopN                    *int     `json:"topN,omitempty"`
+	MinScore                *float64 `json:"minScore,omitempty"`
 }
 
 type Message struct {
@@ -133,14 +272,155 @@ type Mutation struct {
 }
 
 type NormalizationConfigInput struct {
-	Type              ETLJobType `json:"type"`
-	Providers         []string   `json:"providers,omitempty"`
-	Regions           []string   `json:"regions,omitempty"`
-	Services          []string   `json:"services,omitempty"`
-	BatchSize         *int       `json:"batchSize,omitempty"`
-	ConcurrentWorkers *int       `json:"concurrentWorkers,omitempty"`
-	ClearExisting     *bool      `json:"clearExisting,omitempty"`
-	DryRun            *bool      `json:"dryRun,omitempty"`
+	Type              ETLJobType             `json:"type"`
+	Providers         []string               `json:"providers,omitempty"`
+	Regions           []string               `json:"regions,omitempty"`
+	Services          []string               `json:"services,omitempty"`
+	BatchSize         *int                   `json:"batchSize,omitempty"`
+	ConcurrentWorkers *int                   `json:"concurrentWorkers,omitempty"`
+	ClearExisting     *bool                  `json:"clearExisting,omitempty"`
+	DryRun            *bool                  `json:"dryRun,omitempty"`
+	ProviderBudgets   []*ProviderBudgetInput `json:"providerBudgets,omitempty"`
+}
+
+type NormalizedPricing struct {
+	ID                   string              `json:"id"`
+	Provider             string              `json:"provider"`
+	ProviderServiceCode  string              `json:"providerServiceCode"`
+	ProviderSku          *string             `json:"providerSku,omitempty"`
+	ServiceCategory      string              `json:"serviceCategory"`
+	ServiceFamily        string              `json:"serviceFamily"`
+	ServiceType          string              `json:"serviceType"`
+	NormalizedRegion     string              `json:"normalizedRegion"`
+	ProviderRegion       string              `json:"providerRegion"`
+	ResourceName         string              `json:"resourceName"`
+	ResourceDescription  *string             `json:"resourceDescription,omitempty"`
+	PricePerUnit         float64             `json:"pricePerUnit"`
+	Unit                 string              `json:"unit"`
+	OriginalUnit         *string             `json:"originalUnit,omitempty"`
+	UnitMultiplier       *float64            `json:"unitMultiplier,omitempty"`
+	Currency             string              `json:"currency"`
+	PricingModel         string              `json:"pricingModel"`
+	OperatingSystem      *string             `json:"operatingSystem,omitempty"`
+	LicenseModel         *string             `json:"licenseModel,omitempty"`
+	PreInstalledSoftware *string             `json:"preInstalledSoftware,omitempty"`
+	Tenancy              *string             `json:"tenancy,omitempty"`
+	TermLength           *string             `json:"termLength,omitempty"`
+	PaymentOption        *string             `json:"paymentOption,omitempty"`
+	UpfrontCost          *float64            `json:"upfrontCost,omitempty"`
+	EffectiveHourlyRate  *float64            `json:"effectiveHourlyRate,omitempty"`
+	EffectiveMonthlyRate *float64            `json:"effectiveMonthlyRate,omitempty"`
+	PriceTiers           []*PriceTier        `json:"priceTiers,omitempty"`
+	EffectiveDate        *string             `json:"effectiveDate,omitempty"`
+	ExpirationDate       *string             `json:"expirationDate,omitempty"`
+	EstimatedCost        *float64            `json:"estimatedCost,omitempty"`
+	Conversion           *CurrencyConversion `json:"conversion,omitempty"`
+}
+
+type NormalizedPricingFilterInput struct {
+	Provider             *string          `json:"provider,omitempty"`
+	ServiceCategory      *string          `json:"serviceCategory,omitempty"`
+	ServiceFamily        *string          `json:"serviceFamily,omitempty"`
+	ServiceType          *string          `json:"serviceType,omitempty"`
+	NormalizedRegion     *string          `json:"normalizedRegion,omitempty"`
+	PricingModel         *string          `json:"pricingModel,omitempty"`
+	Currency             *string          `json:"currency,omitempty"`
+	OperatingSystem      *string          `json:"operatingSystem,omitempty"`
+	LicenseModel         *string          `json:"licenseModel,omitempty"`
+	PreInstalledSoftware *string          `json:"preInstalledSoftware,omitempty"`
+	Tenancy              *string          `json:"tenancy,omitempty"`
+	MinPricePerUnit      *float64         `json:"minPricePerUnit,omitempty"`
+	MaxPricePerUnit      *float64         `json:"maxPricePerUnit,omitempty"`
+	Limit                *int             `json:"limit,omitempty"`
+	Offset               *int             `json:"offset,omitempty"`
+	UsageQuantity        *float64         `json:"usageQuantity,omitempty"`
+	OrderBy              *string          `json:"orderBy,omitempty"`
+	OrderDirection       *string          `json:"orderDirection,omitempty"`
+	AsOf                 *string          `json:"asOf,omitempty"`
+	Providers            []string         `json:"providers,omitempty"`
+	NormalizedRegions    []string         `json:"normalizedRegions,omitempty"`
+	PricingModels        []string         `json:"pricingModels,omitempty"`
+	Specs                *SpecFilterInput `json:"specs,omitempty"`
+}
+
+type PriceChange struct {
+	ID                   string   `json:"id"`
+	Provider             string   `json:"provider"`
+	CollectionID         string   `json:"collectionId"`
+	PreviousCollectionID string   `json:"previousCollectionId"`
+	PriceKey             string   `json:"priceKey"`
+	Region               string   `json:"region"`
+	ServiceName          string   `json:"serviceName"`
+	Description          string   `json:"description"`
+	ChangeType           string   `json:"changeType"`
+	OldPrice             *float64 `json:"oldPrice,omitempty"`
+	NewPrice             *float64 `json:"newPrice,omitempty"`
+	ChangePercent        *float64 `json:"changePercent,omitempty"`
+	Currency             string   `json:"currency"`
+	Unit                 string   `json:"unit"`
+	DetectedAt           string   `json:"detectedAt"`
+}
+
+type PriceChangeFilterInput struct {
+	Provider     *string  `json:"provider,omitempty"`
+	CollectionID *string  `json:"collectionId,omitempty"`
+	Region       *string  `json:"region,omitempty"`
+	ServiceName  *string  `json:"serviceName,omitempty"`
+	ChangeTypes  []string `json:"changeTypes,omitempty"`
+	Limit        *int     `json:"limit,omitempty"`
+}
+
+type PriceDiffReport struct {
+	Provider             string            `json:"provider"`
+	CollectionID         string            `json:"collectionId"`
+	PreviousCollectionID string            `json:"previousCollectionId"`
+	GeneratedAt          string            `json:"generatedAt"`
+	Summary              *PriceDiffSummary `json:"summary"`
+	Changes              []*PriceChange    `json:"changes"`
+}
+
+type PriceDiffSummary struct {
+	New       int `json:"new"`
+	Retired   int `json:"retired"`
+	Increased int `json:"increased"`
+	Decreased int `json:"decreased"`
+}
+
+type PriceHistoryEntry struct {
+	NaturalKey string             `json:"naturalKey"`
+	ValidFrom  string             `json:"validFrom"`
+	ValidTo    *string            `json:"validTo,omitempty"`
+	Pricing    *NormalizedPricing `json:"pricing"`
+}
+
+type PriceTier struct {
+	StartUnits   float64  `json:"startUnits"`
+	EndUnits     *float64 `json:"endUnits,omitempty"`
+	PricePerUnit float64  `json:"pricePerUnit"`
+}
+
+type PricingComparison struct {
+	ServiceType      string             `json:"serviceType"`
+	NormalizedRegion string             `json:"normalizedRegion"`
+	PricingModel     string             `json:"pricingModel"`
+	Aws              *NormalizedPricing `json:"aws,omitempty"`
+	Azure            *NormalizedPricing `json:"azure,omitempty"`
+	PriceDifference  *float64           `json:"priceDifference,omitempty"`
+	CheaperProvider  *string            `json:"cheaperProvider,omitempty"`
+	SavingsPercent   *float64           `json:"savingsPercent,omitempty"`
+	SimilarityScore  *float64           `json:"similarityScore,omitempty"`
+}
+
+type PricingSearchHit struct {
+	Score   float64            `json:"score"`
+	Pricing *NormalizedPricing `json:"pricing"`
+}
+
+type PricingSearchResult struct {
+	Query  string              `json:"query"`
+	Total  int                 `json:"total"`
+	Hits   []*PricingSearchHit `json:"hits"`
+	Facets *SearchFacets       `json:"facets"`
 }
 
 type Provider struct {
@@ -149,6 +429,17 @@ type Provider struct {
 	CreatedAt string `json:"createdAt"`
 }
 
+type ProviderBudgetInput struct {
+	Provider          string `json:"provider"`
+	BatchSize         *int   `json:"batchSize,omitempty"`
+	ConcurrentWorkers *int   `json:"concurrentWorkers,omitempty"`
+}
+
+type ProviderCoverage struct {
+	Provider string          `json:"provider"`
+	Counts   *CoverageCounts `json:"counts"`
+}
+
 type Query struct {
 }
 
@@ -180,6 +471,51 @@ type RegionOptimization struct {
 	Recommendations []string `json:"recommendations"`
 }
 
+type SearchFacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type SearchFacets struct {
+	Providers         []*SearchFacetValue `json:"providers"`
+	Regions           []*SearchFacetValue `json:"regions"`
+	ServiceCategories []*SearchFacetValue `json:"serviceCategories"`
+	PricingModels     []*SearchFacetValue `json:"pricingModels"`
+}
+
+type SearchFilterInput struct {
+	Provider        *string `json:"provider,omitempty"`
+	Region          *string `json:"region,omitempty"`
+	ServiceCategory *string `json:"serviceCategory,omitempty"`
+	PricingModel    *string `json:"pricingModel,omitempty"`
+}
+
+type SpecFilterInput struct {
+	MinVcpu          *int     `json:"minVcpu,omitempty"`
+	MaxVcpu          *int     `json:"maxVcpu,omitempty"`
+	MinMemoryGb      *float64 `json:"minMemoryGb,omitempty"`
+	MaxMemoryGb      *float64 `json:"maxMemoryGb,omitempty"`
+	MinGpuCount      *int     `json:"minGpuCount,omitempty"`
+	MaxGpuCount      *int     `json:"maxGpuCount,omitempty"`
+	MinGpuMemoryGb   *float64 `json:"minGpuMemoryGb,omitempty"`
+	MaxGpuMemoryGb   *float64 `json:"maxGpuMemoryGb,omitempty"`
+	MinStorageGb     *float64 `json:"minStorageGb,omitempty"`
+	MaxStorageGb     *float64 `json:"maxStorageGb,omitempty"`
+	MinClockSpeedGhz *float64 `json:"minClockSpeedGhz,omitempty"`
+	MaxClockSpeedGhz *float64 `json:"maxClockSpeedGhz,omitempty"`
+	Architecture     *string  `json:"architecture,omitempty"`
+	Burstable        *bool    `json:"burstable,omitempty"`
+	ProcessorVendor  *string  `json:"processorVendor,omitempty"`
+}
+
+type UnmappedService struct {
+	Provider        string   `json:"provider"`
+	Service         string   `json:"service"`
+	ServiceMapped   bool     `json:"serviceMapped"`
+	UnmappedRecords int      `json:"unmappedRecords"`
+	Regions         []string `json:"regions"`
+}
+
 type WorkloadInput struct {
 	ComputeHours float64  `json:"computeHours"`
 	StorageGb    float64  `json:"storageGB"`
@@ -193,11 +529,12 @@ type WorkloadInput struct {
 type ETLJobStatus string
 
 const (
-	ETLJobStatusPending   ETLJobStatus = "PENDING"
-	ETLJobStatusRunning   ETLJobStatus = "RUNNING"
-	ETLJobStatusCompleted ETLJobStatus = "COMPLETED"
-	ETLJobStatusFailed    ETLJobStatus = "FAILED"
-	ETLJobStatusCancelled ETLJobStatus = "CANCELLED"
+	ETLJobStatusPending     ETLJobStatus = "PENDING"
+	ETLJobStatusRunning     ETLJobStatus = "RUNNING"
+	ETLJobStatusCompleted   ETLJobStatus = "COMPLETED"
+	ETLJobStatusFailed      ETLJobStatus = "FAILED"
+	ETLJobStatusCancelled   ETLJobStatus = "CANCELLED"
+	ETLJobStatusInterrupted ETLJobStatus = "INTERRUPTED"
 )
 
 var AllETLJobStatus = []ETLJobStatus{
@@ -206,11 +543,12 @@ var AllETLJobStatus = []ETLJobStatus{
 	ETLJobStatusCompleted,
 	ETLJobStatusFailed,
 	ETLJobStatusCancelled,
+	ETLJobStatusInterrupted,
 }
 
 func (e ETLJobStatus) IsValid() bool {
 	switch e {
-	case ETLJobStatusPending, ETLJobStatusRunning, ETLJobStatusCompleted, ETLJobStatusFailed, ETLJobStatusCancelled:
+	case ETLJobStatusPending, ETLJobStatusRunning, ETLJobStatusCompleted, ETLJobStatusFailed, ETLJobStatusCancelled, ETLJobStatusInterrupted:
 		return true
 	}
 	return false
@@ -220,7 +558,7 @@ func (e ETLJobStatus) String() string {
 	return string(e)
 }
 
-func (e *ETLJobStatus) UnmarshalGQL(v any) error {
+func (e *ETLJobStatus) UnmarshalGQL(v interface{}) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
@@ -237,20 +575,6 @@ func (e ETLJobStatus) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
-func (e *ETLJobStatus) UnmarshalJSON(b []byte) error {
-	s, err := strconv.Unquote(string(b))
-	if err != nil {
-		return err
-	}
-	return e.UnmarshalGQL(s)
-}
-
-func (e ETLJobStatus) MarshalJSON() ([]byte, error) {
-	var buf bytes.Buffer
-	e.MarshalGQL(&buf)
-	return buf.Bytes(), nil
-}
-
 type ETLJobType string
 
 const (
@@ -281,7 +605,7 @@ func (e ETLJobType) String() string {
 	return string(e)
 }
 
-func (e *ETLJobType) UnmarshalGQL(v any) error {
+func (e *ETLJobType) UnmarshalGQL(v interface{}) error {
 	str, ok := v.(string)
 	if !ok {
 		return fmt.Errorf("enums must be strings")
@@ -297,17 +621,3 @@ func (e *ETLJobType) UnmarshalGQL(v any) error {
 func (e ETLJobType) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
-
-func (e *ETLJobType) UnmarshalJSON(b []byte) error {
-	s, err := strconv.Unquote(string(b))
-	if err != nil {
-		return err
-	}
-	return e.UnmarshalGQL(s)
-}
-
-func (e ETLJobType) MarshalJSON() ([]byte, error) {
-	var buf bytes.Buffer
-	e.MarshalGQL(&buf)
-	return buf.Bytes(), nil
-}
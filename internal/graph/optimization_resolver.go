@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/raulc0399/cpc/internal/optimization"
+)
+
+// Region optimization resolver methods
+
+// OptimizeRegions ranks the regions of the requested providers by the monthly cost of a workload
+func (r *queryResolver) OptimizeRegions(ctx context.Context, workload WorkloadInput) ([]*RegionOptimization, error) {
+	optimizer := optimization.NewRegionOptimizerWithRepository(r.pricing())
+	results, err := optimizer.OptimizeRegions(ctx, convertWorkloadInput(workload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to optimize regions: %w", err)
+	}
+
+	optimizations := make([]*RegionOptimization, len(results))
+	for i, result := range results {
+		optimizations[i] = &RegionOptimization{
+			Provider:        result.Provider,
+			Region:          result.Region,
+			RegionName:      result.RegionName,
+			MonthlyCost:     result.MonthlyCost,
+			ComputeCost:     result.ComputeCost,
+			StorageCost:     result.StorageCost,
+			EgressCost:      result.EgressCost,
+			Recommendations: result.Recommendations,
+		}
+	}
+	return optimizations, nil
+}
+
+// CompareRegions compares the monthly cost of a workload in the given regions
+func (r *queryResolver) CompareRegions(ctx context.Context, workload WorkloadInput, regions []*RegionInput) ([]*RegionComparison, error) {
+	inputs := make([]optimization.RegionInput, 0, len(regions))
+	for _, region := range regions {
+		if region != nil {
+			inputs = append(inputs, optimization.RegionInput{Provider: region.Provider, Region: region.Region})
+		}
+	}
+
+	optimizer := optimization.NewRegionOptimizerWithRepository(r.pricing())
+	results, err := optimizer.CompareRegions(ctx, convertWorkloadInput(workload), inputs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare regions: %w", err)
+	}
+
+	comparisons := make([]*RegionComparison, len(results))
+	for i, result := range results {
+		comparisons[i] = &RegionComparison{
+			Provider:       result.Provider,
+			Region:         result.Region,
+			RegionName:     result.RegionName,
+			MonthlyCost:    result.MonthlyCost,
+			ComputeCost:    result.ComputeCost,
+			StorageCost:    result.StorageCost,
+			EgressCost:     result.EgressCost,
+			SavingsPercent: result.SavingsPercent,
+			BestFor:        result.BestFor,
+		}
+	}
+	return comparisons, nil
+}
+
+// convertWorkloadInput converts a GraphQL workload to the optimizer's workload profile
+func convertWorkloadInput(workload WorkloadInput) optimization.WorkloadProfile {
+	profile := optimization.WorkloadProfile{
+		ComputeHours: workload.ComputeHours,
+		StorageGB:    workload.StorageGb,
+		EgressGB:     workload.EgressGb,
+		Providers:    workload.Providers,
+	}
+	if workload.CPUCount != nil {
+		profile.CPUCount = *workload.CPUCount
+	}
+	if workload.GpuCount != nil {
+		profile.GPUCount = *workload.GpuCount
+	}
+	if workload.Geography != nil {
+		profile.Geography = *workload.Geography
+	}
+	return profile
+}
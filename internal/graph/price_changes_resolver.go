@@ -0,0 +1,102 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+)
+
+// PriceChanges returns stored price changes between collections
+func (r *queryResolver) PriceChanges(ctx context.Context, filter *PriceChangeFilterInput) ([]*PriceChange, error) {
+	if r.DB == nil {
+		return nil, errPostgresRequired
+	}
+	var dbFilter database.PriceChangeFilter
+	if filter != nil {
+		if err := database.ValidatePriceChangeTypes(filter.ChangeTypes); err != nil {
+			return nil, err
+		}
+		dbFilter = database.PriceChangeFilter{
+			Provider:     filter.Provider,
+			CollectionID: filter.CollectionID,
+			Region:       filter.Region,
+			ServiceName:  filter.ServiceName,
+			Limit:        filter.Limit,
+		}
+		for _, changeType := range filter.ChangeTypes {
+			dbFilter.ChangeTypes = append(dbFilter.ChangeTypes, strings.ToLower(changeType))
+		}
+	}
+
+	changes, err := r.DB.GetPriceChanges(ctx, dbFilter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price changes: %w", err)
+	}
+
+	return convertPriceChanges(changes), nil
+}
+
+// DiffCollections diffs a collection against the previous one and stores the changes
+func (r *mutationResolver) DiffCollections(ctx context.Context, provider string, collectionID *string, previousCollectionID *string) (*PriceDiffReport, error) {
+	if r.DB == nil {
+		return nil, errPostgresRequired
+	}
+	if r.pipeline == nil {
+		return nil, fmt.Errorf("ETL pipeline not initialized")
+	}
+
+	var current, previous string
+	if collectionID != nil {
+		current = *collectionID
+	}
+	if previousCollectionID != nil {
+		previous = *previousCollectionID
+	}
+
+	report, err := r.pipeline.PriceDiffReport(ctx, strings.ToLower(provider), current, previous)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff collections: %w", err)
+	}
+
+	return &PriceDiffReport{
+		Provider:             report.Provider,
+		CollectionID:         report.CollectionID,
+		PreviousCollectionID: report.PreviousCollectionID,
+		GeneratedAt:          report.GeneratedAt.Format(time.RFC3339),
+		Summary: &PriceDiffSummary{
+			New:       report.Summary.New,
+			Retired:   report.Summary.Retired,
+			Increased: report.Summary.Increased,
+			Decreased: report.Summary.Decreased,
+		},
+		Changes: convertPriceChanges(report.Changes),
+	}, nil
+}
+
+func convertPriceChanges(changes []database.PriceChange) []*PriceChange {
+	result := make([]*PriceChange, len(changes))
+	for i, change := range changes {
+		result[i] = &PriceChange{
+			ID:                   strconv.Itoa(change.ID),
+			Provider:             change.Provider,
+			CollectionID:         change.CollectionID,
+			PreviousCollectionID: change.PreviousCollectionID,
+			PriceKey:             change.PriceKey,
+			Region:               change.Region,
+			ServiceName:          change.ServiceName,
+			Description:          change.Description,
+			ChangeType:           change.ChangeType,
+			OldPrice:             change.OldPrice,
+			NewPrice:             change.NewPrice,
+			ChangePercent:        change.ChangePercent,
+			Currency:             change.Currency,
+			Unit:                 change.Unit,
+			DetectedAt:           change.DetectedAt.Format(time.RFC3339),
+		}
+	}
+	return result
+}
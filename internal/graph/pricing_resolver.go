@@ -4,12 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
+	"strconv"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/currency"
+	"github.com/raulc0399/cpc/internal/database"
 )
 
-// Helper functions to query raw pricing data directly
+// Helper functions to query raw pricing data directly. They fail when serving a snapshot, so the
+// callers fall back to the normalized pricing read path.
 
 func (c *AWSCompute) getInstancePriceFromRaw(ctx context.Context, instanceType string) (float64, error) {
+	// Raw pricing is not part of a pricing snapshot
+	if c.resolver.DB == nil {
+		return 0.0, errPostgresRequired
+	}
+
 	// Normalize region (us-east-1 -> US East (N. Virginia))
 	regionMapping := map[string]string{
 		"us-east-1":      "US East (N. Virginia)",
@@ -17,7 +27,7 @@ func (c *AWSCompute) getInstancePriceFromRaw(ctx context.Context, instanceType s
 		"eu-west-1":      "EU (Ireland)",
 		"ap-southeast-1": "Asia Pacific (Singapore)",
 	}
-	
+
 	displayRegion, ok := regionMapping[c.region]
 	if !ok {
 		displayRegion = c.region
@@ -34,7 +44,7 @@ func (c *AWSCompute) getInstancePriceFromRaw(ctx context.Context, instanceType s
 	`
 
 	var rawData json.RawMessage
-	err := c.resolver.DB.QueryRow(query, displayRegion, instanceType).Scan(&rawData)
+	err := c.resolver.DB.GetConn().QueryRowContext(ctx, query, displayRegion, instanceType).Scan(&rawData)
 	if err != nil {
 		// Try without location filter if not found
 		query = `
@@ -45,7 +55,7 @@ func (c *AWSCompute) getInstancePriceFromRaw(ctx context.Context, instanceType s
 			AND data->'attributes'->>'instanceType' = $1
 			LIMIT 1
 		`
-		err = c.resolver.DB.QueryRow(query, instanceType).Scan(&rawData)
+		err = c.resolver.DB.GetConn().QueryRowContext(ctx, query, instanceType).Scan(&rawData)
 		if err != nil {
 			return 0.0, fmt.Errorf("instance type %s not found: %w", instanceType, err)
 		}
@@ -85,6 +95,11 @@ func (c *AWSCompute) getInstancePriceFromRaw(ctx context.Context, instanceType s
 }
 
 func (s *AWSStorage) getStoragePriceFromRaw(ctx context.Context, tier string) (float64, error) {
+	// Raw pricing is not part of a pricing snapshot
+	if s.resolver.DB == nil {
+		return 0.0, errPostgresRequired
+	}
+
 	// Map tier names to S3 storage class names
 	tierMapping := map[string]string{
 		"standard":          "General Purpose",
@@ -122,7 +137,7 @@ func (s *AWSStorage) getStoragePriceFromRaw(ctx context.Context, tier string) (f
 	}
 
 	var rawData json.RawMessage
-	err := s.resolver.DB.QueryRow(query, "%"+storageClass+"%", displayRegion).Scan(&rawData)
+	err := s.resolver.DB.GetConn().QueryRowContext(ctx, query, "%"+storageClass+"%", displayRegion).Scan(&rawData)
 	if err != nil {
 		// Try without location filter
 		query = `
@@ -133,7 +148,7 @@ func (s *AWSStorage) getStoragePriceFromRaw(ctx context.Context, tier string) (f
 			AND data->'attributes'->>'storageClass' ILIKE $1
 			LIMIT 1
 		`
-		err = s.resolver.DB.QueryRow(query, "%"+storageClass+"%").Scan(&rawData)
+		err = s.resolver.DB.GetConn().QueryRowContext(ctx, query, "%"+storageClass+"%").Scan(&rawData)
 		if err != nil {
 			return 0.0, fmt.Errorf("storage tier %s not found: %w", tier, err)
 		}
@@ -173,6 +188,11 @@ func (s *AWSStorage) getStoragePriceFromRaw(ctx context.Context, tier string) (f
 }
 
 func (c *AzureCompute) getVMPriceFromRaw(ctx context.Context, size string) (float64, error) {
+	// Raw pricing is not part of a pricing snapshot
+	if c.resolver.DB == nil {
+		return 0.0, errPostgresRequired
+	}
+
 	// Normalize region
 	regionMapping := map[string]string{
 		"eastus":        "eastus",
@@ -197,7 +217,7 @@ func (c *AzureCompute) getVMPriceFromRaw(ctx context.Context, size string) (floa
 	`
 
 	var priceStr string
-	err := c.resolver.DB.QueryRow(query, azureRegion, size).Scan(&priceStr)
+	err := c.resolver.DB.GetConn().QueryRowContext(ctx, query, azureRegion, size).Scan(&priceStr)
 	if err != nil {
 		// Try without region filter
 		query = `
@@ -208,7 +228,7 @@ func (c *AzureCompute) getVMPriceFromRaw(ctx context.Context, size string) (floa
 			AND data->>'type' = 'Consumption'
 			LIMIT 1
 		`
-		err = c.resolver.DB.QueryRow(query, size).Scan(&priceStr)
+		err = c.resolver.DB.GetConn().QueryRowContext(ctx, query, size).Scan(&priceStr)
 		if err != nil {
 			return 0.0, fmt.Errorf("VM size %s not found: %w", size, err)
 		}
@@ -220,6 +240,11 @@ func (c *AzureCompute) getVMPriceFromRaw(ctx context.Context, size string) (floa
 }
 
 func (s *AzureStorage) getStoragePriceFromRaw(ctx context.Context, tier string) (float64, error) {
+	// Raw pricing is not part of a pricing snapshot
+	if s.resolver.DB == nil {
+		return 0.0, errPostgresRequired
+	}
+
 	// Map tier names to Azure storage tiers
 	tierMapping := map[string]string{
 		"hot":     "Hot LRS",
@@ -257,7 +282,7 @@ func (s *AzureStorage) getStoragePriceFromRaw(ctx context.Context, tier string)
 	`
 
 	var priceStr string
-	err := s.resolver.DB.QueryRow(query, azureRegion, "%"+azureTier+"%").Scan(&priceStr)
+	err := s.resolver.DB.GetConn().QueryRowContext(ctx, query, azureRegion, "%"+azureTier+"%").Scan(&priceStr)
 	if err != nil {
 		// Try without region filter
 		query = `
@@ -269,7 +294,7 @@ func (s *AzureStorage) getStoragePriceFromRaw(ctx context.Context, tier string)
 			AND data->>'unitOfMeasure' ILIKE '%GB%'
 			LIMIT 1
 		`
-		err = s.resolver.DB.QueryRow(query, "%"+azureTier+"%").Scan(&priceStr)
+		err = s.resolver.DB.GetConn().QueryRowContext(ctx, query, "%"+azureTier+"%").Scan(&priceStr)
 		if err != nil {
 			return 0.0, fmt.Errorf("storage tier %s not found: %w", tier, err)
 		}
@@ -278,4 +303,322 @@ func (s *AzureStorage) getStoragePriceFromRaw(ctx context.Context, tier string)
 	var price float64
 	fmt.Sscanf(priceStr, "%f", &price)
 	return price, nil
-}
\ No newline at end of file
+}
+
+// Normalized pricing resolver methods
+
+// NormalizedPricing queries normalized pricing records across providers, converting prices
+// to the requested currency if one is given
+func (r *queryResolver) NormalizedPricing(ctx context.Context, filter *NormalizedPricingFilterInput, currencyArg *string) ([]*NormalizedPricing, error) {
+	asOf := time.Now()
+	dbFilter := database.PricingFilter{AsOf: &asOf}
+	var usageQuantity *float64
+
+	if filter != nil {
+		dbFilter.Provider = filter.Provider
+		dbFilter.ServiceCategory = filter.ServiceCategory
+		dbFilter.ServiceFamily = filter.ServiceFamily
+		dbFilter.ServiceType = filter.ServiceType
+		dbFilter.NormalizedRegion = filter.NormalizedRegion
+		dbFilter.PricingModel = filter.PricingModel
+		dbFilter.Currency = filter.Currency
+		dbFilter.OperatingSystem = filter.OperatingSystem
+		dbFilter.LicenseModel = filter.LicenseModel
+		dbFilter.PreInstalledSoftware = filter.PreInstalledSoftware
+		dbFilter.Tenancy = filter.Tenancy
+		dbFilter.MinPricePerUnit = filter.MinPricePerUnit
+		dbFilter.MaxPricePerUnit = filter.MaxPricePerUnit
+		dbFilter.Limit = filter.Limit
+		dbFilter.Offset = filter.Offset
+		dbFilter.OrderBy = filter.OrderBy
+		dbFilter.OrderDirection = filter.OrderDirection
+		dbFilter.Providers = filter.Providers
+		dbFilter.NormalizedRegions = filter.NormalizedRegions
+		dbFilter.PricingModels = filter.PricingModels
+		if filter.Specs != nil {
+			dbFilter.Specs = &database.SpecFilter{
+				MinVCPU:          filter.Specs.MinVcpu,
+				MaxVCPU:          filter.Specs.MaxVcpu,
+				MinMemoryGB:      filter.Specs.MinMemoryGb,
+				MaxMemoryGB:      filter.Specs.MaxMemoryGb,
+				MinGPUCount:      filter.Specs.MinGpuCount,
+				MaxGPUCount:      filter.Specs.MaxGpuCount,
+				MinGPUMemoryGB:   filter.Specs.MinGpuMemoryGb,
+				MaxGPUMemoryGB:   filter.Specs.MaxGpuMemoryGb,
+				MinStorageGB:     filter.Specs.MinStorageGb,
+				MaxStorageGB:     filter.Specs.MaxStorageGb,
+				MinClockSpeedGHz: filter.Specs.MinClockSpeedGhz,
+				MaxClockSpeedGHz: filter.Specs.MaxClockSpeedGhz,
+				Architecture:     filter.Specs.Architecture,
+				Burstable:        filter.Specs.Burstable,
+				ProcessorVendor:  filter.Specs.ProcessorVendor,
+			}
+		}
+		usageQuantity = filter.UsageQuantity
+
+		if filter.AsOf != nil && *filter.AsOf != "" {
+			parsed, err := database.ParseAsOf(*filter.AsOf)
+			if err != nil {
+				return nil, err
+			}
+			asOf = parsed
+			dbFilter.History = true
+		}
+	}
+
+	limit := database.DefaultPricingQueryLimit
+	if dbFilter.Limit != nil && *dbFilter.Limit > 0 && *dbFilter.Limit <= database.MaxPricingQueryLimit {
+		limit = *dbFilter.Limit
+	}
+	dbFilter.Limit = &limit
+
+	if usageQuantity != nil && *usageQuantity < 0 {
+		return nil, fmt.Errorf("usageQuantity must not be negative")
+	}
+
+	pricings, err := r.pricing().QueryNormalizedPricing(dbFilter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query normalized pricing: %w", err)
+	}
+
+	var conversions []*currency.Conversion
+	if currencyArg != nil && *currencyArg != "" {
+		if r.Currency == nil {
+			return nil, fmt.Errorf("currency conversion is not configured")
+		}
+		conversions, err = r.Currency.ConvertPricings(ctx, pricings, *currencyArg, asOf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert prices to %s: %w", *currencyArg, err)
+		}
+	}
+
+	result := make([]*NormalizedPricing, len(pricings))
+	for i, pricing := range pricings {
+		result[i] = convertNormalizedPricingToGraphQL(pricing, usageQuantity)
+		if conversions != nil {
+			result[i].Conversion = convertConversionToGraphQL(conversions[i])
+		}
+	}
+
+	return result, nil
+}
+
+// convertConversionToGraphQL converts an applied exchange rate to its GraphQL type
+func convertConversionToGraphQL(conversion *currency.Conversion) *CurrencyConversion {
+	return &CurrencyConversion{
+		FromCurrency: conversion.FromCurrency,
+		ToCurrency:   conversion.ToCurrency,
+		Rate:         conversion.Rate,
+		RateDate:     conversion.RateDate.Format("2006-01-02"),
+	}
+}
+
+// convertNormalizedPricingToGraphQL converts a normalized pricing record to its GraphQL type,
+// estimating the cost of the usage quantity when given
+func convertNormalizedPricingToGraphQL(pricing database.NormalizedPricing, usageQuantity *float64) *NormalizedPricing {
+	result := &NormalizedPricing{
+		ID:                   strconv.Itoa(pricing.ID),
+		Provider:             pricing.Provider,
+		ProviderServiceCode:  pricing.ProviderServiceCode,
+		ProviderSku:          pricing.ProviderSKU,
+		ServiceCategory:      pricing.ServiceCategory,
+		ServiceFamily:        pricing.ServiceFamily,
+		ServiceType:          pricing.ServiceType,
+		NormalizedRegion:     pricing.NormalizedRegion,
+		ProviderRegion:       pricing.ProviderRegion,
+		ResourceName:         pricing.ResourceName,
+		ResourceDescription:  pricing.ResourceDescription,
+		PricePerUnit:         pricing.PricePerUnit,
+		Unit:                 pricing.Unit,
+		OriginalUnit:         pricing.OriginalUnit,
+		Currency:             pricing.Currency,
+		PricingModel:         pricing.PricingModel,
+		OperatingSystem:      pricing.OperatingSystem,
+		LicenseModel:         pricing.LicenseModel,
+		PreInstalledSoftware: pricing.PreInstalledSoftware,
+		Tenancy:              pricing.Tenancy,
+		TermLength:           pricing.PricingDetails.TermLength,
+		PaymentOption:        pricing.PricingDetails.PaymentOption,
+		UpfrontCost:          pricing.PricingDetails.UpfrontCost,
+		EffectiveHourlyRate:  pricing.PricingDetails.EffectiveHourlyRate,
+		EffectiveMonthlyRate: pricing.PricingDetails.EffectiveMonthlyRate,
+	}
+
+	if pricing.UnitMultiplier > 0 {
+		unitMultiplier := pricing.UnitMultiplier
+		result.UnitMultiplier = &unitMultiplier
+	}
+
+	for _, tier := range pricing.PriceTiers {
+		result.PriceTiers = append(result.PriceTiers, &PriceTier{
+			StartUnits:   tier.StartUnits,
+			EndUnits:     tier.EndUnits,
+			PricePerUnit: tier.PricePerUnit,
+		})
+	}
+
+	result.EffectiveDate = formatPriceDate(pricing.EffectiveDate)
+	result.ExpirationDate = formatPriceDate(pricing.ExpirationDate)
+
+	if usageQuantity != nil {
+		cost := pricing.TotalCost(*usageQuantity)
+		result.EstimatedCost = &cost
+	}
+
+	return result
+}
+
+// pricingConverter returns a function that converts a normalized pricing record to its GraphQL
+// type in the requested currency, using the latest effective exchange rate. It converts nothing
+// when no currency is requested.
+func (r *queryResolver) pricingConverter(ctx context.Context, currencyArg *string) (func(database.NormalizedPricing) (*NormalizedPricing, error), error) {
+	if currencyArg == nil || *currencyArg == "" {
+		return func(pricing database.NormalizedPricing) (*NormalizedPricing, error) {
+			return convertNormalizedPricingToGraphQL(pricing, nil), nil
+		}, nil
+	}
+	if r.Currency == nil {
+		return nil, fmt.Errorf("currency conversion is not configured")
+	}
+
+	asOf := time.Now()
+	return func(pricing database.NormalizedPricing) (*NormalizedPricing, error) {
+		conversion, err := r.Currency.ConvertPricing(ctx, &pricing, *currencyArg, asOf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert prices to %s: %w", *currencyArg, err)
+		}
+		result := convertNormalizedPricingToGraphQL(pricing, nil)
+		result.Conversion = convertConversionToGraphQL(conversion)
+		return result, nil
+	}, nil
+}
+
+// formatPriceDate formats an optional price validity date as YYYY-MM-DD
+func formatPriceDate(date *time.Time) *string {
+	if date == nil {
+		return nil
+	}
+	formatted := date.Format("2006-01-02")
+	return &formatted
+}
+
+// PriceTimeline returns every recorded version of the prices of a provider SKU, converting
+// them to the requested currency with the latest effective exchange rate if one is given
+func (r *queryResolver) PriceTimeline(ctx context.Context, provider string, sku string, region *string, currencyArg *string) ([]*PriceHistoryEntry, error) {
+	entries, err := r.pricing().GetPriceTimeline(ctx, provider, sku, region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price timeline: %w", err)
+	}
+
+	convert, err := r.pricingConverter(ctx, currencyArg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*PriceHistoryEntry, len(entries))
+	for i, entry := range entries {
+		result[i] = &PriceHistoryEntry{
+			NaturalKey: entry.NaturalKey,
+			ValidFrom:  entry.ValidFrom.Format(time.RFC3339),
+		}
+		if result[i].Pricing, err = convert(entry.NormalizedPricing); err != nil {
+			return nil, err
+		}
+		if entry.ValidTo != nil {
+			validTo := entry.ValidTo.Format(time.RFC3339)
+			result[i].ValidTo = &validTo
+		}
+	}
+
+	return result, nil
+}
+
+// ComparePricing pairs the AWS offers of a service type with their most similar Azure equivalents,
+// converting both offers and their price difference to the requested currency if one is given
+func (r *queryResolver) ComparePricing(ctx context.Context, serviceType string, region string, pricingModel *string, matching *MatchingConfigInput, currencyArg *string) ([]*PricingComparison, error) {
+	model := database.PricingModelOnDemand
+	if pricingModel != nil && *pricingModel != "" {
+		model = *pricingModel
+	}
+
+	config := matchingConfigFromInput(matching)
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid matching configuration: %w", err)
+	}
+
+	comparisons, err := r.pricing().ComparePricingWithConfig(serviceType, region, model, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare pricing: %w", err)
+	}
+
+	var conversions []currency.ComparisonConversions
+	if currencyArg != nil && *currencyArg != "" {
+		if r.Currency == nil {
+			return nil, fmt.Errorf("currency conversion is not configured")
+		}
+		conversions, err = r.Currency.ConvertComparisons(ctx, comparisons, *currencyArg, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert prices to %s: %w", *currencyArg, err)
+		}
+	}
+
+	result := make([]*PricingComparison, len(comparisons))
+	for i, comparison := range comparisons {
+		result[i] = &PricingComparison{
+			ServiceType:      comparison.ServiceType,
+			NormalizedRegion: comparison.NormalizedRegion,
+			PricingModel:     comparison.PricingModel,
+			PriceDifference:  comparison.PriceDifference,
+			CheaperProvider:  comparison.CheaperProvider,
+			SavingsPercent:   comparison.SavingsPercent,
+			SimilarityScore:  comparison.SimilarityScore,
+		}
+		if comparison.AWS != nil {
+			result[i].Aws = convertNormalizedPricingToGraphQL(*comparison.AWS, nil)
+			if conversions != nil {
+				result[i].Aws.Conversion = convertConversionToGraphQL(conversions[i].AWS)
+			}
+		}
+		if comparison.Azure != nil {
+			result[i].Azure = convertNormalizedPricingToGraphQL(*comparison.Azure, nil)
+			if conversions != nil {
+				result[i].Azure.Conversion = convertConversionToGraphQL(conversions[i].Azure)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// matchingConfigFromInput applies the set fields of a matching input over the default
+// matching configuration
+func matchingConfigFromInput(input *MatchingConfigInput) database.MatchingConfig {
+	config := database.DefaultMatchingConfig()
+	if input == nil {
+		return config
+	}
+
+	setFloat := func(target *float64, value *float64) {
+		if value != nil {
+			*target = *value
+		}
+	}
+	setFloat(&config.VCPUTolerance, input.VcpuTolerance)
+	setFloat(&config.MemoryTolerance, input.MemoryTolerance)
+	setFloat(&config.GPUTolerance, input.GpuTolerance)
+	setFloat(&config.StorageTolerance, input.StorageTolerance)
+	setFloat(&config.VCPUWeight, input.VcpuWeight)
+	setFloat(&config.MemoryWeight, input.MemoryWeight)
+	setFloat(&config.GPUWeight, input.GpuWeight)
+	setFloat(&config.ArchitectureWeight, input.ArchitectureWeight)
+	setFloat(&config.StorageWeight, input.StorageWeight)
+	setFloat(&config.MinScore, input.MinScore)
+
+	if input.RequireSameArchitecture != nil {
+		config.RequireSameArchitecture = *input.RequireSameArchitecture
+	}
+	if input.TopN != nil {
+		config.TopN = *input.TopN
+	}
+	return config
+}
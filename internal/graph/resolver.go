@@ -2,20 +2,37 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/raulc0399/cpc/internal/currency"
 	"github.com/raulc0399/cpc/internal/database"
 	"github.com/raulc0399/cpc/internal/etl"
 )
 
-// Resolver is the root resolver
+// errPostgresRequired is returned by resolvers that are not served from a pricing snapshot
+var errPostgresRequired = errors.New("not available when serving a pricing snapshot")
+
+// Resolver is the root resolver. Pricing serves the normalized pricing read path and defaults
+// to DB; when serving a snapshot, DB is nil and only the read path is available.
 type Resolver struct {
 	DB       *database.DB
+	Pricing  database.PricingRepository
+	Currency *currency.Service
 	pipeline *etl.Pipeline
 }
 
+// pricing returns the repository the normalized pricing read path is served from
+func (r *Resolver) pricing() database.PricingRepository {
+	if r.Pricing != nil {
+		return r.Pricing
+	}
+	return r.DB
+}
+
 // SetPipeline sets the ETL pipeline for the resolver
 func (r *Resolver) SetPipeline(pipeline *etl.Pipeline) {
 	r.pipeline = pipeline
@@ -40,6 +57,9 @@ func (r *queryResolver) Hello(ctx context.Context) (string, error) {
 
 // Messages retrieves all messages
 func (r *queryResolver) Messages(ctx context.Context) ([]*Message, error) {
+	if r.DB == nil {
+		return nil, errPostgresRequired
+	}
 	dbMessages, err := r.DB.GetMessages()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get messages: %w", err)
@@ -59,6 +79,9 @@ func (r *queryResolver) Messages(ctx context.Context) ([]*Message, error) {
 
 // Providers retrieves all providers
 func (r *queryResolver) Providers(ctx context.Context) ([]*Provider, error) {
+	if r.DB == nil {
+		return nil, errPostgresRequired
+	}
 	dbProviders, err := r.DB.GetProviders()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get providers: %w", err)
@@ -78,6 +101,9 @@ func (r *queryResolver) Providers(ctx context.Context) ([]*Provider, error) {
 
 // Categories retrieves all categories
 func (r *queryResolver) Categories(ctx context.Context) ([]*Category, error) {
+	if r.DB == nil {
+		return nil, errPostgresRequired
+	}
 	dbCategories, err := r.DB.GetCategories()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get categories: %w", err)
@@ -110,6 +136,9 @@ type mutationResolver struct{ *Resolver }
 
 // CreateMessage creates a new message
 func (r *mutationResolver) CreateMessage(ctx context.Context, content string) (*Message, error) {
+	if r.DB == nil {
+		return nil, errPostgresRequired
+	}
 	msg, err := r.DB.CreateMessage(content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create message: %w", err)
@@ -122,22 +151,66 @@ func (r *mutationResolver) CreateMessage(ctx context.Context, content string) (*
 	}, nil
 }
 
+// providerConversion returns the conversion of the USD provider prices to the requested
+// currency, which converts nothing when no currency is requested
+func (r *Resolver) providerConversion(ctx context.Context, currencyArg *string) (priceConversion, error) {
+	if currencyArg == nil || *currencyArg == "" {
+		return priceConversion{}, nil
+	}
+	if r.Currency == nil {
+		return priceConversion{}, fmt.Errorf("currency conversion is not configured")
+	}
+	conversion, err := r.Currency.Conversion(ctx, currency.PivotCurrency, *currencyArg, time.Now())
+	if err != nil {
+		return priceConversion{}, fmt.Errorf("failed to convert prices to %s: %w", *currencyArg, err)
+	}
+	return priceConversion{conversion: conversion}, nil
+}
+
 // AWS Provider Implementation - methods for auto-generated types
 // The AWSProvider, AWSCompute, etc. types are auto-generated in models_gen.go
 
-func (p *AWSProvider) Compute(ctx context.Context, region string) (*AWSCompute, error) {
-	return &AWSCompute{resolver: p.resolver, region: region}, nil
+func (p *AWSProvider) Compute(ctx context.Context, region string, currency *string) (*AWSCompute, error) {
+	conversion, err := p.resolver.providerConversion(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &AWSCompute{priceConversion: conversion, resolver: p.resolver, region: region}, nil
 }
 
-func (p *AWSProvider) Storage(ctx context.Context, region string) (*AWSStorage, error) {
-	return &AWSStorage{resolver: p.resolver, region: region}, nil
+func (p *AWSProvider) Storage(ctx context.Context, region string, currency *string) (*AWSStorage, error) {
+	conversion, err := p.resolver.providerConversion(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &AWSStorage{priceConversion: conversion, resolver: p.resolver, region: region}, nil
 }
 
-func (p *AWSProvider) DataTransfer(ctx context.Context, region string) (*AWSDataTransfer, error) {
-	return &AWSDataTransfer{resolver: p.resolver, region: region}, nil
+func (p *AWSProvider) DataTransfer(ctx context.Context, region string, currency *string) (*AWSDataTransfer, error) {
+	conversion, err := p.resolver.providerConversion(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &AWSDataTransfer{priceConversion: conversion, resolver: p.resolver, region: region}, nil
 }
 
-func (c *AWSCompute) InstancePrice(ctx context.Context, instanceType string) (float64, error) {
+// AWSCompute returns the resolver of the AWS compute fields that gqlgen cannot bind to methods
+func (r *Resolver) AWSCompute() AWSComputeResolver {
+	return &awsComputeResolver{r}
+}
+
+type awsComputeResolver struct{ *Resolver }
+
+// InstancePrice resolves instancePrice, whose type argument cannot be a Go parameter name
+func (r *awsComputeResolver) InstancePrice(ctx context.Context, obj *AWSCompute, typeArg string) (float64, error) {
+	price, err := obj.instancePrice(ctx, typeArg)
+	if err != nil {
+		return 0, err
+	}
+	return obj.convert(price), nil
+}
+
+func (c *AWSCompute) instancePrice(ctx context.Context, instanceType string) (float64, error) {
 	// First try to get from raw data
 	price, err := c.getInstancePriceFromRaw(ctx, instanceType)
 	if err == nil {
@@ -151,9 +224,10 @@ func (c *AWSCompute) InstancePrice(ctx context.Context, instanceType string) (fl
 		ServiceType:      &[]string{"ec2"}[0],
 		NormalizedRegion: &c.region,
 		PricingModel:     &[]string{database.PricingModelOnDemand}[0],
+		AsOf:             &[]time.Time{time.Now()}[0],
 	}
 
-	pricings, err := c.resolver.DB.QueryNormalizedPricing(filter)
+	pricings, err := c.resolver.pricing().QueryNormalizedPricing(filter)
 	if err == nil {
 		// Find matching instance type
 		for _, pricing := range pricings {
@@ -175,10 +249,11 @@ func (c *AWSCompute) Instances(ctx context.Context) ([]*AWSInstance, error) {
 		ServiceType:      &[]string{"ec2"}[0],
 		NormalizedRegion: &c.region,
 		PricingModel:     &[]string{database.PricingModelOnDemand}[0],
+		AsOf:             &[]time.Time{time.Now()}[0],
 		Limit:           &[]int{50}[0], // Limit to 50 for performance
 	}
 
-	pricings, err := c.resolver.DB.QueryNormalizedPricing(filter)
+	pricings, err := c.resolver.pricing().QueryNormalizedPricing(filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query AWS instances: %w", err)
 	}
@@ -189,7 +264,7 @@ func (c *AWSCompute) Instances(ctx context.Context) ([]*AWSInstance, error) {
 			Type:         pricing.ResourceName,
 			Vcpu:         pricing.ResourceSpecs.GetVCPU(),
 			MemoryGb:     pricing.ResourceSpecs.GetMemoryGB(),
-			PricePerHour: pricing.PricePerUnit,
+			PricePerHour: c.convert(pricing.PricePerUnit),
 			Architecture: pricing.ResourceSpecs.Architecture,
 			Burstable:    pricing.ResourceSpecs.Burstable,
 		})
@@ -202,6 +277,14 @@ func (c *AWSCompute) Instances(ctx context.Context) ([]*AWSInstance, error) {
 // AWSStorage methods for auto-generated type
 
 func (s *AWSStorage) PricePerGb(ctx context.Context, tier string) (float64, error) {
+	price, err := s.pricePerGB(ctx, tier)
+	if err != nil {
+		return 0, err
+	}
+	return s.convert(price), nil
+}
+
+func (s *AWSStorage) pricePerGB(ctx context.Context, tier string) (float64, error) {
 	// First try to get from raw data
 	price, err := s.getStoragePriceFromRaw(ctx, tier)
 	if err == nil {
@@ -215,9 +298,10 @@ func (s *AWSStorage) PricePerGb(ctx context.Context, tier string) (float64, erro
 		ServiceType:      &[]string{"s3"}[0],
 		NormalizedRegion: &s.region,
 		PricingModel:     &[]string{database.PricingModelOnDemand}[0],
+		AsOf:             &[]time.Time{time.Now()}[0],
 	}
 
-	pricings, err := s.resolver.DB.QueryNormalizedPricing(filter)
+	pricings, err := s.resolver.pricing().QueryNormalizedPricing(filter)
 	if err == nil {
 		// Find matching storage tier
 		for _, pricing := range pricings {
@@ -232,10 +316,10 @@ func (s *AWSStorage) PricePerGb(ctx context.Context, tier string) (float64, erro
 
 func (s *AWSStorage) Tiers(ctx context.Context) ([]*AWSStorageTier, error) {
 	return []*AWSStorageTier{
-		{Name: "standard", PricePerGb: 0.023, Description: "S3 Standard storage"},
-		{Name: "infrequent_access", PricePerGb: 0.0125, Description: "S3 Infrequent Access"},
-		{Name: "glacier", PricePerGb: 0.004, Description: "S3 Glacier"},
-		{Name: "deep_archive", PricePerGb: 0.00099, Description: "S3 Glacier Deep Archive"},
+		{Name: "standard", PricePerGb: s.convert(0.023), Description: &[]string{"S3 Standard storage"}[0]},
+		{Name: "infrequent_access", PricePerGb: s.convert(0.0125), Description: &[]string{"S3 Infrequent Access"}[0]},
+		{Name: "glacier", PricePerGb: s.convert(0.004), Description: &[]string{"S3 Glacier"}[0]},
+		{Name: "deep_archive", PricePerGb: s.convert(0.00099), Description: &[]string{"S3 Glacier Deep Archive"}[0]},
 	}, nil
 }
 
@@ -246,7 +330,7 @@ func (dt *AWSDataTransfer) PricePerGb(ctx context.Context, direction string) (fl
 	if direction == "in" {
 		return 0.0, nil // AWS inbound is free
 	}
-	return 0.09, nil // Default outbound pricing
+	return dt.convert(0.09), nil // Default outbound pricing
 }
 
 func (dt *AWSDataTransfer) Inbound(ctx context.Context) (float64, error) {
@@ -254,28 +338,48 @@ func (dt *AWSDataTransfer) Inbound(ctx context.Context) (float64, error) {
 }
 
 func (dt *AWSDataTransfer) Outbound(ctx context.Context) (float64, error) {
-	return 0.09, nil // Standard outbound pricing
+	return dt.convert(0.09), nil // Standard outbound pricing
 }
 
 // Azure Provider Implementation
 // AzureProvider methods for auto-generated type
 
-func (p *AzureProvider) Compute(ctx context.Context, region string) (*AzureCompute, error) {
-	return &AzureCompute{resolver: p.resolver, region: region}, nil
+func (p *AzureProvider) Compute(ctx context.Context, region string, currency *string) (*AzureCompute, error) {
+	conversion, err := p.resolver.providerConversion(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &AzureCompute{priceConversion: conversion, resolver: p.resolver, region: region}, nil
 }
 
-func (p *AzureProvider) Storage(ctx context.Context, region string) (*AzureStorage, error) {
-	return &AzureStorage{resolver: p.resolver, region: region}, nil
+func (p *AzureProvider) Storage(ctx context.Context, region string, currency *string) (*AzureStorage, error) {
+	conversion, err := p.resolver.providerConversion(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &AzureStorage{priceConversion: conversion, resolver: p.resolver, region: region}, nil
 }
 
-func (p *AzureProvider) DataTransfer(ctx context.Context, region string) (*AzureDataTransfer, error) {
-	return &AzureDataTransfer{resolver: p.resolver, region: region}, nil
+func (p *AzureProvider) DataTransfer(ctx context.Context, region string, currency *string) (*AzureDataTransfer, error) {
+	conversion, err := p.resolver.providerConversion(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &AzureDataTransfer{priceConversion: conversion, resolver: p.resolver, region: region}, nil
 }
 
 // Azure Compute Implementation
 // AzureCompute methods for auto-generated type
 
 func (c *AzureCompute) VmPrice(ctx context.Context, size string) (float64, error) {
+	price, err := c.vmPrice(ctx, size)
+	if err != nil {
+		return 0, err
+	}
+	return c.convert(price), nil
+}
+
+func (c *AzureCompute) vmPrice(ctx context.Context, size string) (float64, error) {
 	// First try to get from raw data
 	price, err := c.getVMPriceFromRaw(ctx, size)
 	if err == nil {
@@ -289,9 +393,10 @@ func (c *AzureCompute) VmPrice(ctx context.Context, size string) (float64, error
 		ServiceType:      &[]string{"virtual_machines"}[0],
 		NormalizedRegion: &c.region,
 		PricingModel:     &[]string{database.PricingModelOnDemand}[0],
+		AsOf:             &[]time.Time{time.Now()}[0],
 	}
 
-	pricings, err := c.resolver.DB.QueryNormalizedPricing(filter)
+	pricings, err := c.resolver.pricing().QueryNormalizedPricing(filter)
 	if err == nil {
 		// Find matching VM size
 		for _, pricing := range pricings {
@@ -311,10 +416,11 @@ func (c *AzureCompute) Vms(ctx context.Context) ([]*AzureVM, error) {
 		ServiceType:      &[]string{"virtual_machines"}[0],
 		NormalizedRegion: &c.region,
 		PricingModel:     &[]string{database.PricingModelOnDemand}[0],
+		AsOf:             &[]time.Time{time.Now()}[0],
 		Limit:           &[]int{50}[0],
 	}
 
-	pricings, err := c.resolver.DB.QueryNormalizedPricing(filter)
+	pricings, err := c.resolver.pricing().QueryNormalizedPricing(filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query Azure VMs: %w", err)
 	}
@@ -325,7 +431,7 @@ func (c *AzureCompute) Vms(ctx context.Context) ([]*AzureVM, error) {
 			Size:         pricing.ResourceName,
 			Vcpu:         pricing.ResourceSpecs.GetVCPU(),
 			MemoryGb:     pricing.ResourceSpecs.GetMemoryGB(),
-			PricePerHour: pricing.PricePerUnit,
+			PricePerHour: c.convert(pricing.PricePerUnit),
 			Architecture: pricing.ResourceSpecs.Architecture,
 			Burstable:    pricing.ResourceSpecs.Burstable,
 		})
@@ -338,6 +444,14 @@ func (c *AzureCompute) Vms(ctx context.Context) ([]*AzureVM, error) {
 // AzureStorage methods for auto-generated type
 
 func (s *AzureStorage) PricePerGb(ctx context.Context, tier string) (float64, error) {
+	price, err := s.pricePerGB(ctx, tier)
+	if err != nil {
+		return 0, err
+	}
+	return s.convert(price), nil
+}
+
+func (s *AzureStorage) pricePerGB(ctx context.Context, tier string) (float64, error) {
 	// First try to get from raw data
 	price, err := s.getStoragePriceFromRaw(ctx, tier)
 	if err == nil {
@@ -351,9 +465,10 @@ func (s *AzureStorage) PricePerGb(ctx context.Context, tier string) (float64, er
 		ServiceType:      &[]string{"blob_storage"}[0],
 		NormalizedRegion: &s.region,
 		PricingModel:     &[]string{database.PricingModelOnDemand}[0],
+		AsOf:             &[]time.Time{time.Now()}[0],
 	}
 
-	pricings, err := s.resolver.DB.QueryNormalizedPricing(filter)
+	pricings, err := s.resolver.pricing().QueryNormalizedPricing(filter)
 	if err == nil {
 		for _, pricing := range pricings {
 			if strings.Contains(strings.ToLower(pricing.ResourceName), strings.ToLower(tier)) {
@@ -367,9 +482,9 @@ func (s *AzureStorage) PricePerGb(ctx context.Context, tier string) (float64, er
 
 func (s *AzureStorage) Tiers(ctx context.Context) ([]*AzureStorageTier, error) {
 	return []*AzureStorageTier{
-		{Name: "hot", PricePerGb: 0.0184, Description: "Hot access tier"},
-		{Name: "cool", PricePerGb: 0.01, Description: "Cool access tier"},
-		{Name: "archive", PricePerGb: 0.00099, Description: "Archive access tier"},
+		{Name: "hot", PricePerGb: s.convert(0.0184), Description: &[]string{"Hot access tier"}[0]},
+		{Name: "cool", PricePerGb: s.convert(0.01), Description: &[]string{"Cool access tier"}[0]},
+		{Name: "archive", PricePerGb: s.convert(0.00099), Description: &[]string{"Archive access tier"}[0]},
 	}, nil
 }
 
@@ -380,7 +495,7 @@ func (dt *AzureDataTransfer) PricePerGb(ctx context.Context, direction string) (
 	if direction == "in" {
 		return 0.0, nil // Azure inbound is free
 	}
-	return 0.0877, nil // Default outbound pricing
+	return dt.convert(0.0877), nil // Default outbound pricing
 }
 
 func (dt *AzureDataTransfer) Inbound(ctx context.Context) (float64, error) {
@@ -388,7 +503,7 @@ func (dt *AzureDataTransfer) Inbound(ctx context.Context) (float64, error) {
 }
 
 func (dt *AzureDataTransfer) Outbound(ctx context.Context) (float64, error) {
-	return 0.0877, nil
+	return dt.convert(0.0877), nil
 }
 
 // Helper functions for fallback pricing
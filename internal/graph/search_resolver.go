@@ -0,0 +1,70 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/raulc0399/cpc/internal/database"
+)
+
+// Search ranks the live normalized prices matching a free-text query with facet counts,
+// converting the prices of the hits to the requested currency if one is given
+func (r *queryResolver) Search(ctx context.Context, query string, filter *SearchFilterInput, limit *int, offset *int, currencyArg *string) (*PricingSearchResult, error) {
+	req := database.PricingSearchRequest{Query: query}
+	if filter != nil {
+		req.Provider = filter.Provider
+		req.NormalizedRegion = filter.Region
+		req.ServiceCategory = filter.ServiceCategory
+		req.PricingModel = filter.PricingModel
+	}
+	if limit != nil {
+		req.Limit = *limit
+	}
+	if offset != nil {
+		req.Offset = *offset
+	}
+
+	found, err := r.pricing().SearchNormalizedPricing(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search pricing: %w", err)
+	}
+
+	convert, err := r.pricingConverter(ctx, currencyArg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PricingSearchResult{
+		Query: found.Query,
+		Total: found.Total,
+		Hits:  make([]*PricingSearchHit, len(found.Hits)),
+		Facets: &SearchFacets{
+			Providers:         convertSearchFacetValues(found.Facets.Providers),
+			Regions:           convertSearchFacetValues(found.Facets.Regions),
+			ServiceCategories: convertSearchFacetValues(found.Facets.ServiceCategories),
+			PricingModels:     convertSearchFacetValues(found.Facets.PricingModels),
+		},
+	}
+	for i, hit := range found.Hits {
+		pricing, err := convert(hit.NormalizedPricing)
+		if err != nil {
+			return nil, err
+		}
+		result.Hits[i] = &PricingSearchHit{
+			Score:   hit.Score,
+			Pricing: pricing,
+		}
+	}
+	return result, nil
+}
+
+func convertSearchFacetValues(values []database.SearchFacetValue) []*SearchFacetValue {
+	result := make([]*SearchFacetValue, len(values))
+	for i, value := range values {
+		result[i] = &SearchFacetValue{
+			Value: value.Value,
+			Count: value.Count,
+		}
+	}
+	return result
+}
@@ -3,7 +3,10 @@ package normalizer
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/raulc0399/cpc/internal/database"
 )
@@ -59,9 +62,9 @@ func (n *AWSNormalizerV2) ValidateInput(input database.NormalizationInput) error
 
 // NormalizePricing normalizes raw AWS pricing data
 func (n *AWSNormalizerV2) NormalizePricing(ctx context.Context, input database.NormalizationInput) (*database.NormalizationResult, error) {
-	n.logger.Info("Starting AWS pricing normalization",
+	logger := n.ContextLogger(ctx, input)
+	logger.Info("Starting AWS pricing normalization",
 		Field{"service", input.ServiceCode},
-		Field{"region", input.Region},
 	)
 
 	// Validate input
@@ -69,6 +72,10 @@ func (n *AWSNormalizerV2) NormalizePricing(ctx context.Context, input database.N
 		return n.CreateErrorResult("validation failed", err), nil
 	}
 
+	if n.IsExcluded(input) {
+		return n.CreateSkippedResult("excluded by mapping configuration", 1), nil
+	}
+
 	// Parse AWS product JSON
 	var awsProduct AWSProduct
 	if err := n.ParseJSONData(input.RawData, &awsProduct); err != nil {
@@ -80,6 +87,11 @@ func (n *AWSNormalizerV2) NormalizePricing(ctx context.Context, input database.N
 		return n.CreateErrorResult("invalid AWS product structure", err), nil
 	}
 
+	// Capacity reservation line items (unused or allocated reservations) are not instance prices
+	if status, ok := awsAttribute(awsProduct.Product.Attributes, "capacitystatus"); ok && !strings.EqualFold(status, "Used") {
+		return n.CreateSkippedResult("capacity reservation line item", 1), nil
+	}
+
 	// Get normalization context
 	normCtx, err := n.GetNormalizationContext(ctx, input)
 	if err != nil {
@@ -92,9 +104,8 @@ func (n *AWSNormalizerV2) NormalizePricing(ctx context.Context, input database.N
 	// Process pricing terms
 	result := n.processPricingTerms(ctx, &awsProduct, normCtx)
 
-	n.logger.Info("Completed AWS pricing normalization",
+	logger.Info("Completed AWS pricing normalization",
 		Field{"service", input.ServiceCode},
-		Field{"region", input.Region},
 		Field{"recordCount", len(result.NormalizedRecords)},
 		Field{"errorCount", result.ErrorCount},
 		Field{"skippedCount", result.SkippedCount},
@@ -119,6 +130,7 @@ type AWSProduct struct {
 type AWSTermData struct {
 	OfferTermCode    string                          `json:"offerTermCode"`
 	SKU              string                          `json:"sku"`
+	EffectiveDate    string                          `json:"effectiveDate"`
 	PriceDimensions  map[string]AWSPriceDimension   `json:"priceDimensions"`
 	TermAttributes   map[string]interface{}         `json:"termAttributes,omitempty"`
 }
@@ -127,6 +139,8 @@ type AWSTermData struct {
 type AWSPriceDimension struct {
 	Description  string                 `json:"description"`
 	Unit         string                 `json:"unit"`
+	BeginRange   string                 `json:"beginRange,omitempty"`
+	EndRange     string                 `json:"endRange,omitempty"`
 	PricePerUnit map[string]string     `json:"pricePerUnit"`
 	AppliesTo    []string              `json:"appliesTo,omitempty"`
 }
@@ -202,9 +216,18 @@ func (n *AWSNormalizerV2) processTermData(
 	var errors []string
 	skippedCount := 0
 
-	for _, dimension := range termData.PriceDimensions {
+	// Extract pricing details
+	pricingDetails := n.extractPricingDetails(termData.TermAttributes, pricingModel)
+	if pricingModel != database.PricingModelOnDemand {
+		n.amortizeReservedTerm(&pricingDetails, termData.PriceDimensions, pricingModel)
+	}
+
+	platform := n.extractPlatformDimensions(attributes)
+
+	// Tiers of the same unit (e.g., S3 storage or egress volume tiers) form a single price
+	for _, dimensions := range groupAWSPriceDimensions(termData.PriceDimensions) {
 		// Extract pricing info
-		priceInfo, err := n.extractPricingFromDimension(&dimension)
+		priceInfo, priceTiers, err := n.extractPricingFromDimensions(dimensions)
 		if err != nil {
 			errors = append(errors, fmt.Sprintf("failed to extract pricing: %v", err))
 			continue
@@ -230,9 +253,6 @@ func (n *AWSNormalizerV2) processTermData(
 		// Create resource name
 		resourceName := n.createResourceName(attributes, normCtx.ServiceMapping.NormalizedServiceType)
 
-		// Extract pricing details
-		pricingDetails := n.extractPricingDetails(termData.TermAttributes, pricingModel)
-
 		// Create normalized record
 		record, err := n.CreateNormalizedRecord(
 			ctx, normCtx, *priceInfo, resourceSpecs,
@@ -247,6 +267,9 @@ func (n *AWSNormalizerV2) processTermData(
 			// Add provider SKU
 			sku := termData.SKU
 			record.ProviderSKU = &sku
+			n.SetPriceTiers(record, priceTiers)
+			n.SetValidity(record, termData.EffectiveDate, "")
+			platform.Apply(record)
 			records = append(records, *record)
 		}
 	}
@@ -283,6 +306,107 @@ func (n *AWSNormalizerV2) extractPricingFromDimension(dimension *AWSPriceDimensi
 	}, nil
 }
 
+// extractPricingFromDimensions extracts pricing info from the dimensions of one unit. A single
+// dimension covering all usage is a flat price; otherwise the dimensions become price tiers and
+// the first non-zero tier sets the headline price.
+func (n *AWSNormalizerV2) extractPricingFromDimensions(dimensions []AWSPriceDimension) (*PricingInfo, []database.PriceTier, error) {
+	if len(dimensions) == 1 && !isAWSTieredDimension(dimensions[0]) {
+		priceInfo, err := n.extractPricingFromDimension(&dimensions[0])
+		return priceInfo, nil, err
+	}
+
+	var headline *PricingInfo
+	tiers := make([]database.PriceTier, 0, len(dimensions))
+	for i := range dimensions {
+		priceInfo, err := n.extractPricingFromDimension(&dimensions[i])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		tier, err := parseAWSPriceTier(dimensions[i], priceInfo.PricePerUnit)
+		if err != nil {
+			return nil, nil, err
+		}
+		tiers = append(tiers, tier)
+
+		if headline == nil && priceInfo.PricePerUnit > 0 {
+			headline = priceInfo
+		}
+	}
+
+	if headline == nil {
+		// All tiers are free; report a zero price so the dimension is skipped
+		return &PricingInfo{Unit: dimensions[0].Unit}, nil, nil
+	}
+
+	return headline, tiers, nil
+}
+
+// groupAWSPriceDimensions groups price dimensions by unit, ordering tiers by their begin range
+func groupAWSPriceDimensions(priceDimensions map[string]AWSPriceDimension) [][]AWSPriceDimension {
+	byUnit := make(map[string][]AWSPriceDimension)
+	for _, dimension := range priceDimensions {
+		byUnit[dimension.Unit] = append(byUnit[dimension.Unit], dimension)
+	}
+
+	units := make([]string, 0, len(byUnit))
+	for unit := range byUnit {
+		units = append(units, unit)
+	}
+	sort.Strings(units)
+
+	groups := make([][]AWSPriceDimension, 0, len(units))
+	for _, unit := range units {
+		dimensions := byUnit[unit]
+		sort.SliceStable(dimensions, func(i, j int) bool {
+			return parseAWSRangeStart(dimensions[i].BeginRange) < parseAWSRangeStart(dimensions[j].BeginRange)
+		})
+		groups = append(groups, dimensions)
+	}
+
+	return groups
+}
+
+// isAWSTieredDimension reports whether a dimension covers only part of the usage range
+func isAWSTieredDimension(dimension AWSPriceDimension) bool {
+	if dimension.BeginRange == "" && dimension.EndRange == "" {
+		return false
+	}
+	return parseAWSRangeStart(dimension.BeginRange) > 0 || (dimension.EndRange != "" && dimension.EndRange != "Inf")
+}
+
+// parseAWSPriceTier converts a dimension's begin/end range into a price tier
+func parseAWSPriceTier(dimension AWSPriceDimension, pricePerUnit float64) (database.PriceTier, error) {
+	tier := database.PriceTier{PricePerUnit: pricePerUnit}
+
+	if dimension.BeginRange != "" {
+		start, err := strconv.ParseFloat(dimension.BeginRange, 64)
+		if err != nil {
+			return tier, fmt.Errorf("failed to parse begin range %s: %w", dimension.BeginRange, err)
+		}
+		tier.StartUnits = start
+	}
+
+	if dimension.EndRange != "" && dimension.EndRange != "Inf" {
+		end, err := strconv.ParseFloat(dimension.EndRange, 64)
+		if err != nil {
+			return tier, fmt.Errorf("failed to parse end range %s: %w", dimension.EndRange, err)
+		}
+		tier.EndUnits = &end
+	}
+
+	return tier, nil
+}
+
+// parseAWSRangeStart parses a begin range for ordering, treating missing values as zero
+func parseAWSRangeStart(beginRange string) float64 {
+	start, err := strconv.ParseFloat(beginRange, 64)
+	if err != nil {
+		return 0
+	}
+	return start
+}
+
 // createResourceName creates a standardized resource name
 func (n *AWSNormalizerV2) createResourceName(attributes map[string]interface{}, serviceType string) string {
 	switch serviceType {
@@ -329,6 +453,65 @@ func (n *AWSNormalizerV2) extractPricingDetails(termAttributes map[string]interf
 	return details
 }
 
+// extractPlatformDimensions extracts the operating system, license model, pre-installed software
+// and tenancy of a product. They are only present for services such as EC2 and RDS.
+func (n *AWSNormalizerV2) extractPlatformDimensions(attributes map[string]interface{}) PlatformDimensions {
+	var platform PlatformDimensions
+
+	if os, ok := awsAttribute(attributes, "operatingSystem"); ok {
+		platform.OperatingSystem = optionalDimension(normalizeOperatingSystem(os))
+	}
+	if license, ok := awsAttribute(attributes, "licenseModel"); ok {
+		platform.LicenseModel = optionalDimension(normalizeLicenseModel(license))
+	}
+	// "NA" means no pre-installed software, so it is not read through awsAttribute
+	if software, ok := attributes["preInstalledSw"].(string); ok {
+		platform.PreInstalledSoftware = optionalDimension(normalizePreInstalledSoftware(software))
+	}
+	if tenancy, ok := awsAttribute(attributes, "tenancy"); ok {
+		platform.Tenancy = optionalDimension(normalizeTenancy(tenancy))
+	}
+	// AWS labels Windows, RHEL and SQL Server offers "No License required" because the license
+	// comes with the price, which Azure and this model call license included
+	if platform.LicenseModel != nil && *platform.LicenseModel == database.LicenseModelNone && platform.runsLicensedSoftware() {
+		platform.LicenseModel = optionalDimension(database.LicenseModelIncluded)
+	}
+
+	return platform
+}
+
+// amortizeReservedTerm sets the effective rates of a reserved term. AWS splits the price of a
+// reserved term into an upfront fee (unit "Quantity") and an hourly fee (unit "Hrs"), which become
+// separate records; both records carry the combined effective rate.
+func (n *AWSNormalizerV2) amortizeReservedTerm(details *database.PricingDetails, priceDimensions map[string]AWSPriceDimension, pricingModel string) {
+	termHours, ok := commitmentTermHours(*details, pricingModel)
+	if !ok {
+		return
+	}
+
+	var upfrontCost, hourlyRate float64
+	found := false
+	for _, dimension := range priceDimensions {
+		priceInfo, err := n.extractPricingFromDimension(&dimension)
+		if err != nil {
+			continue
+		}
+
+		switch dimension.Unit {
+		case "Quantity":
+			upfrontCost += priceInfo.PricePerUnit
+			found = true
+		case "Hrs":
+			hourlyRate += priceInfo.PricePerUnit
+			found = true
+		}
+	}
+
+	if found {
+		details.Amortize(upfrontCost, hourlyRate, termHours)
+	}
+}
+
 // determineReservedPricingModel determines the specific reserved pricing model
 func (n *AWSNormalizerV2) determineReservedPricingModel(termAttributes map[string]interface{}) string {
 	if termAttributes == nil {
@@ -342,4 +525,220 @@ func (n *AWSNormalizerV2) determineReservedPricingModel(termAttributes map[strin
 	}
 
 	return database.PricingModelReserved1Yr
-}
\ No newline at end of file
+}
+
+// AWSResourceSpecExtractor extracts resource specifications from AWS product attributes.
+// It understands the attribute formats used by EC2, RDS and ElastiCache offers.
+type AWSResourceSpecExtractor struct{}
+
+// NewAWSResourceSpecExtractor creates a new AWS resource spec extractor
+func NewAWSResourceSpecExtractor() *AWSResourceSpecExtractor {
+	return &AWSResourceSpecExtractor{}
+}
+
+var (
+	// awsStoragePattern matches local instance storage such as "2 x 300 NVMe SSD" or "1 x 1.9 TB SSD"
+	awsStoragePattern = regexp.MustCompile(`(?i)^(?:(\d+)\s*x\s*)?([\d.,]+)\s*(GB|TB)?\s*(.*)$`)
+
+	// awsSizePattern matches a quantity with an optional binary or decimal unit such as "16 GiB"
+	awsSizePattern = regexp.MustCompile(`(?i)^([\d.,]+)\s*(GiB|GB|TiB|TB|MiB|MB)?$`)
+
+	// awsClockSpeedPattern matches clock speeds such as "3.1 GHz" or "Up to 3.5 GHz"
+	awsClockSpeedPattern = regexp.MustCompile(`(?i)([\d.]+)\s*GHz`)
+
+	// awsInstanceFamilyPattern splits an instance type such as "db.m6gd.large" into family and size
+	awsInstanceFamilyPattern = regexp.MustCompile(`^(?:db\.|cache\.)?([a-z]+)(\d+)([a-z-]*)\.`)
+)
+
+// ExtractResourceSpecs extracts and normalizes resource specifications from AWS attributes
+func (e *AWSResourceSpecExtractor) ExtractResourceSpecs(provider, serviceType string, data map[string]interface{}) (database.ResourceSpecs, error) {
+	specs := database.ResourceSpecs{}
+
+	if value, ok := awsAttribute(data, "vcpu"); ok {
+		vcpu, err := strconv.Atoi(strings.ReplaceAll(value, ",", ""))
+		if err != nil {
+			return specs, fmt.Errorf("invalid vcpu %q: %w", value, err)
+		}
+		specs.VCPU = &vcpu
+	}
+
+	if value, ok := awsAttribute(data, "memory"); ok {
+		memoryGB, err := parseAWSSizeGB(value)
+		if err != nil {
+			return specs, fmt.Errorf("invalid memory %q: %w", value, err)
+		}
+		specs.MemoryGB = &memoryGB
+	}
+
+	if value, ok := awsAttribute(data, "gpu"); ok {
+		gpuCount, err := strconv.Atoi(value)
+		if err != nil {
+			return specs, fmt.Errorf("invalid gpu %q: %w", value, err)
+		}
+		if gpuCount > 0 {
+			specs.GPUCount = &gpuCount
+		}
+	}
+
+	if value, ok := awsAttribute(data, "gpuMemory"); ok {
+		gpuMemoryGB, err := parseAWSSizeGB(value)
+		if err != nil {
+			return specs, fmt.Errorf("invalid gpuMemory %q: %w", value, err)
+		}
+		if gpuMemoryGB > 0 {
+			specs.GPUMemoryGB = &gpuMemoryGB
+		}
+	}
+
+	if value, ok := awsAttribute(data, "storage"); ok {
+		e.extractStorage(&specs, value)
+	}
+
+	if value, ok := awsAttribute(data, "networkPerformance"); ok {
+		specs.NetworkPerformance = &value
+	}
+
+	if value, ok := awsAttribute(data, "physicalProcessor"); ok {
+		specs.ProcessorType = &value
+	}
+
+	if value, ok := awsAttribute(data, "processorFeatures"); ok {
+		specs.ProcessorFeatures = splitAWSList(value)
+	}
+
+	if value, ok := awsAttribute(data, "clockSpeed"); ok {
+		if matches := awsClockSpeedPattern.FindStringSubmatch(value); len(matches) > 1 {
+			if clockSpeed, err := strconv.ParseFloat(matches[1], 64); err == nil {
+				specs.ClockSpeedGHz = &clockSpeed
+			}
+		}
+	}
+
+	instanceType, _ := awsAttribute(data, "instanceType")
+	if architecture := e.detectArchitecture(data, instanceType); architecture != "" {
+		specs.Architecture = &architecture
+	}
+
+	if family := awsInstanceFamilyPattern.FindStringSubmatch(instanceType); len(family) > 1 {
+		// T-family instances (t2, t3, t3a, t4g) run on CPU credits
+		burstable := family[1] == "t"
+		specs.Burstable = &burstable
+	}
+
+	return specs, nil
+}
+
+// extractStorage extracts local instance storage such as "2 x 300 NVMe SSD" or "EBS only"
+func (e *AWSResourceSpecExtractor) extractStorage(specs *database.ResourceSpecs, storage string) {
+	if strings.HasPrefix(strings.ToLower(storage), "ebs") {
+		storageType := "EBS only"
+		specs.StorageType = &storageType
+		return
+	}
+
+	matches := awsStoragePattern.FindStringSubmatch(storage)
+	if len(matches) < 5 {
+		return
+	}
+
+	size, err := strconv.ParseFloat(strings.ReplaceAll(matches[2], ",", ""), 64)
+	if err != nil {
+		return
+	}
+
+	count := 1
+	if matches[1] != "" {
+		if parsed, err := strconv.Atoi(matches[1]); err == nil {
+			count = parsed
+		}
+	}
+
+	if strings.EqualFold(matches[3], "TB") {
+		size *= 1000
+	}
+
+	storageGB := float64(count) * size
+	specs.StorageGB = &storageGB
+
+	if storageType := strings.TrimSpace(matches[4]); storageType != "" {
+		specs.StorageType = &storageType
+	}
+}
+
+// detectArchitecture determines the CPU architecture from the processor and instance family
+func (e *AWSResourceSpecExtractor) detectArchitecture(data map[string]interface{}, instanceType string) string {
+	if processor, ok := awsAttribute(data, "physicalProcessor"); ok {
+		lower := strings.ToLower(processor)
+		if strings.Contains(lower, "graviton") || strings.Contains(lower, "apple m") {
+			return "arm64"
+		}
+	}
+
+	// Graviton families carry a "g" right after the generation, e.g. m6g, c7gn, t4g, g5g
+	if family := awsInstanceFamilyPattern.FindStringSubmatch(instanceType); len(family) > 3 {
+		if strings.HasPrefix(family[3], "g") {
+			return "arm64"
+		}
+		return "x86_64"
+	}
+
+	if architecture, ok := awsAttribute(data, "processorArchitecture"); ok && strings.Contains(architecture, "64-bit") {
+		return "x86_64"
+	}
+
+	return ""
+}
+
+// awsAttribute returns a trimmed string attribute, treating empty and "NA" values as missing
+func awsAttribute(data map[string]interface{}, key string) (string, bool) {
+	value, ok := data[key].(string)
+	if !ok {
+		return "", false
+	}
+
+	value = strings.TrimSpace(value)
+	switch strings.ToUpper(value) {
+	case "", "NA", "N/A":
+		return "", false
+	}
+
+	return value, true
+}
+
+// parseAWSSizeGB parses sizes such as "16 GiB", "1,952 GiB" or "0.5 GiB" into gigabytes
+func parseAWSSizeGB(value string) (float64, error) {
+	matches := awsSizePattern.FindStringSubmatch(strings.TrimSpace(value))
+	if len(matches) < 3 {
+		return 0, fmt.Errorf("unrecognized size format")
+	}
+
+	size, err := strconv.ParseFloat(strings.ReplaceAll(matches[1], ",", ""), 64)
+	if err != nil {
+		return 0, err
+	}
+
+	switch strings.ToLower(matches[2]) {
+	case "tib", "tb":
+		size *= 1024
+	case "mib", "mb":
+		size /= 1024
+	}
+
+	return size, nil
+}
+
+// splitAWSList splits a semicolon or comma separated attribute into its items
+func splitAWSList(value string) []string {
+	parts := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ';' || r == ','
+	})
+
+	items := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if item := strings.TrimSpace(part); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -126,6 +126,37 @@ func TestAWSNormalizerV2_NormalizePricing(t *testing.T) {
 			expectedErrors:  0,
 			expectedSkipped: 0,
 		},
+		{
+			name: "unused capacity reservation - skip",
+			input: database.NormalizationInput{
+				Provider:    database.ProviderAWS,
+				ServiceCode: "AmazonEC2",
+				Region:      "us-east-1",
+				RawData:     getCapacityReservationEC2JSON(),
+				RawDataID:   1,
+			},
+			setupMocks: func(serviceRepo *MockServiceMappingRepository, regionRepo *MockRegionMappingRepository, unitNorm *MockUnitNormalizer) {
+				serviceRepo.AddMapping(database.ProviderAWS, "AmazonEC2", &database.ServiceMapping{
+					ID:                    1,
+					Provider:              database.ProviderAWS,
+					ProviderServiceName:   "Amazon Elastic Compute Cloud",
+					ProviderServiceCode:   stringPtr("AmazonEC2"),
+					NormalizedServiceType: "Virtual Machines",
+					ServiceCategory:       "Compute & Web",
+					ServiceFamily:         "Virtual Machines",
+				})
+				regionRepo.AddRegion(database.ProviderAWS, "us-east-1", &database.NormalizedRegion{
+					ID:             1,
+					NormalizedCode: "us-east",
+					AWSRegion:      stringPtr("us-east-1"),
+				})
+				unitNorm.AddMapping(database.ProviderAWS, "Hrs", database.UnitHour)
+			},
+			expectedSuccess: false,
+			expectedRecords: 0,
+			expectedErrors:  0,
+			expectedSkipped: 1,
+		},
 		{
 			name: "service not mapped - skip",
 			input: database.NormalizationInput{
@@ -513,6 +544,39 @@ func getValidEC2PricingJSON() json.RawMessage {
 	}`)
 }
 
+func getCapacityReservationEC2JSON() json.RawMessage {
+	return json.RawMessage(`{
+		"product": {
+			"sku": "CAPRESV1",
+			"attributes": {
+				"instanceType": "t3.medium",
+				"vcpu": "2",
+				"memory": "4 GiB",
+				"operatingSystem": "Linux",
+				"tenancy": "Shared",
+				"capacitystatus": "UnusedCapacityReservation"
+			}
+		},
+		"terms": {
+			"OnDemand": {
+				"CAPRESV1.JRTCKXETXF": {
+					"offerTermCode": "JRTCKXETXF",
+					"sku": "CAPRESV1",
+					"priceDimensions": {
+						"CAPRESV1.JRTCKXETXF.6YS6EN2CT7": {
+							"description": "Linux Unused Reservation t3.medium",
+							"unit": "Hrs",
+							"pricePerUnit": {
+								"USD": "0.0416"
+							}
+						}
+					}
+				}
+			}
+		}
+	}`)
+}
+
 func getZeroPriceEC2JSON() json.RawMessage {
 	return json.RawMessage(`{
 		"product": {
@@ -599,4 +663,637 @@ func getReservedInstanceJSON() json.RawMessage {
 			}
 		}
 	}`)
-}
\ No newline at end of file
+}
+func TestAWSResourceSpecExtractor_ExtractResourceSpecs(t *testing.T) {
+	extractor := NewAWSResourceSpecExtractor()
+	
+	// Attributes taken from AWS price list offer files
+	tests := []struct {
+		name        string
+		serviceType string
+		attributes  map[string]interface{}
+		expected    database.ResourceSpecs
+		expectError bool
+	}{
+		{
+			name:        "EC2 general purpose m5.large",
+			serviceType: "Virtual Machines",
+			attributes: map[string]interface{}{
+				"instanceType":          "m5.large",
+				"instanceFamily":        "General purpose",
+				"vcpu":                  "2",
+				"memory":                "8 GiB",
+				"storage":               "EBS only",
+				"networkPerformance":    "Up to 10 Gigabit",
+				"physicalProcessor":     "Intel Xeon Platinum 8175",
+				"clockSpeed":            "3.1 GHz",
+				"processorArchitecture": "64-bit",
+				"processorFeatures":     "Intel AVX; Intel AVX2; Intel AVX512; Intel Turbo",
+			},
+			expected: database.ResourceSpecs{
+				VCPU:               intPtr(2),
+				MemoryGB:           float64Ptr(8),
+				StorageType:        stringPtr("EBS only"),
+				NetworkPerformance: stringPtr("Up to 10 Gigabit"),
+				ProcessorType:      stringPtr("Intel Xeon Platinum 8175"),
+				ProcessorFeatures:  []string{"Intel AVX", "Intel AVX2", "Intel AVX512", "Intel Turbo"},
+				ClockSpeedGHz:      float64Ptr(3.1),
+				Architecture:       stringPtr("x86_64"),
+				Burstable:          boolPtr(false),
+			},
+		},
+		{
+			name:        "EC2 burstable t3.medium",
+			serviceType: "Virtual Machines",
+			attributes: map[string]interface{}{
+				"instanceType":          "t3.medium",
+				"vcpu":                  "2",
+				"memory":                "4 GiB",
+				"storage":               "EBS only",
+				"networkPerformance":    "Up to 5 Gigabit",
+				"physicalProcessor":     "Intel Skylake E5 2686 v5",
+				"clockSpeed":            "Up to 3.1 GHz",
+				"processorArchitecture": "64-bit",
+			},
+			expected: database.ResourceSpecs{
+				VCPU:               intPtr(2),
+				MemoryGB:           float64Ptr(4),
+				StorageType:        stringPtr("EBS only"),
+				NetworkPerformance: stringPtr("Up to 5 Gigabit"),
+				ProcessorType:      stringPtr("Intel Skylake E5 2686 v5"),
+				ClockSpeedGHz:      float64Ptr(3.1),
+				Architecture:       stringPtr("x86_64"),
+				Burstable:          boolPtr(true),
+			},
+		},
+		{
+			name:        "EC2 Graviton with local NVMe m6gd.xlarge",
+			serviceType: "Virtual Machines",
+			attributes: map[string]interface{}{
+				"instanceType":          "m6gd.xlarge",
+				"vcpu":                  "4",
+				"memory":                "16 GiB",
+				"storage":               "1 x 237 NVMe SSD",
+				"networkPerformance":    "Up to 10 Gigabit",
+				"physicalProcessor":     "AWS Graviton2 Processor",
+				"clockSpeed":            "2.5 GHz",
+				"processorArchitecture": "64-bit",
+			},
+			expected: database.ResourceSpecs{
+				VCPU:               intPtr(4),
+				MemoryGB:           float64Ptr(16),
+				StorageGB:          float64Ptr(237),
+				StorageType:        stringPtr("NVMe SSD"),
+				NetworkPerformance: stringPtr("Up to 10 Gigabit"),
+				ProcessorType:      stringPtr("AWS Graviton2 Processor"),
+				ClockSpeedGHz:      float64Ptr(2.5),
+				Architecture:       stringPtr("arm64"),
+				Burstable:          boolPtr(false),
+			},
+		},
+		{
+			name:        "EC2 GPU p3.16xlarge",
+			serviceType: "Virtual Machines",
+			attributes: map[string]interface{}{
+				"instanceType":          "p3.16xlarge",
+				"vcpu":                  "64",
+				"memory":                "488 GiB",
+				"gpu":                   "8",
+				"gpuMemory":             "128 GiB",
+				"storage":               "EBS only",
+				"networkPerformance":    "25 Gigabit",
+				"physicalProcessor":     "Intel Xeon E5-2686 v4 (Broadwell)",
+				"clockSpeed":            "2.3 GHz",
+				"processorArchitecture": "64-bit",
+			},
+			expected: database.ResourceSpecs{
+				VCPU:               intPtr(64),
+				MemoryGB:           float64Ptr(488),
+				GPUCount:           intPtr(8),
+				GPUMemoryGB:        float64Ptr(128),
+				StorageType:        stringPtr("EBS only"),
+				NetworkPerformance: stringPtr("25 Gigabit"),
+				ProcessorType:      stringPtr("Intel Xeon E5-2686 v4 (Broadwell)"),
+				ClockSpeedGHz:      float64Ptr(2.3),
+				Architecture:       stringPtr("x86_64"),
+				Burstable:          boolPtr(false),
+			},
+		},
+		{
+			name:        "EC2 storage optimized with multiple disks d2.8xlarge",
+			serviceType: "Virtual Machines",
+			attributes: map[string]interface{}{
+				"instanceType":          "d2.8xlarge",
+				"vcpu":                  "36",
+				"memory":                "244 GiB",
+				"storage":               "24 x 2000 HDD",
+				"networkPerformance":    "10 Gigabit",
+				"physicalProcessor":     "Intel Xeon E5-2676v3 (Haswell)",
+				"clockSpeed":            "2.4 GHz",
+				"processorArchitecture": "64-bit",
+				"gpu":                   "NA",
+			},
+			expected: database.ResourceSpecs{
+				VCPU:               intPtr(36),
+				MemoryGB:           float64Ptr(244),
+				StorageGB:          float64Ptr(48000),
+				StorageType:        stringPtr("HDD"),
+				NetworkPerformance: stringPtr("10 Gigabit"),
+				ProcessorType:      stringPtr("Intel Xeon E5-2676v3 (Haswell)"),
+				ClockSpeedGHz:      float64Ptr(2.4),
+				Architecture:       stringPtr("x86_64"),
+				Burstable:          boolPtr(false),
+			},
+		},
+		{
+			name:        "EC2 memory optimized with thousands separator x1e.32xlarge",
+			serviceType: "Virtual Machines",
+			attributes: map[string]interface{}{
+				"instanceType": "x1e.32xlarge",
+				"vcpu":         "128",
+				"memory":       "3,904 GiB",
+				"storage":      "2 x 1920 SSD",
+			},
+			expected: database.ResourceSpecs{
+				VCPU:         intPtr(128),
+				MemoryGB:     float64Ptr(3904),
+				StorageGB:    float64Ptr(3840),
+				StorageType:  stringPtr("SSD"),
+				Architecture: stringPtr("x86_64"),
+				Burstable:    boolPtr(false),
+			},
+		},
+		{
+			name:        "RDS db.r5.large",
+			serviceType: "Relational Database",
+			attributes: map[string]interface{}{
+				"instanceType":          "db.r5.large",
+				"instanceFamily":        "Memory optimized",
+				"databaseEngine":        "PostgreSQL",
+				"vcpu":                  "2",
+				"memory":                "16 GiB",
+				"storage":               "EBS Only",
+				"networkPerformance":    "Up to 10 Gigabit",
+				"physicalProcessor":     "Intel Xeon Platinum 8175",
+				"clockSpeed":            "3.1 GHz",
+				"processorArchitecture": "64-bit",
+			},
+			expected: database.ResourceSpecs{
+				VCPU:               intPtr(2),
+				MemoryGB:           float64Ptr(16),
+				StorageType:        stringPtr("EBS only"),
+				NetworkPerformance: stringPtr("Up to 10 Gigabit"),
+				ProcessorType:      stringPtr("Intel Xeon Platinum 8175"),
+				ClockSpeedGHz:      float64Ptr(3.1),
+				Architecture:       stringPtr("x86_64"),
+				Burstable:          boolPtr(false),
+			},
+		},
+		{
+			name:        "RDS burstable Graviton db.t4g.micro",
+			serviceType: "Relational Database",
+			attributes: map[string]interface{}{
+				"instanceType":          "db.t4g.micro",
+				"databaseEngine":        "MySQL",
+				"vcpu":                  "2",
+				"memory":                "1 GiB",
+				"storage":               "EBS Only",
+				"networkPerformance":    "Up to 5 Gigabit",
+				"physicalProcessor":     "AWS Graviton2",
+				"processorArchitecture": "64-bit",
+			},
+			expected: database.ResourceSpecs{
+				VCPU:               intPtr(2),
+				MemoryGB:           float64Ptr(1),
+				StorageType:        stringPtr("EBS only"),
+				NetworkPerformance: stringPtr("Up to 5 Gigabit"),
+				ProcessorType:      stringPtr("AWS Graviton2"),
+				Architecture:       stringPtr("arm64"),
+				Burstable:          boolPtr(true),
+			},
+		},
+		{
+			name:        "ElastiCache cache.r6g.large",
+			serviceType: "In-Memory Cache",
+			attributes: map[string]interface{}{
+				"instanceType":       "cache.r6g.large",
+				"instanceFamily":     "Memory optimized",
+				"cacheEngine":        "Redis",
+				"vcpu":               "2",
+				"memory":             "13.07 GiB",
+				"networkPerformance": "Up to 10 Gigabit",
+			},
+			expected: database.ResourceSpecs{
+				VCPU:               intPtr(2),
+				MemoryGB:           float64Ptr(13.07),
+				NetworkPerformance: stringPtr("Up to 10 Gigabit"),
+				Architecture:       stringPtr("arm64"),
+				Burstable:          boolPtr(false),
+			},
+		},
+		{
+			name:        "ElastiCache burstable cache.t3.micro",
+			serviceType: "In-Memory Cache",
+			attributes: map[string]interface{}{
+				"instanceType":       "cache.t3.micro",
+				"cacheEngine":        "Memcached",
+				"vcpu":               "2",
+				"memory":             "0.5 GiB",
+				"networkPerformance": "Up to 5 Gigabit",
+			},
+			expected: database.ResourceSpecs{
+				VCPU:               intPtr(2),
+				MemoryGB:           float64Ptr(0.5),
+				NetworkPerformance: stringPtr("Up to 5 Gigabit"),
+				Architecture:       stringPtr("x86_64"),
+				Burstable:          boolPtr(true),
+			},
+		},
+		{
+			name:        "data transfer product has no specs",
+			serviceType: "Data Transfer",
+			attributes: map[string]interface{}{
+				"transferType": "AWS Outbound",
+				"fromLocation": "US East (N. Virginia)",
+				"toLocation":   "External",
+			},
+			expected: database.ResourceSpecs{},
+		},
+		{
+			name:        "invalid vcpu",
+			serviceType: "Virtual Machines",
+			attributes: map[string]interface{}{
+				"instanceType": "m5.large",
+				"vcpu":         "two",
+			},
+			expectError: true,
+		},
+		{
+			name:        "invalid memory",
+			serviceType: "Virtual Machines",
+			attributes: map[string]interface{}{
+				"instanceType": "m5.large",
+				"memory":       "lots",
+			},
+			expectError: true,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			specs, err := extractor.ExtractResourceSpecs(database.ProviderAWS, tt.serviceType, tt.attributes)
+			
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, specs)
+		})
+	}
+}
+
+func TestParseAWSSizeGB(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    float64
+		expectError bool
+	}{
+		{input: "16 GiB", expected: 16},
+		{input: "0.613 GiB", expected: 0.613},
+		{input: "1,952 GiB", expected: 1952},
+		{input: "24 TiB", expected: 24576},
+		{input: "512 MiB", expected: 0.5},
+		{input: "8", expected: 8},
+		{input: "unknown", expectError: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := parseAWSSizeGB(tt.input)
+			
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expected, result, 0.0001)
+		})
+	}
+}
+
+func TestAWSNormalizerV2_ExtractPricingFromDimensions(t *testing.T) {
+	normalizer := createTestAWSNormalizerV2()
+	
+	tests := []struct {
+		name          string
+		dimensions    []AWSPriceDimension
+		expectError   bool
+		expectedPrice float64
+		expectedTiers []database.PriceTier
+	}{
+		{
+			name: "flat price covering all usage",
+			dimensions: []AWSPriceDimension{
+				{Unit: "Hrs", BeginRange: "0", EndRange: "Inf", PricePerUnit: map[string]string{"USD": "0.0416"}},
+			},
+			expectedPrice: 0.0416,
+		},
+		{
+			name: "flat price without ranges",
+			dimensions: []AWSPriceDimension{
+				{Unit: "Quantity", PricePerUnit: map[string]string{"USD": "243"}},
+			},
+			expectedPrice: 243,
+		},
+		{
+			name: "S3 storage tiers",
+			dimensions: []AWSPriceDimension{
+				{Unit: "GB-Mo", BeginRange: "0", EndRange: "51200", PricePerUnit: map[string]string{"USD": "0.023"}},
+				{Unit: "GB-Mo", BeginRange: "51200", EndRange: "512000", PricePerUnit: map[string]string{"USD": "0.022"}},
+				{Unit: "GB-Mo", BeginRange: "512000", EndRange: "Inf", PricePerUnit: map[string]string{"USD": "0.021"}},
+			},
+			expectedPrice: 0.023,
+			expectedTiers: []database.PriceTier{
+				{StartUnits: 0, EndUnits: float64Ptr(51200), PricePerUnit: 0.023},
+				{StartUnits: 51200, EndUnits: float64Ptr(512000), PricePerUnit: 0.022},
+				{StartUnits: 512000, PricePerUnit: 0.021},
+			},
+		},
+		{
+			name: "egress tiers with a free first tier",
+			dimensions: []AWSPriceDimension{
+				{Unit: "GB", BeginRange: "0", EndRange: "100", PricePerUnit: map[string]string{"USD": "0.0000000000"}},
+				{Unit: "GB", BeginRange: "100", EndRange: "10240", PricePerUnit: map[string]string{"USD": "0.09"}},
+				{Unit: "GB", BeginRange: "10240", EndRange: "Inf", PricePerUnit: map[string]string{"USD": "0.085"}},
+			},
+			expectedPrice: 0.09,
+			expectedTiers: []database.PriceTier{
+				{StartUnits: 0, EndUnits: float64Ptr(100), PricePerUnit: 0},
+				{StartUnits: 100, EndUnits: float64Ptr(10240), PricePerUnit: 0.09},
+				{StartUnits: 10240, PricePerUnit: 0.085},
+			},
+		},
+		{
+			name: "all tiers free",
+			dimensions: []AWSPriceDimension{
+				{Unit: "GB", BeginRange: "0", EndRange: "1", PricePerUnit: map[string]string{"USD": "0"}},
+				{Unit: "GB", BeginRange: "1", EndRange: "Inf", PricePerUnit: map[string]string{"USD": "0"}},
+			},
+			expectedPrice: 0,
+		},
+		{
+			name: "invalid range",
+			dimensions: []AWSPriceDimension{
+				{Unit: "GB", BeginRange: "0", EndRange: "lots", PricePerUnit: map[string]string{"USD": "0.09"}},
+				{Unit: "GB", BeginRange: "lots", EndRange: "Inf", PricePerUnit: map[string]string{"USD": "0.085"}},
+			},
+			expectError: true,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priceInfo, tiers, err := normalizer.extractPricingFromDimensions(tt.dimensions)
+			
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPrice, priceInfo.PricePerUnit)
+			assert.Equal(t, tt.expectedTiers, tiers)
+		})
+	}
+}
+
+func TestGroupAWSPriceDimensions(t *testing.T) {
+	priceDimensions := map[string]AWSPriceDimension{
+		"SKU.TERM.RATE3": {Unit: "GB-Mo", BeginRange: "512000", EndRange: "Inf"},
+		"SKU.TERM.RATE1": {Unit: "GB-Mo", BeginRange: "0", EndRange: "51200"},
+		"SKU.TERM.RATE2": {Unit: "GB-Mo", BeginRange: "51200", EndRange: "512000"},
+		"SKU.TERM.FEE":   {Unit: "Quantity"},
+	}
+	
+	groups := groupAWSPriceDimensions(priceDimensions)
+	
+	require.Len(t, groups, 2)
+	require.Len(t, groups[0], 3)
+	assert.Equal(t, "0", groups[0][0].BeginRange)
+	assert.Equal(t, "51200", groups[0][1].BeginRange)
+	assert.Equal(t, "512000", groups[0][2].BeginRange)
+	require.Len(t, groups[1], 1)
+	assert.Equal(t, "Quantity", groups[1][0].Unit)
+}
+
+func TestAWSNormalizerV2_AmortizeReservedTerm(t *testing.T) {
+	normalizer := createTestAWSNormalizerV2()
+	
+	tests := []struct {
+		name                  string
+		termAttributes        map[string]interface{}
+		pricingModel          string
+		dimensions            map[string]AWSPriceDimension
+		expectedHourlyRate    *float64
+		expectedUpfrontCost   *float64
+		expectedEffectiveRate *float64
+	}{
+		{
+			name: "partial upfront 1yr",
+			termAttributes: map[string]interface{}{
+				"LeaseContractLength": "1yr",
+				"PurchaseOption":      "Partial Upfront",
+			},
+			pricingModel: database.PricingModelReserved1Yr,
+			dimensions: map[string]AWSPriceDimension{
+				"SKU.TERM.2TG2D8R56U": {Unit: "Quantity", PricePerUnit: map[string]string{"USD": "175"}},
+				"SKU.TERM.6YS6EN2CT7": {Unit: "Hrs", PricePerUnit: map[string]string{"USD": "0.0200000000"}},
+			},
+			expectedHourlyRate:    float64Ptr(0.02),
+			expectedUpfrontCost:   float64Ptr(175),
+			expectedEffectiveRate: float64Ptr(0.02 + 175.0/8760),
+		},
+		{
+			name: "all upfront 3yr",
+			termAttributes: map[string]interface{}{
+				"LeaseContractLength": "3yr",
+				"PurchaseOption":      "All Upfront",
+			},
+			pricingModel: database.PricingModelReserved3Yr,
+			dimensions: map[string]AWSPriceDimension{
+				"SKU.TERM.2TG2D8R56U": {Unit: "Quantity", PricePerUnit: map[string]string{"USD": "1051"}},
+				"SKU.TERM.6YS6EN2CT7": {Unit: "Hrs", PricePerUnit: map[string]string{"USD": "0.0000000000"}},
+			},
+			expectedHourlyRate:    float64Ptr(0),
+			expectedUpfrontCost:   float64Ptr(1051),
+			expectedEffectiveRate: float64Ptr(1051.0 / 26280),
+		},
+		{
+			name: "no upfront",
+			termAttributes: map[string]interface{}{
+				"LeaseContractLength": "1yr",
+				"PurchaseOption":      "No Upfront",
+			},
+			pricingModel: database.PricingModelReserved1Yr,
+			dimensions: map[string]AWSPriceDimension{
+				"SKU.TERM.6YS6EN2CT7": {Unit: "Hrs", PricePerUnit: map[string]string{"USD": "0.0260000000"}},
+			},
+			expectedHourlyRate:    float64Ptr(0.026),
+			expectedUpfrontCost:   float64Ptr(0),
+			expectedEffectiveRate: float64Ptr(0.026),
+		},
+		{
+			name:           "term length from pricing model",
+			termAttributes: map[string]interface{}{},
+			pricingModel:   database.PricingModelReserved3Yr,
+			dimensions: map[string]AWSPriceDimension{
+				"SKU.TERM.2TG2D8R56U": {Unit: "Quantity", PricePerUnit: map[string]string{"USD": "2628"}},
+			},
+			expectedHourlyRate:    float64Ptr(0),
+			expectedUpfrontCost:   float64Ptr(2628),
+			expectedEffectiveRate: float64Ptr(0.1),
+		},
+		{
+			name: "no commitment dimensions",
+			termAttributes: map[string]interface{}{
+				"LeaseContractLength": "1yr",
+			},
+			pricingModel: database.PricingModelReserved1Yr,
+			dimensions: map[string]AWSPriceDimension{
+				"SKU.TERM.6YS6EN2CT7": {Unit: "GB-Mo", PricePerUnit: map[string]string{"USD": "0.1"}},
+			},
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := normalizer.extractPricingDetails(tt.termAttributes, tt.pricingModel)
+			normalizer.amortizeReservedTerm(&details, tt.dimensions, tt.pricingModel)
+			
+			if tt.expectedEffectiveRate == nil {
+				assert.Nil(t, details.EffectiveHourlyRate)
+				assert.Nil(t, details.EffectiveMonthlyRate)
+				return
+			}
+			
+			require.NotNil(t, details.HourlyRate)
+			require.NotNil(t, details.UpfrontCost)
+			require.NotNil(t, details.EffectiveHourlyRate)
+			require.NotNil(t, details.EffectiveMonthlyRate)
+			assert.InDelta(t, *tt.expectedHourlyRate, *details.HourlyRate, 1e-9)
+			assert.InDelta(t, *tt.expectedUpfrontCost, *details.UpfrontCost, 1e-9)
+			assert.InDelta(t, *tt.expectedEffectiveRate, *details.EffectiveHourlyRate, 1e-9)
+			assert.InDelta(t, *tt.expectedEffectiveRate*730, *details.EffectiveMonthlyRate, 1e-6)
+		})
+	}
+}
+
+func TestAWSNormalizerV2_ExtractPlatformDimensions(t *testing.T) {
+	normalizer := createTestAWSNormalizerV2()
+	
+	tests := []struct {
+		name       string
+		attributes map[string]interface{}
+		expected   PlatformDimensions
+	}{
+		{
+			name: "Linux on shared tenancy",
+			attributes: map[string]interface{}{
+				"operatingSystem": "Linux",
+				"licenseModel":    "No License required",
+				"preInstalledSw":  "NA",
+				"tenancy":         "Shared",
+			},
+			expected: PlatformDimensions{
+				OperatingSystem:      stringPtr(database.OSLinux),
+				LicenseModel:         stringPtr(database.LicenseModelNone),
+				PreInstalledSoftware: stringPtr(database.SoftwareNone),
+				Tenancy:              stringPtr(database.TenancyShared),
+			},
+		},
+		{
+			name: "Windows with SQL Server Standard on dedicated tenancy",
+			attributes: map[string]interface{}{
+				"operatingSystem": "Windows",
+				"licenseModel":    "No License required",
+				"preInstalledSw":  "SQL Std",
+				"tenancy":         "Dedicated",
+			},
+			expected: PlatformDimensions{
+				OperatingSystem:      stringPtr(database.OSWindows),
+				LicenseModel:         stringPtr(database.LicenseModelIncluded),
+				PreInstalledSoftware: stringPtr(database.SoftwareSQLStandard),
+				Tenancy:              stringPtr(database.TenancyDedicated),
+			},
+		},
+		{
+			name: "SQL Server on Linux",
+			attributes: map[string]interface{}{
+				"operatingSystem": "Linux",
+				"licenseModel":    "No License required",
+				"preInstalledSw":  "SQL Web",
+				"tenancy":         "Shared",
+			},
+			expected: PlatformDimensions{
+				OperatingSystem:      stringPtr(database.OSLinux),
+				LicenseModel:         stringPtr(database.LicenseModelIncluded),
+				PreInstalledSoftware: stringPtr(database.SoftwareSQLWeb),
+				Tenancy:              stringPtr(database.TenancyShared),
+			},
+		},
+		{
+			name: "Windows bring your own license",
+			attributes: map[string]interface{}{
+				"operatingSystem": "Windows",
+				"licenseModel":    "Bring your own license",
+				"preInstalledSw":  "NA",
+				"tenancy":         "Dedicated",
+			},
+			expected: PlatformDimensions{
+				OperatingSystem:      stringPtr(database.OSWindows),
+				LicenseModel:         stringPtr(database.LicenseModelBYOL),
+				PreInstalledSoftware: stringPtr(database.SoftwareNone),
+				Tenancy:              stringPtr(database.TenancyDedicated),
+			},
+		},
+		{
+			name: "RHEL with HA on a dedicated host",
+			attributes: map[string]interface{}{
+				"operatingSystem": "Red Hat Enterprise Linux with HA",
+				"preInstalledSw":  "NA",
+				"tenancy":         "Host",
+			},
+			expected: PlatformDimensions{
+				OperatingSystem:      stringPtr(database.OSRHELHA),
+				PreInstalledSoftware: stringPtr(database.SoftwareNone),
+				Tenancy:              stringPtr(database.TenancyHost),
+			},
+		},
+		{
+			name: "RDS bring your own license",
+			attributes: map[string]interface{}{
+				"databaseEngine": "Oracle",
+				"licenseModel":   "Bring your own license",
+			},
+			expected: PlatformDimensions{
+				LicenseModel: stringPtr(database.LicenseModelBYOL),
+			},
+		},
+		{
+			name: "storage has no platform",
+			attributes: map[string]interface{}{
+				"storageClass": "General Purpose",
+			},
+			expected: PlatformDimensions{},
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, normalizer.extractPlatformDimensions(tt.attributes))
+		})
+	}
+}
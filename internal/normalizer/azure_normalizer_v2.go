@@ -8,6 +8,7 @@ import (
 	"github.com/raulc0399/cpc/internal/database"
 	"strconv"
 	"regexp"
+	"sync"
 )
 
 // AzureNormalizerV2 handles normalization of Azure pricing data using base normalizer
@@ -24,6 +25,9 @@ func NewAzureNormalizerV2(
 	validator *InputValidator,
 	logger Logger,
 ) *AzureNormalizerV2 {
+	specExtractor := NewAzureResourceSpecExtractor()
+	specExtractor.logger = logger
+
 	return &AzureNormalizerV2{
 		BaseNormalizer: NewBaseNormalizer(
 			serviceMappingRepo,
@@ -32,10 +36,20 @@ func NewAzureNormalizerV2(
 			validator,
 			logger,
 		),
-		specExtractor: NewAzureResourceSpecExtractor(),
+		specExtractor: specExtractor,
 	}
 }
 
+// UnknownVMSizes returns the VM sizes seen during normalization that are missing from the size catalog
+func (n *AzureNormalizerV2) UnknownVMSizes() map[string]int {
+	return n.specExtractor.UnknownSizes()
+}
+
+// ResetUnknownVMSizes forgets the unknown VM sizes seen so far, e.g. when a new job starts
+func (n *AzureNormalizerV2) ResetUnknownVMSizes() {
+	n.specExtractor.ResetUnknownSizes()
+}
+
 // GetSupportedProvider returns the provider this normalizer supports
 func (n *AzureNormalizerV2) GetSupportedProvider() string {
 	return database.ProviderAzure
@@ -61,9 +75,9 @@ func (n *AzureNormalizerV2) ValidateInput(input database.NormalizationInput) err
 
 // NormalizePricing normalizes raw Azure pricing data
 func (n *AzureNormalizerV2) NormalizePricing(ctx context.Context, input database.NormalizationInput) (*database.NormalizationResult, error) {
-	n.logger.Info("Starting Azure pricing normalization",
+	logger := n.ContextLogger(ctx, input)
+	logger.Info("Starting Azure pricing normalization",
 		Field{"service", input.ServiceCode},
-		Field{"region", input.Region},
 	)
 
 	// Validate input
@@ -71,6 +85,10 @@ func (n *AzureNormalizerV2) NormalizePricing(ctx context.Context, input database
 		return n.CreateErrorResult("validation failed", err), nil
 	}
 
+	if n.IsExcluded(input) {
+		return n.CreateSkippedResult("excluded by mapping configuration", 1), nil
+	}
+
 	// Parse Azure pricing JSON
 	var azurePricing AzurePricing
 	if err := n.ParseJSONData(input.RawData, &azurePricing); err != nil {
@@ -94,9 +112,8 @@ func (n *AzureNormalizerV2) NormalizePricing(ctx context.Context, input database
 	// Process Azure pricing item
 	result := n.processPricingItem(ctx, &azurePricing, normCtx)
 
-	n.logger.Info("Completed Azure pricing normalization",
+	logger.Info("Completed Azure pricing normalization",
 		Field{"service", input.ServiceCode},
-		Field{"region", input.Region},
 		Field{"recordCount", len(result.NormalizedRecords)},
 		Field{"errorCount", result.ErrorCount},
 		Field{"skippedCount", result.SkippedCount},
@@ -108,12 +125,13 @@ func (n *AzureNormalizerV2) NormalizePricing(ctx context.Context, input database
 // AzurePricing represents the structure of Azure pricing data
 type AzurePricing struct {
 	CurrencyCode     string  `json:"currencyCode"`
-	TierMinimumUnits int     `json:"tierMinimumUnits"`
+	TierMinimumUnits float64 `json:"tierMinimumUnits"`
 	RetailPrice      float64 `json:"retailPrice"`
 	UnitPrice        float64 `json:"unitPrice"`
 	ArmRegionName    string  `json:"armRegionName"`
 	Location         string  `json:"location"`
-	EffectiveDate    string  `json:"effectiveDate"`
+	EffectiveDate    string  `json:"effectiveStartDate"`
+	EffectiveEndDate string  `json:"effectiveEndDate,omitempty"`
 	MeterID          string  `json:"meterId"`
 	MeterName        string  `json:"meterName"`
 	ProductID        string  `json:"productId"`
@@ -125,6 +143,7 @@ type AzurePricing struct {
 	ServiceFamily    string  `json:"serviceFamily"`
 	UnitOfMeasure    string  `json:"unitOfMeasure"`
 	Type             string  `json:"type"`
+	ReservationTerm  string  `json:"reservationTerm,omitempty"` // "1 Year", "3 Years" for reservations
 	IsPrimaryRegion  bool    `json:"isPrimaryMeterRegion"`
 	ArmSKUName       string  `json:"armSkuName"`
 }
@@ -209,6 +228,9 @@ func (n *AzureNormalizerV2) processPricingItem(
 	if record != nil {
 		// Add Azure-specific fields
 		record.ProviderSKU = &azurePricing.SKUID
+		n.SetPriceTiers(record, n.extractPriceTiers(azurePricing, priceInfo.PricePerUnit))
+		n.SetValidity(record, azurePricing.EffectiveDate, azurePricing.EffectiveEndDate)
+		n.extractPlatformDimensions(azurePricing, normCtx.ServiceMapping.NormalizedServiceType).Apply(record)
 		normalizedRecords = append(normalizedRecords, *record)
 	}
 
@@ -237,6 +259,61 @@ func (n *AzureNormalizerV2) extractPricingFromAzureItem(pricing *AzurePricing) *
 	}
 }
 
+// extractPlatformDimensions derives the operating system, license model, pre-installed software
+// and tenancy of VM meters from the product name. Windows meters carry a "Windows" suffix and
+// include the license; meters without an OS suffix are Linux. SQL Server license meters are
+// priced separately under "Virtual Machines Licenses".
+func (n *AzureNormalizerV2) extractPlatformDimensions(pricing *AzurePricing, serviceType string) PlatformDimensions {
+	var platform PlatformDimensions
+	productName := strings.ToLower(pricing.ProductName)
+
+	if strings.Contains(productName, "sql server") {
+		for _, edition := range []string{"enterprise", "standard", "web"} {
+			if strings.Contains(productName, edition) {
+				platform.PreInstalledSoftware = optionalDimension(normalizePreInstalledSoftware("sql " + edition))
+				platform.LicenseModel = optionalDimension(database.LicenseModelIncluded)
+				break
+			}
+		}
+		return platform
+	}
+
+	if serviceType != "Virtual Machines" {
+		return platform
+	}
+
+	if strings.Contains(productName, "dedicated host") {
+		platform.Tenancy = optionalDimension(database.TenancyHost)
+		return platform
+	}
+
+	platform.Tenancy = optionalDimension(database.TenancyShared)
+	platform.PreInstalledSoftware = optionalDimension(database.SoftwareNone)
+	if strings.Contains(productName, "windows") {
+		platform.OperatingSystem = optionalDimension(database.OSWindows)
+		platform.LicenseModel = optionalDimension(database.LicenseModelIncluded)
+	} else {
+		platform.OperatingSystem = optionalDimension(database.OSLinux)
+		platform.LicenseModel = optionalDimension(database.LicenseModelNone)
+	}
+
+	return platform
+}
+
+// extractPriceTiers returns the tier of a tiered meter item. Azure returns each tier as a separate
+// item; the ETL merges the tiers of a meter once all items are normalized. The first tier starts
+// at zero and cannot be told apart from a flat price, so it carries no tier.
+func (n *AzureNormalizerV2) extractPriceTiers(pricing *AzurePricing, pricePerUnit float64) []database.PriceTier {
+	if pricing.TierMinimumUnits <= 0 {
+		return nil
+	}
+
+	return []database.PriceTier{{
+		StartUnits:   pricing.TierMinimumUnits,
+		PricePerUnit: pricePerUnit,
+	}}
+}
+
 // createAttributesFromAzureItem creates attributes map from Azure pricing item
 func (n *AzureNormalizerV2) createAttributesFromAzureItem(pricing *AzurePricing) map[string]interface{} {
 	return map[string]interface{}{
@@ -298,6 +375,13 @@ func (n *AzureNormalizerV2) determinePricingModel(pricing *AzurePricing) string
 	// Azure primarily uses consumption-based pricing
 	// Reserved instances are typically identified by specific product names or types
 	
+	if strings.EqualFold(pricing.Type, "Reservation") {
+		if azureReservationTermLength(pricing.ReservationTerm) == "3yr" {
+			return database.PricingModelReserved3Yr
+		}
+		return database.PricingModelReserved1Yr
+	}
+	
 	productName := strings.ToLower(pricing.ProductName)
 	skuName := strings.ToLower(pricing.SKUName)
 	
@@ -308,7 +392,9 @@ func (n *AzureNormalizerV2) determinePricingModel(pricing *AzurePricing) string
 		return database.PricingModelReserved1Yr
 	}
 	
-	if strings.Contains(productName, "spot") || strings.Contains(skuName, "spot") {
+	// Low Priority meters are the preemptible predecessor of Spot VMs
+	if strings.Contains(productName, "spot") || strings.Contains(skuName, "spot") ||
+		strings.Contains(skuName, "low priority") {
 		return database.PricingModelSpot
 	}
 	
@@ -320,7 +406,9 @@ func (n *AzureNormalizerV2) extractPricingDetails(pricing *AzurePricing, pricing
 	details := database.PricingDetails{}
 
 	// Extract term length for reserved instances
-	if strings.Contains(pricingModel, "reserved") {
+	if termLength := azureReservationTermLength(pricing.ReservationTerm); termLength != "" {
+		details.TermLength = &termLength
+	} else if strings.Contains(pricingModel, "reserved") {
 		productName := strings.ToLower(pricing.ProductName)
 		if strings.Contains(productName, "3 year") {
 			termLength := "3yr"
@@ -331,30 +419,67 @@ func (n *AzureNormalizerV2) extractPricingDetails(pricing *AzurePricing, pricing
 		}
 	}
 
-	// Azure typically uses upfront payment for reserved instances
+	// Azure typically uses upfront payment for reserved instances, and the reservation
+	// price covers the whole term
 	if strings.Contains(pricingModel, "reserved") {
 		paymentOption := "All Upfront"
 		details.PaymentOption = &paymentOption
+
+		if termHours, ok := commitmentTermHours(details, pricingModel); ok {
+			details.Amortize(n.extractPricingFromAzureItem(pricing).PricePerUnit, 0, termHours)
+		}
 	}
 
 	return details
 }
 
+// azureReservationTermLength converts an Azure reservation term (e.g., "3 Years") to a term
+// length such as "3yr", or returns an empty string when the term is not recognized
+func azureReservationTermLength(reservationTerm string) string {
+	fields := strings.Fields(strings.ToLower(reservationTerm))
+	if len(fields) != 2 || !strings.HasPrefix(fields[1], "year") {
+		return ""
+	}
+	if years, err := strconv.Atoi(fields[0]); err != nil || years <= 0 {
+		return ""
+	}
+	return fields[0] + "yr"
+}
+
 // AzureResourceSpecExtractor extracts resource specifications from Azure pricing data
-type AzureResourceSpecExtractor struct{}
+type AzureResourceSpecExtractor struct {
+	catalog *AzureVMSizeCatalog
+	logger  Logger
+
+	mu           sync.Mutex
+	unknownSizes map[string]int
+}
 
-// NewAzureResourceSpecExtractor creates a new Azure resource spec extractor
+// NewAzureResourceSpecExtractor creates a new Azure resource spec extractor using the bundled VM size catalog
 func NewAzureResourceSpecExtractor() *AzureResourceSpecExtractor {
-	return &AzureResourceSpecExtractor{}
+	return NewAzureResourceSpecExtractorWithCatalog(DefaultAzureVMSizeCatalog())
+}
+
+// NewAzureResourceSpecExtractorWithCatalog creates a new Azure resource spec extractor using the given VM size catalog
+func NewAzureResourceSpecExtractorWithCatalog(catalog *AzureVMSizeCatalog) *AzureResourceSpecExtractor {
+	return &AzureResourceSpecExtractor{
+		catalog:      catalog,
+		unknownSizes: make(map[string]int),
+	}
 }
 
 // ExtractResourceSpecs extracts and normalizes resource specifications from Azure attributes
 func (e *AzureResourceSpecExtractor) ExtractResourceSpecs(provider, serviceType string, data map[string]interface{}) (database.ResourceSpecs, error) {
 	specs := database.ResourceSpecs{}
 
-	// Extract from ARM SKU name patterns (e.g., Standard_D2s_v3)
-	if armSKU, ok := data["armSkuName"].(string); ok && armSKU != "" {
-		e.extractFromArmSKU(&specs, armSKU)
+	// Look up VM sizes (e.g., Standard_D2s_v3) in the catalog first
+	if armSKU, ok := data["armSkuName"].(string); ok && strings.HasPrefix(armSKU, "Standard_") {
+		if size, found := e.catalog.Lookup(armSKU); found {
+			return size.ResourceSpecs(), nil
+		}
+		if serviceType == "Virtual Machines" {
+			e.reportUnknownSize(armSKU)
+		}
 	}
 
 	// Extract from SKU name patterns (e.g., D2s v3)
@@ -370,110 +495,39 @@ func (e *AzureResourceSpecExtractor) ExtractResourceSpecs(provider, serviceType
 	return specs, nil
 }
 
-// extractFromArmSKU extracts specs from ARM SKU name (e.g., Standard_D2s_v3)
-func (e *AzureResourceSpecExtractor) extractFromArmSKU(specs *database.ResourceSpecs, armSKU string) {
-	// Pattern: Standard_[Family][Size][Generation]_[Version]
-	// Example: Standard_D2s_v3 = D family, 2 vCPU, s=SSD, v3 generation
-	
-	// D-series VMs
-	if matched, _ := regexp.MatchString(`Standard_D\d+s?_v\d+`, armSKU); matched {
-		e.extractDSeriesSpecs(specs, armSKU)
-	}
-	
-	// F-series VMs (compute optimized)
-	if matched, _ := regexp.MatchString(`Standard_F\d+s?_v\d+`, armSKU); matched {
-		e.extractFSeriesSpecs(specs, armSKU)
-	}
-	
-	// B-series VMs (burstable)
-	if matched, _ := regexp.MatchString(`Standard_B\d+m?s`, armSKU); matched {
-		e.extractBSeriesSpecs(specs, armSKU)
-	}
-	
-	// E-series VMs (memory optimized)
-	if matched, _ := regexp.MatchString(`Standard_E\d+s?_v\d+`, armSKU); matched {
-		e.extractESeriesSpecs(specs, armSKU)
-	}
-}
+// reportUnknownSize records a VM size missing from the catalog, warning the first time it is seen
+func (e *AzureResourceSpecExtractor) reportUnknownSize(armSKU string) {
+	e.mu.Lock()
+	e.unknownSizes[armSKU]++
+	firstSeen := e.unknownSizes[armSKU] == 1
+	e.mu.Unlock()
 
-// extractDSeriesSpecs extracts D-series VM specifications
-func (e *AzureResourceSpecExtractor) extractDSeriesSpecs(specs *database.ResourceSpecs, armSKU string) {
-	// Extract vCPU count from number in SKU
-	re := regexp.MustCompile(`Standard_D(\d+)s?_v\d+`)
-	matches := re.FindStringSubmatch(armSKU)
-	if len(matches) > 1 {
-		if vcpu, err := strconv.Atoi(matches[1]); err == nil {
-			specs.VCPU = &vcpu
-			// D-series memory ratio is typically 4GB per vCPU
-			memoryGB := float64(vcpu * 4)
-			specs.MemoryGB = &memoryGB
-		}
-	}
-	
-	// Check for SSD storage (s suffix)
-	if strings.Contains(armSKU, "s_") {
-		storageType := "Premium SSD"
-		specs.StorageType = &storageType
+	if firstSeen && e.logger != nil {
+		e.logger.Warn("Azure VM size not found in catalog, falling back to name heuristics",
+			Field{"armSkuName", armSKU},
+			Field{"catalogVersion", e.catalog.Version()},
+		)
 	}
 }
 
-// extractFSeriesSpecs extracts F-series VM specifications (compute optimized)
-func (e *AzureResourceSpecExtractor) extractFSeriesSpecs(specs *database.ResourceSpecs, armSKU string) {
-	// Extract vCPU count
-	re := regexp.MustCompile(`Standard_F(\d+)s?_v\d+`)
-	matches := re.FindStringSubmatch(armSKU)
-	if len(matches) > 1 {
-		if vcpu, err := strconv.Atoi(matches[1]); err == nil {
-			specs.VCPU = &vcpu
-			// F-series memory ratio is typically 2GB per vCPU
-			memoryGB := float64(vcpu * 2)
-			specs.MemoryGB = &memoryGB
-		}
-	}
-	
-	if strings.Contains(armSKU, "s_") {
-		storageType := "Premium SSD"
-		specs.StorageType = &storageType
-	}
-}
+// UnknownSizes returns the VM sizes missing from the catalog with the number of records seen for each
+func (e *AzureResourceSpecExtractor) UnknownSizes() map[string]int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
-// extractBSeriesSpecs extracts B-series VM specifications (burstable)
-func (e *AzureResourceSpecExtractor) extractBSeriesSpecs(specs *database.ResourceSpecs, armSKU string) {
-	// B-series has specific mappings
-	bSeriesSpecs := map[string]struct{ vcpu int; memory float64 }{
-		"Standard_B1ls": {1, 0.5},
-		"Standard_B1s":  {1, 1.0},
-		"Standard_B1ms": {1, 2.0},
-		"Standard_B2s":  {2, 4.0},
-		"Standard_B2ms": {2, 8.0},
-		"Standard_B4ms": {4, 16.0},
-		"Standard_B8ms": {8, 32.0},
-	}
-	
-	if spec, exists := bSeriesSpecs[armSKU]; exists {
-		specs.VCPU = &spec.vcpu
-		specs.MemoryGB = &spec.memory
+	unknown := make(map[string]int, len(e.unknownSizes))
+	for size, count := range e.unknownSizes {
+		unknown[size] = count
 	}
+	return unknown
 }
 
-// extractESeriesSpecs extracts E-series VM specifications (memory optimized)
-func (e *AzureResourceSpecExtractor) extractESeriesSpecs(specs *database.ResourceSpecs, armSKU string) {
-	// Extract vCPU count
-	re := regexp.MustCompile(`Standard_E(\d+)s?_v\d+`)
-	matches := re.FindStringSubmatch(armSKU)
-	if len(matches) > 1 {
-		if vcpu, err := strconv.Atoi(matches[1]); err == nil {
-			specs.VCPU = &vcpu
-			// E-series memory ratio is typically 8GB per vCPU
-			memoryGB := float64(vcpu * 8)
-			specs.MemoryGB = &memoryGB
-		}
-	}
-	
-	if strings.Contains(armSKU, "s_") {
-		storageType := "Premium SSD"
-		specs.StorageType = &storageType
-	}
+// ResetUnknownSizes clears the recorded unknown VM sizes, so they are warned about again when next seen
+func (e *AzureResourceSpecExtractor) ResetUnknownSizes() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.unknownSizes = make(map[string]int)
 }
 
 // extractFromSKUName extracts specs from friendly SKU name (e.g., "D2s v3")
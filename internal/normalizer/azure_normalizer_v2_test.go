@@ -467,6 +467,34 @@ func TestAzureNormalizerV2_DeterminePricingModel(t *testing.T) {
 			},
 			expected: database.PricingModelSpot,
 		},
+		{
+			name: "low priority VM",
+			pricing: AzurePricing{
+				ProductName: "Virtual Machines DCasv5 Series",
+				SKUName:     "DC8as v5 Low Priority",
+			},
+			expected: database.PricingModelSpot,
+		},
+		{
+			name: "1-year reservation item",
+			pricing: AzurePricing{
+				ProductName:     "Virtual Machines Dv5 Series",
+				SKUName:         "D2s v5",
+				Type:            "Reservation",
+				ReservationTerm: "1 Year",
+			},
+			expected: database.PricingModelReserved1Yr,
+		},
+		{
+			name: "3-year reservation item",
+			pricing: AzurePricing{
+				ProductName:     "Virtual Machines Dv5 Series",
+				SKUName:         "D2s v5",
+				Type:            "Reservation",
+				ReservationTerm: "3 Years",
+			},
+			expected: database.PricingModelReserved3Yr,
+		},
 		{
 			name: "reserved in SKU name",
 			pricing: AzurePricing{
@@ -642,4 +670,291 @@ func getReservedVMJSON() json.RawMessage {
 		"isPrimaryMeterRegion": true,
 		"armSkuName": "Standard_D2s_v3"
 	}`)
-}
\ No newline at end of file
+}
+func TestAzureResourceSpecExtractor_ExtractResourceSpecs(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceType string
+		data        map[string]interface{}
+		expected    database.ResourceSpecs
+		unknown     map[string]int
+	}{
+		{
+			name:        "catalog size",
+			serviceType: "Virtual Machines",
+			data: map[string]interface{}{
+				"armSkuName": "Standard_NC24ads_A100_v4",
+				"skuName":    "NC24ads A100 v4",
+				"meterName":  "NC24ads A100 v4",
+			},
+			expected: database.ResourceSpecs{
+				VCPU:                  intPtr(24),
+				MemoryGB:              float64Ptr(220),
+				GPUCount:              intPtr(1),
+				GPUModel:              stringPtr("NVIDIA A100 PCIe"),
+				GPUMemoryGB:           float64Ptr(80),
+				StorageGB:             float64Ptr(958),
+				StorageType:           stringPtr("NVMe SSD"),
+				Architecture:          stringPtr("x86_64"),
+				AcceleratedNetworking: boolPtr(true),
+				Burstable:             boolPtr(false),
+			},
+			unknown: map[string]int{},
+		},
+		{
+			name:        "Arm catalog size",
+			serviceType: "Virtual Machines",
+			data: map[string]interface{}{
+				"armSkuName": "Standard_B2ps_v2",
+				"skuName":    "B2ps v2",
+			},
+			expected: database.ResourceSpecs{
+				VCPU:                  intPtr(2),
+				MemoryGB:              float64Ptr(8),
+				Architecture:          stringPtr("arm64"),
+				AcceleratedNetworking: boolPtr(true),
+				Burstable:             boolPtr(true),
+			},
+			unknown: map[string]int{},
+		},
+		{
+			name:        "unknown size is reported and falls back to the meter name",
+			serviceType: "Virtual Machines",
+			data: map[string]interface{}{
+				"armSkuName": "Standard_NP10s",
+				"skuName":    "NP10s",
+				"meterName":  "NP10s 10 vCPU",
+			},
+			expected: database.ResourceSpecs{
+				VCPU: intPtr(10),
+			},
+			unknown: map[string]int{"Standard_NP10s": 1},
+		},
+		{
+			name:        "non-VM services are not reported",
+			serviceType: "Storage",
+			data: map[string]interface{}{
+				"armSkuName": "Standard_LRS",
+				"meterName":  "LRS Data Stored",
+			},
+			expected: database.ResourceSpecs{},
+			unknown:  map[string]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := NewMockLogger()
+			extractor := NewAzureResourceSpecExtractor()
+			extractor.logger = logger
+
+			specs, err := extractor.ExtractResourceSpecs(database.ProviderAzure, tt.serviceType, tt.data)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, specs)
+			assert.Equal(t, tt.unknown, extractor.UnknownSizes())
+			assert.Equal(t, len(tt.unknown) > 0,
+				logger.HasMessage("WARN", "Azure VM size not found in catalog, falling back to name heuristics"))
+		})
+	}
+}
+
+func TestAzureResourceSpecExtractor_ReportsUnknownSizeOnce(t *testing.T) {
+	logger := NewMockLogger()
+	extractor := NewAzureResourceSpecExtractor()
+	extractor.logger = logger
+
+	data := map[string]interface{}{"armSkuName": "Standard_X42_v9"}
+	for i := 0; i < 3; i++ {
+		_, err := extractor.ExtractResourceSpecs(database.ProviderAzure, "Virtual Machines", data)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, map[string]int{"Standard_X42_v9": 3}, extractor.UnknownSizes())
+	assert.Len(t, logger.Messages, 1)
+}
+
+func TestAzureResourceSpecExtractor_ResetUnknownSizes(t *testing.T) {
+	logger := NewMockLogger()
+	extractor := NewAzureResourceSpecExtractor()
+	extractor.logger = logger
+
+	data := map[string]interface{}{"armSkuName": "Standard_X42_v9"}
+	_, err := extractor.ExtractResourceSpecs(database.ProviderAzure, "Virtual Machines", data)
+	require.NoError(t, err)
+
+	extractor.ResetUnknownSizes()
+	assert.Empty(t, extractor.UnknownSizes())
+
+	// A size seen again after the reset is reported again
+	_, err = extractor.ExtractResourceSpecs(database.ProviderAzure, "Virtual Machines", data)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{"Standard_X42_v9": 1}, extractor.UnknownSizes())
+	assert.Len(t, logger.Messages, 2)
+}
+
+func TestAzureNormalizerV2_ExtractPriceTiers(t *testing.T) {
+	normalizer := createTestAzureNormalizerV2()
+	
+	tests := []struct {
+		name     string
+		pricing  *AzurePricing
+		expected []database.PriceTier
+	}{
+		{
+			name:    "first tier has no tier data",
+			pricing: &AzurePricing{TierMinimumUnits: 0, RetailPrice: 0.0184},
+		},
+		{
+			name:    "higher tier starts at the minimum units",
+			pricing: &AzurePricing{TierMinimumUnits: 51200, RetailPrice: 0.0177},
+			expected: []database.PriceTier{
+				{StartUnits: 51200, PricePerUnit: 0.0177},
+			},
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tiers := normalizer.extractPriceTiers(tt.pricing, tt.pricing.RetailPrice)
+			assert.Equal(t, tt.expected, tiers)
+		})
+	}
+}
+
+func TestAzureNormalizerV2_AmortizesReservations(t *testing.T) {
+	normalizer := createTestAzureNormalizerV2()
+	
+	tests := []struct {
+		name                  string
+		pricing               AzurePricing
+		pricingModel          string
+		expectedTermLength    *string
+		expectedEffectiveRate *float64
+	}{
+		{
+			name: "1-year reservation",
+			pricing: AzurePricing{
+				RetailPrice:     613.2,
+				Type:            "Reservation",
+				ReservationTerm: "1 Year",
+			},
+			pricingModel:          database.PricingModelReserved1Yr,
+			expectedTermLength:    stringPtr("1yr"),
+			expectedEffectiveRate: float64Ptr(0.07),
+		},
+		{
+			name: "3-year reservation",
+			pricing: AzurePricing{
+				RetailPrice:     1314,
+				Type:            "Reservation",
+				ReservationTerm: "3 Years",
+			},
+			pricingModel:          database.PricingModelReserved3Yr,
+			expectedTermLength:    stringPtr("3yr"),
+			expectedEffectiveRate: float64Ptr(0.05),
+		},
+		{
+			name: "reserved product without explicit term",
+			pricing: AzurePricing{
+				ProductName: "Virtual Machines Reserved VM Instance",
+				UnitPrice:   876,
+			},
+			pricingModel:          database.PricingModelReserved1Yr,
+			expectedEffectiveRate: float64Ptr(0.1),
+		},
+		{
+			name: "consumption",
+			pricing: AzurePricing{
+				RetailPrice: 0.096,
+				Type:        "Consumption",
+			},
+			pricingModel: database.PricingModelOnDemand,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := normalizer.extractPricingDetails(&tt.pricing, tt.pricingModel)
+			
+			assert.Equal(t, tt.expectedTermLength, details.TermLength)
+			if tt.expectedEffectiveRate == nil {
+				assert.Nil(t, details.EffectiveHourlyRate)
+				assert.Nil(t, details.EffectiveMonthlyRate)
+				return
+			}
+			
+			require.NotNil(t, details.UpfrontCost)
+			require.NotNil(t, details.HourlyRate)
+			require.NotNil(t, details.EffectiveHourlyRate)
+			require.NotNil(t, details.EffectiveMonthlyRate)
+			assert.Equal(t, 0.0, *details.HourlyRate)
+			assert.InDelta(t, *tt.expectedEffectiveRate, *details.EffectiveHourlyRate, 1e-9)
+			assert.InDelta(t, *tt.expectedEffectiveRate*730, *details.EffectiveMonthlyRate, 1e-6)
+		})
+	}
+}
+
+func TestAzureNormalizerV2_ExtractPlatformDimensions(t *testing.T) {
+	normalizer := createTestAzureNormalizerV2()
+	
+	tests := []struct {
+		name        string
+		pricing     AzurePricing
+		serviceType string
+		expected    PlatformDimensions
+	}{
+		{
+			name:        "Linux VM",
+			pricing:     AzurePricing{ProductName: "Virtual Machines Dv5 Series"},
+			serviceType: "Virtual Machines",
+			expected: PlatformDimensions{
+				OperatingSystem:      stringPtr(database.OSLinux),
+				LicenseModel:         stringPtr(database.LicenseModelNone),
+				PreInstalledSoftware: stringPtr(database.SoftwareNone),
+				Tenancy:              stringPtr(database.TenancyShared),
+			},
+		},
+		{
+			name:        "Windows VM",
+			pricing:     AzurePricing{ProductName: "Virtual Machines Edsv4 Series Windows"},
+			serviceType: "Virtual Machines",
+			expected: PlatformDimensions{
+				OperatingSystem:      stringPtr(database.OSWindows),
+				LicenseModel:         stringPtr(database.LicenseModelIncluded),
+				PreInstalledSoftware: stringPtr(database.SoftwareNone),
+				Tenancy:              stringPtr(database.TenancyShared),
+			},
+		},
+		{
+			name:        "dedicated host",
+			pricing:     AzurePricing{ProductName: "Virtual Machines DSv3 Series Dedicated Host"},
+			serviceType: "Virtual Machines",
+			expected: PlatformDimensions{
+				Tenancy: stringPtr(database.TenancyHost),
+			},
+		},
+		{
+			name:        "SQL Server license",
+			pricing:     AzurePricing{ProductName: "SQL Server Enterprise"},
+			serviceType: "Virtual Machines Licenses",
+			expected: PlatformDimensions{
+				LicenseModel:         stringPtr(database.LicenseModelIncluded),
+				PreInstalledSoftware: stringPtr(database.SoftwareSQLEnterprise),
+			},
+		},
+		{
+			name:        "storage has no platform",
+			pricing:     AzurePricing{ProductName: "Standard SSD Managed Disks"},
+			serviceType: "Managed Disks",
+			expected:    PlatformDimensions{},
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, normalizer.extractPlatformDimensions(&tt.pricing, tt.serviceType))
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package normalizer
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/raulc0399/cpc/internal/database"
+)
+
+// azureVMSizesJSON is the bundled Azure VM size catalog. Bump its version when sizes are
+// added or corrected so normalized records can be traced back to the catalog that produced them.
+//
+//go:embed data/azure_vm_sizes.json
+var azureVMSizesJSON []byte
+
+// azureConstrainedSizePattern matches constrained vCPU sizes (e.g., Standard_E96-24ds_v5),
+// which keep the memory and disks of the parent size with fewer active vCPUs
+var azureConstrainedSizePattern = regexp.MustCompile(`^(Standard_[A-Za-z]+)(\d+)-(\d+)(.*)$`)
+
+// AzureVMSize describes the hardware of an Azure VM size
+type AzureVMSize struct {
+	Name                  string  `json:"name"` // armSkuName, e.g. Standard_D2s_v5
+	VCPU                  int     `json:"vcpu"`
+	MemoryGB              float64 `json:"memoryGB"`
+	GPUCount              int     `json:"gpuCount,omitempty"`
+	GPUModel              string  `json:"gpuModel,omitempty"`
+	GPUMemoryGB           float64 `json:"gpuMemoryGB,omitempty"` // total across all GPUs
+	LocalDiskGB           float64 `json:"localDiskGB,omitempty"`
+	LocalDiskType         string  `json:"localDiskType,omitempty"`
+	Architecture          string  `json:"architecture"`
+	AcceleratedNetworking bool    `json:"acceleratedNetworking"`
+	Burstable             bool    `json:"burstable,omitempty"`
+}
+
+// ResourceSpecs converts the VM size to normalized resource specifications
+func (s AzureVMSize) ResourceSpecs() database.ResourceSpecs {
+	vcpu := s.VCPU
+	memoryGB := s.MemoryGB
+	architecture := s.Architecture
+	acceleratedNetworking := s.AcceleratedNetworking
+	burstable := s.Burstable
+
+	specs := database.ResourceSpecs{
+		VCPU:                  &vcpu,
+		MemoryGB:              &memoryGB,
+		Architecture:          &architecture,
+		AcceleratedNetworking: &acceleratedNetworking,
+		Burstable:             &burstable,
+	}
+
+	if s.GPUCount > 0 {
+		gpuCount := s.GPUCount
+		specs.GPUCount = &gpuCount
+		if s.GPUModel != "" {
+			gpuModel := s.GPUModel
+			specs.GPUModel = &gpuModel
+		}
+		if s.GPUMemoryGB > 0 {
+			gpuMemoryGB := s.GPUMemoryGB
+			specs.GPUMemoryGB = &gpuMemoryGB
+		}
+	}
+
+	if s.LocalDiskGB > 0 {
+		storageGB := s.LocalDiskGB
+		storageType := s.LocalDiskType
+		specs.StorageGB = &storageGB
+		specs.StorageType = &storageType
+	}
+
+	return specs
+}
+
+// AzureVMSizeCatalog is a versioned catalog of Azure VM sizes keyed by armSkuName
+type AzureVMSizeCatalog struct {
+	version string
+	sizes   map[string]AzureVMSize
+}
+
+// azureVMSizeCatalogFile is the on-disk format of the catalog
+type azureVMSizeCatalogFile struct {
+	Version string        `json:"version"`
+	Sizes   []AzureVMSize `json:"sizes"`
+}
+
+var (
+	defaultAzureVMSizeCatalog     *AzureVMSizeCatalog
+	defaultAzureVMSizeCatalogOnce sync.Once
+)
+
+// DefaultAzureVMSizeCatalog returns the catalog bundled with the binary
+func DefaultAzureVMSizeCatalog() *AzureVMSizeCatalog {
+	defaultAzureVMSizeCatalogOnce.Do(func() {
+		catalog, err := ParseAzureVMSizeCatalog(azureVMSizesJSON)
+		if err != nil {
+			panic(fmt.Sprintf("invalid bundled Azure VM size catalog: %v", err))
+		}
+		defaultAzureVMSizeCatalog = catalog
+	})
+	return defaultAzureVMSizeCatalog
+}
+
+// ParseAzureVMSizeCatalog parses and validates a JSON VM size catalog
+func ParseAzureVMSizeCatalog(data []byte) (*AzureVMSizeCatalog, error) {
+	var file azureVMSizeCatalogFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse VM size catalog: %w", err)
+	}
+
+	if file.Version == "" {
+		return nil, fmt.Errorf("VM size catalog has no version")
+	}
+
+	catalog := &AzureVMSizeCatalog{
+		version: file.Version,
+		sizes:   make(map[string]AzureVMSize, len(file.Sizes)),
+	}
+
+	for _, size := range file.Sizes {
+		if !strings.HasPrefix(size.Name, "Standard_") {
+			return nil, fmt.Errorf("invalid VM size name: %q", size.Name)
+		}
+		if size.VCPU <= 0 || size.MemoryGB <= 0 {
+			return nil, fmt.Errorf("VM size %s must have positive vCPU and memory", size.Name)
+		}
+		if size.Architecture != "x86_64" && size.Architecture != "arm64" {
+			return nil, fmt.Errorf("VM size %s has unsupported architecture: %q", size.Name, size.Architecture)
+		}
+		if (size.LocalDiskGB > 0) != (size.LocalDiskType != "") {
+			return nil, fmt.Errorf("VM size %s must set both local disk size and type", size.Name)
+		}
+
+		key := strings.ToLower(size.Name)
+		if _, exists := catalog.sizes[key]; exists {
+			return nil, fmt.Errorf("duplicate VM size: %s", size.Name)
+		}
+		catalog.sizes[key] = size
+	}
+
+	return catalog, nil
+}
+
+// Version returns the catalog version
+func (c *AzureVMSizeCatalog) Version() string {
+	return c.version
+}
+
+// Len returns the number of sizes in the catalog
+func (c *AzureVMSizeCatalog) Len() int {
+	return len(c.sizes)
+}
+
+// Lookup finds a VM size by armSkuName. Constrained vCPU sizes resolve to their parent size
+// with the active vCPU count.
+func (c *AzureVMSizeCatalog) Lookup(armSKUName string) (AzureVMSize, bool) {
+	if size, exists := c.sizes[strings.ToLower(armSKUName)]; exists {
+		return size, true
+	}
+
+	matches := azureConstrainedSizePattern.FindStringSubmatch(armSKUName)
+	if matches == nil {
+		return AzureVMSize{}, false
+	}
+
+	parent, exists := c.sizes[strings.ToLower(matches[1]+matches[2]+matches[4])]
+	if !exists {
+		return AzureVMSize{}, false
+	}
+
+	activeVCPU, err := strconv.Atoi(matches[3])
+	if err != nil || activeVCPU <= 0 || activeVCPU >= parent.VCPU {
+		return AzureVMSize{}, false
+	}
+
+	size := parent
+	size.Name = armSKUName
+	size.VCPU = activeVCPU
+	return size, true
+}
@@ -0,0 +1,191 @@
+package normalizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultAzureVMSizeCatalog(t *testing.T) {
+	catalog := DefaultAzureVMSizeCatalog()
+
+	assert.NotEmpty(t, catalog.Version())
+	assert.Greater(t, catalog.Len(), 500)
+}
+
+func TestAzureVMSizeCatalog_Lookup(t *testing.T) {
+	catalog := DefaultAzureVMSizeCatalog()
+
+	tests := []struct {
+		name     string
+		armSKU   string
+		expected AzureVMSize
+		found    bool
+	}{
+		{
+			name:   "general purpose without local disk",
+			armSKU: "Standard_D2s_v5",
+			expected: AzureVMSize{
+				Name: "Standard_D2s_v5", VCPU: 2, MemoryGB: 8,
+				Architecture: "x86_64", AcceleratedNetworking: true,
+			},
+			found: true,
+		},
+		{
+			name:   "lookup is case insensitive",
+			armSKU: "standard_d2S_V5",
+			expected: AzureVMSize{
+				Name: "Standard_D2s_v5", VCPU: 2, MemoryGB: 8,
+				Architecture: "x86_64", AcceleratedNetworking: true,
+			},
+			found: true,
+		},
+		{
+			name:   "Arm size",
+			armSKU: "Standard_D4ps_v5",
+			expected: AzureVMSize{
+				Name: "Standard_D4ps_v5", VCPU: 4, MemoryGB: 16,
+				Architecture: "arm64", AcceleratedNetworking: true,
+			},
+			found: true,
+		},
+		{
+			name:   "burstable size",
+			armSKU: "Standard_B1ls",
+			expected: AzureVMSize{
+				Name: "Standard_B1ls", VCPU: 1, MemoryGB: 0.5,
+				LocalDiskGB: 4, LocalDiskType: "Temp SSD",
+				Architecture: "x86_64", Burstable: true,
+			},
+			found: true,
+		},
+		{
+			name:   "GPU size",
+			armSKU: "Standard_NC48ads_A100_v4",
+			expected: AzureVMSize{
+				Name: "Standard_NC48ads_A100_v4", VCPU: 48, MemoryGB: 440,
+				GPUCount: 2, GPUModel: "NVIDIA A100 PCIe", GPUMemoryGB: 160,
+				LocalDiskGB: 1916, LocalDiskType: "NVMe SSD",
+				Architecture: "x86_64", AcceleratedNetworking: true,
+			},
+			found: true,
+		},
+		{
+			name:   "storage optimized with local NVMe",
+			armSKU: "Standard_L16s_v3",
+			expected: AzureVMSize{
+				Name: "Standard_L16s_v3", VCPU: 16, MemoryGB: 128,
+				LocalDiskGB: 3840, LocalDiskType: "NVMe SSD",
+				Architecture: "x86_64", AcceleratedNetworking: true,
+			},
+			found: true,
+		},
+		{
+			name:   "constrained vCPU size resolves to parent",
+			armSKU: "Standard_E96-24ds_v5",
+			expected: AzureVMSize{
+				Name: "Standard_E96-24ds_v5", VCPU: 24, MemoryGB: 672,
+				LocalDiskGB: 3600, LocalDiskType: "Temp SSD",
+				Architecture: "x86_64", AcceleratedNetworking: true,
+			},
+			found: true,
+		},
+		{
+			name:   "constrained vCPU count must be below parent",
+			armSKU: "Standard_E16-32ds_v5",
+			found:  false,
+		},
+		{
+			name:   "unknown size",
+			armSKU: "Standard_X42_v9",
+			found:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size, found := catalog.Lookup(tt.armSKU)
+
+			assert.Equal(t, tt.found, found)
+			if tt.found {
+				assert.Equal(t, tt.expected, size)
+			}
+		})
+	}
+}
+
+func TestParseAzureVMSizeCatalog(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		expectError bool
+	}{
+		{
+			name: "valid catalog",
+			data: `{"version": "1", "sizes": [
+				{"name": "Standard_D2s_v5", "vcpu": 2, "memoryGB": 8, "architecture": "x86_64"}
+			]}`,
+		},
+		{
+			name:        "invalid JSON",
+			data:        `{"version": `,
+			expectError: true,
+		},
+		{
+			name:        "missing version",
+			data:        `{"sizes": []}`,
+			expectError: true,
+		},
+		{
+			name: "name without Standard prefix",
+			data: `{"version": "1", "sizes": [
+				{"name": "D2s_v5", "vcpu": 2, "memoryGB": 8, "architecture": "x86_64"}
+			]}`,
+			expectError: true,
+		},
+		{
+			name: "missing vCPU",
+			data: `{"version": "1", "sizes": [
+				{"name": "Standard_D2s_v5", "memoryGB": 8, "architecture": "x86_64"}
+			]}`,
+			expectError: true,
+		},
+		{
+			name: "unsupported architecture",
+			data: `{"version": "1", "sizes": [
+				{"name": "Standard_D2s_v5", "vcpu": 2, "memoryGB": 8, "architecture": "sparc"}
+			]}`,
+			expectError: true,
+		},
+		{
+			name: "local disk without type",
+			data: `{"version": "1", "sizes": [
+				{"name": "Standard_D2ds_v5", "vcpu": 2, "memoryGB": 8, "localDiskGB": 75, "architecture": "x86_64"}
+			]}`,
+			expectError: true,
+		},
+		{
+			name: "duplicate size",
+			data: `{"version": "1", "sizes": [
+				{"name": "Standard_D2s_v5", "vcpu": 2, "memoryGB": 8, "architecture": "x86_64"},
+				{"name": "standard_d2s_v5", "vcpu": 2, "memoryGB": 8, "architecture": "x86_64"}
+			]}`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog, err := ParseAzureVMSizeCatalog([]byte(tt.data))
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, 1, catalog.Len())
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/raulc0399/cpc/internal/database"
 )
@@ -16,6 +18,7 @@ type BaseNormalizer struct {
 	unitNormalizer     UnitNormalizer
 	validator          *InputValidator
 	logger             Logger
+	exclusions         MappingExclusions
 }
 
 // Logger interface for structured logging
@@ -49,6 +52,16 @@ func NewBaseNormalizer(
 	}
 }
 
+// SetMappingExclusions sets the rules that exclude raw pricing from normalization
+func (n *BaseNormalizer) SetMappingExclusions(exclusions MappingExclusions) {
+	n.exclusions = exclusions
+}
+
+// IsExcluded reports whether the input is excluded from normalization by the mapping configuration
+func (n *BaseNormalizer) IsExcluded(input database.NormalizationInput) bool {
+	return n.exclusions != nil && n.exclusions.IsExcluded(input.Provider, input.ServiceCode, input.Region)
+}
+
 // NormalizationContext holds common normalization data
 type NormalizationContext struct {
 	Provider         string
@@ -56,13 +69,31 @@ type NormalizationContext struct {
 	NormalizedRegion *database.NormalizedRegion
 	RawDataID        int
 	CollectionID     string
+	Logger           Logger
+}
+
+// PricingInfo holds the price of a single provider price point before normalization
+type PricingInfo struct {
+	PricePerUnit float64
+	Unit         string
+	Currency     string
+	Description  string
+}
+
+// ContextLogger returns the logger with the log fields of the context and the input's
+// provider, region and collection ID
+func (n *BaseNormalizer) ContextLogger(ctx context.Context, input database.NormalizationInput) Logger {
+	fields := []Field{{LogKeyProvider, input.Provider}, {LogKeyRegion, input.Region}}
+	if input.CollectionID != "" {
+		fields = append(fields, Field{LogKeyCollectionID, input.CollectionID})
+	}
+	return LoggerFromContext(ContextWithLogFields(ctx, fields...), n.logger)
 }
 
 // ValidateCommonInput performs common input validation
 func (n *BaseNormalizer) ValidateCommonInput(ctx context.Context, input database.NormalizationInput) error {
 	if err := n.validator.ValidateNormalizationInput(input); err != nil {
-		n.logger.Error("Input validation failed",
-			Field{"provider", input.Provider},
+		n.ContextLogger(ctx, input).Error("Input validation failed",
 			Field{"service", input.ServiceCode},
 			Field{"error", err},
 		)
@@ -76,19 +107,19 @@ func (n *BaseNormalizer) GetNormalizationContext(
 	ctx context.Context,
 	input database.NormalizationInput,
 ) (*NormalizationContext, error) {
+	logger := n.ContextLogger(ctx, input)
+
 	// Get service mapping
 	serviceMapping, err := n.serviceMappingRepo.GetServiceMappingByProvider(ctx, input.Provider, input.ServiceCode)
 	if err != nil {
-		n.logger.Error("Failed to get service mapping",
-			Field{"provider", input.Provider},
+		logger.Error("Failed to get service mapping",
 			Field{"service", input.ServiceCode},
 			Field{"error", err},
 		)
 		return nil, fmt.Errorf("failed to get service mapping: %w", err)
 	}
 	if serviceMapping == nil {
-		n.logger.Warn("No service mapping found",
-			Field{"provider", input.Provider},
+		logger.Warn("No service mapping found",
 			Field{"service", input.ServiceCode},
 		)
 		return nil, nil // This is a valid case - service not mapped
@@ -97,18 +128,13 @@ func (n *BaseNormalizer) GetNormalizationContext(
 	// Get normalized region
 	normalizedRegion, err := n.regionMappingRepo.GetNormalizedRegionByProvider(ctx, input.Provider, input.Region)
 	if err != nil {
-		n.logger.Error("Failed to get normalized region",
-			Field{"provider", input.Provider},
-			Field{"region", input.Region},
+		logger.Error("Failed to get normalized region",
 			Field{"error", err},
 		)
 		return nil, fmt.Errorf("failed to get normalized region: %w", err)
 	}
 	if normalizedRegion == nil {
-		n.logger.Warn("No normalized region found",
-			Field{"provider", input.Provider},
-			Field{"region", input.Region},
-		)
+		logger.Warn("No normalized region found")
 		return nil, nil // This is a valid case - region not mapped
 	}
 
@@ -118,6 +144,7 @@ func (n *BaseNormalizer) GetNormalizationContext(
 		NormalizedRegion: normalizedRegion,
 		RawDataID:        input.RawDataID,
 		CollectionID:     input.CollectionID,
+		Logger:           logger,
 	}, nil
 }
 
@@ -131,17 +158,24 @@ func (n *BaseNormalizer) CreateNormalizedRecord(
 	pricingModel string,
 	pricingDetails database.PricingDetails,
 ) (*database.NormalizedPricing, error) {
+	logger := n.logger
+	if normCtx.Logger != nil {
+		logger = normCtx.Logger
+	}
+
 	// Skip zero-cost items
 	if priceInfo.PricePerUnit == 0 {
-		n.logger.Debug("Skipping zero-cost item",
+		logger.Debug("Skipping zero-cost item",
 			Field{"resource", resourceName},
 			Field{"service", normCtx.ServiceMapping.NormalizedServiceType},
 		)
 		return nil, nil
 	}
 
-	// Normalize unit
-	normalizedUnit := n.unitNormalizer.NormalizeUnit(normCtx.Provider, priceInfo.Unit)
+	// Normalize the unit and rescale the price to the canonical unit, e.g. "100 Hours" to hour
+	scaledUnit := n.unitNormalizer.ScaleUnit(normCtx.Provider, priceInfo.Unit)
+	normalizedUnit := scaledUnit.Unit
+	originalUnit := priceInfo.Unit
 
 	// Determine provider region
 	var providerRegion string
@@ -173,8 +207,10 @@ func (n *BaseNormalizer) CreateNormalizedRecord(
 		ResourceName:        resourceName,
 		ResourceDescription: &priceInfo.Description,
 		ResourceSpecs:       resourceSpecs,
-		PricePerUnit:        priceInfo.PricePerUnit,
+		PricePerUnit:        priceInfo.PricePerUnit * scaledUnit.Scale,
 		Unit:                normalizedUnit,
+		OriginalUnit:        &originalUnit,
+		UnitMultiplier:      scaledUnit.Multiplier,
 		Currency:            priceInfo.Currency,
 		PricingModel:        pricingModel,
 		PricingDetails:      pricingDetails,
@@ -190,16 +226,16 @@ func (n *BaseNormalizer) CreateNormalizedRecord(
 
 	// Validate the created record
 	if err := n.validator.ValidateNormalizedPricing(record); err != nil {
-		n.logger.Error("Created record validation failed",
+		logger.Error("Created record validation failed",
 			Field{"resource", resourceName},
 			Field{"error", err},
 		)
 		return nil, fmt.Errorf("record validation failed: %w", err)
 	}
 
-	n.logger.Debug("Created normalized record",
+	logger.Debug("Created normalized record",
 		Field{"resource", resourceName},
-		Field{"price", priceInfo.PricePerUnit},
+		Field{"price", record.PricePerUnit},
 		Field{"unit", normalizedUnit},
 		Field{"model", pricingModel},
 	)
@@ -207,6 +243,70 @@ func (n *BaseNormalizer) CreateNormalizedRecord(
 	return &record, nil
 }
 
+// SetPriceTiers sets tiers expressed in the provider unit on a record, rescaling their ranges
+// and prices to the record's canonical unit
+func (n *BaseNormalizer) SetPriceTiers(record *database.NormalizedPricing, tiers []database.PriceTier) {
+	if len(tiers) == 0 || record.OriginalUnit == nil {
+		record.PriceTiers = tiers
+		return
+	}
+
+	scaledUnit := n.unitNormalizer.ScaleUnit(record.Provider, *record.OriginalUnit)
+	scaled := make([]database.PriceTier, len(tiers))
+	for i, tier := range tiers {
+		scaled[i] = database.PriceTier{
+			StartUnits:   tier.StartUnits * scaledUnit.Quantity,
+			PricePerUnit: tier.PricePerUnit * scaledUnit.Scale,
+		}
+		if tier.EndUnits != nil {
+			endUnits := *tier.EndUnits * scaledUnit.Quantity
+			scaled[i].EndUnits = &endUnits
+		}
+	}
+	record.PriceTiers = scaled
+}
+
+// SetValidity sets the dates between which the price of a record applies from the provider's
+// timestamps. Values that cannot be parsed are logged and left unset.
+func (n *BaseNormalizer) SetValidity(record *database.NormalizedPricing, effectiveDate, expirationDate string) {
+	record.EffectiveDate = n.parsePriceDate("effectiveDate", effectiveDate)
+	record.ExpirationDate = n.parsePriceDate("expirationDate", expirationDate)
+}
+
+// priceDateLayouts are the timestamp formats used by the provider price lists
+var priceDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}
+
+func (n *BaseNormalizer) parsePriceDate(field, value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	for _, layout := range priceDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			date = date.UTC()
+			return &date
+		}
+	}
+
+	n.logger.Warn("Ignoring invalid price date",
+		Field{"field", field},
+		Field{"value", value},
+	)
+	return nil
+}
+
+// commitmentTermHours returns the length of a commitment in hours, taken from the term length
+// when known and otherwise from the reserved pricing model
+func commitmentTermHours(details database.PricingDetails, pricingModel string) (float64, bool) {
+	if details.TermLength != nil {
+		if hours, ok := database.TermHours(*details.TermLength); ok {
+			return hours, true
+		}
+	}
+	return database.PricingModelTermHours(pricingModel)
+}
+
 // CreateErrorResult creates a standardized error result
 func (n *BaseNormalizer) CreateErrorResult(message string, err error) *database.NormalizationResult {
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -311,6 +312,82 @@ func TestBaseNormalizer_CreateNormalizedRecord(t *testing.T) {
 	}
 }
 
+func TestBaseNormalizer_RescalesUnitsAndTiers(t *testing.T) {
+	ctx := context.Background()
+	base := NewBaseNormalizer(nil, nil, NewStandardUnitNormalizer(), NewInputValidator(), NewMockLogger())
+
+	normCtx := &NormalizationContext{
+		Provider: database.ProviderAzure,
+		ServiceMapping: &database.ServiceMapping{
+			ID:                    1,
+			ProviderServiceName:   "Storage",
+			NormalizedServiceType: "Object Storage",
+			ServiceCategory:       "Storage",
+			ServiceFamily:         "Object Storage",
+		},
+		NormalizedRegion: &database.NormalizedRegion{
+			ID:             1,
+			NormalizedCode: "us-east",
+			AzureRegion:    stringPtr("eastus"),
+		},
+		RawDataID: 7,
+	}
+
+	priceInfo := PricingInfo{
+		PricePerUnit: 2,
+		Unit:         "100 GB/Month",
+		Currency:     "USD",
+		Description:  "Hot LRS Data Stored",
+	}
+
+	record, err := base.CreateNormalizedRecord(ctx, normCtx, priceInfo, database.ResourceSpecs{},
+		"Hot LRS", database.PricingModelOnDemand, database.PricingDetails{})
+	require.NoError(t, err)
+	require.NotNil(t, record)
+
+	assert.Equal(t, database.UnitGBMonth, record.Unit)
+	assert.InDelta(t, 0.02, record.PricePerUnit, 1e-12)
+	require.NotNil(t, record.OriginalUnit)
+	assert.Equal(t, "100 GB/Month", *record.OriginalUnit)
+	assert.Equal(t, 100.0, record.UnitMultiplier)
+
+	base.SetPriceTiers(record, []database.PriceTier{
+		{StartUnits: 0, EndUnits: float64Ptr(500), PricePerUnit: 2},
+		{StartUnits: 500, PricePerUnit: 1.8},
+	})
+	require.Len(t, record.PriceTiers, 2)
+	assert.Equal(t, 0.0, record.PriceTiers[0].StartUnits)
+	require.NotNil(t, record.PriceTiers[0].EndUnits)
+	assert.Equal(t, 50000.0, *record.PriceTiers[0].EndUnits)
+	assert.InDelta(t, 0.02, record.PriceTiers[0].PricePerUnit, 1e-12)
+	assert.Equal(t, 50000.0, record.PriceTiers[1].StartUnits)
+	assert.Nil(t, record.PriceTiers[1].EndUnits)
+	assert.InDelta(t, 0.018, record.PriceTiers[1].PricePerUnit, 1e-12)
+}
+
+func TestBaseNormalizer_SetValidity(t *testing.T) {
+	mockLogger := NewMockLogger()
+	base := NewBaseNormalizer(nil, nil, nil, nil, mockLogger)
+
+	record := &database.NormalizedPricing{}
+	base.SetValidity(record, "2024-12-01T00:00:00Z", "2025-06-30")
+	require.NotNil(t, record.EffectiveDate)
+	require.NotNil(t, record.ExpirationDate)
+	assert.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), *record.EffectiveDate)
+	assert.Equal(t, time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), *record.ExpirationDate)
+
+	assert.True(t, record.IsValidAt(time.Date(2024, 12, 1, 12, 0, 0, 0, time.UTC)))
+	assert.True(t, record.IsValidAt(time.Date(2025, 6, 30, 23, 0, 0, 0, time.UTC)))
+	assert.False(t, record.IsValidAt(time.Date(2024, 11, 30, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, record.IsValidAt(time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)))
+
+	base.SetValidity(record, "not a date", "")
+	assert.Nil(t, record.EffectiveDate)
+	assert.Nil(t, record.ExpirationDate)
+	assert.True(t, mockLogger.HasMessage("WARN", "Ignoring invalid price date"))
+	assert.True(t, record.IsValidAt(time.Now()))
+}
+
 func TestBaseNormalizer_ParseJSONData(t *testing.T) {
 	mockLogger := NewMockLogger()
 	base := NewBaseNormalizer(nil, nil, nil, nil, mockLogger)
@@ -393,4 +470,7 @@ func intPtr(i int) *int {
 
 func float64Ptr(f float64) *float64 {
 	return &f
-}
\ No newline at end of file
+}
+func boolPtr(b bool) *bool {
+	return &b
+}
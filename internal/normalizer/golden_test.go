@@ -0,0 +1,361 @@
+package normalizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/stretchr/testify/require"
+)
+
+// Golden-file tests run real raw pricing records through the normalizers and compare the
+// output with the expected output checked in next to them. Each testdata/golden/<provider>/<case>.json
+// holds a JSON array of raw records: Azure retail prices items (the format of
+// azure_pricing_sample.json) or AWS price list products. The expected output of a case is
+// <case>.golden.json. After an intended change, regenerate it and review the diff:
+//
+//	go test ./internal/normalizer -run TestGolden -update
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+const goldenDir = "testdata/golden"
+
+// goldenVolatileFields are normalized record fields set by the database
+var goldenVolatileFields = []string{"id", "createdAt", "updatedAt"}
+
+// goldenEntry is the normalization result of one raw record
+type goldenEntry struct {
+	Source      string                   `json:"source"`
+	ServiceCode string                   `json:"serviceCode"`
+	Region      string                   `json:"region"`
+	Success     bool                     `json:"success"`
+	Skipped     int                      `json:"skipped,omitempty"`
+	Errors      []string                 `json:"errors,omitempty"`
+	Records     []map[string]interface{} `json:"records,omitempty"`
+}
+
+// goldenProvider describes how the raw records of a provider are fed to its normalizer
+type goldenProvider struct {
+	provider string
+	newInput func(raw json.RawMessage) (source string, input database.NormalizationInput, err error)
+}
+
+var goldenProviders = []goldenProvider{
+	{provider: database.ProviderAWS, newInput: newAWSGoldenInput},
+	{provider: database.ProviderAzure, newInput: newAzureGoldenInput},
+}
+
+// newAWSGoldenInput builds the input of a price list product like the AWS ETL does
+func newAWSGoldenInput(raw json.RawMessage) (string, database.NormalizationInput, error) {
+	var product struct {
+		Product struct {
+			SKU        string `json:"sku"`
+			Attributes struct {
+				ServiceCode string `json:"servicecode"`
+				RegionCode  string `json:"regionCode"`
+			} `json:"attributes"`
+		} `json:"product"`
+	}
+	if err := json.Unmarshal(raw, &product); err != nil {
+		return "", database.NormalizationInput{}, err
+	}
+
+	return product.Product.SKU, database.NormalizationInput{
+		Provider:    database.ProviderAWS,
+		ServiceCode: product.Product.Attributes.ServiceCode,
+		Region:      product.Product.Attributes.RegionCode,
+		RawData:     raw,
+	}, nil
+}
+
+// newAzureGoldenInput builds the input of a retail prices item like the Azure ETL does
+func newAzureGoldenInput(raw json.RawMessage) (string, database.NormalizationInput, error) {
+	var item struct {
+		MeterID          string  `json:"meterId"`
+		ServiceName      string  `json:"serviceName"`
+		ArmRegionName    string  `json:"armRegionName"`
+		TierMinimumUnits float64 `json:"tierMinimumUnits"`
+	}
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return "", database.NormalizationInput{}, err
+	}
+
+	// Tiers of a meter are separate items
+	source := item.MeterID
+	if item.TierMinimumUnits > 0 {
+		source = fmt.Sprintf("%s (tier %g)", item.MeterID, item.TierMinimumUnits)
+	}
+
+	return source, database.NormalizationInput{
+		Provider:    database.ProviderAzure,
+		ServiceCode: item.ServiceName,
+		Region:      item.ArmRegionName,
+		RawData:     raw,
+	}, nil
+}
+
+func TestGolden(t *testing.T) {
+	config, err := LoadMappingConfig(filepath.Join(goldenDir, "mappings.yaml"))
+	require.NoError(t, err)
+
+	for _, gp := range goldenProviders {
+		cases, err := filepath.Glob(filepath.Join(goldenDir, gp.provider, "*.json"))
+		require.NoError(t, err)
+
+		for _, path := range cases {
+			if strings.HasSuffix(path, ".golden.json") {
+				continue
+			}
+
+			name := strings.TrimSuffix(filepath.Base(path), ".json")
+			t.Run(gp.provider+"/"+name, func(t *testing.T) {
+				actual := runGoldenCase(t, gp, newGoldenNormalizer(gp.provider, config), path)
+				goldenPath := strings.TrimSuffix(path, ".json") + ".golden.json"
+
+				if *updateGolden {
+					data, err := marshalGolden(actual)
+					require.NoError(t, err)
+					require.NoError(t, os.WriteFile(goldenPath, data, 0o644))
+					return
+				}
+
+				data, err := os.ReadFile(goldenPath)
+				require.NoError(t, err, "missing golden file, run with -update to create it")
+
+				var expected []goldenEntry
+				require.NoError(t, json.Unmarshal(data, &expected))
+				compareGolden(t, goldenPath, expected, actual)
+			})
+		}
+	}
+}
+
+// newGoldenNormalizer creates a provider normalizer backed by mock repositories serving the
+// mappings of the golden mapping configuration
+func newGoldenNormalizer(provider string, config *MappingConfig) PricingNormalizer {
+	serviceRepo := NewMockServiceMappingRepository()
+	for i, mapping := range config.ServiceMappings() {
+		mapping := mapping
+		mapping.ID = i + 1
+		serviceRepo.AddMapping(mapping.Provider, mapping.ProviderServiceName, &mapping)
+		if mapping.ProviderServiceCode != nil {
+			serviceRepo.AddMapping(mapping.Provider, *mapping.ProviderServiceCode, &mapping)
+		}
+	}
+
+	regionRepo := NewMockRegionMappingRepository()
+	for i, region := range config.RegionMappings() {
+		region := region
+		region.ID = i + 1
+		if region.AWSRegion != nil {
+			regionRepo.AddRegion(database.ProviderAWS, *region.AWSRegion, &region)
+		}
+		if region.AzureRegion != nil {
+			regionRepo.AddRegion(database.ProviderAzure, *region.AzureRegion, &region)
+		}
+	}
+
+	if provider == database.ProviderAWS {
+		normalizer := NewAWSNormalizerV2(serviceRepo, regionRepo, NewStandardUnitNormalizer(), NewInputValidator(), NewMockLogger())
+		normalizer.SetMappingExclusions(config)
+		return normalizer
+	}
+
+	normalizer := NewAzureNormalizerV2(serviceRepo, regionRepo, NewStandardUnitNormalizer(), NewInputValidator(), NewMockLogger())
+	normalizer.SetMappingExclusions(config)
+	return normalizer
+}
+
+// runGoldenCase normalizes every raw record of a case file
+func runGoldenCase(t *testing.T, gp goldenProvider, normalizer PricingNormalizer, path string) []goldenEntry {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var raws []json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &raws), "%s must hold a JSON array of raw records", path)
+
+	entries := make([]goldenEntry, 0, len(raws))
+	sources := make(map[string]bool, len(raws))
+	for i, raw := range raws {
+		source, input, err := gp.newInput(raw)
+		require.NoError(t, err, "raw record %d", i)
+		require.False(t, sources[source], "raw record %s appears twice in %s", source, path)
+		sources[source] = true
+		input.RawDataID = i + 1
+		input.CollectionID = "golden"
+
+		result, err := normalizer.NormalizePricing(context.Background(), input)
+		require.NoError(t, err, "raw record %d", i)
+
+		entry := goldenEntry{
+			Source:      source,
+			ServiceCode: input.ServiceCode,
+			Region:      input.Region,
+			Success:     result.Success,
+			Skipped:     result.SkippedCount,
+			Errors:      append([]string(nil), result.Errors...),
+		}
+		sort.Strings(entry.Errors)
+
+		for _, record := range result.NormalizedRecords {
+			fields, err := goldenRecord(record)
+			require.NoError(t, err)
+			entry.Records = append(entry.Records, fields)
+		}
+		// AWS terms are maps, so records come out in random order
+		sort.Slice(entry.Records, func(i, j int) bool {
+			return goldenKey(entry.Records[i]) < goldenKey(entry.Records[j])
+		})
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// goldenRecord converts a normalized record to its JSON fields without the database-set ones
+func goldenRecord(record database.NormalizedPricing) (map[string]interface{}, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, field := range goldenVolatileFields {
+		delete(fields, field)
+	}
+	return fields, nil
+}
+
+func goldenKey(fields map[string]interface{}) string {
+	data, _ := json.Marshal(fields)
+	return string(data)
+}
+
+func marshalGolden(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// compareGolden reports differences per raw record, so a change shows up as a diff of the
+// affected record instead of the whole file
+func compareGolden(t *testing.T, goldenPath string, expected, actual []goldenEntry) {
+	t.Helper()
+
+	expectedBySource := make(map[string]goldenEntry, len(expected))
+	for _, entry := range expected {
+		expectedBySource[entry.Source] = entry
+	}
+
+	seen := make(map[string]bool, len(actual))
+	for _, entry := range actual {
+		seen[entry.Source] = true
+
+		want, ok := expectedBySource[entry.Source]
+		if !ok {
+			t.Errorf("%s: raw record %s is not in the golden file", goldenPath, entry.Source)
+			continue
+		}
+
+		wantJSON, err := marshalGolden(want)
+		require.NoError(t, err)
+		gotJSON, err := marshalGolden(entry)
+		require.NoError(t, err)
+		if !bytes.Equal(wantJSON, gotJSON) {
+			t.Errorf("%s: raw record %s changed (-golden +actual):\n%s", goldenPath, entry.Source, goldenDiff(string(wantJSON), string(gotJSON)))
+		}
+	}
+
+	for _, entry := range expected {
+		if !seen[entry.Source] {
+			t.Errorf("%s: raw record %s is missing from the case file", goldenPath, entry.Source)
+		}
+	}
+}
+
+// goldenDiff returns a line diff of two JSON documents, keeping unchanged lines next to changes
+// as context
+func goldenDiff(want, got string) string {
+	a := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	type diffLine struct {
+		op   byte
+		text string
+	}
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+
+	const contextLines = 2
+	var out strings.Builder
+	last := -1
+	for k, line := range lines {
+		near := false
+		for c := k - contextLines; c <= k+contextLines; c++ {
+			if c >= 0 && c < len(lines) && lines[c].op != ' ' {
+				near = true
+				break
+			}
+		}
+		if !near {
+			continue
+		}
+		if last >= 0 && k > last+1 {
+			out.WriteString("  ...\n")
+		}
+		out.WriteByte(line.op)
+		out.WriteString(line.text)
+		out.WriteByte('\n')
+		last = k
+	}
+	return out.String()
+}
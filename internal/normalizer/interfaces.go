@@ -37,6 +37,7 @@ type ResourceSpecExtractor interface {
 // UnitNormalizer defines interface for normalizing pricing units
 type UnitNormalizer interface {
 	NormalizeUnit(provider, originalUnit string) string
+	ScaleUnit(provider, originalUnit string) ScaledUnit
 }
 
 // PricingModelDetector defines interface for detecting pricing models
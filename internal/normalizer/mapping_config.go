@@ -0,0 +1,286 @@
+package normalizer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"gopkg.in/yaml.v3"
+)
+
+// MappingConfigVersion is the mapping configuration format version this build understands
+const MappingConfigVersion = 1
+
+// MappingConfig declares service and region mappings so they can be changed without a SQL
+// migration. Overrides patch individual fields of a service mapping, whether it is declared
+// in the file or already stored in the tables, and exclusions skip matching raw pricing.
+type MappingConfig struct {
+	Version    int                      `json:"version" yaml:"version"`
+	Services   []ServiceMappingConfig   `json:"services" yaml:"services"`
+	Regions    []RegionMappingConfig    `json:"regions" yaml:"regions"`
+	Overrides  []ServiceMappingOverride `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+	Exclusions []MappingExclusion       `json:"exclusions,omitempty" yaml:"exclusions,omitempty"`
+}
+
+// ServiceMappingConfig maps a provider service to a normalized service type, category and family
+type ServiceMappingConfig struct {
+	Provider              string `json:"provider" yaml:"provider"`
+	ServiceName           string `json:"service_name" yaml:"service_name"`
+	ServiceCode           string `json:"service_code,omitempty" yaml:"service_code,omitempty"`
+	NormalizedServiceType string `json:"normalized_service_type" yaml:"normalized_service_type"`
+	ServiceCategory       string `json:"service_category" yaml:"service_category"`
+	ServiceFamily         string `json:"service_family" yaml:"service_family"`
+}
+
+// RegionMappingConfig maps provider regions to a normalized region
+type RegionMappingConfig struct {
+	NormalizedCode string `json:"normalized_code" yaml:"normalized_code"`
+	AWSRegion      string `json:"aws_region,omitempty" yaml:"aws_region,omitempty"`
+	AzureRegion    string `json:"azure_region,omitempty" yaml:"azure_region,omitempty"`
+	DisplayName    string `json:"display_name" yaml:"display_name"`
+	Country        string `json:"country,omitempty" yaml:"country,omitempty"`
+	Continent      string `json:"continent,omitempty" yaml:"continent,omitempty"`
+}
+
+// ServiceMappingOverride replaces the non-empty fields of the mapping for a provider service
+type ServiceMappingOverride struct {
+	Provider              string `json:"provider" yaml:"provider"`
+	ServiceName           string `json:"service_name" yaml:"service_name"`
+	NormalizedServiceType string `json:"normalized_service_type,omitempty" yaml:"normalized_service_type,omitempty"`
+	ServiceCategory       string `json:"service_category,omitempty" yaml:"service_category,omitempty"`
+	ServiceFamily         string `json:"service_family,omitempty" yaml:"service_family,omitempty"`
+}
+
+// MappingExclusion skips raw pricing of a provider that matches all of its non-empty fields.
+// Service matches the raw service code; an exclusion with neither service nor region excludes
+// the whole provider.
+type MappingExclusion struct {
+	Provider string `json:"provider" yaml:"provider"`
+	Service  string `json:"service,omitempty" yaml:"service,omitempty"`
+	Region   string `json:"region,omitempty" yaml:"region,omitempty"`
+	Reason   string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// serviceCategories lists the normalized service categories a mapping may use
+var serviceCategories = []string{
+	database.CategoryGeneral,
+	database.CategoryNetworking,
+	database.CategoryComputeWeb,
+	database.CategoryContainers,
+	database.CategoryDatabases,
+	database.CategoryStorage,
+	database.CategoryAIML,
+	database.CategoryAnalyticsIoT,
+	database.CategoryVirtualDesktop,
+	database.CategoryDevTools,
+	database.CategoryIntegration,
+	database.CategoryMigration,
+	database.CategoryManagement,
+}
+
+// LoadMappingConfig reads and validates a mapping configuration from a YAML or JSON file,
+// chosen by extension
+func LoadMappingConfig(path string) (*MappingConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping config: %w", err)
+	}
+
+	var config MappingConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&config)
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&config)
+	default:
+		return nil, fmt.Errorf("unsupported mapping config format %q: use .yaml, .yml or .json", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mapping config %s: %w", path, err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid mapping config %s: %w", path, err)
+	}
+
+	return &config, nil
+}
+
+// Validate checks the configuration version, required fields, providers and categories, and
+// rejects duplicate service and region mappings
+func (c *MappingConfig) Validate() error {
+	if c.Version != MappingConfigVersion {
+		return fmt.Errorf("unsupported version %d: expected %d", c.Version, MappingConfigVersion)
+	}
+
+	services := make(map[string]bool)
+	for i, service := range c.Services {
+		if err := validateMappingProvider(service.Provider); err != nil {
+			return fmt.Errorf("services[%d]: %w", i, err)
+		}
+		if service.ServiceName == "" || service.NormalizedServiceType == "" || service.ServiceFamily == "" {
+			return fmt.Errorf("services[%d]: service_name, normalized_service_type and service_family are required", i)
+		}
+		if err := validateServiceCategory(service.ServiceCategory); err != nil {
+			return fmt.Errorf("services[%d]: %w", i, err)
+		}
+		key := service.Provider + ":" + service.ServiceName
+		if services[key] {
+			return fmt.Errorf("services[%d]: duplicate mapping for %s", i, key)
+		}
+		services[key] = true
+	}
+
+	codes := make(map[string]bool)
+	providerRegions := make(map[string]string)
+	for i, region := range c.Regions {
+		if region.NormalizedCode == "" || region.DisplayName == "" {
+			return fmt.Errorf("regions[%d]: normalized_code and display_name are required", i)
+		}
+		if region.AWSRegion == "" && region.AzureRegion == "" {
+			return fmt.Errorf("regions[%d]: %s maps no provider region", i, region.NormalizedCode)
+		}
+		if codes[region.NormalizedCode] {
+			return fmt.Errorf("regions[%d]: duplicate normalized_code %s", i, region.NormalizedCode)
+		}
+		codes[region.NormalizedCode] = true
+
+		for provider, providerRegion := range map[string]string{
+			database.ProviderAWS:   region.AWSRegion,
+			database.ProviderAzure: region.AzureRegion,
+		} {
+			if providerRegion == "" {
+				continue
+			}
+			key := provider + ":" + providerRegion
+			if existing, ok := providerRegions[key]; ok {
+				return fmt.Errorf("regions[%d]: %s is already mapped to %s", i, key, existing)
+			}
+			providerRegions[key] = region.NormalizedCode
+		}
+	}
+
+	for i, override := range c.Overrides {
+		if err := validateMappingProvider(override.Provider); err != nil {
+			return fmt.Errorf("overrides[%d]: %w", i, err)
+		}
+		if override.ServiceName == "" {
+			return fmt.Errorf("overrides[%d]: service_name is required", i)
+		}
+		if override.NormalizedServiceType == "" && override.ServiceCategory == "" && override.ServiceFamily == "" {
+			return fmt.Errorf("overrides[%d]: override for %s changes no field", i, override.ServiceName)
+		}
+		if override.ServiceCategory != "" {
+			if err := validateServiceCategory(override.ServiceCategory); err != nil {
+				return fmt.Errorf("overrides[%d]: %w", i, err)
+			}
+		}
+	}
+
+	for i, exclusion := range c.Exclusions {
+		if err := validateMappingProvider(exclusion.Provider); err != nil {
+			return fmt.Errorf("exclusions[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// validateMappingProvider checks that a mapping refers to a supported provider
+func validateMappingProvider(provider string) error {
+	if provider != database.ProviderAWS && provider != database.ProviderAzure {
+		return fmt.Errorf("unsupported provider %q", provider)
+	}
+	return nil
+}
+
+// validateServiceCategory checks that a category is one of the normalized service categories
+func validateServiceCategory(category string) error {
+	for _, known := range serviceCategories {
+		if category == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown service category %q", category)
+}
+
+// ServiceMappings returns the declared service mappings with overrides applied
+func (c *MappingConfig) ServiceMappings() []database.ServiceMapping {
+	mappings := make([]database.ServiceMapping, len(c.Services))
+	for i, service := range c.Services {
+		mappings[i] = database.ServiceMapping{
+			Provider:              service.Provider,
+			ProviderServiceName:   service.ServiceName,
+			ProviderServiceCode:   optionalDimension(service.ServiceCode),
+			NormalizedServiceType: service.NormalizedServiceType,
+			ServiceCategory:       service.ServiceCategory,
+			ServiceFamily:         service.ServiceFamily,
+		}
+		c.ApplyOverrides(&mappings[i])
+	}
+	return mappings
+}
+
+// ApplyOverrides applies the overrides for a service mapping's provider service. It reports
+// whether any override matched.
+func (c *MappingConfig) ApplyOverrides(mapping *database.ServiceMapping) bool {
+	matched := false
+	for _, override := range c.Overrides {
+		if override.Provider != mapping.Provider || override.ServiceName != mapping.ProviderServiceName {
+			continue
+		}
+		if override.NormalizedServiceType != "" {
+			mapping.NormalizedServiceType = override.NormalizedServiceType
+		}
+		if override.ServiceCategory != "" {
+			mapping.ServiceCategory = override.ServiceCategory
+		}
+		if override.ServiceFamily != "" {
+			mapping.ServiceFamily = override.ServiceFamily
+		}
+		matched = true
+	}
+	return matched
+}
+
+// RegionMappings returns the declared region mappings
+func (c *MappingConfig) RegionMappings() []database.NormalizedRegion {
+	regions := make([]database.NormalizedRegion, len(c.Regions))
+	for i, region := range c.Regions {
+		regions[i] = database.NormalizedRegion{
+			NormalizedCode: region.NormalizedCode,
+			AWSRegion:      optionalDimension(region.AWSRegion),
+			AzureRegion:    optionalDimension(region.AzureRegion),
+			DisplayName:    region.DisplayName,
+			Country:        optionalDimension(region.Country),
+			Continent:      optionalDimension(region.Continent),
+		}
+	}
+	return regions
+}
+
+// IsExcluded reports whether raw pricing for a provider service and region matches an
+// exclusion rule
+func (c *MappingConfig) IsExcluded(provider, service, region string) bool {
+	for _, exclusion := range c.Exclusions {
+		if exclusion.Provider != provider {
+			continue
+		}
+		if exclusion.Service != "" && exclusion.Service != service {
+			continue
+		}
+		if exclusion.Region != "" && exclusion.Region != region {
+			continue
+		}
+		return true
+	}
+	return false
+}
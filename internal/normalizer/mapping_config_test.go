@@ -0,0 +1,260 @@
+package normalizer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMappingConfigYAML = `version: 1
+services:
+  - provider: aws
+    service_name: Amazon EC2
+    service_code: AmazonEC2
+    normalized_service_type: Virtual Machines
+    service_category: Compute & Web
+    service_family: Virtual Machines
+regions:
+  - normalized_code: us-east
+    aws_region: us-east-1
+    azure_region: eastus
+    display_name: US East (Virginia)
+overrides:
+  - provider: aws
+    service_name: Amazon EC2
+    service_family: Compute Instances
+  - provider: azure
+    service_name: Virtual Machines
+    normalized_service_type: Azure VMs
+exclusions:
+  - provider: aws
+    service: AWSDataTransfer
+  - provider: azure
+    region: global
+`
+
+func writeMappingConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadMappingConfig(t *testing.T) {
+	t.Run("YAML", func(t *testing.T) {
+		config, err := LoadMappingConfig(writeMappingConfig(t, "mappings.yaml", testMappingConfigYAML))
+		require.NoError(t, err)
+
+		services := config.ServiceMappings()
+		require.Len(t, services, 1)
+		assert.Equal(t, "Amazon EC2", services[0].ProviderServiceName)
+		assert.Equal(t, stringPtr("AmazonEC2"), services[0].ProviderServiceCode)
+		assert.Equal(t, "Compute Instances", services[0].ServiceFamily)
+		assert.Equal(t, "Virtual Machines", services[0].NormalizedServiceType)
+
+		regions := config.RegionMappings()
+		require.Len(t, regions, 1)
+		assert.Equal(t, stringPtr("us-east-1"), regions[0].AWSRegion)
+		assert.Equal(t, stringPtr("eastus"), regions[0].AzureRegion)
+		assert.Nil(t, regions[0].Country)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		config, err := LoadMappingConfig(writeMappingConfig(t, "mappings.json", `{
+			"version": 1,
+			"services": [{"provider": "azure", "service_name": "Virtual Machines",
+				"normalized_service_type": "Virtual Machines", "service_category": "Compute & Web",
+				"service_family": "Virtual Machines"}],
+			"regions": []
+		}`))
+		require.NoError(t, err)
+		assert.Len(t, config.Services, 1)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := LoadMappingConfig(writeMappingConfig(t, "mappings.yaml", "version: 1\nservice: []\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		_, err := LoadMappingConfig(writeMappingConfig(t, "mappings.toml", "version = 1"))
+		assert.Error(t, err)
+	})
+}
+
+func TestMappingConfig_Validate(t *testing.T) {
+	validService := ServiceMappingConfig{
+		Provider:              database.ProviderAWS,
+		ServiceName:           "Amazon EC2",
+		NormalizedServiceType: "Virtual Machines",
+		ServiceCategory:       database.CategoryComputeWeb,
+		ServiceFamily:         "Virtual Machines",
+	}
+	validRegion := RegionMappingConfig{NormalizedCode: "us-east", AWSRegion: "us-east-1", DisplayName: "US East"}
+
+	tests := []struct {
+		name        string
+		config      MappingConfig
+		expectError bool
+	}{
+		{
+			name:   "valid",
+			config: MappingConfig{Version: 1, Services: []ServiceMappingConfig{validService}, Regions: []RegionMappingConfig{validRegion}},
+		},
+		{
+			name:        "unsupported version",
+			config:      MappingConfig{Version: 2},
+			expectError: true,
+		},
+		{
+			name: "unsupported provider",
+			config: MappingConfig{Version: 1, Services: []ServiceMappingConfig{func() ServiceMappingConfig {
+				service := validService
+				service.Provider = "gcp"
+				return service
+			}()}},
+			expectError: true,
+		},
+		{
+			name: "unknown category",
+			config: MappingConfig{Version: 1, Services: []ServiceMappingConfig{func() ServiceMappingConfig {
+				service := validService
+				service.ServiceCategory = "Compute"
+				return service
+			}()}},
+			expectError: true,
+		},
+		{
+			name:        "duplicate service",
+			config:      MappingConfig{Version: 1, Services: []ServiceMappingConfig{validService, validService}},
+			expectError: true,
+		},
+		{
+			name:        "region without provider region",
+			config:      MappingConfig{Version: 1, Regions: []RegionMappingConfig{{NormalizedCode: "us-east", DisplayName: "US East"}}},
+			expectError: true,
+		},
+		{
+			name: "provider region mapped twice",
+			config: MappingConfig{Version: 1, Regions: []RegionMappingConfig{
+				validRegion,
+				{NormalizedCode: "us-east-alt", AWSRegion: "us-east-1", DisplayName: "US East Alt"},
+			}},
+			expectError: true,
+		},
+		{
+			name: "empty override",
+			config: MappingConfig{Version: 1, Overrides: []ServiceMappingOverride{
+				{Provider: database.ProviderAWS, ServiceName: "Amazon EC2"},
+			}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMappingConfig_IsExcluded(t *testing.T) {
+	config := MappingConfig{Exclusions: []MappingExclusion{
+		{Provider: database.ProviderAWS, Service: "AWSDataTransfer"},
+		{Provider: database.ProviderAzure, Region: "global"},
+		{Provider: database.ProviderAzure, Service: "Bandwidth", Region: "eastus"},
+	}}
+
+	assert.True(t, config.IsExcluded(database.ProviderAWS, "AWSDataTransfer", "us-east-1"))
+	assert.False(t, config.IsExcluded(database.ProviderAWS, "AmazonEC2", "us-east-1"))
+	assert.True(t, config.IsExcluded(database.ProviderAzure, "Virtual Machines", "global"))
+	assert.True(t, config.IsExcluded(database.ProviderAzure, "Bandwidth", "eastus"))
+	assert.False(t, config.IsExcluded(database.ProviderAzure, "Bandwidth", "westus"))
+	assert.False(t, config.IsExcluded(database.ProviderAWS, "AWSDataTransfer-other", "global"))
+}
+
+// mockMappingStore records synced mappings
+type mockMappingStore struct {
+	stored   []database.ServiceMapping
+	services []database.ServiceMapping
+	regions  []database.NormalizedRegion
+}
+
+func (m *mockMappingStore) GetServiceMappings() ([]database.ServiceMapping, error) {
+	return m.stored, nil
+}
+
+func (m *mockMappingStore) SyncMappings(ctx context.Context, services []database.ServiceMapping, regions []database.NormalizedRegion) (*database.MappingSyncResult, error) {
+	m.services = services
+	m.regions = regions
+	return &database.MappingSyncResult{ServiceMappings: len(services), Regions: len(regions)}, nil
+}
+
+// mockCache counts cache invalidations
+type mockCache struct {
+	invalidations int
+}
+
+func (m *mockCache) InvalidateCache() {
+	m.invalidations++
+}
+
+func TestMappingConfigLoader_Load(t *testing.T) {
+	path := writeMappingConfig(t, "mappings.yaml", testMappingConfigYAML)
+	store := &mockMappingStore{stored: []database.ServiceMapping{
+		{Provider: database.ProviderAzure, ProviderServiceName: "Virtual Machines", NormalizedServiceType: "Virtual Machines",
+			ServiceCategory: database.CategoryComputeWeb, ServiceFamily: "Virtual Machines"},
+		{Provider: database.ProviderAzure, ProviderServiceName: "Functions", NormalizedServiceType: "Serverless Functions",
+			ServiceCategory: database.CategoryComputeWeb, ServiceFamily: "Serverless"},
+	}}
+	cache := &mockCache{}
+	loader := NewMappingConfigLoader(path, store, NewMockLogger(), cache)
+
+	assert.False(t, loader.IsExcluded(database.ProviderAWS, "AWSDataTransfer", "us-east-1"))
+
+	result, err := loader.Load(context.Background())
+	require.NoError(t, err)
+
+	// The stored Azure VM mapping is patched by its override; the untouched one is not synced
+	assert.Equal(t, 2, result.ServiceMappings)
+	require.Len(t, store.services, 2)
+	assert.Equal(t, "Azure VMs", store.services[1].NormalizedServiceType)
+	assert.Len(t, store.regions, 1)
+	assert.Equal(t, 1, cache.invalidations)
+	assert.True(t, loader.IsExcluded(database.ProviderAWS, "AWSDataTransfer", "us-east-1"))
+
+	// An invalid file keeps the previous configuration
+	require.NoError(t, os.WriteFile(path, []byte("version: 2\n"), 0o644))
+	_, err = loader.Load(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 1, cache.invalidations)
+	assert.True(t, loader.IsExcluded(database.ProviderAWS, "AWSDataTransfer", "us-east-1"))
+}
+
+func TestAzureNormalizerV2_SkipsExcludedInput(t *testing.T) {
+	normalizer := createTestAzureNormalizerV2()
+	normalizer.SetMappingExclusions(&MappingConfig{Exclusions: []MappingExclusion{
+		{Provider: database.ProviderAzure, Region: "global"},
+	}})
+
+	result, err := normalizer.NormalizePricing(context.Background(), database.NormalizationInput{
+		Provider:    database.ProviderAzure,
+		ServiceCode: "Virtual Machines",
+		Region:      "global",
+		RawDataID:   1,
+		RawData:     []byte(`{}`),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.SkippedCount)
+	assert.Contains(t, result.Errors, "excluded by mapping configuration")
+}
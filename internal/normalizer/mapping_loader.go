@@ -0,0 +1,176 @@
+package normalizer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+)
+
+// MappingStore persists the mappings of a mapping configuration
+type MappingStore interface {
+	GetServiceMappings() ([]database.ServiceMapping, error)
+	SyncMappings(ctx context.Context, services []database.ServiceMapping, regions []database.NormalizedRegion) (*database.MappingSyncResult, error)
+}
+
+// CacheInvalidator is implemented by repositories that cache mappings
+type CacheInvalidator interface {
+	InvalidateCache()
+}
+
+// MappingExclusions decides whether raw pricing is excluded from normalization
+type MappingExclusions interface {
+	IsExcluded(provider, service, region string) bool
+}
+
+// MappingConfigLoader loads a mapping configuration file, syncs it to the mapping tables and
+// invalidates repository caches. Watch reloads the file whenever it changes; if a reload
+// fails, the previously loaded configuration stays in effect until the file changes again.
+type MappingConfigLoader struct {
+	path    string
+	store   MappingStore
+	caches  []CacheInvalidator
+	logger  Logger
+	config  atomic.Pointer[MappingConfig]
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+// NewMappingConfigLoader creates a loader for a YAML or JSON mapping configuration file
+func NewMappingConfigLoader(path string, store MappingStore, logger Logger, caches ...CacheInvalidator) *MappingConfigLoader {
+	return &MappingConfigLoader{
+		path:   path,
+		store:  store,
+		caches: caches,
+		logger: logger,
+	}
+}
+
+// Config returns the currently loaded configuration, or nil before the first successful load
+func (l *MappingConfigLoader) Config() *MappingConfig {
+	return l.config.Load()
+}
+
+// IsExcluded reports whether the loaded configuration excludes raw pricing for a provider
+// service and region
+func (l *MappingConfigLoader) IsExcluded(provider, service, region string) bool {
+	config := l.config.Load()
+	return config != nil && config.IsExcluded(provider, service, region)
+}
+
+// Load reads, validates and syncs the configuration file, then invalidates repository caches
+func (l *MappingConfigLoader) Load(ctx context.Context) (*database.MappingSyncResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping config: %w", err)
+	}
+	l.modTime = info.ModTime()
+	l.size = info.Size()
+
+	config, err := LoadMappingConfig(l.path)
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := l.resolveServiceMappings(config)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := l.store.SyncMappings(ctx, services, config.RegionMappings())
+	if err != nil {
+		return nil, err
+	}
+
+	l.config.Store(config)
+	for _, cache := range l.caches {
+		cache.InvalidateCache()
+	}
+
+	l.logger.Info("Loaded mapping config",
+		Field{"path", l.path},
+		Field{"version", config.Version},
+		Field{"serviceMappings", result.ServiceMappings},
+		Field{"regions", result.Regions},
+		Field{"exclusions", len(config.Exclusions)},
+	)
+	return result, nil
+}
+
+// resolveServiceMappings returns the declared service mappings plus stored mappings that are
+// patched by an override without being declared in the file
+func (l *MappingConfigLoader) resolveServiceMappings(config *MappingConfig) ([]database.ServiceMapping, error) {
+	services := config.ServiceMappings()
+	if len(config.Overrides) == 0 {
+		return services, nil
+	}
+
+	declared := make(map[string]bool, len(services))
+	for _, service := range services {
+		declared[service.Provider+":"+service.ProviderServiceName] = true
+	}
+
+	stored, err := l.store.GetServiceMappings()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stored service mappings: %w", err)
+	}
+	for _, mapping := range stored {
+		if declared[mapping.Provider+":"+mapping.ProviderServiceName] {
+			continue
+		}
+		if config.ApplyOverrides(&mapping) {
+			services = append(services, mapping)
+		}
+	}
+
+	return services, nil
+}
+
+// changed reports whether the configuration file was modified since it was last loaded
+func (l *MappingConfigLoader) changed() (bool, error) {
+	info, err := os.Stat(l.path)
+	if err != nil {
+		return false, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return !info.ModTime().Equal(l.modTime) || info.Size() != l.size, nil
+}
+
+// Watch polls the configuration file at the given interval and reloads it when it changes,
+// until the context is cancelled
+func (l *MappingConfigLoader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := l.changed()
+			if err != nil {
+				l.logger.Warn("Failed to check mapping config", Field{"path", l.path}, Field{"error", err})
+				continue
+			}
+			if !changed {
+				continue
+			}
+			if _, err := l.Load(ctx); err != nil {
+				l.logger.Error("Failed to reload mapping config, keeping previous mappings",
+					Field{"path", l.path},
+					Field{"error", err},
+				)
+			}
+		}
+	}
+}
@@ -121,6 +121,17 @@ func (m *MockUnitNormalizer) NormalizeUnit(provider, originalUnit string) string
 	return originalUnit
 }
 
+// ScaleUnit mock implementation - maps the unit without rescaling
+func (m *MockUnitNormalizer) ScaleUnit(provider, originalUnit string) ScaledUnit {
+	return ScaledUnit{
+		Unit:       m.NormalizeUnit(provider, originalUnit),
+		Scale:      1,
+		Quantity:   1,
+		Multiplier: 1,
+		Original:   originalUnit,
+	}
+}
+
 // AddMapping adds a unit mapping
 func (m *MockUnitNormalizer) AddMapping(provider, originalUnit, normalizedUnit string) {
 	key := fmt.Sprintf("%s:%s", provider, originalUnit)
@@ -0,0 +1,123 @@
+package normalizer
+
+import (
+	"strings"
+
+	"github.com/raulc0399/cpc/internal/database"
+)
+
+// PlatformDimensions holds the normalized software and placement dimensions of a price.
+// Unset dimensions do not apply to the service.
+type PlatformDimensions struct {
+	OperatingSystem      *string
+	LicenseModel         *string
+	PreInstalledSoftware *string
+	Tenancy              *string
+}
+
+// Apply sets the dimensions on a normalized record
+func (d PlatformDimensions) Apply(record *database.NormalizedPricing) {
+	record.OperatingSystem = d.OperatingSystem
+	record.LicenseModel = d.LicenseModel
+	record.PreInstalledSoftware = d.PreInstalledSoftware
+	record.Tenancy = d.Tenancy
+}
+
+// runsLicensedSoftware reports whether the price of a platform covers software licenses: an
+// operating system other than Linux, or pre-installed software such as SQL Server
+func (d PlatformDimensions) runsLicensedSoftware() bool {
+	if d.OperatingSystem != nil && *d.OperatingSystem != database.OSLinux {
+		return true
+	}
+	return d.PreInstalledSoftware != nil && *d.PreInstalledSoftware != database.SoftwareNone
+}
+
+// normalizeOperatingSystem maps provider operating system names (e.g., "RHEL",
+// "Red Hat Enterprise Linux with HA", "Windows") to normalized values
+func normalizeOperatingSystem(value string) string {
+	os := strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case os == "" || os == "na":
+		return ""
+	case strings.Contains(os, "windows"):
+		return database.OSWindows
+	case strings.Contains(os, "red hat") || strings.HasPrefix(os, "rhel"):
+		if strings.HasSuffix(os, "with ha") {
+			return database.OSRHELHA
+		}
+		return database.OSRHEL
+	case strings.Contains(os, "suse"):
+		return database.OSSUSE
+	case strings.Contains(os, "ubuntu pro"):
+		return database.OSUbuntuPro
+	case strings.Contains(os, "linux"):
+		return database.OSLinux
+	}
+	return normalizeDimensionValue(os)
+}
+
+// normalizeLicenseModel maps provider license models (e.g., "Bring your own license",
+// "No License required") to normalized values
+func normalizeLicenseModel(value string) string {
+	license := strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case license == "" || license == "na":
+		return ""
+	case strings.Contains(license, "bring your own") || license == "byol":
+		return database.LicenseModelBYOL
+	case strings.Contains(license, "no license"):
+		return database.LicenseModelNone
+	case strings.Contains(license, "license included"):
+		return database.LicenseModelIncluded
+	}
+	return normalizeDimensionValue(license)
+}
+
+// normalizePreInstalledSoftware maps pre-installed software (e.g., "SQL Std", "NA") to
+// normalized values. "NA" means no software, as opposed to an empty value.
+func normalizePreInstalledSoftware(value string) string {
+	software := strings.ToLower(strings.TrimSpace(value))
+	switch software {
+	case "":
+		return ""
+	case "na", "none":
+		return database.SoftwareNone
+	case "sql web":
+		return database.SoftwareSQLWeb
+	case "sql std", "sql standard":
+		return database.SoftwareSQLStandard
+	case "sql ent", "sql enterprise":
+		return database.SoftwareSQLEnterprise
+	}
+	return normalizeDimensionValue(software)
+}
+
+// normalizeTenancy maps provider tenancies (e.g., "Shared", "Dedicated", "Host") to normalized values
+func normalizeTenancy(value string) string {
+	tenancy := strings.ToLower(strings.TrimSpace(value))
+	switch tenancy {
+	case "", "na":
+		return ""
+	case "shared", "default":
+		return database.TenancyShared
+	case "dedicated", "reserved":
+		return database.TenancyDedicated
+	case "host":
+		return database.TenancyHost
+	}
+	return normalizeDimensionValue(tenancy)
+}
+
+// normalizeDimensionValue converts an unrecognized dimension value to snake case so it still
+// groups consistently
+func normalizeDimensionValue(value string) string {
+	return strings.Join(strings.Fields(strings.ToLower(value)), "_")
+}
+
+// optionalDimension returns nil for an empty dimension value
+func optionalDimension(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,72 @@
+package normalizer
+
+import (
+	"testing"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePlatformDimensions(t *testing.T) {
+	tests := []struct {
+		name      string
+		normalize func(string) string
+		input     string
+		expected  string
+	}{
+		{"Linux", normalizeOperatingSystem, "Linux", database.OSLinux},
+		{"Windows", normalizeOperatingSystem, "Windows", database.OSWindows},
+		{"RHEL", normalizeOperatingSystem, "RHEL", database.OSRHEL},
+		{"Red Hat with HA", normalizeOperatingSystem, "Red Hat Enterprise Linux with HA", database.OSRHELHA},
+		{"SUSE", normalizeOperatingSystem, "SUSE", database.OSSUSE},
+		{"Ubuntu Pro", normalizeOperatingSystem, "Ubuntu Pro", database.OSUbuntuPro},
+		{"unknown OS", normalizeOperatingSystem, "Free BSD", "free_bsd"},
+		{"no OS", normalizeOperatingSystem, "NA", ""},
+		{"BYOL", normalizeLicenseModel, "Bring your own license", database.LicenseModelBYOL},
+		{"no license", normalizeLicenseModel, "No License required", database.LicenseModelNone},
+		{"license included", normalizeLicenseModel, "License included", database.LicenseModelIncluded},
+		{"no software", normalizePreInstalledSoftware, "NA", database.SoftwareNone},
+		{"SQL Web", normalizePreInstalledSoftware, "SQL Web", database.SoftwareSQLWeb},
+		{"SQL Enterprise", normalizePreInstalledSoftware, "SQL Ent", database.SoftwareSQLEnterprise},
+		{"empty software", normalizePreInstalledSoftware, "", ""},
+		{"shared", normalizeTenancy, "Shared", database.TenancyShared},
+		{"dedicated", normalizeTenancy, "Dedicated", database.TenancyDedicated},
+		{"host", normalizeTenancy, "Host", database.TenancyHost},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.normalize(tt.input))
+		})
+	}
+}
+
+func TestPlatformDimensions_WindowsOffersAreComparableAcrossProviders(t *testing.T) {
+	aws := createTestAWSNormalizerV2().extractPlatformDimensions(map[string]interface{}{
+		"operatingSystem": "Windows",
+		"licenseModel":    "No License required",
+		"preInstalledSw":  "NA",
+		"tenancy":         "Shared",
+	})
+	azure := createTestAzureNormalizerV2().extractPlatformDimensions(
+		&AzurePricing{ProductName: "Virtual Machines Dv5 Series Windows"}, "Virtual Machines")
+
+	vcpu, memoryGB, architecture := 4, 16.0, "x86_64"
+	specs := database.ResourceSpecs{VCPU: &vcpu, MemoryGB: &memoryGB, Architecture: &architecture}
+	instance := database.NormalizedPricing{
+		ID: 1, Provider: database.ProviderAWS, ResourceName: "m5.xlarge", Unit: database.UnitHour,
+		PricePerUnit: 0.376, ResourceSpecs: specs,
+	}
+	aws.Apply(&instance)
+	candidate := database.NormalizedPricing{
+		ID: 2, Provider: database.ProviderAzure, ResourceName: "D4s v5", Unit: database.UnitHour,
+		PricePerUnit: 0.376, ResourceSpecs: specs,
+	}
+	azure.Apply(&candidate)
+
+	assert.Equal(t, instance.PlatformKey(), candidate.PlatformKey())
+	matches := database.MatchInstances(instance, []database.NormalizedPricing{candidate}, database.DefaultMatchingConfig())
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, 2, matches[0].Pricing.ID)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/raulc0399/cpc/internal/database"
@@ -11,9 +12,10 @@ import (
 
 // ServiceMappingRepositoryImpl implements ServiceMappingRepository using database
 type ServiceMappingRepositoryImpl struct {
-	db     *database.DB
-	cache  map[string]*database.ServiceMapping
-	logger Logger
+	db      *database.DB
+	cacheMu sync.RWMutex
+	cache   map[string]*database.ServiceMapping
+	logger  Logger
 }
 
 // NewServiceMappingRepository creates a new service mapping repository
@@ -29,7 +31,10 @@ func NewServiceMappingRepository(db *database.DB, logger Logger) *ServiceMapping
 func (r *ServiceMappingRepositoryImpl) GetServiceMappingByProvider(ctx context.Context, provider, serviceName string) (*database.ServiceMapping, error) {
 	// Check cache first
 	cacheKey := fmt.Sprintf("%s:%s", provider, serviceName)
-	if mapping, exists := r.cache[cacheKey]; exists {
+	r.cacheMu.RLock()
+	mapping, exists := r.cache[cacheKey]
+	r.cacheMu.RUnlock()
+	if exists {
 		r.logger.Debug("Service mapping cache hit",
 			Field{"provider", provider},
 			Field{"service", serviceName},
@@ -45,7 +50,9 @@ func (r *ServiceMappingRepositoryImpl) GetServiceMappingByProvider(ctx context.C
 
 	// Cache the result
 	if mapping != nil {
+		r.cacheMu.Lock()
 		r.cache[cacheKey] = mapping
+		r.cacheMu.Unlock()
 	}
 
 	return mapping, nil
@@ -100,19 +107,29 @@ func (r *ServiceMappingRepositoryImpl) GetAllServiceMappings(ctx context.Context
 	}
 
 	// Populate cache
+	r.cacheMu.Lock()
 	for i := range mappings {
 		cacheKey := fmt.Sprintf("%s:%s", mappings[i].Provider, mappings[i].ProviderServiceName)
 		r.cache[cacheKey] = &mappings[i]
 	}
+	r.cacheMu.Unlock()
 
 	return mappings, nil
 }
 
+// InvalidateCache drops all cached service mappings so they are reloaded from the database
+func (r *ServiceMappingRepositoryImpl) InvalidateCache() {
+	r.cacheMu.Lock()
+	r.cache = make(map[string]*database.ServiceMapping)
+	r.cacheMu.Unlock()
+}
+
 // RegionMappingRepositoryImpl implements RegionMappingRepository using database
 type RegionMappingRepositoryImpl struct {
-	db     *database.DB
-	cache  map[string]*database.NormalizedRegion
-	logger Logger
+	db      *database.DB
+	cacheMu sync.RWMutex
+	cache   map[string]*database.NormalizedRegion
+	logger  Logger
 }
 
 // NewRegionMappingRepository creates a new region mapping repository
@@ -128,7 +145,10 @@ func NewRegionMappingRepository(db *database.DB, logger Logger) *RegionMappingRe
 func (r *RegionMappingRepositoryImpl) GetNormalizedRegionByProvider(ctx context.Context, provider, providerRegion string) (*database.NormalizedRegion, error) {
 	// Check cache first
 	cacheKey := fmt.Sprintf("%s:%s", provider, providerRegion)
-	if region, exists := r.cache[cacheKey]; exists {
+	r.cacheMu.RLock()
+	region, exists := r.cache[cacheKey]
+	r.cacheMu.RUnlock()
+	if exists {
 		r.logger.Debug("Region mapping cache hit",
 			Field{"provider", provider},
 			Field{"region", providerRegion},
@@ -144,7 +164,9 @@ func (r *RegionMappingRepositoryImpl) GetNormalizedRegionByProvider(ctx context.
 
 	// Cache the result
 	if region != nil {
+		r.cacheMu.Lock()
 		r.cache[cacheKey] = region
+		r.cacheMu.Unlock()
 	}
 
 	return region, nil
@@ -211,6 +233,8 @@ func (r *RegionMappingRepositoryImpl) GetAllNormalizedRegions(ctx context.Contex
 	}
 
 	// Populate cache
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
 	for i := range regions {
 		if regions[i].AWSRegion != nil {
 			cacheKey := fmt.Sprintf("%s:%s", database.ProviderAWS, *regions[i].AWSRegion)
@@ -225,6 +249,13 @@ func (r *RegionMappingRepositoryImpl) GetAllNormalizedRegions(ctx context.Contex
 	return regions, nil
 }
 
+// InvalidateCache drops all cached regions so they are reloaded from the database
+func (r *RegionMappingRepositoryImpl) InvalidateCache() {
+	r.cacheMu.Lock()
+	r.cache = make(map[string]*database.NormalizedRegion)
+	r.cacheMu.Unlock()
+}
+
 // NormalizedPricingRepository handles normalized pricing data operations
 type NormalizedPricingRepository interface {
 	Insert(ctx context.Context, pricing *database.NormalizedPricing) error
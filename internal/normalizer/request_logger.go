@@ -0,0 +1,80 @@
+package normalizer
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LogKeyRequestID is the key of the request ID propagated to handlers through the request context
+const LogKeyRequestID = "requestId"
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(data)
+	r.bytes += n
+	return n, err
+}
+
+// Flush supports streaming handlers
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// LogRequests logs every request handled by next. The request context carries a request ID
+// log field, so handlers can log with LoggerFromContext.
+func LogRequests(logger Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-ID")
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set("X-Request-ID", requestID)
+
+		ctx := ContextWithLogFields(r.Context(), Field{LogKeyRequestID, requestID})
+		recorder := &statusRecorder{ResponseWriter: w}
+		start := time.Now()
+
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+
+		fields := []Field{
+			{"method", r.Method},
+			{"path", r.URL.Path},
+			{"status", recorder.status},
+			{"bytes", recorder.bytes},
+			{"durationMs", time.Since(start).Milliseconds()},
+			{"remoteAddr", r.RemoteAddr},
+		}
+
+		requestLogger := LoggerFromContext(ctx, logger)
+		switch {
+		case recorder.status >= http.StatusInternalServerError:
+			requestLogger.Error("HTTP request", fields...)
+		case recorder.status >= http.StatusBadRequest:
+			requestLogger.Warn("HTTP request", fields...)
+		default:
+			requestLogger.Info("HTTP request", fields...)
+		}
+	})
+}
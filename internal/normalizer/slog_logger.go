@@ -0,0 +1,251 @@
+package normalizer
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Keys of the log fields propagated through contexts
+const (
+	LogKeyJobID        = "jobId"
+	LogKeyCollectionID = "collectionId"
+	LogKeyProvider     = "provider"
+	LogKeyRegion       = "region"
+)
+
+// Log output formats
+const (
+	LogFormatJSON = "json"
+	LogFormatText = "text"
+)
+
+// LogConfig configures the slog-backed logger
+type LogConfig struct {
+	Level  string
+	Format string
+	Output io.Writer
+}
+
+// LogConfigFromEnv reads LOG_LEVEL (debug, info, warn, error; default info) and LOG_FORMAT
+// (json, text; default json)
+func LogConfigFromEnv() LogConfig {
+	config := LogConfig{Level: "info", Format: LogFormatJSON}
+	if level := os.Getenv("LOG_LEVEL"); level != "" {
+		config.Level = level
+	}
+	if format := os.Getenv("LOG_FORMAT"); format != "" {
+		config.Format = format
+	}
+	return config
+}
+
+// RegisterFlags adds -log-level and -log-format flags that default to the current values
+func (c *LogConfig) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Level, "log-level", c.Level, "Log level: debug, info, warn, error")
+	fs.StringVar(&c.Format, "log-format", c.Format, "Log format: json, text")
+}
+
+// parseLogLevel converts a level name to a slog level
+func parseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unsupported log level: %s", level)
+	}
+}
+
+// NewSlogHandler creates the slog handler described by the configuration
+func (c LogConfig) NewSlogHandler() (slog.Handler, error) {
+	level, err := parseLogLevel(c.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	output := c.Output
+	if output == nil {
+		output = os.Stderr
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	switch strings.ToLower(strings.TrimSpace(c.Format)) {
+	case "", LogFormatJSON:
+		return slog.NewJSONHandler(output, options), nil
+	case LogFormatText:
+		return slog.NewTextHandler(output, options), nil
+	default:
+		return nil, fmt.Errorf("unsupported log format: %s", c.Format)
+	}
+}
+
+// SetupLogging builds the logger described by the configuration and installs it as the
+// process-wide slog default, which also routes the standard log package through it
+func SetupLogging(config LogConfig) (*SlogLogger, error) {
+	handler, err := config.NewSlogHandler()
+	if err != nil {
+		return nil, err
+	}
+
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+	return NewSlogLogger(logger), nil
+}
+
+// SlogLogger implements Logger on top of log/slog
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger wraps a slog logger; a nil logger uses the slog default
+func NewSlogLogger(logger *slog.Logger) *SlogLogger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogLogger{logger: logger}
+}
+
+// NewDefaultLogger creates a logger writing to the process-wide slog default
+func NewDefaultLogger() *SlogLogger {
+	return NewSlogLogger(nil)
+}
+
+// Slog returns the underlying slog logger
+func (l *SlogLogger) Slog() *slog.Logger {
+	return l.logger
+}
+
+// Debug logs a debug message
+func (l *SlogLogger) Debug(msg string, fields ...Field) {
+	l.log(slog.LevelDebug, msg, fields)
+}
+
+// Info logs an info message
+func (l *SlogLogger) Info(msg string, fields ...Field) {
+	l.log(slog.LevelInfo, msg, fields)
+}
+
+// Warn logs a warning message
+func (l *SlogLogger) Warn(msg string, fields ...Field) {
+	l.log(slog.LevelWarn, msg, fields)
+}
+
+// Error logs an error message
+func (l *SlogLogger) Error(msg string, fields ...Field) {
+	l.log(slog.LevelError, msg, fields)
+}
+
+// With returns a logger that adds the fields to every message
+func (l *SlogLogger) With(fields ...Field) Logger {
+	if len(fields) == 0 {
+		return l
+	}
+	return &SlogLogger{logger: l.logger.With(slogAttrs(fields)...)}
+}
+
+func (l *SlogLogger) log(level slog.Level, msg string, fields []Field) {
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+	l.logger.Log(ctx, level, msg, slogAttrs(fields)...)
+}
+
+// slogAttrs converts log fields to slog attributes. Errors are logged by message, since the
+// JSON handler would encode most error values as empty objects.
+func slogAttrs(fields []Field) []any {
+	attrs := make([]any, len(fields))
+	for i, field := range fields {
+		if err, ok := field.Value.(error); ok {
+			attrs[i] = slog.String(field.Key, err.Error())
+			continue
+		}
+		attrs[i] = slog.Any(field.Key, field.Value)
+	}
+	return attrs
+}
+
+// fieldLogger adds fields to the messages of a logger that cannot bind fields itself
+type fieldLogger struct {
+	logger Logger
+	fields []Field
+}
+
+func (l *fieldLogger) Debug(msg string, fields ...Field) {
+	l.logger.Debug(msg, append(l.fields[:len(l.fields):len(l.fields)], fields...)...)
+}
+
+func (l *fieldLogger) Info(msg string, fields ...Field) {
+	l.logger.Info(msg, append(l.fields[:len(l.fields):len(l.fields)], fields...)...)
+}
+
+func (l *fieldLogger) Warn(msg string, fields ...Field) {
+	l.logger.Warn(msg, append(l.fields[:len(l.fields):len(l.fields)], fields...)...)
+}
+
+func (l *fieldLogger) Error(msg string, fields ...Field) {
+	l.logger.Error(msg, append(l.fields[:len(l.fields):len(l.fields)], fields...)...)
+}
+
+func (l *fieldLogger) With(fields ...Field) Logger {
+	return &fieldLogger{logger: l.logger, fields: append(l.fields[:len(l.fields):len(l.fields)], fields...)}
+}
+
+// WithFields returns a logger that adds the fields to every message
+func WithFields(logger Logger, fields ...Field) Logger {
+	if len(fields) == 0 {
+		return logger
+	}
+	if binder, ok := logger.(interface{ With(...Field) Logger }); ok {
+		return binder.With(fields...)
+	}
+	return &fieldLogger{logger: logger, fields: fields}
+}
+
+type logFieldsKey struct{}
+
+// ContextWithLogFields returns a context carrying log fields, such as the job ID, collection
+// ID, provider and region of the work it belongs to. A field replaces an earlier field of the
+// same key.
+func ContextWithLogFields(ctx context.Context, fields ...Field) context.Context {
+	existing := LogFieldsFromContext(ctx)
+	merged := make([]Field, 0, len(existing)+len(fields))
+	for _, field := range existing {
+		replaced := false
+		for _, update := range fields {
+			if update.Key == field.Key {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, field)
+		}
+	}
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, logFieldsKey{}, merged)
+}
+
+// LogFieldsFromContext returns the log fields carried by a context
+func LogFieldsFromContext(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(logFieldsKey{}).([]Field)
+	return fields
+}
+
+// LoggerFromContext returns the logger with the log fields carried by the context
+func LoggerFromContext(ctx context.Context, logger Logger) Logger {
+	return WithFields(logger, LogFieldsFromContext(ctx)...)
+}
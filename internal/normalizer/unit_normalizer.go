@@ -115,6 +115,37 @@ func (n *StandardUnitNormalizer) normalizeGenericUnit(unit string) string {
 		"transactions": database.UnitTransaction,
 		"instance":     database.UnitInstance,
 		"instances":    database.UnitInstance,
+		"second":       "second",
+		"seconds":      "second",
+		"minute":       "minute",
+		"minutes":      "minute",
+		"day":          "day",
+		"days":         "day",
+		"month":        "month",
+		"months":       "month",
+		"year":         "year",
+		"years":        "year",
+		"mb":           "mb",
+		"gib":          database.UnitGB,
+		"gb/month":     database.UnitGBMonth,
+		"gib/month":    database.UnitGBMonth,
+		"tb/month":     "tb_month",
+		"tb-mo":        "tb_month",
+		"gb/hour":      "gb_hour",
+		"gib/hour":     "gb_hour",
+		"gb hour":      "gb_hour",
+		"gib hour":     "gb_hour",
+		"gb-hour":      "gb_hour",
+		"gb second":    "gb_second",
+		"gib second":   "gb_second",
+		"gb-second":    "gb_second",
+		"vcpu second":  "vcpu_second",
+		"vcpu seconds": "vcpu_second",
+		"vcpu-second":  "vcpu_second",
+		"vcpu hour":    "vcpu_hour",
+		"vcpu hours":   "vcpu_hour",
+		"vcpu-hours":   "vcpu_hour",
+		"count":        database.UnitCount,
 	}
 
 	if normalized, exists := genericUnitMap[unit]; exists {
@@ -125,46 +156,82 @@ func (n *StandardUnitNormalizer) normalizeGenericUnit(unit string) string {
 	return unit
 }
 
-// ConvertUnitValue converts pricing values when changing units
-func (n *StandardUnitNormalizer) ConvertUnitValue(originalUnit, targetUnit string, value float64) float64 {
-	// Handle special conversions
-	if originalUnit == "10k requests" && targetUnit == database.UnitRequest {
-		return value / 10000 // Convert per-10k-request to per-request
+// ScaledUnit is a provider unit of measure resolved to a canonical unit
+type ScaledUnit struct {
+	Unit       string  // canonical normalized unit
+	Scale      float64 // provider price * Scale = price per canonical unit
+	Quantity   float64 // provider units per canonical unit, for rescaling usage such as tier ranges
+	Multiplier float64 // quantity parsed from the provider unit, e.g. 100 for "100 Hours"
+	Original   string
+}
+
+// ScaleUnit resolves a provider unit such as "100 Hours", "10K" or "1M Requests" to the canonical
+// unit of its dimension and the factor that rescales prices to it. Unknown units keep their
+// normalized name and are only divided by the parsed multiplier.
+func (n *StandardUnitNormalizer) ScaleUnit(provider, originalUnit string) ScaledUnit {
+	uom := ParseUnitOfMeasure(originalUnit)
+
+	unit := database.UnitCount
+	if uom.BaseUnit != "" {
+		unit = n.NormalizeUnit(provider, uom.BaseUnit)
 	}
 
-	if originalUnit == "10000 requests" && targetUnit == database.UnitRequest {
-		return value / 10000 // Convert per-10k-request to per-request
+	size := 1.0
+	if definition, exists := unitDefinitions[unit]; exists {
+		unit = definition.canonical
+		size = definition.size
 	}
 
-	// Add more conversions as needed
-	return value
+	return ScaledUnit{
+		Unit:       unit,
+		Scale:      1 / (uom.Multiplier * size),
+		Quantity:   uom.Multiplier * size,
+		Multiplier: uom.Multiplier,
+		Original:   uom.Original,
+	}
 }
 
-// GetUnitCategory returns the category of a unit for grouping
-func (n *StandardUnitNormalizer) GetUnitCategory(unit string) string {
-	timeUnits := []string{database.UnitHour, "second", "minute", "vcpu_hour", "lambda_gb_second"}
-	storageUnits := []string{database.UnitGB, database.UnitTB, database.UnitGBMonth}
-	requestUnits := []string{database.UnitRequest, database.UnitMillionRequests, database.UnitTransaction}
-	resourceUnits := []string{database.UnitInstance, "compute_unit"}
+// ConvertUnitValue converts a price per originalUnit to a price per targetUnit. Units convert
+// within the same dimension (time, data, data over time, compute-seconds, requests); other
+// values are returned unchanged.
+func (n *StandardUnitNormalizer) ConvertUnitValue(originalUnit, targetUnit string, value float64) float64 {
+	original, originalKnown := n.resolveUnit(originalUnit)
+	target, targetKnown := n.resolveUnit(targetUnit)
+	if !originalKnown || !targetKnown || original.canonical != target.canonical {
+		return value
+	}
 
-	for _, timeUnit := range timeUnits {
-		if unit == timeUnit {
-			return "time"
-		}
+	return value * target.size / original.size
+}
+
+// resolveUnit resolves a normalized or provider unit, including a quantity multiplier, to its
+// size in the canonical unit of its dimension
+func (n *StandardUnitNormalizer) resolveUnit(unit string) (unitDefinition, bool) {
+	if definition, exists := unitDefinitions[unit]; exists {
+		return definition, true
 	}
 
-	for _, storageUnit := range storageUnits {
-		if unit == storageUnit {
-			return "storage"
-		}
+	uom := ParseUnitOfMeasure(unit)
+	normalized := database.UnitCount
+	if uom.BaseUnit != "" {
+		normalized = n.normalizeAWSUnit(n.normalizeAzureUnit(strings.ToLower(uom.BaseUnit)))
 	}
 
-	for _, requestUnit := range requestUnits {
-		if unit == requestUnit {
-			return "requests"
-		}
+	definition, exists := unitDefinitions[normalized]
+	if !exists {
+		return unitDefinition{}, false
 	}
+	definition.size *= uom.Multiplier
+	return definition, true
+}
 
+// GetUnitCategory returns the category of a unit for grouping
+func (n *StandardUnitNormalizer) GetUnitCategory(unit string) string {
+	if definition, exists := unitDefinitions[unit]; exists {
+		return definition.category
+	}
+
+	resourceUnits := []string{database.UnitInstance, "compute_unit"}
 	for _, resourceUnit := range resourceUnits {
 		if unit == resourceUnit {
 			return "resources"
@@ -172,4 +239,4 @@ func (n *StandardUnitNormalizer) GetUnitCategory(unit string) string {
 	}
 
 	return "other"
-}
\ No newline at end of file
+}
@@ -139,6 +139,48 @@ func TestStandardUnitNormalizer_ConvertUnitValue(t *testing.T) {
 			value:        2.0,
 			expected:     0.0002,
 		},
+		{
+			name:         "million requests to requests",
+			originalUnit: database.UnitMillionRequests,
+			targetUnit:   database.UnitRequest,
+			value:        0.2,
+			expected:     0.0000002,
+		},
+		{
+			name:         "second to hour",
+			originalUnit: "second",
+			targetUnit:   database.UnitHour,
+			value:        0.0001,
+			expected:     0.36,
+		},
+		{
+			name:         "hour to month",
+			originalUnit: database.UnitHour,
+			targetUnit:   "month",
+			value:        0.01,
+			expected:     7.3,
+		},
+		{
+			name:         "TB to GB",
+			originalUnit: database.UnitTB,
+			targetUnit:   database.UnitGB,
+			value:        10.24,
+			expected:     0.01,
+		},
+		{
+			name:         "vCPU hour to vCPU second",
+			originalUnit: "vcpu_hour",
+			targetUnit:   "vcpu_second",
+			value:        0.036,
+			expected:     0.00001,
+		},
+		{
+			name:         "different dimensions are not converted",
+			originalUnit: database.UnitHour,
+			targetUnit:   database.UnitGB,
+			value:        1.5,
+			expected:     1.5,
+		},
 		{
 			name:         "No conversion needed",
 			originalUnit: database.UnitHour,
@@ -151,7 +193,7 @@ func TestStandardUnitNormalizer_ConvertUnitValue(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := normalizer.ConvertUnitValue(tt.originalUnit, tt.targetUnit, tt.value)
-			assert.Equal(t, tt.expected, result)
+			assert.InDelta(t, tt.expected, result, 1e-12)
 		})
 	}
 }
@@ -192,13 +234,28 @@ func TestStandardUnitNormalizer_GetUnitCategory(t *testing.T) {
 		{
 			name:     "Lambda GB-second",
 			unit:     "lambda_gb_second",
-			expected: "time",
+			expected: "compute_seconds",
 		},
 		{
 			name:     "vCPU hour",
 			unit:     "vcpu_hour",
+			expected: "compute_seconds",
+		},
+		{
+			name:     "Month",
+			unit:     "month",
 			expected: "time",
 		},
+		{
+			name:     "GB-month",
+			unit:     database.UnitGBMonth,
+			expected: "storage",
+		},
+		{
+			name:     "Million requests",
+			unit:     database.UnitMillionRequests,
+			expected: "requests",
+		},
 	}
 
 	for _, tt := range tests {
@@ -255,4 +312,69 @@ func TestAzureUnitNormalization(t *testing.T) {
 			assert.Equal(t, expectedUnit, result, "Failed to normalize Azure unit: %s", originalUnit)
 		})
 	}
-}
\ No newline at end of file
+}
+func TestParseUnitOfMeasure(t *testing.T) {
+	tests := []struct {
+		unit               string
+		expectedMultiplier float64
+		expectedBaseUnit   string
+	}{
+		{"1 Hour", 1, "Hour"},
+		{"100 Hours", 100, "Hours"},
+		{"10K", 10000, ""},
+		{"1M", 1000000, ""},
+		{"1/Month", 1, "Month"},
+		{"10K/Month", 10000, "Month"},
+		{"100 GB/Month", 100, "GB/Month"},
+		{"1M requests", 1000000, "requests"},
+		{"1 MB", 1, "MB"},
+		{"1 GiB Hour", 1, "GiB Hour"},
+		{"100", 100, ""},
+		{"Hrs", 1, "Hrs"},
+		{"Lambda-GB-Second", 1, "Lambda-GB-Second"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.unit, func(t *testing.T) {
+			uom := ParseUnitOfMeasure(tt.unit)
+			assert.Equal(t, tt.unit, uom.Original)
+			assert.Equal(t, tt.expectedMultiplier, uom.Multiplier)
+			assert.Equal(t, tt.expectedBaseUnit, uom.BaseUnit)
+		})
+	}
+}
+
+func TestStandardUnitNormalizer_ScaleUnit(t *testing.T) {
+	normalizer := NewStandardUnitNormalizer()
+
+	tests := []struct {
+		name          string
+		provider      string
+		unit          string
+		price         float64
+		expectedUnit  string
+		expectedPrice float64
+	}{
+		{"Azure hour", database.ProviderAzure, "1 Hour", 0.096, database.UnitHour, 0.096},
+		{"Azure 100 hours", database.ProviderAzure, "100 Hours", 5, database.UnitHour, 0.05},
+		{"Azure per month", database.ProviderAzure, "1/Month", 3.65, database.UnitHour, 0.005},
+		{"Azure per day", database.ProviderAzure, "1/Day", 2.4, database.UnitHour, 0.1},
+		{"Azure 10K", database.ProviderAzure, "10K", 0.05, database.UnitCount, 0.000005},
+		{"Azure 100 GB/Month", database.ProviderAzure, "100 GB/Month", 2, database.UnitGBMonth, 0.02},
+		{"Azure GiB hour", database.ProviderAzure, "1 GiB Hour", 0.036, "gb_second", 0.00001},
+		{"AWS hours", database.ProviderAWS, "Hrs", 0.0416, database.UnitHour, 0.0416},
+		{"AWS 1M requests", database.ProviderAWS, "1M requests", 0.2, database.UnitRequest, 0.0000002},
+		{"AWS GB-Mo", database.ProviderAWS, "GB-Mo", 0.023, database.UnitGBMonth, 0.023},
+		{"AWS Lambda GB-second", database.ProviderAWS, "Lambda-GB-Second", 0.0000166667, "gb_second", 0.0000166667},
+		{"unknown unit", database.ProviderAWS, "Quantity", 243, "quantity", 243},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scaled := normalizer.ScaleUnit(tt.provider, tt.unit)
+			assert.Equal(t, tt.expectedUnit, scaled.Unit)
+			assert.Equal(t, tt.unit, scaled.Original)
+			assert.InDelta(t, tt.expectedPrice, tt.price*scaled.Scale, 1e-12)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package normalizer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/raulc0399/cpc/internal/database"
+)
+
+// unitQuantityPattern splits a leading quantity with an optional K/M/B suffix from a unit of
+// measure, e.g. "100 Hours", "10K", "1M Requests", "1/Month" or "100 GB/Month"
+var unitQuantityPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([KkMmBb]\b)?\s*/?\s*(.*)$`)
+
+// unitSuffixMultipliers are the quantity suffixes used in units of measure
+var unitSuffixMultipliers = map[string]float64{
+	"k": 1e3,
+	"m": 1e6,
+	"b": 1e9,
+}
+
+// UnitOfMeasure is a provider unit of measure split into a quantity and a base unit
+type UnitOfMeasure struct {
+	Original   string
+	Multiplier float64 // e.g. 10000 for "10K Requests"
+	BaseUnit   string  // e.g. "Requests"; empty for bare counts such as "10K"
+}
+
+// ParseUnitOfMeasure splits the quantity multiplier from a provider unit of measure.
+// "1/Month" is a rate per month, so its base unit is "Month".
+func ParseUnitOfMeasure(unit string) UnitOfMeasure {
+	original := strings.TrimSpace(unit)
+	uom := UnitOfMeasure{Original: original, Multiplier: 1, BaseUnit: original}
+
+	matches := unitQuantityPattern.FindStringSubmatch(original)
+	if matches == nil {
+		return uom
+	}
+
+	quantity, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil || quantity <= 0 {
+		return uom
+	}
+	if suffix := strings.ToLower(matches[2]); suffix != "" {
+		quantity *= unitSuffixMultipliers[suffix]
+	}
+
+	uom.Multiplier = quantity
+	uom.BaseUnit = strings.TrimSpace(matches[3])
+	return uom
+}
+
+// unitDefinition places a normalized unit in a dimension: prices convert between units sharing
+// a canonical unit, and size is the unit expressed in the canonical unit
+type unitDefinition struct {
+	category  string
+	canonical string
+	size      float64
+}
+
+// unitDefinitions lists the units that rescale to a canonical unit
+var unitDefinitions = map[string]unitDefinition{
+	// Time, canonical hour
+	"second":          {"time", database.UnitHour, 1.0 / 3600},
+	"minute":          {"time", database.UnitHour, 1.0 / 60},
+	database.UnitHour: {"time", database.UnitHour, 1},
+	"day":             {"time", database.UnitHour, 24},
+	"month":           {"time", database.UnitHour, database.HoursPerMonth},
+	"year":            {"time", database.UnitHour, database.HoursPerYear},
+
+	// Data, canonical GB
+	"mb":            {"storage", database.UnitGB, 1.0 / 1024},
+	database.UnitGB: {"storage", database.UnitGB, 1},
+	database.UnitTB: {"storage", database.UnitGB, 1024},
+
+	// Data over time, canonical GB-month
+	database.UnitGBMonth: {"storage", database.UnitGBMonth, 1},
+	"tb_month":           {"storage", database.UnitGBMonth, 1024},
+
+	// Compute-seconds, canonical GB-second and vCPU-second
+	"gb_second":        {"compute_seconds", "gb_second", 1},
+	"lambda_gb_second": {"compute_seconds", "gb_second", 1},
+	"gb_hour":          {"compute_seconds", "gb_second", 3600},
+	"vcpu_second":      {"compute_seconds", "vcpu_second", 1},
+	"vcpu_hour":        {"compute_seconds", "vcpu_second", 3600},
+
+	// Requests, canonical request
+	database.UnitRequest:         {"requests", database.UnitRequest, 1},
+	database.UnitMillionRequests: {"requests", database.UnitRequest, 1e6},
+	database.UnitTransaction:     {"requests", database.UnitTransaction, 1},
+	database.UnitCount:           {"requests", database.UnitCount, 1},
+}
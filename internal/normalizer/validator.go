@@ -3,6 +3,7 @@ package normalizer
 import (
 	"encoding/json"
 	"strings"
+	"unicode"
 
 	"github.com/raulc0399/cpc/internal/database"
 )
@@ -251,8 +252,9 @@ func (v *InputValidator) validateCurrency(currency string) error {
 		}
 	}
 
-	// Validate currency format (should be 3-letter ISO code)
-	if len(currency) != 3 {
+	// Validate currency format (should be 3-letter ISO code); any code can be converted
+	// once an exchange rate for it is loaded
+	if len(currency) != 3 || strings.IndexFunc(currency, func(r rune) bool { return !unicode.IsLetter(r) }) >= 0 {
 		return ValidationError{
 			Field:   "currency",
 			Value:   currency,
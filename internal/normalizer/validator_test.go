@@ -202,6 +202,19 @@ func TestInputValidator_ValidateNormalizedPricing(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "non-alphabetic currency",
+			pricing: database.NormalizedPricing{
+				Provider:            database.ProviderAWS,
+				ProviderServiceCode: "AmazonEC2",
+				ResourceName:        "t3.medium",
+				PricePerUnit:        0.0416,
+				Unit:                database.UnitHour,
+				Currency:            "U$D",
+				PricingModel:        database.PricingModelOnDemand,
+			},
+			expectError: true,
+		},
 		{
 			name: "invalid pricing model",
 			pricing: database.NormalizedPricing{
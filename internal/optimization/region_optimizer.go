@@ -4,11 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"log"
 	"math"
 	"sort"
 
 	_ "github.com/lib/pq"
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/raulc0399/cpc/internal/normalizer"
 )
 
 // WorkloadProfile represents compute requirements for optimization
@@ -47,35 +48,60 @@ type RegionComparison struct {
 	BestFor        []string `json:"best_for"`
 }
 
+// PriceRepository is where the optimizer looks up prices: database.DB on Postgres or a
+// snapshot.Store
+type PriceRepository interface {
+	GetComputePriceSummaries(ctx context.Context, filter database.ComputePriceSummaryFilter) ([]database.ComputePriceSummary, error)
+	GetRegionPriceIndices(ctx context.Context, filter database.RegionPriceIndexFilter) ([]database.RegionPriceIndex, error)
+}
+
 // RegionOptimizer provides region optimization functionality using real CPC data
 type RegionOptimizer struct {
-	db *sql.DB
+	db     PriceRepository
+	logger normalizer.Logger
 }
 
 // NewRegionOptimizer creates a new region optimizer
 func NewRegionOptimizer(db *sql.DB) *RegionOptimizer {
-	return &RegionOptimizer{db: db}
+	return NewRegionOptimizerWithRepository(database.New(db))
+}
+
+// NewRegionOptimizerWithRepository creates a region optimizer that looks up prices in a
+// repository
+func NewRegionOptimizerWithRepository(repo PriceRepository) *RegionOptimizer {
+	return &RegionOptimizer{db: repo, logger: normalizer.NewDefaultLogger()}
+}
+
+// SetLogger replaces the optimizer's logger
+func (ro *RegionOptimizer) SetLogger(logger normalizer.Logger) {
+	ro.logger = logger
 }
 
 // OptimizeRegions finds the best regions for a given workload using real pricing data
 func (ro *RegionOptimizer) OptimizeRegions(ctx context.Context, workload WorkloadProfile) ([]RegionOptimization, error) {
 	// Get normalized pricing data for compute, storage, and egress
-	computePrices, err := ro.getComputePrices(ctx, workload.Providers)
+	computePrices, err := ro.getComputePrices(ctx, workload.Providers, workload.CPUCount)
 	if err != nil {
-		log.Printf("Error getting compute prices: %v", err)
+		normalizer.LoggerFromContext(ctx, ro.logger).Error("Failed to get compute prices, using fallback optimizations",
+			normalizer.Field{Key: "error", Value: err},
+		)
 		// Fall back to sample data if database query fails
 		return ro.getFallbackOptimizations(workload), nil
 	}
 
 	storagePrices, err := ro.getStoragePrices(ctx, workload.Providers)
 	if err != nil {
-		log.Printf("Error getting storage prices: %v", err)
+		normalizer.LoggerFromContext(ctx, ro.logger).Error("Failed to get storage prices, using fallback optimizations",
+			normalizer.Field{Key: "error", Value: err},
+		)
 		return ro.getFallbackOptimizations(workload), nil
 	}
 
 	egressPrices, err := ro.getEgressPrices(ctx, workload.Providers)
 	if err != nil {
-		log.Printf("Error getting egress prices: %v", err)
+		normalizer.LoggerFromContext(ctx, ro.logger).Error("Failed to get egress prices, using fallback optimizations",
+			normalizer.Field{Key: "error", Value: err},
+		)
 		return ro.getFallbackOptimizations(workload), nil
 	}
 
@@ -128,21 +154,33 @@ func (ro *RegionOptimizer) CompareRegions(ctx context.Context, workload Workload
 
 	// First pass: calculate costs for all regions
 	for _, region := range regions {
-		computePrice, err := ro.getSpecificComputePrice(ctx, region.Provider, region.Region)
+		computePrice, err := ro.getSpecificComputePrice(ctx, region.Provider, region.Region, workload.CPUCount)
 		if err != nil {
-			log.Printf("Error getting compute price for %s/%s: %v", region.Provider, region.Region, err)
+			normalizer.LoggerFromContext(ctx, ro.logger).Warn("Failed to get compute price",
+				normalizer.Field{Key: normalizer.LogKeyProvider, Value: region.Provider},
+				normalizer.Field{Key: normalizer.LogKeyRegion, Value: region.Region},
+				normalizer.Field{Key: "error", Value: err},
+			)
 			continue
 		}
 
 		storagePrice, err := ro.getSpecificStoragePrice(ctx, region.Provider, region.Region)
 		if err != nil {
-			log.Printf("Error getting storage price for %s/%s: %v", region.Provider, region.Region, err)
+			normalizer.LoggerFromContext(ctx, ro.logger).Warn("Failed to get storage price",
+				normalizer.Field{Key: normalizer.LogKeyProvider, Value: region.Provider},
+				normalizer.Field{Key: normalizer.LogKeyRegion, Value: region.Region},
+				normalizer.Field{Key: "error", Value: err},
+			)
 			storagePrice = 0.025 // Default fallback
 		}
 
 		egressPrice, err := ro.getSpecificEgressPrice(ctx, region.Provider, region.Region)
 		if err != nil {
-			log.Printf("Error getting egress price for %s/%s: %v", region.Provider, region.Region, err)
+			normalizer.LoggerFromContext(ctx, ro.logger).Warn("Failed to get egress price",
+				normalizer.Field{Key: normalizer.LogKeyProvider, Value: region.Provider},
+				normalizer.Field{Key: normalizer.LogKeyRegion, Value: region.Region},
+				normalizer.Field{Key: "error", Value: err},
+			)
 			egressPrice = 0.09 // Default fallback
 		}
 
@@ -193,188 +231,135 @@ type PriceData struct {
 
 // Database query methods
 
-func (ro *RegionOptimizer) getComputePrices(ctx context.Context, providers []string) ([]PriceData, error) {
-	query := `
-		SELECT DISTINCT 
-			provider,
-			region,
-			region as region_name,
-			AVG(price_per_unit) as avg_price
-		FROM normalized_pricing 
-		WHERE service_category = 'Compute & Web' 
-		AND price_per_unit > 0
-		AND ($1 = '' OR provider = ANY($2))
-		GROUP BY provider, region
-		ORDER BY avg_price ASC
-		LIMIT 50
-	`
-
-	var args []interface{}
-	if len(providers) == 0 {
-		args = []interface{}{"", nil}
-	} else {
-		// Convert to PostgreSQL array format
-		args = []interface{}{"has_providers", providers}
-	}
-
-	rows, err := ro.db.QueryContext(ctx, query, args...)
+// The optimizer prices workloads from the summary tables the ETL refreshes after every job:
+// compute as the cheapest Linux on-demand instance with enough vCPUs, storage and egress as
+// the median per-GB price of the region's storage and networking prices
+const (
+	optimizerCurrency        = "USD"
+	optimizerOperatingSystem = "linux"
+	storageUnit              = "gb_month"
+	egressUnit               = "gb"
+)
+
+func (ro *RegionOptimizer) computeFilter(providers, regions []string, cpuCount int) database.ComputePriceSummaryFilter {
+	minVCPU := cpuCount
+	if minVCPU < 1 {
+		minVCPU = 1
+	}
+	operatingSystem := optimizerOperatingSystem
+	currency := optimizerCurrency
+	return database.ComputePriceSummaryFilter{
+		Providers:         providers,
+		Regions:           regions,
+		MinVCPU:           &minVCPU,
+		OperatingSystem:   &operatingSystem,
+		Currency:          &currency,
+		CheapestPerRegion: true,
+	}
+}
+
+func (ro *RegionOptimizer) getComputePrices(ctx context.Context, providers []string, cpuCount int) ([]PriceData, error) {
+	filter := ro.computeFilter(providers, nil, cpuCount)
+	limit := 50
+	filter.Limit = &limit
+
+	summaries, err := ro.db.GetComputePriceSummaries(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("query compute prices: %w", err)
 	}
-	defer rows.Close()
+	if len(summaries) == 0 {
+		return nil, fmt.Errorf("query compute prices: compute price summary is empty")
+	}
 
-	var results []PriceData
-	for rows.Next() {
-		var pd PriceData
-		err := rows.Scan(&pd.Provider, &pd.Region, &pd.RegionName, &pd.Price)
-		if err != nil {
-			log.Printf("Error scanning compute price row: %v", err)
-			continue
+	results := make([]PriceData, len(summaries))
+	for i, summary := range summaries {
+		results[i] = PriceData{
+			Provider:   summary.Provider,
+			Region:     summary.ProviderRegion,
+			RegionName: summary.RegionName,
+			Price:      summary.PricePerHour,
 		}
-		results = append(results, pd)
 	}
-
 	return results, nil
 }
 
 func (ro *RegionOptimizer) getStoragePrices(ctx context.Context, providers []string) ([]PriceData, error) {
-	query := `
-		SELECT DISTINCT 
-			provider,
-			region,
-			region as region_name,
-			AVG(price_per_unit) as avg_price
-		FROM normalized_pricing 
-		WHERE service_category = 'Storage' 
-		AND price_per_unit > 0
-		AND ($1 = '' OR provider = ANY($2))
-		GROUP BY provider, region
-		ORDER BY avg_price ASC
-	`
-
-	var args []interface{}
-	if len(providers) == 0 {
-		args = []interface{}{"", nil}
-	} else {
-		args = []interface{}{"has_providers", providers}
-	}
-
-	rows, err := ro.db.QueryContext(ctx, query, args...)
+	prices, err := ro.getIndexPrices(ctx, providers, nil, database.CategoryStorage, storageUnit)
 	if err != nil {
 		return nil, fmt.Errorf("query storage prices: %w", err)
 	}
-	defer rows.Close()
-
-	var results []PriceData
-	for rows.Next() {
-		var pd PriceData
-		err := rows.Scan(&pd.Provider, &pd.Region, &pd.RegionName, &pd.Price)
-		if err != nil {
-			log.Printf("Error scanning storage price row: %v", err)
-			continue
-		}
-		results = append(results, pd)
-	}
-
-	return results, nil
+	return prices, nil
 }
 
 func (ro *RegionOptimizer) getEgressPrices(ctx context.Context, providers []string) ([]PriceData, error) {
-	query := `
-		SELECT DISTINCT 
-			provider,
-			region,
-			region as region_name,
-			AVG(price_per_unit) as avg_price
-		FROM normalized_pricing 
-		WHERE service_category = 'Networking' 
-		AND (resource_name ILIKE '%transfer%' OR resource_name ILIKE '%egress%' OR resource_name ILIKE '%bandwidth%')
-		AND price_per_unit > 0
-		AND ($1 = '' OR provider = ANY($2))
-		GROUP BY provider, region
-		ORDER BY avg_price ASC
-	`
-
-	var args []interface{}
-	if len(providers) == 0 {
-		args = []interface{}{"", nil}
-	} else {
-		args = []interface{}{"has_providers", providers}
-	}
-
-	rows, err := ro.db.QueryContext(ctx, query, args...)
+	prices, err := ro.getIndexPrices(ctx, providers, nil, database.CategoryNetworking, egressUnit)
 	if err != nil {
 		return nil, fmt.Errorf("query egress prices: %w", err)
 	}
-	defer rows.Close()
+	return prices, nil
+}
 
-	var results []PriceData
-	for rows.Next() {
-		var pd PriceData
-		err := rows.Scan(&pd.Provider, &pd.Region, &pd.RegionName, &pd.Price)
-		if err != nil {
-			log.Printf("Error scanning egress price row: %v", err)
-			continue
-		}
-		results = append(results, pd)
+// getIndexPrices returns the median price of a category and unit per region from the region
+// price index
+func (ro *RegionOptimizer) getIndexPrices(ctx context.Context, providers, regions []string, category, unit string) ([]PriceData, error) {
+	currency := optimizerCurrency
+	indices, err := ro.db.GetRegionPriceIndices(ctx, database.RegionPriceIndexFilter{
+		Providers:       providers,
+		Regions:         regions,
+		ServiceCategory: &category,
+		Unit:            &unit,
+		Currency:        &currency,
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	results := make([]PriceData, len(indices))
+	for i, index := range indices {
+		results[i] = PriceData{
+			Provider:   index.Provider,
+			Region:     index.ProviderRegion,
+			RegionName: index.RegionName,
+			Price:      index.MedianPrice,
+		}
+	}
 	return results, nil
 }
 
-func (ro *RegionOptimizer) getSpecificComputePrice(ctx context.Context, provider, region string) (float64, error) {
-	query := `
-		SELECT AVG(price_per_unit) 
-		FROM normalized_pricing 
-		WHERE provider = $1 AND region = $2 
-		AND service_category = 'Compute & Web' 
-		AND price_per_unit > 0
-	`
-
-	var price float64
-	err := ro.db.QueryRowContext(ctx, query, provider, region).Scan(&price)
+func (ro *RegionOptimizer) getSpecificComputePrice(ctx context.Context, provider, region string, cpuCount int) (float64, error) {
+	summaries, err := ro.db.GetComputePriceSummaries(ctx, ro.computeFilter([]string{provider}, []string{region}, cpuCount))
 	if err != nil {
 		return 0, fmt.Errorf("query specific compute price: %w", err)
 	}
+	if len(summaries) == 0 {
+		return 0, fmt.Errorf("query specific compute price: no instance with %d vCPUs in %s %s", cpuCount, provider, region)
+	}
 
-	return price, nil
+	return summaries[0].PricePerHour, nil
 }
 
 func (ro *RegionOptimizer) getSpecificStoragePrice(ctx context.Context, provider, region string) (float64, error) {
-	query := `
-		SELECT AVG(price_per_unit) 
-		FROM normalized_pricing 
-		WHERE provider = $1 AND region = $2 
-		AND service_category = 'Storage' 
-		AND price_per_unit > 0
-	`
-
-	var price float64
-	err := ro.db.QueryRowContext(ctx, query, provider, region).Scan(&price)
+	prices, err := ro.getIndexPrices(ctx, []string{provider}, []string{region}, database.CategoryStorage, storageUnit)
 	if err != nil {
 		return 0, fmt.Errorf("query specific storage price: %w", err)
 	}
+	if len(prices) == 0 {
+		return 0, fmt.Errorf("query specific storage price: no storage prices in %s %s", provider, region)
+	}
 
-	return price, nil
+	return prices[0].Price, nil
 }
 
 func (ro *RegionOptimizer) getSpecificEgressPrice(ctx context.Context, provider, region string) (float64, error) {
-	query := `
-		SELECT AVG(price_per_unit) 
-		FROM normalized_pricing 
-		WHERE provider = $1 AND region = $2 
-		AND service_category = 'Networking' 
-		AND (resource_name ILIKE '%transfer%' OR resource_name ILIKE '%egress%')
-		AND price_per_unit > 0
-	`
-
-	var price float64
-	err := ro.db.QueryRowContext(ctx, query, provider, region).Scan(&price)
+	prices, err := ro.getIndexPrices(ctx, []string{provider}, []string{region}, database.CategoryNetworking, egressUnit)
 	if err != nil {
 		return 0, fmt.Errorf("query specific egress price: %w", err)
 	}
+	if len(prices) == 0 {
+		return 0, fmt.Errorf("query specific egress price: no egress prices in %s %s", provider, region)
+	}
 
-	return price, nil
+	return prices[0].Price, nil
 }
 
 // Helper methods
@@ -0,0 +1,282 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+)
+
+// DefaultBatchSize is the number of prices copied per batch
+const DefaultBatchSize = 5000
+
+// Source is the database a snapshot is exported from. database.DB implements it.
+type Source interface {
+	ExportNormalizedPricing(ctx context.Context, afterID, limit int) ([]database.NormalizedPricing, error)
+	ExportPriceHistory(ctx context.Context, afterID int64, limit int) ([]database.PriceHistoryEntry, int64, error)
+	GetServiceMappings() ([]database.ServiceMapping, error)
+	GetNormalizedRegions() ([]database.NormalizedRegion, error)
+	GetComputePriceSummaries(ctx context.Context, filter database.ComputePriceSummaryFilter) ([]database.ComputePriceSummary, error)
+	GetRegionPriceIndices(ctx context.Context, filter database.RegionPriceIndexFilter) ([]database.RegionPriceIndex, error)
+	GetExchangeRates(ctx context.Context) ([]database.ExchangeRate, error)
+}
+
+var _ Source = (*database.DB)(nil)
+
+// ExportOptions configures an export. SourceName is recorded in the snapshot info.
+type ExportOptions struct {
+	BatchSize      int
+	IncludeHistory bool
+	SourceName     string
+}
+
+// ExportResult counts the exported rows
+type ExportResult struct {
+	Regions         int           `json:"regions"`
+	ServiceMappings int           `json:"serviceMappings"`
+	Prices          int           `json:"prices"`
+	HistoryVersions int           `json:"historyVersions"`
+	ComputeSummary  int           `json:"computeSummary"`
+	RegionIndex     int           `json:"regionIndex"`
+	ExchangeRates   int           `json:"exchangeRates"`
+	Duration        time.Duration `json:"duration"`
+}
+
+// Export copies the read path of a source database into an empty snapshot
+func Export(ctx context.Context, source Source, store *Store, options ExportOptions) (*ExportResult, error) {
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultBatchSize
+	}
+	start := time.Now()
+	result := &ExportResult{}
+
+	regions, err := source.GetNormalizedRegions()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.insertRegions(ctx, regions); err != nil {
+		return nil, err
+	}
+	result.Regions = len(regions)
+
+	mappings, err := source.GetServiceMappings()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.insertServiceMappings(ctx, mappings); err != nil {
+		return nil, err
+	}
+	result.ServiceMappings = len(mappings)
+
+	for afterID := 0; ; {
+		pricings, err := source.ExportNormalizedPricing(ctx, afterID, options.BatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(pricings) == 0 {
+			break
+		}
+		if err := store.insertPricings(ctx, pricings); err != nil {
+			return nil, err
+		}
+		result.Prices += len(pricings)
+		afterID = pricings[len(pricings)-1].ID
+		log.Printf("📦 Exported %d prices", result.Prices)
+	}
+
+	if options.IncludeHistory {
+		for afterID := int64(0); ; {
+			entries, lastID, err := source.ExportPriceHistory(ctx, afterID, options.BatchSize)
+			if err != nil {
+				return nil, err
+			}
+			if len(entries) == 0 {
+				break
+			}
+			if err := store.insertPriceHistory(ctx, entries); err != nil {
+				return nil, err
+			}
+			result.HistoryVersions += len(entries)
+			afterID = lastID
+			log.Printf("📦 Exported %d price history versions", result.HistoryVersions)
+		}
+	}
+
+	summaries, err := source.GetComputePriceSummaries(ctx, database.ComputePriceSummaryFilter{})
+	if err != nil {
+		return nil, err
+	}
+	if err := store.insertComputeSummaries(ctx, summaries); err != nil {
+		return nil, err
+	}
+	result.ComputeSummary = len(summaries)
+
+	indices, err := source.GetRegionPriceIndices(ctx, database.RegionPriceIndexFilter{})
+	if err != nil {
+		return nil, err
+	}
+	if err := store.insertRegionIndices(ctx, indices); err != nil {
+		return nil, err
+	}
+	result.RegionIndex = len(indices)
+
+	rates, err := source.GetExchangeRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if result.ExchangeRates, err = store.UpsertExchangeRates(ctx, rates); err != nil {
+		return nil, err
+	}
+
+	if err := store.setInfo(ctx, "source", options.SourceName); err != nil {
+		return nil, err
+	}
+	if err := store.setInfo(ctx, "created_at", start.UTC().Format(time.RFC3339)); err != nil {
+		return nil, err
+	}
+
+	result.Duration = time.Since(start)
+	log.Printf("✅ Snapshot exported: %d prices, %d history versions in %v", result.Prices, result.HistoryVersions, result.Duration)
+	return result, nil
+}
+
+// inTx runs fn in a transaction, so each batch is written at once
+func (s *Store) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) insertRegions(ctx context.Context, regions []database.NormalizedRegion) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, region := range regions {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO normalized_regions (id, normalized_code, aws_region, azure_region, display_name, country, continent)
+				VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				region.ID, region.NormalizedCode, region.AWSRegion, region.AzureRegion,
+				region.DisplayName, region.Country, region.Continent,
+			); err != nil {
+				return fmt.Errorf("failed to insert region %s: %w", region.NormalizedCode, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) insertServiceMappings(ctx context.Context, mappings []database.ServiceMapping) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, mapping := range mappings {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO service_mappings (id, provider, provider_service_name, provider_service_code,
+					normalized_service_type, service_category, service_family)
+				VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				mapping.ID, mapping.Provider, mapping.ProviderServiceName, mapping.ProviderServiceCode,
+				mapping.NormalizedServiceType, mapping.ServiceCategory, mapping.ServiceFamily,
+			); err != nil {
+				return fmt.Errorf("failed to insert service mapping %s/%s: %w", mapping.Provider, mapping.ProviderServiceName, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) insertPricings(ctx context.Context, pricings []database.NormalizedPricing) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`INSERT INTO normalized_pricing (%s) VALUES (%s)`,
+			pricingColumns, pricingPlaceholders))
+		if err != nil {
+			return fmt.Errorf("failed to prepare pricing insert: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, pricing := range pricings {
+			values, err := pricingValues(pricing)
+			if err != nil {
+				return err
+			}
+			if _, err := stmt.ExecContext(ctx, values...); err != nil {
+				return fmt.Errorf("failed to insert pricing %d: %w", pricing.ID, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) insertPriceHistory(ctx context.Context, entries []database.PriceHistoryEntry) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`
+			INSERT INTO normalized_pricing_history (natural_key, valid_from, valid_to, %s)
+			VALUES (?, ?, ?, %s)`, pricingColumns, pricingPlaceholders))
+		if err != nil {
+			return fmt.Errorf("failed to prepare price history insert: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, entry := range entries {
+			values, err := pricingValues(entry.NormalizedPricing)
+			if err != nil {
+				return err
+			}
+			var validTo interface{}
+			if entry.ValidTo != nil {
+				validTo = formatTimestamp(*entry.ValidTo)
+			}
+			args := append([]interface{}{entry.NaturalKey, formatTimestamp(entry.ValidFrom), validTo}, values...)
+			if _, err := stmt.ExecContext(ctx, args...); err != nil {
+				return fmt.Errorf("failed to insert price history version of %s: %w", entry.NaturalKey, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) insertComputeSummaries(ctx context.Context, summaries []database.ComputePriceSummary) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, summary := range summaries {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO compute_price_summary (provider, provider_region, normalized_region, vcpu,
+					memory_bucket_gb, architecture, operating_system, currency, resource_name, provider_sku,
+					memory_gb, price_per_hour, offer_count, refreshed_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				summary.Provider, summary.ProviderRegion, summary.NormalizedRegion, summary.VCPU,
+				summary.MemoryBucketGB, summary.Architecture, summary.OperatingSystem, summary.Currency,
+				summary.ResourceName, summary.ProviderSKU, summary.MemoryGB, summary.PricePerHour,
+				summary.OfferCount, formatTimestamp(summary.RefreshedAt),
+			); err != nil {
+				return fmt.Errorf("failed to insert compute price summary: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) insertRegionIndices(ctx context.Context, indices []database.RegionPriceIndex) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, index := range indices {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO region_price_index (provider, provider_region, normalized_region, service_category,
+					unit, currency, price_index, item_count, median_price, min_price, refreshed_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				index.Provider, index.ProviderRegion, index.NormalizedRegion, index.ServiceCategory,
+				index.Unit, index.Currency, index.PriceIndex, index.ItemCount, index.MedianPrice,
+				index.MinPrice, formatTimestamp(index.RefreshedAt),
+			); err != nil {
+				return fmt.Errorf("failed to insert region price index: %w", err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,230 @@
+package snapshot
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource serves fixture rows in batches like the keyset queries of database.DB
+type fakeSource struct {
+	regions   []database.NormalizedRegion
+	mappings  []database.ServiceMapping
+	pricings  []database.NormalizedPricing
+	history   []database.PriceHistoryEntry
+	summaries []database.ComputePriceSummary
+	indices   []database.RegionPriceIndex
+	rates     []database.ExchangeRate
+
+	pricingBatches int
+	historyBatches int
+}
+
+var _ Source = (*fakeSource)(nil)
+
+func (f *fakeSource) ExportNormalizedPricing(ctx context.Context, afterID, limit int) ([]database.NormalizedPricing, error) {
+	f.pricingBatches++
+	var batch []database.NormalizedPricing
+	for _, pricing := range f.pricings {
+		if pricing.ID > afterID && len(batch) < limit {
+			batch = append(batch, pricing)
+		}
+	}
+	return batch, nil
+}
+
+// ExportPriceHistory numbers the history versions from 1 in fixture order
+func (f *fakeSource) ExportPriceHistory(ctx context.Context, afterID int64, limit int) ([]database.PriceHistoryEntry, int64, error) {
+	f.historyBatches++
+	var batch []database.PriceHistoryEntry
+	lastID := afterID
+	for i, entry := range f.history {
+		historyID := int64(i + 1)
+		if historyID > afterID && len(batch) < limit {
+			batch = append(batch, entry)
+			lastID = historyID
+		}
+	}
+	return batch, lastID, nil
+}
+
+func (f *fakeSource) GetServiceMappings() ([]database.ServiceMapping, error) {
+	return f.mappings, nil
+}
+
+func (f *fakeSource) GetNormalizedRegions() ([]database.NormalizedRegion, error) {
+	return f.regions, nil
+}
+
+func (f *fakeSource) GetComputePriceSummaries(ctx context.Context, filter database.ComputePriceSummaryFilter) ([]database.ComputePriceSummary, error) {
+	return f.summaries, nil
+}
+
+func (f *fakeSource) GetRegionPriceIndices(ctx context.Context, filter database.RegionPriceIndexFilter) ([]database.RegionPriceIndex, error) {
+	return f.indices, nil
+}
+
+func (f *fakeSource) GetExchangeRates(ctx context.Context) ([]database.ExchangeRate, error) {
+	return f.rates, nil
+}
+
+// newFixtureSource returns a source holding a few rows of every exported table
+func newFixtureSource() *fakeSource {
+	refreshedAt := time.Date(2026, 3, 1, 6, 0, 0, 0, time.UTC)
+	effectiveDate := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	reserved := testPricing(3, "m5.xlarge", 1200, database.ResourceSpecs{VCPU: intPtr(4), MemoryGB: float64Ptr(16)})
+	reserved.PricingModel = database.PricingModelReserved1Yr
+	reserved.Unit = "year"
+	reserved.PricingDetails = database.PricingDetails{
+		TermLength:          stringPtr("1yr"),
+		PaymentOption:       stringPtr("all_upfront"),
+		UpfrontCost:         float64Ptr(1200),
+		EffectiveHourlyRate: float64Ptr(0.137),
+	}
+	reserved.EffectiveDate = &effectiveDate
+
+	storage := testPricing(7, "gp3", 0.08, database.ResourceSpecs{StorageType: stringPtr("ssd")})
+	storage.ServiceCategory = "Storage"
+	storage.Unit = database.UnitGBMonth
+	storage.PriceTiers = []database.PriceTier{
+		{StartUnits: 0, EndUnits: float64Ptr(1000), PricePerUnit: 0.08},
+		{StartUnits: 1000, PricePerUnit: 0.07},
+	}
+
+	onDemand := testPricing(1, "m5.xlarge", 0.192, database.ResourceSpecs{
+		VCPU: intPtr(4), MemoryGB: float64Ptr(16), Architecture: stringPtr("x86_64"), ProcessorType: stringPtr("Intel Xeon Platinum"),
+	})
+	onDemand.ProviderSKU = stringPtr("SKU-M5XL")
+	onDemand.OperatingSystem = stringPtr("Linux")
+	onDemand.CreatedAt = refreshedAt
+	onDemand.UpdatedAt = refreshedAt
+
+	oldVersion := onDemand
+	oldVersion.PricePerUnit = 0.2
+	validTo := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	return &fakeSource{
+		regions: []database.NormalizedRegion{
+			{ID: 1, NormalizedCode: "us-east", AWSRegion: stringPtr("us-east-1"), AzureRegion: stringPtr("eastus"),
+				DisplayName: "US East", Country: stringPtr("US"), Continent: stringPtr("North America")},
+		},
+		mappings: []database.ServiceMapping{
+			{ID: 1, Provider: database.ProviderAWS, ProviderServiceName: "Amazon EC2", ProviderServiceCode: stringPtr("AmazonEC2"),
+				NormalizedServiceType: "Virtual Machines", ServiceCategory: "Compute & Web", ServiceFamily: "Virtual Machines"},
+		},
+		pricings: []database.NormalizedPricing{onDemand, reserved, storage},
+		history: []database.PriceHistoryEntry{
+			{NormalizedPricing: oldVersion, NaturalKey: "aws|SKU-M5XL", ValidFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), ValidTo: &validTo},
+			{NormalizedPricing: onDemand, NaturalKey: "aws|SKU-M5XL", ValidFrom: validTo},
+		},
+		summaries: []database.ComputePriceSummary{
+			{Provider: database.ProviderAWS, ProviderRegion: "us-east-1", NormalizedRegion: "us-east", RegionName: "US East",
+				VCPU: 4, MemoryBucketGB: 16, Architecture: "x86_64", OperatingSystem: "Linux", Currency: "USD",
+				ResourceName: "m5.xlarge", ProviderSKU: stringPtr("SKU-M5XL"), MemoryGB: 16, PricePerHour: 0.192,
+				OfferCount: 3, RefreshedAt: refreshedAt},
+		},
+		indices: []database.RegionPriceIndex{
+			{Provider: database.ProviderAWS, ProviderRegion: "us-east-1", NormalizedRegion: "us-east", RegionName: "US East",
+				ServiceCategory: "Compute & Web", Unit: database.UnitHour, Currency: "USD", PriceIndex: 0.94,
+				ItemCount: 120, MedianPrice: 0.17, MinPrice: 0.0042, RefreshedAt: refreshedAt},
+		},
+		rates: []database.ExchangeRate{
+			{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: 0.92, EffectiveDate: effectiveDate, Source: "ecb"},
+		},
+	}
+}
+
+func TestExport_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := newFixtureSource()
+	path := filepath.Join(t.TempDir(), "snapshot.db")
+
+	store, err := Create(path)
+	require.NoError(t, err)
+	result, err := Export(ctx, source, store, ExportOptions{BatchSize: 2, IncludeHistory: true, SourceName: "postgres://pricing"})
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	assert.Equal(t, 1, result.Regions)
+	assert.Equal(t, 1, result.ServiceMappings)
+	assert.Equal(t, 3, result.Prices)
+	assert.Equal(t, 2, result.HistoryVersions)
+	assert.Equal(t, 1, result.ComputeSummary)
+	assert.Equal(t, 1, result.RegionIndex)
+	assert.Equal(t, 1, result.ExchangeRates)
+	// Batches of 2 take a second batch for the third price and an empty one to stop
+	assert.Equal(t, 3, source.pricingBatches)
+	assert.Equal(t, 2, source.historyBatches)
+
+	store, err = Open(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	info, err := store.Info(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, FormatVersion, info.FormatVersion)
+	assert.Equal(t, "postgres://pricing", info.Source)
+	assert.False(t, info.CreatedAt.IsZero())
+
+	regions, err := store.GetNormalizedRegions()
+	require.NoError(t, err)
+	assert.Equal(t, source.regions, regions)
+
+	mappings, err := store.GetServiceMappings()
+	require.NoError(t, err)
+	assert.Equal(t, source.mappings, mappings)
+
+	pricings, err := store.QueryNormalizedPricing(database.PricingFilter{})
+	require.NoError(t, err)
+	require.Len(t, pricings, 3)
+	byID := map[int]database.NormalizedPricing{}
+	for _, pricing := range pricings {
+		byID[pricing.ID] = pricing
+	}
+	for _, expected := range source.pricings {
+		assert.Equal(t, expected, byID[expected.ID], "price %d", expected.ID)
+	}
+
+	timeline, err := store.GetPriceTimeline(ctx, database.ProviderAWS, "SKU-M5XL", nil)
+	require.NoError(t, err)
+	assert.Equal(t, source.history, timeline)
+
+	summaries, err := store.GetComputePriceSummaries(ctx, database.ComputePriceSummaryFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, source.summaries, summaries)
+
+	indices, err := store.GetRegionPriceIndices(ctx, database.RegionPriceIndexFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, source.indices, indices)
+
+	rate, err := store.GetExchangeRate(ctx, "USD", "EUR", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.NotNil(t, rate)
+	assert.Equal(t, source.rates[0], *rate)
+}
+
+func TestExport_WithoutHistory(t *testing.T) {
+	ctx := context.Background()
+	source := newFixtureSource()
+
+	store, err := Create(filepath.Join(t.TempDir(), "snapshot.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	result, err := Export(ctx, source, store, ExportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Prices)
+	assert.Zero(t, result.HistoryVersions)
+	assert.Zero(t, source.historyBatches)
+
+	// Point-in-time queries fail like Postgres before the first history sync
+	asOf := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, err = store.QueryNormalizedPricing(database.PricingFilter{History: true, AsOf: &asOf})
+	assert.ErrorIs(t, err, database.ErrPriceHistoryUnavailable)
+}
@@ -0,0 +1,358 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+)
+
+// effectiveHourlyRate is the amortized hourly rate of a record, falling back to the price of
+// hourly records without a commitment, as in the Postgres read path
+const effectiveHourlyRate = `COALESCE(json_extract(pricing_details, '$.effective_hourly_rate'), CASE WHEN unit = 'hour' THEN price_per_unit END)`
+
+// pricingOrderExpressions maps the PricingFilter.OrderBy values supported by the Postgres read
+// path to SQLite
+var pricingOrderExpressions = map[string]string{
+	database.OrderByPricePerUnit:         "price_per_unit",
+	database.OrderByEffectiveHourlyRate:  effectiveHourlyRate,
+	database.OrderByEffectiveMonthlyRate: effectiveHourlyRate, // monthly is a fixed multiple of hourly
+	"resource_name":                      "resource_name",
+	"provider":                           "provider",
+	"service_type":                       "service_type",
+	"normalized_region":                  "normalized_region",
+	"pricing_model":                      "pricing_model",
+	"created_at":                         "created_at",
+	// Cast to REAL, as SQLite divides integers without a remainder
+	database.OrderByPricePerVCPU:     "CAST(" + effectiveHourlyRate + " AS REAL) / NULLIF(spec_vcpu, 0)",
+	database.OrderByPricePerMemoryGB: "CAST(" + effectiveHourlyRate + " AS REAL) / NULLIF(spec_memory_gb, 0)",
+}
+
+// QueryNormalizedPricing queries the snapshot prices with the filters of the Postgres read path
+func (s *Store) QueryNormalizedPricing(filter database.PricingFilter) ([]database.NormalizedPricing, error) {
+	if filter.Specs != nil {
+		if err := filter.Specs.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	table := database.NormalizedPricingTable
+	if filter.History {
+		if filter.AsOf == nil {
+			return nil, fmt.Errorf("history queries require an asOf date")
+		}
+		if err := s.checkPriceHistoryCovers(*filter.AsOf); err != nil {
+			return nil, err
+		}
+		table = database.NormalizedPricingHistoryTable
+	}
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE 1=1`, pricingColumns, table)
+	args := []interface{}{}
+
+	// Point-in-time queries read the price versions the ETL had live at that moment
+	if filter.History {
+		asOf := formatTimestamp(*filter.AsOf)
+		query += " AND valid_from <= ? AND (valid_to IS NULL OR valid_to > ?)"
+		args = append(args, asOf, asOf)
+	}
+
+	addFilter := func(column string, value *string) {
+		if value != nil {
+			query += fmt.Sprintf(" AND %s = ?", column)
+			args = append(args, *value)
+		}
+	}
+	addFilter("provider", filter.Provider)
+	addFilter("service_category", filter.ServiceCategory)
+	addFilter("service_family", filter.ServiceFamily)
+	addFilter("service_type", filter.ServiceType)
+	addFilter("normalized_region", filter.NormalizedRegion)
+	addFilter("pricing_model", filter.PricingModel)
+	addFilter("currency", filter.Currency)
+	addFilter("operating_system", filter.OperatingSystem)
+	addFilter("license_model", filter.LicenseModel)
+	addFilter("pre_installed_software", filter.PreInstalledSoftware)
+	addFilter("tenancy", filter.Tenancy)
+
+	// Multi-value filters match any of their values
+	anyOf := func(column string, values []string) {
+		if len(values) > 0 {
+			query += " AND " + inClause(column, values, &args)
+		}
+	}
+	anyOf("provider", filter.Providers)
+	anyOf("normalized_region", filter.NormalizedRegions)
+	anyOf("pricing_model", filter.PricingModels)
+
+	if filter.MaxPricePerUnit != nil {
+		query += " AND price_per_unit <= ?"
+		args = append(args, *filter.MaxPricePerUnit)
+	}
+	if filter.MinPricePerUnit != nil {
+		query += " AND price_per_unit >= ?"
+		args = append(args, *filter.MinPricePerUnit)
+	}
+
+	// Effective and expiration dates are stored as dates, so validity is decided per day
+	if filter.AsOf != nil {
+		asOfDate := filter.AsOf.UTC().Format(dateLayout)
+		query += " AND (effective_date IS NULL OR effective_date <= ?) AND (expiration_date IS NULL OR expiration_date >= ?)"
+		args = append(args, asOfDate, asOfDate)
+	}
+
+	// The snapshot tables generate the same typed spec columns as Postgres
+	for _, predicate := range filter.SpecPredicates() {
+		query += fmt.Sprintf(" AND %s %s ?", predicate.Column, predicate.Operator)
+		args = append(args, predicate.Value)
+	}
+
+	orderClause, err := orderClause(filter)
+	if err != nil {
+		return nil, err
+	}
+	query += orderClause
+
+	if filter.Limit != nil {
+		query += " LIMIT ?"
+		args = append(args, *filter.Limit)
+		if filter.Offset != nil {
+			query += " OFFSET ?"
+			args = append(args, *filter.Offset)
+		}
+	}
+
+	rows, err := s.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query normalized pricing: %w", err)
+	}
+	defer rows.Close()
+
+	var pricings []database.NormalizedPricing
+	for rows.Next() {
+		pricing, err := scanPricing(rows)
+		if err != nil {
+			return nil, err
+		}
+		pricings = append(pricings, pricing)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read normalized pricing: %w", err)
+	}
+	return pricings, nil
+}
+
+// orderClause builds the ORDER BY clause of a filter, ordering by price per unit by default
+func orderClause(filter database.PricingFilter) (string, error) {
+	orderBy := database.OrderByPricePerUnit
+	if filter.OrderBy != nil {
+		orderBy = *filter.OrderBy
+	}
+	orderExpr, ok := pricingOrderExpressions[orderBy]
+	if !ok {
+		return "", fmt.Errorf("unsupported order by: %s", orderBy)
+	}
+
+	orderDirection := "ASC"
+	if filter.OrderDirection != nil {
+		orderDirection = strings.ToUpper(*filter.OrderDirection)
+	}
+	if orderDirection != "ASC" && orderDirection != "DESC" {
+		return "", fmt.Errorf("unsupported order direction: %s", orderDirection)
+	}
+
+	return fmt.Sprintf(" ORDER BY %s %s NULLS LAST, id", orderExpr, orderDirection), nil
+}
+
+// checkPriceHistoryCovers returns database.ErrPriceHistoryUnavailable when asOf is before the
+// first history sync recorded in the snapshot. Timestamps are fixed-width, so they compare as text.
+func (s *Store) checkPriceHistoryCovers(asOf time.Time) error {
+	var firstSync sql.NullString
+	err := s.conn.QueryRow(`SELECT MIN(valid_from) FROM normalized_pricing_history`).Scan(&firstSync)
+	if err != nil {
+		return fmt.Errorf("failed to query price history start: %w", err)
+	}
+
+	if !firstSync.Valid {
+		return fmt.Errorf("%w: the price history is empty", database.ErrPriceHistoryUnavailable)
+	}
+	if formatTimestamp(asOf) < firstSync.String {
+		return fmt.Errorf("%w: the price history starts at %s", database.ErrPriceHistoryUnavailable, firstSync.String)
+	}
+	return nil
+}
+
+// GetPriceTimeline returns every version of the prices of a provider SKU in the snapshot,
+// optionally limited to one normalized region
+func (s *Store) GetPriceTimeline(ctx context.Context, provider, sku string, normalizedRegion *string) ([]database.PriceHistoryEntry, error) {
+	query := fmt.Sprintf(`
+		SELECT %s, natural_key, valid_from, valid_to
+		FROM normalized_pricing_history
+		WHERE provider = ? AND provider_sku = ?
+		  AND (? IS NULL OR normalized_region = ?)
+		ORDER BY natural_key, valid_from`, pricingColumns)
+
+	var region sql.NullString
+	if normalizedRegion != nil {
+		region = sql.NullString{String: *normalizedRegion, Valid: true}
+	}
+
+	rows, err := s.conn.QueryContext(ctx, query, provider, sku, region, region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query price timeline: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []database.PriceHistoryEntry
+	for rows.Next() {
+		var entry database.PriceHistoryEntry
+		var validTo sql.NullTime
+		entry.NormalizedPricing, err = scanPricing(rows, &entry.NaturalKey, &entry.ValidFrom, &validTo)
+		if err != nil {
+			return nil, err
+		}
+		if validTo.Valid {
+			entry.ValidTo = &validTo.Time
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read price timeline: %w", err)
+	}
+	return entries, nil
+}
+
+// ComparePricing compares equivalent services between providers using the default matching
+// configuration
+func (s *Store) ComparePricing(serviceType, normalizedRegion, pricingModel string) ([]database.PricingComparison, error) {
+	return s.ComparePricingWithConfig(serviceType, normalizedRegion, pricingModel, database.DefaultMatchingConfig())
+}
+
+// ComparePricingWithConfig pairs the AWS and Azure offers valid today like the Postgres read path
+func (s *Store) ComparePricingWithConfig(serviceType, normalizedRegion, pricingModel string, config database.MatchingConfig) ([]database.PricingComparison, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid matching configuration: %w", err)
+	}
+
+	asOf := time.Now()
+	pricings, err := s.QueryNormalizedPricing(database.PricingFilter{
+		ServiceType:      &serviceType,
+		NormalizedRegion: &normalizedRegion,
+		PricingModel:     &pricingModel,
+		AsOf:             &asOf,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare pricing: %w", err)
+	}
+
+	return database.CompareOffers(pricings, serviceType, normalizedRegion, pricingModel, config), nil
+}
+
+// GetServiceMappings retrieves all service mappings
+func (s *Store) GetServiceMappings() ([]database.ServiceMapping, error) {
+	rows, err := s.conn.Query(`
+		SELECT id, provider, provider_service_name, provider_service_code,
+		       normalized_service_type, service_category, service_family
+		FROM service_mappings
+		ORDER BY provider, service_category, normalized_service_type`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query service mappings: %w", err)
+	}
+	defer rows.Close()
+
+	var mappings []database.ServiceMapping
+	for rows.Next() {
+		var mapping database.ServiceMapping
+		if err := rows.Scan(&mapping.ID, &mapping.Provider, &mapping.ProviderServiceName,
+			&mapping.ProviderServiceCode, &mapping.NormalizedServiceType, &mapping.ServiceCategory,
+			&mapping.ServiceFamily); err != nil {
+			return nil, fmt.Errorf("failed to scan service mapping: %w", err)
+		}
+		mappings = append(mappings, mapping)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read service mappings: %w", err)
+	}
+	return mappings, nil
+}
+
+// GetServiceMappingByProvider retrieves the service mapping of a provider service
+func (s *Store) GetServiceMappingByProvider(provider, serviceName string) (*database.ServiceMapping, error) {
+	var mapping database.ServiceMapping
+	err := s.conn.QueryRow(`
+		SELECT id, provider, provider_service_name, provider_service_code,
+		       normalized_service_type, service_category, service_family
+		FROM service_mappings
+		WHERE provider = ? AND (provider_service_name = ? OR provider_service_code = ?)
+		LIMIT 1`, provider, serviceName, serviceName,
+	).Scan(&mapping.ID, &mapping.Provider, &mapping.ProviderServiceName, &mapping.ProviderServiceCode,
+		&mapping.NormalizedServiceType, &mapping.ServiceCategory, &mapping.ServiceFamily)
+	if errNotFound(err) {
+		return nil, nil // Not found
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service mapping: %w", err)
+	}
+	return &mapping, nil
+}
+
+// GetNormalizedRegions retrieves all normalized regions
+func (s *Store) GetNormalizedRegions() ([]database.NormalizedRegion, error) {
+	rows, err := s.conn.Query(`
+		SELECT id, normalized_code, aws_region, azure_region, display_name, country, continent
+		FROM normalized_regions
+		ORDER BY normalized_code`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query normalized regions: %w", err)
+	}
+	defer rows.Close()
+
+	var regions []database.NormalizedRegion
+	for rows.Next() {
+		var region database.NormalizedRegion
+		if err := rows.Scan(&region.ID, &region.NormalizedCode, &region.AWSRegion, &region.AzureRegion,
+			&region.DisplayName, &region.Country, &region.Continent); err != nil {
+			return nil, fmt.Errorf("failed to scan normalized region: %w", err)
+		}
+		regions = append(regions, region)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read normalized regions: %w", err)
+	}
+	return regions, nil
+}
+
+// GetNormalizedRegionByProvider finds the normalized region of a provider region
+func (s *Store) GetNormalizedRegionByProvider(provider, providerRegion string) (*database.NormalizedRegion, error) {
+	var column string
+	switch provider {
+	case database.ProviderAWS:
+		column = "aws_region"
+	case database.ProviderAzure:
+		column = "azure_region"
+	default:
+		return nil, fmt.Errorf("unsupported provider: %s", provider)
+	}
+
+	var region database.NormalizedRegion
+	err := s.conn.QueryRow(fmt.Sprintf(`
+		SELECT id, normalized_code, aws_region, azure_region, display_name, country, continent
+		FROM normalized_regions
+		WHERE %s = ?
+		LIMIT 1`, column), providerRegion,
+	).Scan(&region.ID, &region.NormalizedCode, &region.AWSRegion, &region.AzureRegion,
+		&region.DisplayName, &region.Country, &region.Continent)
+	if errNotFound(err) {
+		return nil, nil // Not found
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get normalized region: %w", err)
+	}
+	return &region, nil
+}
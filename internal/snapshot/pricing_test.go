@@ -0,0 +1,284 @@
+package snapshot
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func intPtr(value int) *int { return &value }
+
+func float64Ptr(value float64) *float64 { return &value }
+
+func stringPtr(value string) *string { return &value }
+
+func boolPtr(value bool) *bool { return &value }
+
+// newTestStore creates a snapshot in a temp file holding the given prices
+func newTestStore(t *testing.T, pricings ...database.NormalizedPricing) *Store {
+	t.Helper()
+
+	store, err := Create(filepath.Join(t.TempDir(), "snapshot.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	require.NoError(t, store.insertPricings(context.Background(), pricings))
+	return store
+}
+
+// testPricing is a live on-demand hourly price with the given resource specs
+func testPricing(id int, name string, price float64, specs database.ResourceSpecs) database.NormalizedPricing {
+	return database.NormalizedPricing{
+		ID: id, Provider: database.ProviderAWS, ProviderServiceCode: "AmazonEC2",
+		ServiceCategory: "Compute & Web", ServiceFamily: "Virtual Machines", ServiceType: "Virtual Machines",
+		NormalizedRegion: "us-east", ProviderRegion: "us-east-1", ResourceName: name,
+		ResourceSpecs: specs, PricePerUnit: price, Unit: database.UnitHour, Currency: "USD",
+		PricingModel: database.PricingModelOnDemand, MinimumCommitment: 1, UnitMultiplier: 1,
+	}
+}
+
+func TestStore_QueryNormalizedPricing_GPUCount(t *testing.T) {
+	store := newTestStore(t,
+		testPricing(1, "m5.xlarge", 0.192, database.ResourceSpecs{VCPU: intPtr(4)}),
+		testPricing(2, "g5.xlarge", 1.006, database.ResourceSpecs{VCPU: intPtr(4), GPUCount: intPtr(1)}),
+		testPricing(3, "p4d.24xlarge", 32.77, database.ResourceSpecs{VCPU: intPtr(96), GPUCount: intPtr(8)}),
+		// Storage states no specs an instance has
+		testPricing(4, "gp3", 0.08, database.ResourceSpecs{}),
+	)
+
+	tests := []struct {
+		name        string
+		specs       database.SpecFilter
+		expectedIDs []int
+	}{
+		{"CPU-only instances", database.SpecFilter{MaxGPUCount: intPtr(0)}, []int{1}},
+		{"any GPU", database.SpecFilter{MinGPUCount: intPtr(1)}, []int{2, 3}},
+		{"up to one GPU", database.SpecFilter{MaxGPUCount: intPtr(1)}, []int{1, 2}},
+		{"no GPU bound on instances", database.SpecFilter{MinGPUCount: intPtr(0)}, []int{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pricings, err := store.QueryNormalizedPricing(database.PricingFilter{Specs: &tt.specs})
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.expectedIDs, pricingIDs(pricings))
+		})
+	}
+}
+
+// pricingIDs returns the ids of prices in their order
+func pricingIDs(pricings []database.NormalizedPricing) []int {
+	ids := make([]int, len(pricings))
+	for i, pricing := range pricings {
+		ids[i] = pricing.ID
+	}
+	return ids
+}
+
+// newQueryTestStore holds on-demand and reserved compute prices of both providers in two regions
+func newQueryTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	m5 := testPricing(1, "m5.xlarge", 0.192, database.ResourceSpecs{VCPU: intPtr(4), MemoryGB: float64Ptr(16),
+		Architecture: stringPtr("x86_64"), ProcessorType: stringPtr("Intel Xeon Platinum 8175"), Burstable: boolPtr(false)})
+	t4g := testPricing(2, "t4g.medium", 0.0336, database.ResourceSpecs{VCPU: intPtr(2), MemoryGB: float64Ptr(4),
+		Architecture: stringPtr("aarch64"), ProcessorType: stringPtr("AWS Graviton2"), Burstable: boolPtr(true)})
+	d4s := testPricing(3, "D4s_v5", 0.192, database.ResourceSpecs{VCPU: intPtr(4), MemoryGB: float64Ptr(16),
+		Architecture: stringPtr("x64"), ProcessorType: stringPtr("Intel Xeon Platinum 8370C"), Burstable: boolPtr(false)})
+	d4s.Provider = database.ProviderAzure
+	d4s.ProviderRegion = "eastus"
+	m5EU := testPricing(4, "m5.xlarge", 0.214, database.ResourceSpecs{VCPU: intPtr(4), MemoryGB: float64Ptr(16),
+		Architecture: stringPtr("x86_64")})
+	m5EU.NormalizedRegion = "eu-west"
+	m5EU.ProviderRegion = "eu-west-1"
+	m5Reserved := testPricing(5, "m5.xlarge", 1051.2, database.ResourceSpecs{VCPU: intPtr(4), MemoryGB: float64Ptr(16)})
+	m5Reserved.PricingModel = database.PricingModelReserved1Yr
+	m5Reserved.Unit = "year"
+	m5Reserved.PricingDetails = database.PricingDetails{UpfrontCost: float64Ptr(1051.2), EffectiveHourlyRate: float64Ptr(0.12)}
+
+	return newTestStore(t, m5, t4g, d4s, m5EU, m5Reserved)
+}
+
+func TestStore_QueryNormalizedPricing_Filters(t *testing.T) {
+	store := newQueryTestStore(t)
+
+	tests := []struct {
+		name        string
+		filter      database.PricingFilter
+		expectedIDs []int
+	}{
+		{"no filter", database.PricingFilter{}, []int{2, 1, 3, 4, 5}},
+		{"provider", database.PricingFilter{Provider: stringPtr(database.ProviderAzure)}, []int{3}},
+		{"any of providers in a region", database.PricingFilter{
+			Providers:        []string{database.ProviderAWS, database.ProviderAzure},
+			NormalizedRegion: stringPtr("eu-west"),
+		}, []int{4}},
+		{"any of regions", database.PricingFilter{NormalizedRegions: []string{"eu-west", "ap-south"}}, []int{4}},
+		{"any of pricing models", database.PricingFilter{PricingModels: []string{database.PricingModelReserved1Yr}}, []int{5}},
+		{"price range", database.PricingFilter{MinPricePerUnit: float64Ptr(0.1), MaxPricePerUnit: float64Ptr(0.2)}, []int{1, 3}},
+		{"other currency", database.PricingFilter{Currency: stringPtr("EUR")}, []int{}},
+		{"page", database.PricingFilter{Limit: intPtr(2), Offset: intPtr(1)}, []int{1, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pricings, err := store.QueryNormalizedPricing(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIDs, pricingIDs(pricings))
+		})
+	}
+}
+
+func TestStore_QueryNormalizedPricing_Ordering(t *testing.T) {
+	store := newQueryTestStore(t)
+
+	tests := []struct {
+		name        string
+		orderBy     string
+		direction   string
+		expectedIDs []int
+	}{
+		// Equal prices keep id order in both directions
+		{"price descending", database.OrderByPricePerUnit, "desc", []int{5, 4, 1, 3, 2}},
+		{"effective hourly rate", database.OrderByEffectiveHourlyRate, "asc", []int{2, 5, 1, 3, 4}},
+		{"price per vCPU", database.OrderByPricePerVCPU, "asc", []int{2, 5, 1, 3, 4}},
+		{"price per memory GB", database.OrderByPricePerMemoryGB, "asc", []int{5, 2, 1, 3, 4}},
+		{"resource name", "resource_name", "asc", []int{3, 1, 4, 5, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pricings, err := store.QueryNormalizedPricing(database.PricingFilter{
+				OrderBy: stringPtr(tt.orderBy), OrderDirection: stringPtr(tt.direction),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIDs, pricingIDs(pricings))
+		})
+	}
+
+	_, err := store.QueryNormalizedPricing(database.PricingFilter{OrderBy: stringPtr("price_per_unit; DROP TABLE normalized_pricing")})
+	assert.EqualError(t, err, "unsupported order by: price_per_unit; DROP TABLE normalized_pricing")
+	_, err = store.QueryNormalizedPricing(database.PricingFilter{OrderDirection: stringPtr("sideways")})
+	assert.EqualError(t, err, "unsupported order direction: SIDEWAYS")
+}
+
+func TestStore_QueryNormalizedPricing_SpecPredicates(t *testing.T) {
+	store := newQueryTestStore(t)
+
+	tests := []struct {
+		name        string
+		filter      database.PricingFilter
+		expectedIDs []int
+	}{
+		{"architecture alias", database.PricingFilter{Specs: &database.SpecFilter{Architecture: stringPtr("arm64")}}, []int{2}},
+		{"normalized architecture", database.PricingFilter{Specs: &database.SpecFilter{Architecture: stringPtr("amd64")}}, []int{1, 3, 4}},
+		{"burstable", database.PricingFilter{Specs: &database.SpecFilter{Burstable: boolPtr(true)}}, []int{2}},
+		{"not burstable", database.PricingFilter{Specs: &database.SpecFilter{Burstable: boolPtr(false)}}, []int{1, 3}},
+		{"processor vendor", database.PricingFilter{Specs: &database.SpecFilter{ProcessorVendor: stringPtr("Intel")}}, []int{1, 3}},
+		{"vCPU and memory range", database.PricingFilter{Specs: &database.SpecFilter{
+			MinVCPU: intPtr(4), MaxMemoryGB: float64Ptr(16),
+		}}, []int{1, 3, 4, 5}},
+		{"exact vCPU count", database.PricingFilter{ResourceSpecs: &database.ResourceSpecs{VCPU: intPtr(2)}}, []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pricings, err := store.QueryNormalizedPricing(tt.filter)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.expectedIDs, pricingIDs(pricings))
+		})
+	}
+
+	_, err := store.QueryNormalizedPricing(database.PricingFilter{Specs: &database.SpecFilter{MinVCPU: intPtr(8), MaxVCPU: intPtr(4)}})
+	assert.EqualError(t, err, "minimum vcpu 8 is above the maximum 4")
+}
+
+func TestStore_QueryNormalizedPricing_AsOf(t *testing.T) {
+	date := func(year int, month time.Month, day int) *time.Time {
+		value := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return &value
+	}
+
+	undated := testPricing(1, "m5.xlarge", 0.192, database.ResourceSpecs{})
+	expiring := testPricing(2, "m5.xlarge", 0.2, database.ResourceSpecs{})
+	expiring.EffectiveDate = date(2026, 1, 1)
+	expiring.ExpirationDate = date(2026, 6, 30)
+	upcoming := testPricing(3, "m5.xlarge", 0.18, database.ResourceSpecs{})
+	upcoming.EffectiveDate = date(2026, 7, 1)
+	store := newTestStore(t, undated, expiring, upcoming)
+
+	tests := []struct {
+		name        string
+		asOf        time.Time
+		expectedIDs []int
+	}{
+		{"before the first effective date", time.Date(2025, 12, 31, 12, 0, 0, 0, time.UTC), []int{1}},
+		// Validity is decided per day, so a price is valid all day on its expiration date
+		{"on the expiration date", time.Date(2026, 6, 30, 23, 0, 0, 0, time.UTC), []int{1, 2}},
+		{"on the next effective date", time.Date(2026, 7, 1, 0, 30, 0, 0, time.UTC), []int{3, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pricings, err := store.QueryNormalizedPricing(database.PricingFilter{AsOf: &tt.asOf})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIDs, pricingIDs(pricings))
+		})
+	}
+}
+
+func TestStore_QueryNormalizedPricing_History(t *testing.T) {
+	store := newTestStore(t)
+	at := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	closedAt := func(month time.Month, day int) *time.Time {
+		value := at(month, day)
+		return &value
+	}
+
+	m5 := testPricing(1, "m5.xlarge", 0.2, database.ResourceSpecs{})
+	m5Lowered := testPricing(1, "m5.xlarge", 0.192, database.ResourceSpecs{})
+	c5 := testPricing(2, "c5.xlarge", 0.17, database.ResourceSpecs{})
+	require.NoError(t, store.insertPriceHistory(context.Background(), []database.PriceHistoryEntry{
+		{NormalizedPricing: m5, NaturalKey: "m5", ValidFrom: at(1, 1), ValidTo: closedAt(2, 1)},
+		{NormalizedPricing: m5Lowered, NaturalKey: "m5", ValidFrom: at(2, 1)},
+		{NormalizedPricing: c5, NaturalKey: "c5", ValidFrom: at(1, 15), ValidTo: closedAt(3, 1)},
+	}))
+
+	tests := []struct {
+		name           string
+		asOf           time.Time
+		expectedPrices []float64
+	}{
+		{"first versions", at(1, 20), []float64{0.17, 0.2}},
+		// A version closed at asOf is replaced by the one opened at the same moment
+		{"when a price changed", at(2, 1), []float64{0.17, 0.192}},
+		{"after a price disappeared", at(3, 5), []float64{0.192}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pricings, err := store.QueryNormalizedPricing(database.PricingFilter{History: true, AsOf: &tt.asOf})
+			require.NoError(t, err)
+
+			prices := make([]float64, len(pricings))
+			for i, pricing := range pricings {
+				prices[i] = pricing.PricePerUnit
+			}
+			assert.Equal(t, tt.expectedPrices, prices)
+		})
+	}
+
+	beforeHistory := at(1, 1).Add(-time.Hour)
+	_, err := store.QueryNormalizedPricing(database.PricingFilter{History: true, AsOf: &beforeHistory})
+	assert.ErrorIs(t, err, database.ErrPriceHistoryUnavailable)
+
+	_, err = store.QueryNormalizedPricing(database.PricingFilter{History: true})
+	assert.EqualError(t, err, "history queries require an asOf date")
+}
@@ -0,0 +1,239 @@
+-- Pricing snapshot schema: a read-only copy of the normalized pricing read path in one SQLite
+-- file. JSON columns hold the same documents as the Postgres JSONB columns; dates are stored
+-- as YYYY-MM-DD and timestamps as fixed-width UTC text, so they compare as strings. The spec_*
+-- columns are generated from resource_specs like the Postgres ones.
+
+CREATE TABLE snapshot_info (
+    key TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+);
+
+CREATE TABLE normalized_regions (
+    id INTEGER PRIMARY KEY,
+    normalized_code TEXT NOT NULL UNIQUE,
+    aws_region TEXT,
+    azure_region TEXT,
+    display_name TEXT NOT NULL,
+    country TEXT,
+    continent TEXT
+);
+
+CREATE TABLE service_mappings (
+    id INTEGER PRIMARY KEY,
+    provider TEXT NOT NULL,
+    provider_service_name TEXT NOT NULL,
+    provider_service_code TEXT,
+    normalized_service_type TEXT NOT NULL,
+    service_category TEXT NOT NULL,
+    service_family TEXT NOT NULL,
+    UNIQUE(provider, provider_service_name)
+);
+
+CREATE TABLE normalized_pricing (
+    id INTEGER PRIMARY KEY,
+    provider TEXT NOT NULL,
+    provider_service_code TEXT NOT NULL,
+    provider_sku TEXT,
+    service_mapping_id INTEGER,
+    service_category TEXT NOT NULL,
+    service_family TEXT NOT NULL,
+    service_type TEXT NOT NULL,
+    region_id INTEGER,
+    normalized_region TEXT NOT NULL,
+    provider_region TEXT NOT NULL,
+    resource_name TEXT NOT NULL,
+    resource_description TEXT,
+    resource_specs TEXT NOT NULL DEFAULT '{}',
+    operating_system TEXT,
+    license_model TEXT,
+    pre_installed_software TEXT,
+    tenancy TEXT,
+    price_per_unit REAL NOT NULL,
+    unit TEXT NOT NULL,
+    original_unit TEXT,
+    unit_multiplier REAL DEFAULT 1,
+    currency TEXT NOT NULL,
+    pricing_model TEXT NOT NULL,
+    pricing_details TEXT DEFAULT '{}',
+    price_tiers TEXT,
+    effective_date DATE,
+    expiration_date DATE,
+    minimum_commitment INTEGER DEFAULT 1,
+    aws_raw_id INTEGER,
+    azure_raw_id INTEGER,
+    spec_vcpu REAL GENERATED ALWAYS AS (
+        CASE WHEN json_type(resource_specs, '$.vcpu') IN ('integer', 'real') THEN json_extract(resource_specs, '$.vcpu') END
+    ) STORED,
+    spec_memory_gb REAL GENERATED ALWAYS AS (
+        CASE WHEN json_type(resource_specs, '$.memory_gb') IN ('integer', 'real') THEN json_extract(resource_specs, '$.memory_gb') END
+    ) STORED,
+    spec_gpu_count INTEGER GENERATED ALWAYS AS (
+        CASE WHEN json_type(resource_specs, '$.gpu_count') IN ('integer', 'real') THEN CAST(json_extract(resource_specs, '$.gpu_count') AS INTEGER) END
+    ) STORED,
+    spec_gpu_memory_gb REAL GENERATED ALWAYS AS (
+        CASE WHEN json_type(resource_specs, '$.gpu_memory_gb') IN ('integer', 'real') THEN json_extract(resource_specs, '$.gpu_memory_gb') END
+    ) STORED,
+    spec_storage_gb REAL GENERATED ALWAYS AS (
+        CASE WHEN json_type(resource_specs, '$.storage_gb') IN ('integer', 'real') THEN json_extract(resource_specs, '$.storage_gb') END
+    ) STORED,
+    spec_clock_speed_ghz REAL GENERATED ALWAYS AS (
+        CASE WHEN json_type(resource_specs, '$.clock_speed_ghz') IN ('integer', 'real') THEN json_extract(resource_specs, '$.clock_speed_ghz') END
+    ) STORED,
+    spec_burstable INTEGER GENERATED ALWAYS AS (
+        CASE WHEN json_type(resource_specs, '$.burstable') IN ('true', 'false') THEN json_extract(resource_specs, '$.burstable') END
+    ) STORED,
+    spec_architecture TEXT GENERATED ALWAYS AS (
+        CASE
+            WHEN lower(trim(json_extract(resource_specs, '$.architecture'))) IN ('arm64', 'aarch64', 'arm') THEN 'arm64'
+            WHEN lower(trim(json_extract(resource_specs, '$.architecture'))) IN ('x86_64', 'x64', 'amd64', 'x86', 'i386') THEN 'x86_64'
+            ELSE NULLIF(lower(trim(json_extract(resource_specs, '$.architecture'))), '')
+        END
+    ) STORED,
+    spec_processor_vendor TEXT GENERATED ALWAYS AS (
+        CASE
+            WHEN lower(json_extract(resource_specs, '$.processor_type')) LIKE '%graviton%' THEN 'aws'
+            WHEN lower(json_extract(resource_specs, '$.processor_type')) LIKE '%ampere%' THEN 'ampere'
+            WHEN lower(json_extract(resource_specs, '$.processor_type')) LIKE '%amd%'
+              OR lower(json_extract(resource_specs, '$.processor_type')) LIKE '%epyc%' THEN 'amd'
+            WHEN lower(json_extract(resource_specs, '$.processor_type')) LIKE '%intel%'
+              OR lower(json_extract(resource_specs, '$.processor_type')) LIKE '%xeon%' THEN 'intel'
+        END
+    ) STORED,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_provider_service ON normalized_pricing(provider, service_type);
+CREATE INDEX idx_category_region ON normalized_pricing(service_category, normalized_region);
+CREATE INDEX idx_service_region_model ON normalized_pricing(service_type, normalized_region, pricing_model);
+CREATE INDEX idx_resource_name ON normalized_pricing(resource_name);
+CREATE INDEX idx_price_per_unit ON normalized_pricing(price_per_unit);
+CREATE INDEX idx_spec_vcpu_memory ON normalized_pricing(spec_vcpu, spec_memory_gb);
+CREATE INDEX idx_spec_memory ON normalized_pricing(spec_memory_gb);
+CREATE INDEX idx_spec_gpu ON normalized_pricing(spec_gpu_count, spec_gpu_memory_gb) WHERE spec_gpu_count > 0;
+CREATE INDEX idx_spec_platform ON normalized_pricing(spec_architecture, spec_processor_vendor, spec_burstable);
+
+CREATE TABLE normalized_pricing_history (
+    history_id INTEGER PRIMARY KEY,
+    natural_key TEXT NOT NULL,
+    valid_from TIMESTAMP NOT NULL,
+    valid_to TIMESTAMP,
+    id INTEGER NOT NULL,
+    provider TEXT NOT NULL,
+    provider_service_code TEXT NOT NULL,
+    provider_sku TEXT,
+    service_mapping_id INTEGER,
+    service_category TEXT NOT NULL,
+    service_family TEXT NOT NULL,
+    service_type TEXT NOT NULL,
+    region_id INTEGER,
+    normalized_region TEXT NOT NULL,
+    provider_region TEXT NOT NULL,
+    resource_name TEXT NOT NULL,
+    resource_description TEXT,
+    resource_specs TEXT NOT NULL DEFAULT '{}',
+    operating_system TEXT,
+    license_model TEXT,
+    pre_installed_software TEXT,
+    tenancy TEXT,
+    price_per_unit REAL NOT NULL,
+    unit TEXT NOT NULL,
+    original_unit TEXT,
+    unit_multiplier REAL DEFAULT 1,
+    currency TEXT NOT NULL,
+    pricing_model TEXT NOT NULL,
+    pricing_details TEXT DEFAULT '{}',
+    price_tiers TEXT,
+    effective_date DATE,
+    expiration_date DATE,
+    minimum_commitment INTEGER DEFAULT 1,
+    aws_raw_id INTEGER,
+    azure_raw_id INTEGER,
+    spec_vcpu REAL GENERATED ALWAYS AS (
+        CASE WHEN json_type(resource_specs, '$.vcpu') IN ('integer', 'real') THEN json_extract(resource_specs, '$.vcpu') END
+    ) STORED,
+    spec_memory_gb REAL GENERATED ALWAYS AS (
+        CASE WHEN json_type(resource_specs, '$.memory_gb') IN ('integer', 'real') THEN json_extract(resource_specs, '$.memory_gb') END
+    ) STORED,
+    spec_gpu_count INTEGER GENERATED ALWAYS AS (
+        CASE WHEN json_type(resource_specs, '$.gpu_count') IN ('integer', 'real') THEN CAST(json_extract(resource_specs, '$.gpu_count') AS INTEGER) END
+    ) STORED,
+    spec_gpu_memory_gb REAL GENERATED ALWAYS AS (
+        CASE WHEN json_type(resource_specs, '$.gpu_memory_gb') IN ('integer', 'real') THEN json_extract(resource_specs, '$.gpu_memory_gb') END
+    ) STORED,
+    spec_storage_gb REAL GENERATED ALWAYS AS (
+        CASE WHEN json_type(resource_specs, '$.storage_gb') IN ('integer', 'real') THEN json_extract(resource_specs, '$.storage_gb') END
+    ) STORED,
+    spec_clock_speed_ghz REAL GENERATED ALWAYS AS (
+        CASE WHEN json_type(resource_specs, '$.clock_speed_ghz') IN ('integer', 'real') THEN json_extract(resource_specs, '$.clock_speed_ghz') END
+    ) STORED,
+    spec_burstable INTEGER GENERATED ALWAYS AS (
+        CASE WHEN json_type(resource_specs, '$.burstable') IN ('true', 'false') THEN json_extract(resource_specs, '$.burstable') END
+    ) STORED,
+    spec_architecture TEXT GENERATED ALWAYS AS (
+        CASE
+            WHEN lower(trim(json_extract(resource_specs, '$.architecture'))) IN ('arm64', 'aarch64', 'arm') THEN 'arm64'
+            WHEN lower(trim(json_extract(resource_specs, '$.architecture'))) IN ('x86_64', 'x64', 'amd64', 'x86', 'i386') THEN 'x86_64'
+            ELSE NULLIF(lower(trim(json_extract(resource_specs, '$.architecture'))), '')
+        END
+    ) STORED,
+    spec_processor_vendor TEXT GENERATED ALWAYS AS (
+        CASE
+            WHEN lower(json_extract(resource_specs, '$.processor_type')) LIKE '%graviton%' THEN 'aws'
+            WHEN lower(json_extract(resource_specs, '$.processor_type')) LIKE '%ampere%' THEN 'ampere'
+            WHEN lower(json_extract(resource_specs, '$.processor_type')) LIKE '%amd%'
+              OR lower(json_extract(resource_specs, '$.processor_type')) LIKE '%epyc%' THEN 'amd'
+            WHEN lower(json_extract(resource_specs, '$.processor_type')) LIKE '%intel%'
+              OR lower(json_extract(resource_specs, '$.processor_type')) LIKE '%xeon%' THEN 'intel'
+        END
+    ) STORED,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_pricing_history_sku ON normalized_pricing_history(provider, provider_sku, valid_from);
+CREATE INDEX idx_pricing_history_window ON normalized_pricing_history(valid_from, valid_to);
+
+CREATE TABLE compute_price_summary (
+    provider TEXT NOT NULL,
+    provider_region TEXT NOT NULL,
+    normalized_region TEXT NOT NULL,
+    vcpu INTEGER NOT NULL,
+    memory_bucket_gb REAL NOT NULL,
+    architecture TEXT NOT NULL,
+    operating_system TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    resource_name TEXT NOT NULL,
+    provider_sku TEXT,
+    memory_gb REAL NOT NULL,
+    price_per_hour REAL NOT NULL,
+    offer_count INTEGER NOT NULL,
+    refreshed_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (provider, provider_region, vcpu, memory_bucket_gb, architecture, operating_system, currency)
+);
+
+CREATE INDEX idx_compute_summary_price ON compute_price_summary(price_per_hour);
+
+CREATE TABLE region_price_index (
+    provider TEXT NOT NULL,
+    provider_region TEXT NOT NULL,
+    normalized_region TEXT NOT NULL,
+    service_category TEXT NOT NULL,
+    unit TEXT NOT NULL,
+    currency TEXT NOT NULL,
+    price_index REAL NOT NULL,
+    item_count INTEGER NOT NULL,
+    median_price REAL NOT NULL,
+    min_price REAL NOT NULL,
+    refreshed_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (provider, provider_region, service_category, unit, currency)
+);
+
+CREATE TABLE exchange_rates (
+    base_currency TEXT NOT NULL,
+    quote_currency TEXT NOT NULL,
+    rate REAL NOT NULL,
+    effective_date DATE NOT NULL,
+    source TEXT NOT NULL,
+    PRIMARY KEY (base_currency, quote_currency, effective_date)
+);
@@ -0,0 +1,376 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/raulc0399/cpc/internal/database"
+)
+
+// Trigram thresholds of the Postgres search, the pg_trgm defaults
+const (
+	similarityThreshold     = 0.3
+	wordSimilarityThreshold = 0.6
+)
+
+// Full-text weights of the search document fields, the ts_rank_cd defaults for weights A, B and C
+const (
+	weightName        = 1.0
+	weightServiceType = 0.4
+	weightDescription = 0.2
+)
+
+// searchCandidate is the part of a live price the search matches and counts facets on
+type searchCandidate struct {
+	id               int
+	resourceName     string
+	providerSKU      string
+	serviceType      string
+	description      string
+	provider         string
+	normalizedRegion string
+	serviceCategory  string
+	pricingModel     string
+	pricePerUnit     float64
+	score            float64
+}
+
+// SearchNormalizedPricing ranks the live prices matching a free-text query like the Postgres
+// search, which SQLite has no full-text or trigram operators for: matching and scoring are done
+// here on the same terms, with the full-text rank approximated by the weights of the matched
+// fields
+func (s *Store) SearchNormalizedPricing(ctx context.Context, req database.PricingSearchRequest) (*database.PricingSearchResult, error) {
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Query == "" {
+		return nil, fmt.Errorf("search query is required")
+	}
+	if req.Limit <= 0 {
+		req.Limit = database.DefaultSearchLimit
+	}
+	if req.Limit > database.MaxSearchLimit {
+		req.Limit = database.MaxSearchLimit
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	candidates, err := s.searchCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	term := strings.ToLower(req.Query)
+	tokens := searchTokens(term)
+	termTrigrams := trigrams(term)
+
+	// Facets are counted over every text match, the hits only over the ones of the requested
+	// facet values
+	filters := newSearchFacetFilters(req)
+	var textMatches, matches []searchCandidate
+	for _, candidate := range candidates {
+		if score, ok := scoreCandidate(candidate, term, tokens, termTrigrams); ok {
+			candidate.score = score
+			textMatches = append(textMatches, candidate)
+			if filters.match(candidate, "") {
+				matches = append(matches, candidate)
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		if matches[i].pricePerUnit != matches[j].pricePerUnit {
+			return matches[i].pricePerUnit < matches[j].pricePerUnit
+		}
+		return matches[i].id < matches[j].id
+	})
+
+	result := &database.PricingSearchResult{
+		Query:  req.Query,
+		Total:  len(matches),
+		Hits:   []database.PricingSearchHit{},
+		Facets: searchFacets(textMatches, filters),
+	}
+
+	if req.Offset >= len(matches) {
+		return result, nil
+	}
+	page := matches[req.Offset:]
+	if len(page) > req.Limit {
+		page = page[:req.Limit]
+	}
+
+	hits, err := s.searchHits(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+	result.Hits = hits
+	return result, nil
+}
+
+// searchCandidates loads the live prices
+func (s *Store) searchCandidates(ctx context.Context) ([]searchCandidate, error) {
+	today := time.Now().UTC().Format(dateLayout)
+	query := `
+		SELECT id, resource_name, COALESCE(provider_sku, ''), service_type,
+		       COALESCE(resource_description, ''), provider, normalized_region, service_category,
+		       pricing_model, price_per_unit
+		FROM normalized_pricing
+		WHERE (effective_date IS NULL OR effective_date <= ?)
+		  AND (expiration_date IS NULL OR expiration_date >= ?)`
+
+	rows, err := s.conn.QueryContext(ctx, query, today, today)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search normalized pricing: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []searchCandidate
+	for rows.Next() {
+		var candidate searchCandidate
+		if err := rows.Scan(&candidate.id, &candidate.resourceName, &candidate.providerSKU,
+			&candidate.serviceType, &candidate.description, &candidate.provider,
+			&candidate.normalizedRegion, &candidate.serviceCategory, &candidate.pricingModel,
+			&candidate.pricePerUnit); err != nil {
+			return nil, fmt.Errorf("failed to scan search candidate: %w", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read search candidates: %w", err)
+	}
+	return candidates, nil
+}
+
+// scoreCandidate matches a price on every query word, on word similarity to its search text or
+// on similarity of its resource name, and scores it like the Postgres search
+func scoreCandidate(candidate searchCandidate, term string, tokens []string, termTrigrams map[string]bool) (float64, bool) {
+	name := strings.ToLower(candidate.resourceName)
+	sku := strings.ToLower(candidate.providerSKU)
+	serviceType := strings.ToLower(candidate.serviceType)
+	description := strings.ToLower(candidate.description)
+	searchText := strings.Join([]string{name, sku, serviceType, description}, " ")
+
+	// Full-text: every query word appears in a field, ranked by the heaviest field it appears in
+	fields := []struct {
+		words  map[string]bool
+		weight float64
+	}{
+		{wordSet(name + " " + sku), weightName},
+		{wordSet(serviceType), weightServiceType},
+		{wordSet(description), weightDescription},
+	}
+	fullText := len(tokens) > 0
+	rank := 0.0
+	for _, token := range tokens {
+		best := 0.0
+		for _, field := range fields {
+			if field.words[token] && field.weight > best {
+				best = field.weight
+			}
+		}
+		if best == 0 {
+			fullText = false
+		}
+		rank += best
+	}
+	if len(tokens) > 0 {
+		rank /= float64(len(tokens))
+	}
+	if !fullText {
+		rank = 0
+	}
+
+	nameSimilarity := jaccard(termTrigrams, trigrams(name))
+	wordSimilarity := maxWordSimilarity(termTrigrams, len(tokens), searchTokens(searchText))
+	if !fullText && wordSimilarity < wordSimilarityThreshold && nameSimilarity < similarityThreshold {
+		return 0, false
+	}
+
+	score := 2*rank + 3*nameSimilarity + wordSimilarity
+	if name == term || sku == term {
+		score += 10
+	}
+	return score, true
+}
+
+// searchHits loads the prices of a page of matches, in match order
+func (s *Store) searchHits(ctx context.Context, page []searchCandidate) ([]database.PricingSearchHit, error) {
+	args := make([]interface{}, len(page))
+	placeholders := make([]string, len(page))
+	for i, candidate := range page {
+		args[i] = candidate.id
+		placeholders[i] = "?"
+	}
+
+	rows, err := s.conn.QueryContext(ctx, fmt.Sprintf(`SELECT %s FROM normalized_pricing WHERE id IN (%s)`,
+		pricingColumns, strings.Join(placeholders, ", ")), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load search results: %w", err)
+	}
+	defer rows.Close()
+
+	pricings := make(map[int]database.NormalizedPricing, len(page))
+	for rows.Next() {
+		pricing, err := scanPricing(rows)
+		if err != nil {
+			return nil, err
+		}
+		pricings[pricing.ID] = pricing
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read search results: %w", err)
+	}
+
+	hits := make([]database.PricingSearchHit, 0, len(page))
+	for _, candidate := range page {
+		hits = append(hits, database.PricingSearchHit{
+			NormalizedPricing: pricings[candidate.id],
+			Score:             candidate.score,
+		})
+	}
+	return hits, nil
+}
+
+// searchFacetFilter narrows a search to a value of a facet
+type searchFacetFilter struct {
+	facet string
+	value *string
+	of    func(searchCandidate) string
+}
+
+// searchFacetFilters are the facet filters of a search
+type searchFacetFilters []searchFacetFilter
+
+// newSearchFacetFilters returns the facet filters of a search request, in the order of the facets
+func newSearchFacetFilters(req database.PricingSearchRequest) searchFacetFilters {
+	return searchFacetFilters{
+		{database.SearchFacetProvider, req.Provider, func(c searchCandidate) string { return c.provider }},
+		{database.SearchFacetRegion, req.NormalizedRegion, func(c searchCandidate) string { return c.normalizedRegion }},
+		{database.SearchFacetServiceCategory, req.ServiceCategory, func(c searchCandidate) string { return c.serviceCategory }},
+		{database.SearchFacetPricingModel, req.PricingModel, func(c searchCandidate) string { return c.pricingModel }},
+	}
+}
+
+// match reports whether a candidate has the requested value of every facet but the excepted one
+func (filters searchFacetFilters) match(candidate searchCandidate, except string) bool {
+	for _, filter := range filters {
+		if filter.facet != except && filter.value != nil && filter.of(candidate) != *filter.value {
+			return false
+		}
+	}
+	return true
+}
+
+// searchFacets counts the matches per facet value, most frequent first. Like the Postgres
+// search, each facet is counted without its own filter.
+func searchFacets(matches []searchCandidate, filters searchFacetFilters) database.PricingSearchFacets {
+	count := func(filter searchFacetFilter) []database.SearchFacetValue {
+		counts := make(map[string]int)
+		for _, match := range matches {
+			if filters.match(match, filter.facet) {
+				counts[filter.of(match)]++
+			}
+		}
+		values := make([]database.SearchFacetValue, 0, len(counts))
+		for value, n := range counts {
+			values = append(values, database.SearchFacetValue{Value: value, Count: n})
+		}
+		sort.Slice(values, func(i, j int) bool {
+			if values[i].Count != values[j].Count {
+				return values[i].Count > values[j].Count
+			}
+			return values[i].Value < values[j].Value
+		})
+		if len(values) == 0 {
+			return nil
+		}
+		return values
+	}
+
+	return database.PricingSearchFacets{
+		Providers:         count(filters[0]),
+		Regions:           count(filters[1]),
+		ServiceCategories: count(filters[2]),
+		PricingModels:     count(filters[3]),
+	}
+}
+
+// searchTokens splits lowercased text into words of letters and digits
+func searchTokens(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func wordSet(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range searchTokens(text) {
+		words[word] = true
+	}
+	return words
+}
+
+// trigrams returns the pg_trgm trigrams of lowercased text: each word is padded with two
+// spaces in front and one behind
+func trigrams(text string) map[string]bool {
+	result := make(map[string]bool)
+	for _, word := range searchTokens(text) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			result[string(padded[i:i+3])] = true
+		}
+	}
+	return result
+}
+
+// jaccard is the pg_trgm similarity of two trigram sets
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for trigram := range a {
+		if b[trigram] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// maxWordSimilarity approximates pg_trgm word_similarity: the share of the query trigrams found
+// in the best run of consecutive words of the text, as long as the query
+func maxWordSimilarity(termTrigrams map[string]bool, termWords int, words []string) float64 {
+	if len(termTrigrams) == 0 || len(words) == 0 {
+		return 0
+	}
+	if termWords < 1 {
+		termWords = 1
+	}
+
+	best := 0.0
+	for start := range words {
+		end := start + termWords
+		if end > len(words) {
+			end = len(words)
+		}
+		window := trigrams(strings.Join(words[start:end], " "))
+		common := 0
+		for trigram := range termTrigrams {
+			if window[trigram] {
+				common++
+			}
+		}
+		if similarity := float64(common) / float64(len(termTrigrams)); similarity > best {
+			best = similarity
+		}
+	}
+	return best
+}
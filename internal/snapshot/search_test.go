@@ -0,0 +1,31 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchFacets_CountsEachFacetWithoutItsOwnFilter(t *testing.T) {
+	matches := []searchCandidate{
+		{id: 1, provider: "aws", normalizedRegion: "us-east", serviceCategory: "Compute & Web", pricingModel: "on_demand"},
+		{id: 2, provider: "aws", normalizedRegion: "eu-west", serviceCategory: "Compute & Web", pricingModel: "on_demand"},
+		{id: 3, provider: "azure", normalizedRegion: "us-east", serviceCategory: "Compute & Web", pricingModel: "on_demand"},
+		{id: 4, provider: "azure", normalizedRegion: "us-east", serviceCategory: "Compute & Web", pricingModel: "reserved"},
+	}
+	provider := "aws"
+	region := "us-east"
+
+	facets := searchFacets(matches, newSearchFacetFilters(database.PricingSearchRequest{
+		Provider:         &provider,
+		NormalizedRegion: &region,
+	}))
+
+	// Providers are counted in the requested region only, regions for the requested provider only
+	assert.Equal(t, []database.SearchFacetValue{{Value: "azure", Count: 2}, {Value: "aws", Count: 1}}, facets.Providers)
+	assert.Equal(t, []database.SearchFacetValue{{Value: "eu-west", Count: 1}, {Value: "us-east", Count: 1}}, facets.Regions)
+	// Facets without a filter count the matches of every filter
+	assert.Equal(t, []database.SearchFacetValue{{Value: "Compute & Web", Count: 1}}, facets.ServiceCategories)
+	assert.Equal(t, []database.SearchFacetValue{{Value: "on_demand", Count: 1}}, facets.PricingModels)
+}
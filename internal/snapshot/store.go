@@ -0,0 +1,285 @@
+// Package snapshot keeps a copy of the normalized pricing read path in a single SQLite file, so
+// the GraphQL and REST servers and the region optimizer can run without Postgres.
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/raulc0399/cpc/internal/database"
+)
+
+// FormatVersion is the version of the snapshot schema. Open refuses files of another version;
+// they have to be exported again.
+const FormatVersion = 3
+
+// Dates and timestamps are stored as text in these layouts, so they compare as strings
+const (
+	dateLayout      = "2006-01-02"
+	timestampLayout = "2006-01-02 15:04:05.000000"
+)
+
+//go:embed schema.sql
+var schema string
+
+// Store is a pricing snapshot in a SQLite file. It implements database.PricingRepository.
+type Store struct {
+	conn *sql.DB
+}
+
+var _ database.PricingRepository = (*Store)(nil)
+
+// Info describes where and when a snapshot was taken
+type Info struct {
+	FormatVersion int       `json:"formatVersion"`
+	Source        string    `json:"source"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// Create creates a snapshot file with an empty schema. It refuses to overwrite an existing file.
+func Create(path string) (*Store, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("snapshot file already exists: %s", path)
+	}
+
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create snapshot: %w", err)
+	}
+	store := &Store{conn: conn}
+
+	if _, err := conn.Exec(schema); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create snapshot schema: %w", err)
+	}
+	if err := store.setInfo(context.Background(), "format_version", strconv.Itoa(FormatVersion)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// Open opens an existing snapshot file
+func Open(path string) (*Store, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	store := &Store{conn: conn}
+
+	info, err := store.Info(context.Background())
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if info.FormatVersion != FormatVersion {
+		conn.Close()
+		return nil, fmt.Errorf("snapshot %s has format version %d, expected %d; export it again", path, info.FormatVersion, FormatVersion)
+	}
+	return store, nil
+}
+
+// Close closes the snapshot file
+func (s *Store) Close() error {
+	return s.conn.Close()
+}
+
+// Info returns the metadata recorded when the snapshot was exported
+func (s *Store) Info(ctx context.Context) (*Info, error) {
+	rows, err := s.conn.QueryContext(ctx, `SELECT key, value FROM snapshot_info`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot info: %w", err)
+	}
+	defer rows.Close()
+
+	info := &Info{}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot info: %w", err)
+		}
+		switch key {
+		case "format_version":
+			info.FormatVersion, _ = strconv.Atoi(value)
+		case "source":
+			info.Source = value
+		case "created_at":
+			info.CreatedAt, _ = time.Parse(time.RFC3339, value)
+		}
+	}
+	return info, rows.Err()
+}
+
+func (s *Store) setInfo(ctx context.Context, key, value string) error {
+	if _, err := s.conn.ExecContext(ctx,
+		`INSERT INTO snapshot_info (key, value) VALUES (?, ?)
+		 ON CONFLICT (key) DO UPDATE SET value = excluded.value`, key, value,
+	); err != nil {
+		return fmt.Errorf("failed to write snapshot info: %w", err)
+	}
+	return nil
+}
+
+// pricingColumns are the normalized pricing columns written by insertPricing and read by
+// scanPricing, shared by the live and history tables
+const pricingColumns = `id, provider, provider_service_code, provider_sku, service_mapping_id,
+	service_category, service_family, service_type, region_id,
+	normalized_region, provider_region, resource_name, resource_description,
+	resource_specs, price_per_unit, unit, currency, pricing_model,
+	pricing_details, effective_date, expiration_date, minimum_commitment,
+	aws_raw_id, azure_raw_id, price_tiers, operating_system, license_model,
+	pre_installed_software, tenancy, original_unit, unit_multiplier,
+	created_at, updated_at`
+
+const pricingPlaceholders = `?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?`
+
+// pricingValues returns the values of pricingColumns for a normalized price
+func pricingValues(pricing database.NormalizedPricing) ([]interface{}, error) {
+	resourceSpecs, err := json.Marshal(pricing.ResourceSpecs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resource specs for ID %d: %w", pricing.ID, err)
+	}
+	pricingDetails, err := json.Marshal(pricing.PricingDetails)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal pricing details for ID %d: %w", pricing.ID, err)
+	}
+	var priceTiers interface{}
+	if len(pricing.PriceTiers) > 0 {
+		tiers, err := json.Marshal(pricing.PriceTiers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal price tiers for ID %d: %w", pricing.ID, err)
+		}
+		priceTiers = string(tiers)
+	}
+
+	return []interface{}{
+		pricing.ID,
+		pricing.Provider,
+		pricing.ProviderServiceCode,
+		pricing.ProviderSKU,
+		pricing.ServiceMappingID,
+		pricing.ServiceCategory,
+		pricing.ServiceFamily,
+		pricing.ServiceType,
+		pricing.RegionID,
+		pricing.NormalizedRegion,
+		pricing.ProviderRegion,
+		pricing.ResourceName,
+		pricing.ResourceDescription,
+		string(resourceSpecs),
+		pricing.PricePerUnit,
+		pricing.Unit,
+		pricing.Currency,
+		pricing.PricingModel,
+		string(pricingDetails),
+		formatDate(pricing.EffectiveDate),
+		formatDate(pricing.ExpirationDate),
+		pricing.MinimumCommitment,
+		pricing.AWSRawID,
+		pricing.AzureRawID,
+		priceTiers,
+		pricing.OperatingSystem,
+		pricing.LicenseModel,
+		pricing.PreInstalledSoftware,
+		pricing.Tenancy,
+		pricing.OriginalUnit,
+		pricing.UnitMultiplier,
+		formatTimestamp(pricing.CreatedAt),
+		formatTimestamp(pricing.UpdatedAt),
+	}, nil
+}
+
+// scanPricing scans a row selected with pricingColumns, followed by any extra columns
+func scanPricing(rows *sql.Rows, extra ...interface{}) (database.NormalizedPricing, error) {
+	var pricing database.NormalizedPricing
+	var resourceSpecs, pricingDetails, priceTiers sql.NullString
+	var unitMultiplier sql.NullFloat64
+
+	dest := []interface{}{
+		&pricing.ID,
+		&pricing.Provider,
+		&pricing.ProviderServiceCode,
+		&pricing.ProviderSKU,
+		&pricing.ServiceMappingID,
+		&pricing.ServiceCategory,
+		&pricing.ServiceFamily,
+		&pricing.ServiceType,
+		&pricing.RegionID,
+		&pricing.NormalizedRegion,
+		&pricing.ProviderRegion,
+		&pricing.ResourceName,
+		&pricing.ResourceDescription,
+		&resourceSpecs,
+		&pricing.PricePerUnit,
+		&pricing.Unit,
+		&pricing.Currency,
+		&pricing.PricingModel,
+		&pricingDetails,
+		&pricing.EffectiveDate,
+		&pricing.ExpirationDate,
+		&pricing.MinimumCommitment,
+		&pricing.AWSRawID,
+		&pricing.AzureRawID,
+		&priceTiers,
+		&pricing.OperatingSystem,
+		&pricing.LicenseModel,
+		&pricing.PreInstalledSoftware,
+		&pricing.Tenancy,
+		&pricing.OriginalUnit,
+		&unitMultiplier,
+		&pricing.CreatedAt,
+		&pricing.UpdatedAt,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return pricing, fmt.Errorf("failed to scan pricing record: %w", err)
+	}
+
+	pricing.UnitMultiplier = 1
+	if unitMultiplier.Valid {
+		pricing.UnitMultiplier = unitMultiplier.Float64
+	}
+	if resourceSpecs.Valid {
+		if err := json.Unmarshal([]byte(resourceSpecs.String), &pricing.ResourceSpecs); err != nil {
+			return pricing, fmt.Errorf("failed to unmarshal resource specs for ID %d: %w", pricing.ID, err)
+		}
+	}
+	if pricingDetails.Valid {
+		if err := json.Unmarshal([]byte(pricingDetails.String), &pricing.PricingDetails); err != nil {
+			return pricing, fmt.Errorf("failed to unmarshal pricing details for ID %d: %w", pricing.ID, err)
+		}
+	}
+	if priceTiers.Valid {
+		if err := json.Unmarshal([]byte(priceTiers.String), &pricing.PriceTiers); err != nil {
+			return pricing, fmt.Errorf("failed to unmarshal price tiers for ID %d: %w", pricing.ID, err)
+		}
+	}
+	return pricing, nil
+}
+
+func formatDate(date *time.Time) interface{} {
+	if date == nil {
+		return nil
+	}
+	return date.UTC().Format(dateLayout)
+}
+
+func formatTimestamp(moment time.Time) string {
+	return moment.UTC().Format(timestampLayout)
+}
+
+// errNotFound reports whether a single-row query found nothing
+func errNotFound(err error) bool {
+	return errors.Is(err, sql.ErrNoRows)
+}
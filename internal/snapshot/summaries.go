@@ -0,0 +1,219 @@
+package snapshot
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+)
+
+// inClause returns "column IN (?, ...)" for the values and appends them to args
+func inClause(column string, values []string, args *[]interface{}) string {
+	placeholders := make([]string, len(values))
+	for i, value := range values {
+		placeholders[i] = "?"
+		*args = append(*args, value)
+	}
+	return fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", "))
+}
+
+// GetComputePriceSummaries returns compute summary rows, cheapest first
+func (s *Store) GetComputePriceSummaries(ctx context.Context, filter database.ComputePriceSummaryFilter) ([]database.ComputePriceSummary, error) {
+	where := " WHERE 1=1"
+	args := []interface{}{}
+
+	if len(filter.Providers) > 0 {
+		where += " AND " + inClause("s.provider", filter.Providers, &args)
+	}
+	if len(filter.Regions) > 0 {
+		where += " AND (" + inClause("s.provider_region", filter.Regions, &args) +
+			" OR " + inClause("s.normalized_region", filter.Regions, &args) + ")"
+	}
+	if filter.MinVCPU != nil {
+		where += " AND s.vcpu >= ?"
+		args = append(args, *filter.MinVCPU)
+	}
+	if filter.MaxVCPU != nil {
+		where += " AND s.vcpu <= ?"
+		args = append(args, *filter.MaxVCPU)
+	}
+	if filter.MinMemoryGB != nil {
+		where += " AND s.memory_gb >= ?"
+		args = append(args, *filter.MinMemoryGB)
+	}
+	if filter.MaxMemoryGB != nil {
+		where += " AND s.memory_gb <= ?"
+		args = append(args, *filter.MaxMemoryGB)
+	}
+	if filter.Architecture != nil {
+		where += " AND s.architecture = ?"
+		args = append(args, database.NormalizeArchitecture(*filter.Architecture))
+	}
+	if filter.OperatingSystem != nil {
+		where += " AND s.operating_system = ?"
+		args = append(args, *filter.OperatingSystem)
+	}
+	if filter.Currency != nil {
+		where += " AND s.currency = ?"
+		args = append(args, *filter.Currency)
+	}
+
+	columns := `s.provider, s.provider_region, s.normalized_region,
+		COALESCE(r.display_name, s.normalized_region) AS region_name, s.vcpu, s.memory_bucket_gb,
+		s.architecture, s.operating_system, s.currency, s.resource_name, s.provider_sku, s.memory_gb,
+		s.price_per_hour, s.offer_count, s.refreshed_at`
+	from := ` FROM compute_price_summary s
+		LEFT JOIN normalized_regions r ON r.normalized_code = s.normalized_region`
+
+	var query string
+	if filter.CheapestPerRegion {
+		query = fmt.Sprintf(`SELECT provider, provider_region, normalized_region, region_name, vcpu,
+				memory_bucket_gb, architecture, operating_system, currency, resource_name, provider_sku,
+				memory_gb, price_per_hour, offer_count, refreshed_at
+			FROM (
+				SELECT %s, ROW_NUMBER() OVER (PARTITION BY s.provider, s.provider_region ORDER BY s.price_per_hour) AS rank%s%s
+			) cheapest WHERE rank = 1 ORDER BY price_per_hour`, columns, from, where)
+	} else {
+		query = fmt.Sprintf(`SELECT %s%s%s ORDER BY s.price_per_hour, s.provider, s.provider_region`, columns, from, where)
+	}
+	if filter.Limit != nil {
+		query += " LIMIT ?"
+		args = append(args, *filter.Limit)
+	}
+
+	rows, err := s.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query compute price summary: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []database.ComputePriceSummary
+	for rows.Next() {
+		var summary database.ComputePriceSummary
+		var providerSKU sql.NullString
+		err := rows.Scan(&summary.Provider, &summary.ProviderRegion, &summary.NormalizedRegion,
+			&summary.RegionName, &summary.VCPU, &summary.MemoryBucketGB, &summary.Architecture,
+			&summary.OperatingSystem, &summary.Currency, &summary.ResourceName, &providerSKU,
+			&summary.MemoryGB, &summary.PricePerHour, &summary.OfferCount, &summary.RefreshedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan compute price summary: %w", err)
+		}
+		if providerSKU.Valid {
+			summary.ProviderSKU = &providerSKU.String
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read compute price summary: %w", err)
+	}
+	return summaries, nil
+}
+
+// GetRegionPriceIndices returns price index rows, cheapest regions first
+func (s *Store) GetRegionPriceIndices(ctx context.Context, filter database.RegionPriceIndexFilter) ([]database.RegionPriceIndex, error) {
+	query := `
+		SELECT i.provider, i.provider_region, i.normalized_region, COALESCE(r.display_name, i.normalized_region),
+		       i.service_category, i.unit, i.currency, i.price_index, i.item_count, i.median_price,
+		       i.min_price, i.refreshed_at
+		FROM region_price_index i
+		LEFT JOIN normalized_regions r ON r.normalized_code = i.normalized_region
+		WHERE 1=1`
+	args := []interface{}{}
+
+	if len(filter.Providers) > 0 {
+		query += " AND " + inClause("i.provider", filter.Providers, &args)
+	}
+	if len(filter.Regions) > 0 {
+		query += " AND (" + inClause("i.provider_region", filter.Regions, &args) +
+			" OR " + inClause("i.normalized_region", filter.Regions, &args) + ")"
+	}
+	addFilter := func(column string, value *string) {
+		if value != nil {
+			query += fmt.Sprintf(" AND %s = ?", column)
+			args = append(args, *value)
+		}
+	}
+	addFilter("i.service_category", filter.ServiceCategory)
+	addFilter("i.unit", filter.Unit)
+	addFilter("i.currency", filter.Currency)
+	query += " ORDER BY i.service_category, i.unit, i.price_index, i.provider, i.provider_region"
+
+	rows, err := s.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query region price index: %w", err)
+	}
+	defer rows.Close()
+
+	var indices []database.RegionPriceIndex
+	for rows.Next() {
+		var index database.RegionPriceIndex
+		err := rows.Scan(&index.Provider, &index.ProviderRegion, &index.NormalizedRegion, &index.RegionName,
+			&index.ServiceCategory, &index.Unit, &index.Currency, &index.PriceIndex, &index.ItemCount,
+			&index.MedianPrice, &index.MinPrice, &index.RefreshedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan region price index: %w", err)
+		}
+		indices = append(indices, index)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read region price index: %w", err)
+	}
+	return indices, nil
+}
+
+// GetExchangeRate retrieves the latest rate for a currency pair that is effective on the
+// given date. It returns nil if no such rate exists.
+func (s *Store) GetExchangeRate(ctx context.Context, baseCurrency, quoteCurrency string, asOf time.Time) (*database.ExchangeRate, error) {
+	var rate database.ExchangeRate
+	err := s.conn.QueryRowContext(ctx, `
+		SELECT base_currency, quote_currency, rate, effective_date, source
+		FROM exchange_rates
+		WHERE base_currency = ? AND quote_currency = ? AND effective_date <= ?
+		ORDER BY effective_date DESC
+		LIMIT 1`, baseCurrency, quoteCurrency, asOf.UTC().Format(dateLayout),
+	).Scan(&rate.BaseCurrency, &rate.QuoteCurrency, &rate.Rate, &rate.EffectiveDate, &rate.Source)
+	if errNotFound(err) {
+		return nil, nil // Not found
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exchange rate %s/%s: %w", baseCurrency, quoteCurrency, err)
+	}
+	return &rate, nil
+}
+
+// UpsertExchangeRates stores exchange rates, replacing existing rates for the same currency
+// pair and effective date. The currency service uses it to refresh rates in a snapshot.
+func (s *Store) UpsertExchangeRates(ctx context.Context, rates []database.ExchangeRate) (int, error) {
+	if len(rates) == 0 {
+		return 0, nil
+	}
+
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin exchange rate transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, rate := range rates {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO exchange_rates (base_currency, quote_currency, rate, effective_date, source)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (base_currency, quote_currency, effective_date) DO UPDATE SET
+				rate = excluded.rate,
+				source = excluded.source`,
+			rate.BaseCurrency, rate.QuoteCurrency, rate.Rate, rate.EffectiveDate.UTC().Format(dateLayout), rate.Source,
+		); err != nil {
+			return 0, fmt.Errorf("failed to upsert exchange rate %s/%s: %w", rate.BaseCurrency, rate.QuoteCurrency, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit exchange rates: %w", err)
+	}
+	return len(rates), nil
+}
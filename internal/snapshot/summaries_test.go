@@ -0,0 +1,77 @@
+package snapshot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/raulc0399/cpc/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSummary is a Linux compute summary row of a provider region
+func testSummary(provider, providerRegion, normalizedRegion, name string, vcpu int, memoryGB, price float64, architecture string) database.ComputePriceSummary {
+	return database.ComputePriceSummary{
+		Provider: provider, ProviderRegion: providerRegion, NormalizedRegion: normalizedRegion,
+		VCPU: vcpu, MemoryBucketGB: memoryGB, Architecture: architecture, OperatingSystem: "Linux",
+		Currency: "USD", ResourceName: name, MemoryGB: memoryGB, PricePerHour: price, OfferCount: 1,
+		RefreshedAt: time.Date(2026, 3, 1, 6, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestStore_GetComputePriceSummaries(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	require.NoError(t, store.insertRegions(ctx, []database.NormalizedRegion{
+		{ID: 1, NormalizedCode: "us-east", DisplayName: "US East"},
+	}))
+	require.NoError(t, store.insertComputeSummaries(ctx, []database.ComputePriceSummary{
+		testSummary(database.ProviderAWS, "us-east-1", "us-east", "m5.xlarge", 4, 16, 0.192, "x86_64"),
+		testSummary(database.ProviderAWS, "us-east-1", "us-east", "t4g.medium", 2, 4, 0.0336, "arm64"),
+		testSummary(database.ProviderAWS, "eu-west-1", "eu-west", "m5.xlarge", 4, 16, 0.214, "x86_64"),
+		testSummary(database.ProviderAzure, "eastus", "us-east", "D4s_v5", 4, 16, 0.188, "x86_64"),
+		testSummary(database.ProviderAzure, "eastus", "us-east", "D8s_v5", 8, 32, 0.384, "x86_64"),
+	}))
+
+	tests := []struct {
+		name          string
+		filter        database.ComputePriceSummaryFilter
+		expectedNames []string
+	}{
+		{"cheapest first", database.ComputePriceSummaryFilter{}, []string{"t4g.medium", "D4s_v5", "m5.xlarge", "m5.xlarge", "D8s_v5"}},
+		{"providers", database.ComputePriceSummaryFilter{Providers: []string{database.ProviderAzure}}, []string{"D4s_v5", "D8s_v5"}},
+		{"provider region", database.ComputePriceSummaryFilter{Regions: []string{"eu-west-1"}}, []string{"m5.xlarge"}},
+		{"normalized region", database.ComputePriceSummaryFilter{Regions: []string{"us-east"}, MinVCPU: intPtr(4)},
+			[]string{"D4s_v5", "m5.xlarge", "D8s_v5"}},
+		{"vCPU and memory range", database.ComputePriceSummaryFilter{MaxVCPU: intPtr(4), MinMemoryGB: float64Ptr(8), MaxMemoryGB: float64Ptr(16)},
+			[]string{"D4s_v5", "m5.xlarge", "m5.xlarge"}},
+		{"architecture alias", database.ComputePriceSummaryFilter{Architecture: stringPtr("aarch64")}, []string{"t4g.medium"}},
+		{"other currency", database.ComputePriceSummaryFilter{Currency: stringPtr("EUR")}, nil},
+		{"cheapest per region", database.ComputePriceSummaryFilter{CheapestPerRegion: true}, []string{"t4g.medium", "D4s_v5", "m5.xlarge"}},
+		{"cheapest per region of the matches", database.ComputePriceSummaryFilter{CheapestPerRegion: true, MinVCPU: intPtr(4), Limit: intPtr(2)},
+			[]string{"D4s_v5", "m5.xlarge"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summaries, err := store.GetComputePriceSummaries(ctx, tt.filter)
+			require.NoError(t, err)
+
+			var names []string
+			for _, summary := range summaries {
+				names = append(names, summary.ResourceName)
+			}
+			assert.Equal(t, tt.expectedNames, names)
+		})
+	}
+
+	// Regions without a display name fall back to their normalized code
+	summaries, err := store.GetComputePriceSummaries(ctx, database.ComputePriceSummaryFilter{CheapestPerRegion: true})
+	require.NoError(t, err)
+	regionNames := map[string]string{}
+	for _, summary := range summaries {
+		regionNames[summary.ProviderRegion] = summary.RegionName
+	}
+	assert.Equal(t, map[string]string{"us-east-1": "US East", "eastus": "US East", "eu-west-1": "eu-west"}, regionNames)
+}